@@ -260,8 +260,9 @@ type Model struct {
 	configWizard         *ConfigWizardState
 
 	// Northstar Wizard State
-	awaitingNorthstar bool
-	northstarWizard   *NorthstarWizardState
+	awaitingNorthstar             bool
+	northstarWizard               *NorthstarWizardState
+	northstarRequirementPartialCh chan requirementPartialMsg // Real-time partial requirements from streaming generation
 
 	// Onboarding Wizard State (first-run experience)
 	awaitingOnboarding bool
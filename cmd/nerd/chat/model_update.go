@@ -717,6 +717,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.SetContent(m.renderHistory())
 		m.viewport.GotoBottom()
 
+	case requirementsStreamStartedMsg:
+		m.northstarRequirementPartialCh = msg.ch
+		return m, m.listenRequirementPartial()
+
+	case requirementPartialMsg:
+		if msg.err != nil {
+			m.isLoading = false
+			m.history = append(m.history, Message{
+				Role:    "assistant",
+				Content: fmt.Sprintf("⚠️ Requirement generation encountered an error: %v\n\nYou can add requirements manually.", msg.err),
+				Time:    time.Now(),
+			})
+			m.viewport.SetContent(m.renderHistory())
+			m.viewport.GotoBottom()
+			break
+		}
+		if msg.done {
+			m.isLoading = false
+			m.textarea.Placeholder = "Add requirement or 'done'..."
+			break
+		}
+		if msg.requirement != nil && m.northstarWizard != nil {
+			m.northstarWizard.Requirements = append(m.northstarWizard.Requirements, *msg.requirement)
+			m.history = append(m.history, Message{
+				Role:    "assistant",
+				Content: fmt.Sprintf("- **%s** [%s]: %s", msg.requirement.ID, msg.requirement.Priority, msg.requirement.Description),
+				Time:    time.Now(),
+			})
+			m.viewport.SetContent(m.renderHistory())
+			m.viewport.GotoBottom()
+		}
+		return m, m.listenRequirementPartial()
+
 	// Campaign message handlers
 	case campaignStartedMsg:
 		m.isLoading = false
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"codenerd/internal/config"
+	"codenerd/internal/perception"
 	"codenerd/internal/prompt"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,55 +21,53 @@ import (
 // NORTHSTAR LLM INTEGRATION
 // =============================================================================
 
-// generateRequirementsWithLLM uses the LLM to intelligently generate requirements.
-func (m Model) generateRequirementsWithLLM() tea.Cmd {
-	return func() tea.Msg {
-		w := m.northstarWizard
-
-		// Build context for LLM
-		var contextBuilder strings.Builder
-		contextBuilder.WriteString("## Project Vision\n")
-		contextBuilder.WriteString(fmt.Sprintf("Mission: %s\n\n", w.Mission))
-		contextBuilder.WriteString(fmt.Sprintf("Problem: %s\n\n", w.Problem))
-		contextBuilder.WriteString(fmt.Sprintf("Vision: %s\n\n", w.Vision))
-
-		if len(w.Capabilities) > 0 {
-			contextBuilder.WriteString("## Capabilities\n")
-			for _, cap := range w.Capabilities {
-				contextBuilder.WriteString(fmt.Sprintf("- [%s/%s] %s\n", cap.Timeline, cap.Priority, cap.Description))
-			}
-			contextBuilder.WriteString("\n")
+// requirementsContext builds the shared prompt context describing the
+// wizard's current vision, capabilities, risks, personas, and research
+// insights, used by both the blocking and streaming requirement generators.
+func requirementsContext(w *NorthstarWizardState) string {
+	var contextBuilder strings.Builder
+	contextBuilder.WriteString("## Project Vision\n")
+	contextBuilder.WriteString(fmt.Sprintf("Mission: %s\n\n", w.Mission))
+	contextBuilder.WriteString(fmt.Sprintf("Problem: %s\n\n", w.Problem))
+	contextBuilder.WriteString(fmt.Sprintf("Vision: %s\n\n", w.Vision))
+
+	if len(w.Capabilities) > 0 {
+		contextBuilder.WriteString("## Capabilities\n")
+		for _, cap := range w.Capabilities {
+			contextBuilder.WriteString(fmt.Sprintf("- [%s/%s] %s\n", cap.Timeline, cap.Priority, cap.Description))
 		}
+		contextBuilder.WriteString("\n")
+	}
 
-		if len(w.Risks) > 0 {
-			contextBuilder.WriteString("## Risks\n")
-			for _, risk := range w.Risks {
-				contextBuilder.WriteString(fmt.Sprintf("- [%s/%s] %s\n", risk.Likelihood, risk.Impact, risk.Description))
-				if risk.Mitigation != "" && risk.Mitigation != "none" {
-					contextBuilder.WriteString(fmt.Sprintf("  Mitigation: %s\n", risk.Mitigation))
-				}
+	if len(w.Risks) > 0 {
+		contextBuilder.WriteString("## Risks\n")
+		for _, risk := range w.Risks {
+			contextBuilder.WriteString(fmt.Sprintf("- [%s/%s] %s\n", risk.Likelihood, risk.Impact, risk.Description))
+			if risk.Mitigation != "" && risk.Mitigation != "none" {
+				contextBuilder.WriteString(fmt.Sprintf("  Mitigation: %s\n", risk.Mitigation))
 			}
-			contextBuilder.WriteString("\n")
 		}
+		contextBuilder.WriteString("\n")
+	}
 
-		if len(w.Personas) > 0 {
-			contextBuilder.WriteString("## User Personas\n")
-			for _, p := range w.Personas {
-				contextBuilder.WriteString(fmt.Sprintf("- %s\n", p.Name))
-				contextBuilder.WriteString(fmt.Sprintf("  Needs: %s\n", strings.Join(p.Needs, ", ")))
-			}
-			contextBuilder.WriteString("\n")
+	if len(w.Personas) > 0 {
+		contextBuilder.WriteString("## User Personas\n")
+		for _, p := range w.Personas {
+			contextBuilder.WriteString(fmt.Sprintf("- %s\n", p.Name))
+			contextBuilder.WriteString(fmt.Sprintf("  Needs: %s\n", strings.Join(p.Needs, ", ")))
 		}
+		contextBuilder.WriteString("\n")
+	}
 
-		if len(w.ExtractedFacts) > 0 {
-			contextBuilder.WriteString("## Research Insights\n")
-			for _, fact := range w.ExtractedFacts {
-				contextBuilder.WriteString(fmt.Sprintf("- %s\n", fact))
-			}
-			contextBuilder.WriteString("\n")
+	if len(w.ExtractedFacts) > 0 {
+		contextBuilder.WriteString("## Research Insights\n")
+		for _, fact := range w.ExtractedFacts {
+			contextBuilder.WriteString(fmt.Sprintf("- %s\n", fact))
 		}
+		contextBuilder.WriteString("\n")
+	}
 
-		contextBuilder.WriteString(`
+	contextBuilder.WriteString(`
 Based on the above context, generate concrete, actionable requirements.
 
 For each requirement, provide:
@@ -86,19 +85,28 @@ Generate between 5-15 requirements focusing on:
 - User needs from personas
 - Constraints and non-functional requirements (performance, security, usability)`)
 
+	return contextBuilder.String()
+}
+
+// generateRequirementsWithLLM uses the LLM to intelligently generate requirements.
+// It asks for a strict JSON schema via perception.CompleteStructured first, and
+// only falls back to the free-form REQ-NNN|TYPE|PRIORITY line format (and its
+// brittle parseGeneratedRequirements parser) if the client doesn't produce a
+// schema-conformant response.
+func (m Model) generateRequirementsWithLLM() tea.Cmd {
+	return func() tea.Msg {
+		w := m.northstarWizard
+
 		ctx, cancel := context.WithTimeout(context.Background(), northstarLLMTimeout())
 		defer cancel()
 
 		// Build prompt using helper (supports JIT if available)
-		systemPrompt, userPrompt := m.buildNorthstarPrompt(ctx, "requirements", contextBuilder.String())
+		systemPrompt, userPrompt := m.buildNorthstarPrompt(ctx, "requirements", requirementsContext(w))
 
-		response, err := m.client.CompleteWithSystem(ctx, systemPrompt, userPrompt)
+		requirements, err := m.generateRequirementsBlocking(ctx, systemPrompt, userPrompt, len(w.Requirements))
 		if err != nil {
 			return requirementsGeneratedMsg{err: err}
 		}
-
-		// Parse LLM response into requirements
-		requirements := parseGeneratedRequirements(response, len(w.Requirements))
 		return requirementsGeneratedMsg{requirements: requirements}
 	}
 }
@@ -219,6 +227,14 @@ func (m Model) analyzeNorthstarDocs(docPaths []string) tea.Cmd {
 		// Build prompt using helper (supports JIT if available)
 		systemPrompt, userPrompt := m.buildNorthstarPrompt(ctx, "doc_ingestion", docContents.String())
 
+		// Ask for a strict JSON schema first; fall back to the free-form
+		// bullet-line parser if the client doesn't produce conformant JSON.
+		if jsonResponse, err := perception.CompleteStructured(ctx, m.client, systemPrompt, userPrompt, northstarInsightsSchema()); err == nil {
+			if insights, perr := parseStructuredInsights(jsonResponse); perr == nil {
+				return northstarDocsAnalyzedMsg{facts: insights}
+			}
+		}
+
 		response, err := m.client.CompleteWithSystem(ctx, systemPrompt, userPrompt)
 		if err != nil {
 			return northstarDocsAnalyzedMsg{err: err}
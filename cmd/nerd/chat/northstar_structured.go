@@ -0,0 +1,326 @@
+// Package chat provides the interactive TUI chat interface for codeNERD.
+// This file contains the structured-JSON and streaming variants of Northstar
+// requirement/insight generation, layered on top of northstar_llm.go's
+// free-form prompting.
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"codenerd/internal/perception"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// requirementPartialMsg carries one requirement as it arrives from a
+// streaming generation request, or signals completion/failure of the stream.
+type requirementPartialMsg struct {
+	requirement *NorthstarRequirement
+	done        bool
+	err         error
+}
+
+// requirementsStreamStartedMsg carries the channel a streaming requirement
+// generation request will deliver requirementPartialMsg values on, mirroring
+// campaignStartedMsg's progressChan/eventChan handoff in campaign.go.
+type requirementsStreamStartedMsg struct {
+	ch chan requirementPartialMsg
+}
+
+// llmStreamingChannels mirrors the optional capability interface of the same
+// name in internal/perception/transducer.go: concrete LLM clients that can
+// stream implement it, and callers type-assert for it rather than requiring
+// every types.LLMClient implementation to support streaming.
+type llmStreamingChannels interface {
+	CompleteWithStreaming(ctx context.Context, systemPrompt, userPrompt string, enableThinking bool) (<-chan string, <-chan error)
+}
+
+// structuredRequirement is the wire shape of one element of the
+// "requirements" array in northstarRequirementsSchema.
+type structuredRequirement struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Priority    string `json:"priority"`
+	Description string `json:"description"`
+	Rationale   string `json:"rationale"`
+}
+
+// northstarRequirementsSchema is the JSON Schema passed to
+// perception.CompleteStructured for requirement generation.
+func northstarRequirementsSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"requirements": map[string]interface{}{
+				"type":  "array",
+				"items": requirementItemSchema(),
+			},
+		},
+		"required":             []string{"requirements"},
+		"additionalProperties": false,
+	}
+}
+
+// requirementItemSchema is the schema for a single requirement object, shared
+// between the batch and streaming schemas.
+func requirementItemSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":          map[string]interface{}{"type": "string"},
+			"type":        map[string]interface{}{"type": "string"},
+			"priority":    map[string]interface{}{"type": "string"},
+			"description": map[string]interface{}{"type": "string"},
+			"rationale":   map[string]interface{}{"type": "string"},
+		},
+		"required":             []string{"type", "priority", "description"},
+		"additionalProperties": false,
+	}
+}
+
+// northstarInsightsSchema is the JSON Schema passed to
+// perception.CompleteStructured for document insight extraction.
+func northstarInsightsSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"insights": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+		},
+		"required":             []string{"insights"},
+		"additionalProperties": false,
+	}
+}
+
+// parseStructuredRequirements decodes a perception.CompleteStructured
+// response validated against northstarRequirementsSchema into
+// NorthstarRequirements, assigning sequential IDs where the model omitted one.
+func parseStructuredRequirements(jsonResponse string, startIdx int) ([]NorthstarRequirement, error) {
+	var payload struct {
+		Requirements []structuredRequirement `json:"requirements"`
+	}
+	if err := json.Unmarshal([]byte(jsonResponse), &payload); err != nil {
+		return nil, fmt.Errorf("decode structured requirements: %w", err)
+	}
+
+	requirements := make([]NorthstarRequirement, 0, len(payload.Requirements))
+	for i, r := range payload.Requirements {
+		requirements = append(requirements, structuredRequirementToNorthstar(r, startIdx+i))
+	}
+	return requirements, nil
+}
+
+func structuredRequirementToNorthstar(r structuredRequirement, idx int) NorthstarRequirement {
+	id := r.ID
+	if id == "" {
+		id = fmt.Sprintf("REQ-%03d", idx+1)
+	}
+	return NorthstarRequirement{
+		ID:          id,
+		Type:        r.Type,
+		Description: r.Description,
+		Priority:    r.Priority,
+		Source:      "auto-generated",
+	}
+}
+
+// parseStructuredInsights decodes a perception.CompleteStructured response
+// validated against northstarInsightsSchema.
+func parseStructuredInsights(jsonResponse string) ([]string, error) {
+	var payload struct {
+		Insights []string `json:"insights"`
+	}
+	if err := json.Unmarshal([]byte(jsonResponse), &payload); err != nil {
+		return nil, fmt.Errorf("decode structured insights: %w", err)
+	}
+	return payload.Insights, nil
+}
+
+// generateRequirementsWithLLMStreaming is the streaming counterpart to
+// generateRequirementsWithLLM: it returns a tea.Cmd that launches the
+// generation in the background and reports requirements one at a time via
+// requirementPartialMsg, instead of blocking the UI for the whole response.
+// If m.client doesn't implement llmStreamingChannels, it falls back to a
+// single blocking structured-then-regex generation and delivers the result
+// as one batch of partial messages, so callers don't need a separate
+// non-streaming path.
+func (m Model) generateRequirementsWithLLMStreaming() tea.Cmd {
+	return func() tea.Msg {
+		ch := make(chan requirementPartialMsg, 16)
+		go m.runRequirementsStream(ch)
+		return requirementsStreamStartedMsg{ch: ch}
+	}
+}
+
+// listenRequirementPartial returns a tea.Cmd that blocks for the next
+// requirementPartialMsg on m.northstarRequirementPartialCh, mirroring
+// listenCampaignProgress in campaign.go. The Update handler must re-issue
+// this command after each non-terminal message to keep listening.
+func (m Model) listenRequirementPartial() tea.Cmd {
+	if m.northstarRequirementPartialCh == nil {
+		return nil
+	}
+	ch := m.northstarRequirementPartialCh
+
+	return func() tea.Msg {
+		partial, ok := <-ch
+		if !ok {
+			return requirementPartialMsg{done: true}
+		}
+		return partial
+	}
+}
+
+// runRequirementsStream does the actual generation work for
+// generateRequirementsWithLLMStreaming, sending partial results on ch and
+// closing it when done.
+func (m Model) runRequirementsStream(ch chan<- requirementPartialMsg) {
+	defer close(ch)
+
+	w := m.northstarWizard
+	if w == nil || m.client == nil {
+		ch <- requirementPartialMsg{err: fmt.Errorf("northstar wizard or LLM client not available")}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), northstarLLMTimeout())
+	defer cancel()
+
+	systemPrompt, userPrompt := m.buildNorthstarPrompt(ctx, "requirements", requirementsContext(w))
+	startIdx := len(w.Requirements)
+
+	streamer, ok := m.client.(llmStreamingChannels)
+	if !ok {
+		requirements, err := m.generateRequirementsBlocking(ctx, systemPrompt, userPrompt, startIdx)
+		if err != nil {
+			ch <- requirementPartialMsg{err: err}
+			return
+		}
+		for i := range requirements {
+			ch <- requirementPartialMsg{requirement: &requirements[i]}
+		}
+		return
+	}
+
+	schemaJSON, err := json.MarshalIndent(northstarRequirementsSchema(), "", "  ")
+	if err != nil {
+		ch <- requirementPartialMsg{err: fmt.Errorf("marshal requirements schema: %w", err)}
+		return
+	}
+	augmentedPrompt := userPrompt + perception.StructuredOutputInstructions(string(schemaJSON))
+
+	textChan, errChan := streamer.CompleteWithStreaming(ctx, systemPrompt, augmentedPrompt, false)
+
+	scanner := newIncrementalJSONObjectScanner()
+	idx := startIdx
+	for textChan != nil || errChan != nil {
+		select {
+		case chunk, ok := <-textChan:
+			if !ok {
+				textChan = nil
+				continue
+			}
+			for _, objJSON := range scanner.feed(chunk) {
+				var r structuredRequirement
+				if json.Unmarshal([]byte(objJSON), &r) != nil {
+					continue // malformed element; skip rather than abort the stream
+				}
+				req := structuredRequirementToNorthstar(r, idx)
+				idx++
+				ch <- requirementPartialMsg{requirement: &req}
+			}
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			if err != nil {
+				ch <- requirementPartialMsg{err: err}
+				return
+			}
+		}
+	}
+}
+
+// generateRequirementsBlocking performs one non-streaming structured
+// generation call, falling back to the free-form regex parser if the client
+// doesn't produce schema-conformant JSON.
+func (m Model) generateRequirementsBlocking(ctx context.Context, systemPrompt, userPrompt string, startIdx int) ([]NorthstarRequirement, error) {
+	jsonResponse, err := perception.CompleteStructured(ctx, m.client, systemPrompt, userPrompt, northstarRequirementsSchema())
+	if err == nil {
+		if requirements, perr := parseStructuredRequirements(jsonResponse, startIdx); perr == nil {
+			return requirements, nil
+		}
+	}
+
+	response, err := m.client.CompleteWithSystem(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return nil, err
+	}
+	return parseGeneratedRequirements(response, startIdx), nil
+}
+
+// incrementalJSONObjectScanner extracts complete top-level JSON objects from
+// a streamed `{"requirements": [ {...}, {...} ]}`-shaped response as they
+// close, without waiting for the whole array to finish. It assumes the
+// objects of interest are exactly one brace-level below the outer wrapper
+// object and contain no nested objects of their own, which holds for
+// requirementItemSchema's flat string fields.
+type incrementalJSONObjectScanner struct {
+	buf      []byte
+	depth    int
+	objStart int
+	inString bool
+	escaped  bool
+}
+
+func newIncrementalJSONObjectScanner() *incrementalJSONObjectScanner {
+	return &incrementalJSONObjectScanner{}
+}
+
+// feed appends chunk to the scanner and returns the JSON text of any
+// requirement objects that closed within it.
+func (s *incrementalJSONObjectScanner) feed(chunk string) []string {
+	var objects []string
+
+	for i := 0; i < len(chunk); i++ {
+		c := chunk[i]
+		s.buf = append(s.buf, c)
+		pos := len(s.buf) - 1
+
+		if s.escaped {
+			s.escaped = false
+			continue
+		}
+		if s.inString {
+			switch c {
+			case '\\':
+				s.escaped = true
+			case '"':
+				s.inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			s.inString = true
+		case '{':
+			s.depth++
+			if s.depth == 2 {
+				s.objStart = pos
+			}
+		case '}':
+			if s.depth == 2 {
+				objects = append(objects, string(s.buf[s.objStart:pos+1]))
+			}
+			s.depth--
+		}
+	}
+
+	return objects
+}
@@ -1,273 +1,285 @@
-// Package main implements the codeNERD CLI commands.
-// This file contains browser automation commands.
-package main
-
-import (
-	"context"
-	"fmt"
-	"os"
-	"os/signal"
-	"path/filepath"
-	"strings"
-	"syscall"
-	"time"
-
-	"codenerd/internal/browser"
-	"codenerd/internal/logging"
-	"codenerd/internal/mangle"
-
-	"github.com/spf13/cobra"
-	"go.uber.org/zap"
-)
-
-// =============================================================================
-// BROWSER COMMANDS - Browser automation (§9.0 Browser Physics)
-// =============================================================================
-
-// browserCmd manages browser sessions (§9.0 Browser Physics)
-var browserCmd = &cobra.Command{
-	Use:   "browser",
-	Short: "Browser automation commands (DOM snapshotting, React reification)",
-}
-
-var browserLaunchCmd = &cobra.Command{
-	Use:   "launch",
-	Short: "Launch the browser instance",
-	RunE:  browserLaunch,
-}
-
-var browserSessionCmd = &cobra.Command{
-	Use:   "session [url]",
-	Short: "Create a new browser session",
-	Args:  cobra.ExactArgs(1),
-	RunE:  browserSession,
-}
-
-var browserSnapshotCmd = &cobra.Command{
-	Use:   "snapshot [session-id]",
-	Short: "Snapshot DOM as Mangle facts",
-	Args:  cobra.ExactArgs(1),
-	RunE:  browserSnapshot,
-}
-
-// getBrowserConfig returns browser config with persistent session store
-func getBrowserConfig() browser.Config {
-	cwd, _ := os.Getwd()
-	cfg := browser.DefaultConfig()
-	cfg.SessionStore = filepath.Join(cwd, ".nerd", "browser", "sessions.json")
-	return cfg
-}
-
-// browserLaunch launches the browser instance
-func browserLaunch(cmd *cobra.Command, args []string) error {
-	logger.Info("Launching browser")
-
-	// Initialize browser session manager with persistent store
-	cfg := getBrowserConfig()
-	engine, err := mangle.NewEngine(mangle.DefaultConfig(), nil)
-	if err != nil {
-		return fmt.Errorf("failed to create mangle engine: %w", err)
-	}
-
-	mgr := browser.NewSessionManager(cfg, engine)
-
-	// Start the session manager (loads persisted sessions)
-	if err := mgr.Start(context.Background()); err != nil {
-		return fmt.Errorf("failed to start session manager: %w", err)
-	}
-
-	// Write control URL to file for other commands to use
-	cwd, err := os.Getwd()
-	if err != nil {
-		logging.BootWarn("failed to get working directory: %v", err)
-	}
-	controlFile := filepath.Join(cwd, ".nerd", "browser", "control.txt")
-	if err := os.MkdirAll(filepath.Dir(controlFile), 0o755); err == nil {
-		if err := os.WriteFile(controlFile, []byte(mgr.ControlURL()), 0o644); err != nil {
-			logging.BootWarn("failed to write browser control file: %v", err)
-		}
-	}
-
-	fmt.Printf("Browser launched. Control URL: %s\n", mgr.ControlURL())
-	fmt.Printf("Session store: %s\n", cfg.SessionStore)
-	fmt.Println("Press Ctrl+C to shutdown")
-
-	// Wait for shutdown signal
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	<-sigCh
-
-	// Clean up control file
-	if err := os.Remove(controlFile); err != nil && !os.IsNotExist(err) {
-		logging.BootWarn("failed to remove browser control file: %v", err)
-	}
-	if err := mgr.Shutdown(context.Background()); err != nil {
-		logging.BootWarn("failed to shutdown browser manager: %v", err)
-	}
-	return nil
-}
-
-// browserSession creates a new browser session
-func browserSession(cmd *cobra.Command, args []string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	url := args[0]
-	logger.Info("Creating browser session", zap.String("url", url))
-
-	cfg := getBrowserConfig()
-
-	// Try to connect to existing browser first
-	cwd, _ := os.Getwd()
-	controlFile := filepath.Join(cwd, ".nerd", "browser", "control.txt")
-	if controlURL, err := os.ReadFile(controlFile); err == nil && len(controlURL) > 0 {
-		cfg.DebuggerURL = string(controlURL)
-		logger.Info("Connecting to existing browser", zap.String("url", cfg.DebuggerURL))
-	}
-
-	engine, err := mangle.NewEngine(mangle.DefaultConfig(), nil)
-	if err != nil {
-		return fmt.Errorf("failed to create mangle engine: %w", err)
-	}
-
-	mgr := browser.NewSessionManager(cfg, engine)
-	if err := mgr.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start session manager: %w", err)
-	}
-
-	session, err := mgr.CreateSession(ctx, url)
-	if err != nil {
-		// Shutdown only if we launched a new browser
-		if cfg.DebuggerURL == "" {
-			_ = mgr.Shutdown(context.Background())
-		}
-		return fmt.Errorf("failed to create session: %w", err)
-	}
-
-	fmt.Printf("Session created: %s\n", session.ID)
-	fmt.Printf("Target ID: %s\n", session.TargetID)
-	fmt.Printf("URL: %s\n", session.URL)
-	fmt.Printf("\nUse 'nerd browser snapshot %s' to capture DOM facts\n", session.ID)
-
-	// Note: Don't shutdown - leave browser running for snapshot command
-	return nil
-}
-
-// browserSnapshot snapshots DOM as Mangle facts
-func browserSnapshot(cmd *cobra.Command, args []string) error {
-	sessionID := args[0]
-	logger.Info("Snapshotting DOM", zap.String("session", sessionID))
-
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	cfg := getBrowserConfig()
-
-	// Must connect to existing browser
-	cwd, _ := os.Getwd()
-	controlFile := filepath.Join(cwd, ".nerd", "browser", "control.txt")
-	controlURL, err := os.ReadFile(controlFile)
-	if err != nil || len(controlURL) == 0 {
-		return fmt.Errorf("no browser running - use 'nerd browser launch' first")
-	}
-	cfg.DebuggerURL = string(controlURL)
-
-	// Create mangle engine to receive facts
-	engine, err := mangle.NewEngine(mangle.DefaultConfig(), nil)
-	if err != nil {
-		return fmt.Errorf("failed to create mangle engine: %w", err)
-	}
-
-	mgr := browser.NewSessionManager(cfg, engine)
-	if err := mgr.Start(ctx); err != nil {
-		return fmt.Errorf("failed to connect to browser: %w", err)
-	}
-
-	// Look up session
-	session, found := mgr.GetSession(sessionID)
-	if !found {
-		// List available sessions
-		sessions := mgr.List()
-		if len(sessions) == 0 {
-			return fmt.Errorf("session %q not found - no active sessions", sessionID)
-		}
-		fmt.Printf("Session %q not found. Available sessions:\n", sessionID)
-		for _, s := range sessions {
-			fmt.Printf("  %s  [%s] %s\n", s.ID, s.Status, s.URL)
-		}
-		return fmt.Errorf("session not found")
-	}
-
-	// Reattach to the session's target if needed
-	if session.Status == "detached" && session.TargetID != "" {
-		logger.Info("Reattaching to detached session", zap.String("target", session.TargetID))
-		reattached, err := mgr.Attach(ctx, session.TargetID)
-		if err != nil {
-			return fmt.Errorf("failed to reattach to session: %w", err)
-		}
-		sessionID = reattached.ID
-	}
-
-	// Capture DOM facts
-	fmt.Printf("Capturing DOM for session %s...\n", sessionID)
-	if err := mgr.SnapshotDOM(ctx, sessionID); err != nil {
-		return fmt.Errorf("DOM snapshot failed: %w", err)
-	}
-
-	// Also capture React components if available
-	reactFacts, err := mgr.ReifyReact(ctx, sessionID)
-	if err != nil {
-		logger.Info("React reification skipped", zap.Error(err))
-	} else {
-		fmt.Printf("Captured %d React component facts\n", len(reactFacts))
-	}
-
-	// Export facts to file
-	factsDir := filepath.Join(cwd, ".nerd", "browser", "snapshots")
-	if err := os.MkdirAll(factsDir, 0o755); err != nil {
-		return fmt.Errorf("failed to create snapshots dir: %w", err)
-	}
-
-	snapshotFile := filepath.Join(factsDir, fmt.Sprintf("%s_%d.mg", sessionID, time.Now().Unix()))
-
-	// Query for all DOM-related predicates
-	domPredicates := []string{
-		"dom_node", "dom_text", "dom_attr", "dom_layout",
-		"react_component", "react_prop", "react_state", "dom_mapping",
-		"navigation_event", "current_url", "console_event",
-		"net_request", "net_response", "net_header", "request_initiator",
-		"click_event", "input_event", "state_change",
-	}
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("// DOM Snapshot for session %s\n", sessionID))
-	sb.WriteString(fmt.Sprintf("// Captured at %s\n", time.Now().Format(time.RFC3339)))
-	sb.WriteString(fmt.Sprintf("// URL: %s\n\n", session.URL))
-
-	totalFacts := 0
-	for _, pred := range domPredicates {
-		facts, err := engine.GetFacts(pred)
-		if err != nil {
-			continue // Predicate not declared, skip
-		}
-		for _, fact := range facts {
-			sb.WriteString(fact.String())
-			sb.WriteString(".\n")
-			totalFacts++
-		}
-	}
-
-	if totalFacts == 0 {
-		fmt.Println("Warning: No DOM facts captured. The page may not have loaded yet.")
-		fmt.Println("Try waiting for the page to fully load, then run snapshot again.")
-	}
-
-	if err := os.WriteFile(snapshotFile, []byte(sb.String()), 0o644); err != nil {
-		return fmt.Errorf("failed to write snapshot: %w", err)
-	}
-
-	fmt.Printf("DOM snapshot complete:\n")
-	fmt.Printf("  Facts captured: %d\n", totalFacts)
-	fmt.Printf("  Saved to: %s\n", snapshotFile)
-	return nil
-}
+// Package main implements the codeNERD CLI commands.
+// This file contains browser automation commands.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"codenerd/internal/browser"
+	"codenerd/internal/logging"
+	"codenerd/internal/mangle"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// =============================================================================
+// BROWSER COMMANDS - Browser automation (§9.0 Browser Physics)
+// =============================================================================
+
+// browserCmd manages browser sessions (§9.0 Browser Physics)
+var browserCmd = &cobra.Command{
+	Use:   "browser",
+	Short: "Browser automation commands (DOM snapshotting, React reification)",
+}
+
+var browserLaunchCmd = &cobra.Command{
+	Use:   "launch",
+	Short: "Launch the browser instance",
+	RunE:  browserLaunch,
+}
+
+var browserSessionCmd = &cobra.Command{
+	Use:   "session [url]",
+	Short: "Create a new browser session",
+	Args:  cobra.ExactArgs(1),
+	RunE:  browserSession,
+}
+
+var browserSnapshotCmd = &cobra.Command{
+	Use:   "snapshot [session-id]",
+	Short: "Snapshot DOM as Mangle facts",
+	Args:  cobra.ExactArgs(1),
+	RunE:  browserSnapshot,
+}
+
+// browserMaxContexts caps how many isolated incognito browser contexts a
+// SessionManager will host at once (see --browser.max-contexts), so
+// parallel research agents don't collide on a single global session.
+var browserMaxContexts int
+
+func init() {
+	browserCmd.PersistentFlags().IntVar(&browserMaxContexts, "browser.max-contexts", 0, "Max concurrent isolated browser contexts (0 = use browser package default)")
+}
+
+// getBrowserConfig returns browser config with persistent session store
+func getBrowserConfig() browser.Config {
+	cwd, _ := os.Getwd()
+	cfg := browser.DefaultConfig()
+	cfg.SessionStore = filepath.Join(cwd, ".nerd", "browser", "sessions.json")
+	if browserMaxContexts > 0 {
+		cfg.MaxContexts = browserMaxContexts
+	}
+	return cfg
+}
+
+// browserLaunch launches the browser instance
+func browserLaunch(cmd *cobra.Command, args []string) error {
+	logger.Info("Launching browser")
+
+	// Initialize browser session manager with persistent store
+	cfg := getBrowserConfig()
+	engine, err := mangle.NewEngine(mangle.DefaultConfig(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create mangle engine: %w", err)
+	}
+
+	mgr := browser.NewSessionManager(cfg, engine)
+
+	// Start the session manager (loads persisted sessions)
+	if err := mgr.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start session manager: %w", err)
+	}
+
+	// Write control URL to file for other commands to use
+	cwd, err := os.Getwd()
+	if err != nil {
+		logging.BootWarn("failed to get working directory: %v", err)
+	}
+	controlFile := filepath.Join(cwd, ".nerd", "browser", "control.txt")
+	if err := os.MkdirAll(filepath.Dir(controlFile), 0o755); err == nil {
+		if err := os.WriteFile(controlFile, []byte(mgr.ControlURL()), 0o644); err != nil {
+			logging.BootWarn("failed to write browser control file: %v", err)
+		}
+	}
+
+	fmt.Printf("Browser launched. Control URL: %s\n", mgr.ControlURL())
+	fmt.Printf("Session store: %s\n", cfg.SessionStore)
+	fmt.Println("Press Ctrl+C to shutdown")
+
+	// Wait for shutdown signal
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	<-sigCh
+
+	// Clean up control file
+	if err := os.Remove(controlFile); err != nil && !os.IsNotExist(err) {
+		logging.BootWarn("failed to remove browser control file: %v", err)
+	}
+	if err := mgr.Shutdown(context.Background()); err != nil {
+		logging.BootWarn("failed to shutdown browser manager: %v", err)
+	}
+	return nil
+}
+
+// browserSession creates a new browser session
+func browserSession(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	url := args[0]
+	logger.Info("Creating browser session", zap.String("url", url))
+
+	cfg := getBrowserConfig()
+
+	// Try to connect to existing browser first
+	cwd, _ := os.Getwd()
+	controlFile := filepath.Join(cwd, ".nerd", "browser", "control.txt")
+	if controlURL, err := os.ReadFile(controlFile); err == nil && len(controlURL) > 0 {
+		cfg.DebuggerURL = string(controlURL)
+		logger.Info("Connecting to existing browser", zap.String("url", cfg.DebuggerURL))
+	}
+
+	engine, err := mangle.NewEngine(mangle.DefaultConfig(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create mangle engine: %w", err)
+	}
+
+	mgr := browser.NewSessionManager(cfg, engine)
+	if err := mgr.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start session manager: %w", err)
+	}
+
+	session, err := mgr.CreateSession(ctx, url)
+	if err != nil {
+		// Shutdown only if we launched a new browser
+		if cfg.DebuggerURL == "" {
+			_ = mgr.Shutdown(context.Background())
+		}
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	fmt.Printf("Session created: %s\n", session.ID)
+	fmt.Printf("Target ID: %s\n", session.TargetID)
+	fmt.Printf("URL: %s\n", session.URL)
+	fmt.Printf("\nUse 'nerd browser snapshot %s' to capture DOM facts\n", session.ID)
+
+	// Note: Don't shutdown - leave browser running for snapshot command
+	return nil
+}
+
+// browserSnapshot snapshots DOM as Mangle facts
+func browserSnapshot(cmd *cobra.Command, args []string) error {
+	sessionID := args[0]
+	logger.Info("Snapshotting DOM", zap.String("session", sessionID))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cfg := getBrowserConfig()
+
+	// Must connect to existing browser
+	cwd, _ := os.Getwd()
+	controlFile := filepath.Join(cwd, ".nerd", "browser", "control.txt")
+	controlURL, err := os.ReadFile(controlFile)
+	if err != nil || len(controlURL) == 0 {
+		return fmt.Errorf("no browser running - use 'nerd browser launch' first")
+	}
+	cfg.DebuggerURL = string(controlURL)
+
+	// Create mangle engine to receive facts
+	engine, err := mangle.NewEngine(mangle.DefaultConfig(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create mangle engine: %w", err)
+	}
+
+	mgr := browser.NewSessionManager(cfg, engine)
+	if err := mgr.Start(ctx); err != nil {
+		return fmt.Errorf("failed to connect to browser: %w", err)
+	}
+
+	// Look up session
+	session, found := mgr.GetSession(sessionID)
+	if !found {
+		// List available sessions
+		sessions := mgr.List()
+		if len(sessions) == 0 {
+			return fmt.Errorf("session %q not found - no active sessions", sessionID)
+		}
+		fmt.Printf("Session %q not found. Available sessions:\n", sessionID)
+		for _, s := range sessions {
+			fmt.Printf("  %s  [%s] %s\n", s.ID, s.Status, s.URL)
+		}
+		return fmt.Errorf("session not found")
+	}
+
+	// Reattach to the session's target if needed
+	if session.Status == "detached" && session.TargetID != "" {
+		logger.Info("Reattaching to detached session", zap.String("target", session.TargetID))
+		reattached, err := mgr.Attach(ctx, session.TargetID)
+		if err != nil {
+			return fmt.Errorf("failed to reattach to session: %w", err)
+		}
+		sessionID = reattached.ID
+	}
+
+	// Capture DOM facts
+	fmt.Printf("Capturing DOM for session %s...\n", sessionID)
+	if err := mgr.SnapshotDOM(ctx, sessionID); err != nil {
+		return fmt.Errorf("DOM snapshot failed: %w", err)
+	}
+
+	// Also capture React components if available
+	reactFacts, err := mgr.ReifyReact(ctx, sessionID)
+	if err != nil {
+		logger.Info("React reification skipped", zap.Error(err))
+	} else {
+		fmt.Printf("Captured %d React component facts\n", len(reactFacts))
+	}
+
+	// Export facts to file
+	factsDir := filepath.Join(cwd, ".nerd", "browser", "snapshots")
+	if err := os.MkdirAll(factsDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshots dir: %w", err)
+	}
+
+	snapshotFile := filepath.Join(factsDir, fmt.Sprintf("%s_%d.mg", sessionID, time.Now().Unix()))
+
+	// Query for all DOM-related predicates
+	domPredicates := []string{
+		"dom_node", "dom_text", "dom_attr", "dom_layout",
+		"react_component", "react_prop", "react_state", "dom_mapping",
+		"navigation_event", "current_url", "console_event",
+		"net_request", "net_response", "net_header", "request_initiator",
+		"click_event", "input_event", "state_change",
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("// DOM Snapshot for session %s\n", sessionID))
+	sb.WriteString(fmt.Sprintf("// Captured at %s\n", time.Now().Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("// URL: %s\n\n", session.URL))
+
+	totalFacts := 0
+	for _, pred := range domPredicates {
+		facts, err := engine.GetFacts(pred)
+		if err != nil {
+			continue // Predicate not declared, skip
+		}
+		for _, fact := range facts {
+			sb.WriteString(fact.String())
+			sb.WriteString(".\n")
+			totalFacts++
+		}
+	}
+
+	if totalFacts == 0 {
+		fmt.Println("Warning: No DOM facts captured. The page may not have loaded yet.")
+		fmt.Println("Try waiting for the page to fully load, then run snapshot again.")
+	}
+
+	if err := os.WriteFile(snapshotFile, []byte(sb.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	fmt.Printf("DOM snapshot complete:\n")
+	fmt.Printf("  Facts captured: %d\n", totalFacts)
+	fmt.Printf("  Saved to: %s\n", snapshotFile)
+	return nil
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"time"
+
+	"codenerd/internal/core"
+	"codenerd/internal/shards/reviewer"
+	coresys "codenerd/internal/system"
+
+	"github.com/spf13/cobra"
+)
+
+// diagManifestVersion is the bundle format version. Bump it whenever the set
+// of files or their schemas inside the tarball changes, so `diag inspect`
+// can refuse (or adapt to) bundles from a different version.
+const diagManifestVersion = 1
+
+// diagCmd is the parent command for the offline diagnostics bundle workflow.
+var diagCmd = &cobra.Command{
+	Use:   "diag",
+	Short: "Collect and inspect diagnostic bundles for offline analysis",
+}
+
+// diagCollectCmd packages the current workspace's Mangle kernel state,
+// reviewer pipeline configuration, and recent enhancement runs into a
+// self-contained tarball.
+var diagCollectCmd = &cobra.Command{
+	Use:   "collect [output.tar.gz]",
+	Short: "Package a diagnostic bundle for offline analysis",
+	Long: `Collects the current Mangle schema/policy/learned rules, a snapshot of the
+fact store, recent creative-enhancement run summaries, the reviewer's
+NeuroSymbolicConfig, kernel adapter stats, and Go runtime info into a
+gzipped tarball.
+
+Examples:
+  nerd diag collect
+  nerd diag collect bundle.tar.gz --include-facts
+  nerd diag collect --redact-pattern 'sk-[A-Za-z0-9]+' --redact-pattern '\b\d{16}\b'`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDiagCollect,
+}
+
+// diagInspectCmd loads a bundle into an ephemeral kernel + reviewer so an
+// engineer can replay queries against a user's state offline.
+var diagInspectCmd = &cobra.Command{
+	Use:   "inspect <bundle.tar.gz>",
+	Short: "Load a diagnostic bundle into an ephemeral kernel for replay",
+	Long: `Unpacks a bundle produced by 'nerd diag collect' into an ephemeral
+KernelAdapter (wrapping a fresh in-memory kernel seeded from the bundle's
+schema/policy/learned rules and, if present, its full fact dump) plus a
+ReviewerShard wired to it, and prints a summary. Pass --query to evaluate a
+single predicate against the restored fact store.
+
+Examples:
+  nerd diag inspect bundle.tar.gz
+  nerd diag inspect bundle.tar.gz --query review_finding`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDiagInspect,
+}
+
+var (
+	diagIncludeFacts   bool
+	diagRedactPatterns []string
+	diagEnhanceLimit   int
+	diagInspectQuery   string
+)
+
+// diagManifest is the manifest.json entry at the root of every bundle.
+type diagManifest struct {
+	SchemaVersion int       `json:"schema_version"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	Workspace     string    `json:"workspace"`
+	IncludesFacts bool      `json:"includes_facts"`
+	RedactionUsed []string  `json:"redaction_patterns,omitempty"`
+}
+
+// diagKernelState captures the Mangle-level state of the kernel.
+type diagKernelState struct {
+	Schemas            string         `json:"schemas"`
+	Policy             string         `json:"policy"`
+	Learned            string         `json:"learned"`
+	DeclaredPredicates []string       `json:"declared_predicates"`
+	FactCount          int            `json:"fact_count"`
+	PredicateHistogram map[string]int `json:"predicate_histogram"`
+	Facts              []core.Fact    `json:"facts,omitempty"`
+}
+
+// diagEnhancementRun is a summary of one past creative-enhancement run, built
+// from the "enhancement_result" cold-storage facts PersistEnhancements
+// writes. The reviewer pipeline does not persist full EnhancementResult or
+// CreativeFirstPass bodies anywhere today, so a faithful replay bundle can
+// only carry these summary fields.
+type diagEnhancementRun struct {
+	ReviewID          string  `json:"review_id"`
+	TotalSuggestions  int     `json:"total_suggestions"`
+	FileSuggestions   int     `json:"file_suggestions"`
+	ModuleSuggestions int     `json:"module_suggestions"`
+	SystemInsights    int     `json:"system_insights"`
+	FeatureIdeas      int     `json:"feature_ideas"`
+	EnhancementRatio  float64 `json:"enhancement_ratio"`
+	Timestamp         int64   `json:"timestamp"`
+}
+
+// diagRuntimeInfo is a snapshot of the collecting process's Go runtime.
+type diagRuntimeInfo struct {
+	GoVersion    string `json:"go_version"`
+	GOMAXPROCS   int    `json:"gomaxprocs"`
+	NumGoroutine int    `json:"num_goroutine"`
+	AllocBytes   uint64 `json:"alloc_bytes"`
+	SysBytes     uint64 `json:"sys_bytes"`
+	NumGC        uint32 `json:"num_gc"`
+}
+
+func runDiagCollect(cmd *cobra.Command, args []string) error {
+	outputPath := "diag-bundle.tar.gz"
+	if len(args) == 1 {
+		outputPath = args[0]
+	}
+
+	redactors, err := compileRedactPatterns(diagRedactPatterns)
+	if err != nil {
+		return err
+	}
+
+	baseCtx := cmd.Context()
+	if baseCtx == nil {
+		baseCtx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(baseCtx, timeout)
+	defer cancel()
+
+	key := apiKey
+	if key == "" {
+		key = os.Getenv("ZAI_API_KEY")
+	}
+	cortex, err := coresys.GetOrBootCortex(ctx, workspace, key, disableSystemShards)
+	if err != nil {
+		return fmt.Errorf("failed to boot cortex: %w", err)
+	}
+	defer cortex.Close()
+
+	kernel, ok := cortex.Kernel.(*core.RealKernel)
+	if !ok {
+		return fmt.Errorf("diag collect requires a *core.RealKernel, got %T", cortex.Kernel)
+	}
+
+	kernelState := buildKernelState(kernel, diagIncludeFacts)
+	enhancementRuns, err := collectEnhancementRuns(kernel, diagEnhanceLimit)
+	if err != nil {
+		return fmt.Errorf("failed to collect enhancement runs: %w", err)
+	}
+
+	adapter := core.NewKernelAdapter(kernel)
+	neuroConfig := reviewer.DefaultNeuroSymbolicConfig()
+	runtimeInfo := collectRuntimeInfo()
+
+	manifest := diagManifest{
+		SchemaVersion: diagManifestVersion,
+		GeneratedAt:   diagNow(),
+		Workspace:     cortex.Workspace,
+		IncludesFacts: diagIncludeFacts,
+		RedactionUsed: diagRedactPatterns,
+	}
+
+	files := map[string]interface{}{
+		"manifest.json":             manifest,
+		"kernel_state.json":         kernelState,
+		"enhancement_runs.json":     enhancementRuns,
+		"neurosymbolic_config.json": neuroConfig,
+		"kernel_adapter_stats.json": adapter.Stats(),
+		"runtime.json":              runtimeInfo,
+	}
+
+	if err := writeDiagBundle(outputPath, files, redactors); err != nil {
+		return fmt.Errorf("failed to write diagnostic bundle: %w", err)
+	}
+
+	fmt.Printf("Diagnostic bundle written to %s\n", outputPath)
+	return nil
+}
+
+func runDiagInspect(cmd *cobra.Command, args []string) error {
+	bundlePath := args[0]
+
+	files, err := readDiagBundle(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to read diagnostic bundle: %w", err)
+	}
+
+	var manifest diagManifest
+	if raw, ok := files["manifest.json"]; ok {
+		if err := json.Unmarshal(raw, &manifest); err != nil {
+			return fmt.Errorf("failed to parse manifest.json: %w", err)
+		}
+	}
+	if manifest.SchemaVersion != diagManifestVersion {
+		fmt.Printf("Warning: bundle schema version %d does not match this build's version %d\n",
+			manifest.SchemaVersion, diagManifestVersion)
+	}
+
+	var kernelState diagKernelState
+	if raw, ok := files["kernel_state.json"]; ok {
+		if err := json.Unmarshal(raw, &kernelState); err != nil {
+			return fmt.Errorf("failed to parse kernel_state.json: %w", err)
+		}
+	}
+
+	kernel, err := core.NewRealKernel()
+	if err != nil {
+		return fmt.Errorf("failed to create ephemeral kernel: %w", err)
+	}
+	if kernelState.Schemas != "" {
+		kernel.AppendPolicy(kernelState.Schemas)
+	}
+	if kernelState.Policy != "" {
+		kernel.AppendPolicy(kernelState.Policy)
+	}
+	if kernelState.Learned != "" {
+		kernel.AppendPolicy(kernelState.Learned)
+	}
+	if len(kernelState.Facts) > 0 {
+		if err := kernel.AssertBatch(kernelState.Facts); err != nil {
+			return fmt.Errorf("failed to restore facts into ephemeral kernel: %w", err)
+		}
+	} else if err := kernel.Evaluate(); err != nil {
+		return fmt.Errorf("failed to evaluate ephemeral kernel: %w", err)
+	}
+
+	// Wire an ephemeral KernelAdapter + ReviewerShard onto the restored
+	// kernel, exactly as production code does, so replayed queries exercise
+	// the same path a live session would.
+	adapter := core.NewKernelAdapter(kernel)
+	shard := reviewer.NewReviewerShard()
+	shard.SetParentKernel(kernel)
+
+	fmt.Printf("Bundle: %s (schema version %d, generated %s, workspace %s)\n",
+		bundlePath, manifest.SchemaVersion, manifest.GeneratedAt.Format(time.RFC3339), manifest.Workspace)
+	fmt.Printf("Facts restored: %d (full facts included: %v)\n", kernel.FactCount(), len(kernelState.Facts) > 0)
+	fmt.Printf("Declared predicates: %d\n", len(kernelState.DeclaredPredicates))
+	fmt.Printf("Predicate histogram entries: %d\n", len(kernelState.PredicateHistogram))
+	fmt.Printf("Reviewer shard wired to restored kernel: %v\n", shard.GetKernel() != nil)
+
+	if diagInspectQuery != "" {
+		facts, err := adapter.QueryPredicate(diagInspectQuery)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		if len(facts) == 0 {
+			fmt.Printf("No facts found for predicate '%s'\n", diagInspectQuery)
+			return nil
+		}
+		fmt.Printf("Facts for '%s':\n", diagInspectQuery)
+		for _, f := range facts {
+			fmt.Printf("  %s(%v)\n", f.Predicate, f.Args)
+		}
+	}
+
+	return nil
+}
+
+// buildKernelState snapshots the schema/policy/learned rule text, declared
+// predicates, and a predicate/arity -> count histogram from kernel. Full
+// ground facts are only included when includeFacts is set.
+func buildKernelState(kernel *core.RealKernel, includeFacts bool) diagKernelState {
+	state := diagKernelState{
+		Schemas:            kernel.GetSchemas(),
+		Policy:             kernel.GetPolicy(),
+		Learned:            kernel.GetLearned(),
+		DeclaredPredicates: kernel.GetDeclaredPredicates(),
+		FactCount:          kernel.FactCount(),
+		PredicateHistogram: make(map[string]int),
+	}
+
+	facts := kernel.GetAllFacts()
+	for _, f := range facts {
+		key := fmt.Sprintf("%s/%d", f.Predicate, len(f.Args))
+		state.PredicateHistogram[key]++
+	}
+
+	if includeFacts {
+		state.Facts = facts
+	}
+	return state
+}
+
+// collectEnhancementRuns reads the last limit "enhancement_result" facts
+// PersistEnhancements wrote, newest first. These are the only persisted
+// record of past creative-enhancement runs in this codebase.
+func collectEnhancementRuns(kernel *core.RealKernel, limit int) ([]diagEnhancementRun, error) {
+	facts, err := kernel.Query("enhancement_result")
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]diagEnhancementRun, 0, len(facts))
+	for _, f := range facts {
+		if len(f.Args) < 8 {
+			continue
+		}
+		run := diagEnhancementRun{
+			ReviewID:          fmt.Sprintf("%v", f.Args[0]),
+			TotalSuggestions:  toInt(f.Args[1]),
+			FileSuggestions:   toInt(f.Args[2]),
+			ModuleSuggestions: toInt(f.Args[3]),
+			SystemInsights:    toInt(f.Args[4]),
+			FeatureIdeas:      toInt(f.Args[5]),
+			EnhancementRatio:  toFloat(f.Args[6]),
+			Timestamp:         int64(toInt(f.Args[7])),
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Timestamp > runs[j].Timestamp })
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
+
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int64:
+		return float64(n)
+	case int:
+		return float64(n)
+	default:
+		return 0
+	}
+}
+
+// collectRuntimeInfo snapshots the collecting process's Go runtime state.
+func collectRuntimeInfo() diagRuntimeInfo {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return diagRuntimeInfo{
+		GoVersion:    runtime.Version(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		NumGoroutine: runtime.NumGoroutine(),
+		AllocBytes:   m.Alloc,
+		SysBytes:     m.Sys,
+		NumGC:        m.NumGC,
+	}
+}
+
+// compileRedactPatterns compiles each --redact-pattern regex up front so a
+// bad pattern fails before any work is collected.
+func compileRedactPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --redact-pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// redactString replaces every match of every pattern with "[REDACTED]".
+func redactString(s string, redactors []*regexp.Regexp) string {
+	for _, re := range redactors {
+		s = re.ReplaceAllString(s, "[REDACTED]")
+	}
+	return s
+}
+
+// writeDiagBundle marshals each named value to JSON, redacts matching
+// substrings, and writes the results as a gzipped tarball at outputPath.
+func writeDiagBundle(outputPath string, files map[string]interface{}, redactors []*regexp.Regexp) error {
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gw := gzip.NewWriter(out)
+	defer gw.Close()
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		raw, err := json.MarshalIndent(files[name], "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+		redacted := redactString(string(raw), redactors)
+
+		hdr := &tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(redacted)),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write([]byte(redacted)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDiagBundle extracts every file from a gzipped tarball into memory,
+// keyed by name.
+func readDiagBundle(bundlePath string) (map[string][]byte, error) {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(hdr.Name), err)
+		}
+		files[hdr.Name] = data
+	}
+	return files, nil
+}
+
+// diagNow is a thin wrapper over time.Now so it is the single seam to swap
+// out in tests if deterministic manifests are ever needed.
+func diagNow() time.Time {
+	return time.Now()
+}
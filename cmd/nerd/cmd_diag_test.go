@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"codenerd/internal/core"
+)
+
+func TestCompileRedactPatterns_InvalidPattern(t *testing.T) {
+	if _, err := compileRedactPatterns([]string{"("}); err == nil {
+		t.Fatal("expected an error for an invalid regex pattern")
+	}
+
+	redactors, err := compileRedactPatterns([]string{"sk-[A-Za-z0-9]+"})
+	if err != nil {
+		t.Fatalf("compileRedactPatterns failed: %v", err)
+	}
+	if len(redactors) != 1 {
+		t.Fatalf("expected 1 compiled pattern, got %d", len(redactors))
+	}
+}
+
+func TestRedactString(t *testing.T) {
+	redactors, err := compileRedactPatterns([]string{`sk-[A-Za-z0-9]+`})
+	if err != nil {
+		t.Fatalf("compileRedactPatterns failed: %v", err)
+	}
+
+	got := redactString(`api key: sk-abc123XYZ in use`, redactors)
+	want := `api key: [REDACTED] in use`
+	if got != want {
+		t.Errorf("redactString() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteAndReadDiagBundle_Roundtrip(t *testing.T) {
+	dir := t.TempDir()
+	bundlePath := dir + "/bundle.tar.gz"
+
+	files := map[string]interface{}{
+		"manifest.json": diagManifest{
+			SchemaVersion: diagManifestVersion,
+			Workspace:     "/tmp/ws",
+		},
+		"kernel_state.json": diagKernelState{
+			Schemas:   "Decl foo(Name).",
+			FactCount: 1,
+		},
+	}
+
+	if err := writeDiagBundle(bundlePath, files, nil); err != nil {
+		t.Fatalf("writeDiagBundle failed: %v", err)
+	}
+
+	read, err := readDiagBundle(bundlePath)
+	if err != nil {
+		t.Fatalf("readDiagBundle failed: %v", err)
+	}
+
+	var manifest diagManifest
+	if err := json.Unmarshal(read["manifest.json"], &manifest); err != nil {
+		t.Fatalf("failed to unmarshal manifest.json: %v", err)
+	}
+	if manifest.SchemaVersion != diagManifestVersion || manifest.Workspace != "/tmp/ws" {
+		t.Errorf("manifest roundtrip mismatch: %+v", manifest)
+	}
+
+	var kernelState diagKernelState
+	if err := json.Unmarshal(read["kernel_state.json"], &kernelState); err != nil {
+		t.Fatalf("failed to unmarshal kernel_state.json: %v", err)
+	}
+	if kernelState.Schemas != "Decl foo(Name)." || kernelState.FactCount != 1 {
+		t.Errorf("kernel_state roundtrip mismatch: %+v", kernelState)
+	}
+}
+
+func TestWriteDiagBundle_Redacts(t *testing.T) {
+	dir := t.TempDir()
+	bundlePath := dir + "/bundle.tar.gz"
+
+	redactors, err := compileRedactPatterns([]string{`sk-[A-Za-z0-9]+`})
+	if err != nil {
+		t.Fatalf("compileRedactPatterns failed: %v", err)
+	}
+
+	files := map[string]interface{}{
+		"kernel_state.json": diagKernelState{Schemas: "token sk-abc123XYZ embedded"},
+	}
+	if err := writeDiagBundle(bundlePath, files, redactors); err != nil {
+		t.Fatalf("writeDiagBundle failed: %v", err)
+	}
+
+	read, err := readDiagBundle(bundlePath)
+	if err != nil {
+		t.Fatalf("readDiagBundle failed: %v", err)
+	}
+
+	var kernelState diagKernelState
+	if err := json.Unmarshal(read["kernel_state.json"], &kernelState); err != nil {
+		t.Fatalf("failed to unmarshal kernel_state.json: %v", err)
+	}
+	if kernelState.Schemas != "token [REDACTED] embedded" {
+		t.Errorf("expected redaction to apply before writing, got %q", kernelState.Schemas)
+	}
+}
+
+func TestBuildKernelState(t *testing.T) {
+	k, err := core.NewRealKernel()
+	if err != nil {
+		t.Fatalf("NewRealKernel failed: %v", err)
+	}
+	k.AppendPolicy(`Decl foo(Name).`)
+	if err := k.Assert(core.Fact{Predicate: "foo", Args: []interface{}{"bar"}}); err != nil {
+		t.Fatalf("Assert failed: %v", err)
+	}
+
+	state := buildKernelState(k, false)
+	if state.FactCount != 1 {
+		t.Errorf("expected FactCount 1, got %d", state.FactCount)
+	}
+	if state.PredicateHistogram["foo/1"] != 1 {
+		t.Errorf("expected histogram foo/1=1, got %+v", state.PredicateHistogram)
+	}
+	if state.Facts != nil {
+		t.Error("expected Facts to be omitted when includeFacts is false")
+	}
+
+	stateWithFacts := buildKernelState(k, true)
+	if len(stateWithFacts.Facts) != 1 {
+		t.Errorf("expected 1 fact when includeFacts is true, got %d", len(stateWithFacts.Facts))
+	}
+}
+
+func TestCollectEnhancementRuns(t *testing.T) {
+	k, err := core.NewRealKernel()
+	if err != nil {
+		t.Fatalf("NewRealKernel failed: %v", err)
+	}
+	k.AppendPolicy(`Decl enhancement_result(ReviewID, Total, Files, Modules, System, Feature, Ratio, Timestamp).`)
+	if err := k.Assert(core.Fact{
+		Predicate: "enhancement_result",
+		Args:      []interface{}{"rev-1", int64(4), int64(2), int64(1), int64(1), int64(0), 2.0, int64(100)},
+	}); err != nil {
+		t.Fatalf("Assert failed: %v", err)
+	}
+	if err := k.Assert(core.Fact{
+		Predicate: "enhancement_result",
+		Args:      []interface{}{"rev-2", int64(2), int64(1), int64(1), int64(0), int64(0), 1.0, int64(200)},
+	}); err != nil {
+		t.Fatalf("Assert failed: %v", err)
+	}
+
+	runs, err := collectEnhancementRuns(k, 1)
+	if err != nil {
+		t.Fatalf("collectEnhancementRuns failed: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run after limiting, got %d", len(runs))
+	}
+	if runs[0].ReviewID != "rev-2" {
+		t.Errorf("expected the newest run (rev-2) first, got %s", runs[0].ReviewID)
+	}
+}
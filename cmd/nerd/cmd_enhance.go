@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"codenerd/internal/shards/reviewer"
+
+	"github.com/spf13/cobra"
+)
+
+// enhanceCmd is the parent command for Enhancement Proposal lifecycle
+// operations (mirroring kepctl).
+var enhanceCmd = &cobra.Command{
+	Use:   "enhance",
+	Short: "Manage Enhancement Proposals",
+	Long: `Enhancement Proposals track accepted creative suggestions (from
+/review --andEnhance) through a KEP/PRR-style lifecycle: provisional ->
+implementable -> implemented -> deprecated.
+
+Examples:
+  nerd enhance create "Dedup transaction snapshots" --owner internal/core
+  nerd enhance promote prop-abc123 implementable --approver alice
+  nerd enhance query --stage implementable`,
+}
+
+// enhanceCreateCmd creates a new proposal in the provisional stage.
+var enhanceCreateCmd = &cobra.Command{
+	Use:   "create [title]",
+	Short: "Create a new Enhancement Proposal",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEnhanceCreate,
+}
+
+// enhancePromoteCmd advances an existing proposal to its next stage.
+var enhancePromoteCmd = &cobra.Command{
+	Use:   "promote [id] [stage]",
+	Short: "Promote a proposal to its next lifecycle stage",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runEnhancePromote,
+}
+
+// enhanceQueryCmd lists proposals, optionally filtered by stage.
+var enhanceQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "List Enhancement Proposals",
+	RunE:  runEnhanceQuery,
+}
+
+var (
+	enhanceOwner      string
+	enhanceMilestone  string
+	enhanceApprovers  []string
+	enhanceApprover   string
+	enhanceNotes      string
+	enhanceQueryStage string
+)
+
+func runEnhanceCreate(cmd *cobra.Command, args []string) error {
+	if enhanceOwner == "" {
+		return fmt.Errorf("--owner is required")
+	}
+
+	cwd := workspace
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+
+	p := &reviewer.Proposal{
+		ID:        fmt.Sprintf("prop-%d", time.Now().UnixNano()),
+		Title:     args[0],
+		Stage:     reviewer.StageProvisional,
+		Owner:     enhanceOwner,
+		Milestone: enhanceMilestone,
+		Approvers: enhanceApprovers,
+	}
+
+	store := reviewer.NewProposalStore(cwd)
+	if err := store.Create(p); err != nil {
+		return fmt.Errorf("failed to create proposal: %w", err)
+	}
+
+	fmt.Printf("✓ Created proposal %s (%s)\n", p.ID, p.Stage)
+	return nil
+}
+
+func runEnhancePromote(cmd *cobra.Command, args []string) error {
+	id, stage := args[0], reviewer.ProposalStage(args[1])
+
+	cwd := workspace
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+
+	store := reviewer.NewProposalStore(cwd)
+
+	if enhanceApprover != "" {
+		if _, err := store.Approve(id, reviewer.Approval{
+			Stage:    reviewer.StageProvisional,
+			Approver: enhanceApprover,
+			Notes:    enhanceNotes,
+		}); err != nil {
+			return fmt.Errorf("failed to record approval: %w", err)
+		}
+	}
+
+	p, err := store.Promote(id, stage)
+	if err != nil {
+		return fmt.Errorf("failed to promote proposal: %w", err)
+	}
+
+	fmt.Printf("✓ Promoted %s to %s\n", p.ID, p.Stage)
+	return nil
+}
+
+func runEnhanceQuery(cmd *cobra.Command, args []string) error {
+	cwd := workspace
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+
+	store := reviewer.NewProposalStore(cwd)
+	proposals, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list proposals: %w", err)
+	}
+
+	for _, p := range proposals {
+		if enhanceQueryStage != "" && string(p.Stage) != enhanceQueryStage {
+			continue
+		}
+		fmt.Printf("%-20s %-14s %-24s %s\n", p.ID, p.Stage, p.Owner, p.Title)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"codenerd/internal/mangle"
+
+	"github.com/google/mangle/parse"
+	"github.com/spf13/cobra"
+)
+
+// mangleCmd is the parent command for interacting with the Mangle engine
+// directly, outside of a running codeNERD session.
+var mangleCmd = &cobra.Command{
+	Use:   "mangle",
+	Short: "Interact with the Mangle (Datalog) engine",
+}
+
+// mangleQueryCmd loads a set of .gl files and either runs a single query or
+// drops into an interactive REPL.
+var mangleQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Evaluate a Mangle query, one-shot or interactively",
+	Long: `Loads schema and rule/fact files, evaluates to fixed point, and
+either executes a single query (--query) printing JSON or TSV rows, or
+drops into an interactive REPL.
+
+REPL commands:
+  :load <file>             load another .gl file and re-evaluate
+  :assert pred(/a,/b).      assert a ground fact
+  :retract pred(/a,/b).     retract a ground fact
+  :facts <predicate>        list stored facts for a predicate
+  :rules                    list loaded rule clauses
+  :stats                    show fact counts and last eval stats
+  :quit                     exit the REPL
+
+Examples:
+  nerd mangle query --schema internal/mangle/schemas.gl --include policy.gl --query 'ancestor(A, D)'
+  nerd mangle query --schema internal/mangle/schemas.gl`,
+	RunE: runMangleQuery,
+}
+
+var (
+	mangleSchemaFiles  []string
+	mangleIncludeFiles []string
+	mangleQueryStr     string
+	mangleFormat       string
+)
+
+func runMangleQuery(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	engine, err := mangle.NewEngine(mangle.DefaultConfig(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to initialize mangle engine: %w", err)
+	}
+
+	for _, path := range mangleSchemaFiles {
+		if err := engine.LoadSchema(path); err != nil {
+			return fmt.Errorf("failed to load schema %s: %w", path, err)
+		}
+	}
+	for _, path := range mangleIncludeFiles {
+		if err := engine.LoadSchema(path); err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+	}
+
+	if err := engine.RecomputeRulesContext(ctx); err != nil {
+		return fmt.Errorf("failed to evaluate to fixed point: %w", err)
+	}
+
+	if mangleQueryStr != "" {
+		return runMangleOneShotQuery(ctx, engine, mangleQueryStr)
+	}
+	return runMangleREPL(ctx, engine)
+}
+
+func runMangleOneShotQuery(ctx context.Context, engine *mangle.Engine, query string) error {
+	result, err := engine.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	printMangleBindings(result.Bindings, mangleFormat)
+	return nil
+}
+
+func runMangleREPL(ctx context.Context, engine *mangle.Engine) error {
+	fmt.Println("nerd mangle query REPL - type :quit to exit")
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		fmt.Print("mangle> ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if err := dispatchMangleREPLLine(ctx, engine, line); err != nil {
+			if err == errMangleREPLQuit {
+				return nil
+			}
+			fmt.Printf("error: %v\n", err)
+		}
+	}
+}
+
+var errMangleREPLQuit = fmt.Errorf("quit")
+
+func dispatchMangleREPLLine(ctx context.Context, engine *mangle.Engine, line string) error {
+	switch {
+	case line == ":quit" || line == ":q":
+		return errMangleREPLQuit
+
+	case strings.HasPrefix(line, ":load "):
+		path := strings.TrimSpace(strings.TrimPrefix(line, ":load "))
+		if err := engine.LoadSchema(path); err != nil {
+			return err
+		}
+		return engine.RecomputeRulesContext(ctx)
+
+	case strings.HasPrefix(line, ":assert "):
+		atom, err := parse.Atom(strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(line, ":assert ")), "."))
+		if err != nil {
+			return fmt.Errorf("failed to parse fact: %w", err)
+		}
+		return engine.AssertAtom(atom)
+
+	case strings.HasPrefix(line, ":retract "):
+		atom, err := parse.Atom(strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(line, ":retract ")), "."))
+		if err != nil {
+			return fmt.Errorf("failed to parse fact: %w", err)
+		}
+		return engine.RetractAtom(atom)
+
+	case strings.HasPrefix(line, ":facts "):
+		predicate := strings.TrimSpace(strings.TrimPrefix(line, ":facts "))
+		facts, err := engine.GetFacts(predicate)
+		if err != nil {
+			return err
+		}
+		for _, f := range facts {
+			fmt.Println(f.String())
+		}
+		return nil
+
+	case line == ":rules":
+		for _, rule := range engine.ListRules() {
+			fmt.Println(rule)
+		}
+		return nil
+
+	case line == ":stats":
+		stats := engine.GetStats()
+		summary, duration := engine.LastEvalSummary()
+		fmt.Printf("total facts: %d\n", stats.TotalFacts)
+		for pred, count := range stats.PredicateCounts {
+			fmt.Printf("  %s: %d\n", pred, count)
+		}
+		fmt.Printf("last eval: %v (%s)\n", duration, summary)
+		return nil
+
+	default:
+		result, err := engine.Query(ctx, line)
+		if err != nil {
+			return err
+		}
+		printMangleBindings(result.Bindings, mangleFormat)
+		return nil
+	}
+}
+
+// printMangleBindings prints query result rows either as JSON (one array of
+// objects) or TSV (one row per line, columns sorted by variable name for a
+// stable header).
+func printMangleBindings(bindings []map[string]interface{}, format string) {
+	if format == "json" {
+		data, err := json.MarshalIndent(bindings, "", "  ")
+		if err != nil {
+			fmt.Printf("error: failed to marshal results: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(bindings) == 0 {
+		fmt.Println("(no results)")
+		return
+	}
+
+	var columns []string
+	for name := range bindings[0] {
+		columns = append(columns, name)
+	}
+	sort.Strings(columns)
+
+	fmt.Println(strings.Join(columns, "\t"))
+	for _, row := range bindings {
+		values := make([]string, len(columns))
+		for i, col := range columns {
+			values[i] = fmt.Sprintf("%v", row[col])
+		}
+		fmt.Println(strings.Join(values, "\t"))
+	}
+}
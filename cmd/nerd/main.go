@@ -33,6 +33,9 @@
 //   - cmd_mangle_check.go - checkMangleCmd, runCheckMangle(), checkFile()
 //   - cmd_mangle_lsp.go   - mangleLSPCmd, runMangleLSP() (Language Server Protocol for IDE integration)
 //
+// Diagnostics:
+//   - cmd_diag.go        - diagCmd, diagCollectCmd, diagInspectCmd (offline diagnostic bundles)
+//
 // Query & Status:
 //   - cmd_query.go       - queryCmd, statusCmd, whyCmd, queryFacts(), showStatus(),
 //                          runWhy(), joinArgs(), sanitizeFactForMangle()
@@ -202,6 +205,36 @@ func init() {
 		authStatusCmd,
 	)
 
+	// Enhance subcommands
+	enhanceCreateCmd.Flags().StringVar(&enhanceOwner, "owner", "", "Owning module (required)")
+	enhanceCreateCmd.Flags().StringVar(&enhanceMilestone, "milestone", "", "Targeted release/milestone")
+	enhanceCreateCmd.Flags().StringArrayVar(&enhanceApprovers, "approvers", nil, "Approvers required before promotion")
+	enhancePromoteCmd.Flags().StringVar(&enhanceApprover, "approver", "", "Record an approval from this approver before promoting")
+	enhancePromoteCmd.Flags().StringVar(&enhanceNotes, "notes", "", "Notes attached to the recorded approval")
+	enhanceQueryCmd.Flags().StringVar(&enhanceQueryStage, "stage", "", "Filter by lifecycle stage")
+	enhanceCmd.AddCommand(
+		enhanceCreateCmd,
+		enhancePromoteCmd,
+		enhanceQueryCmd,
+	)
+
+	// Mangle query subcommands
+	mangleQueryCmd.Flags().StringArrayVar(&mangleSchemaFiles, "schema", nil, "Schema .gl file(s) providing predicate declarations")
+	mangleQueryCmd.Flags().StringArrayVar(&mangleIncludeFiles, "include", nil, "Additional .gl file(s) with facts/rules for context")
+	mangleQueryCmd.Flags().StringVar(&mangleQueryStr, "query", "", "Run a single query non-interactively, e.g. 'ancestor(A, D)'")
+	mangleQueryCmd.Flags().StringVar(&mangleFormat, "format", "tsv", "Output format for --query results: tsv or json")
+	mangleCmd.AddCommand(mangleQueryCmd)
+
+	// Diag subcommands
+	diagCollectCmd.Flags().BoolVar(&diagIncludeFacts, "include-facts", false, "Include the full fact store dump, not just a histogram")
+	diagCollectCmd.Flags().StringArrayVar(&diagRedactPatterns, "redact-pattern", nil, "Regex pattern to redact from bundle contents (repeatable)")
+	diagCollectCmd.Flags().IntVar(&diagEnhanceLimit, "last-n-enhancements", 10, "Number of recent enhancement run summaries to include (0 = all)")
+	diagInspectCmd.Flags().StringVar(&diagInspectQuery, "query", "", "Replay a single predicate query against the restored fact store")
+	diagCmd.AddCommand(
+		diagCollectCmd,
+		diagInspectCmd,
+	)
+
 	// Add commands to root
 	rootCmd.AddCommand(
 		runCmd,
@@ -217,6 +250,9 @@ func init() {
 		checkMangleCmd,
 		mangleLSPCmd,
 		authCmd,
+		enhanceCmd,
+		mangleCmd,
+		diagCmd,
 	)
 
 	// Direct action commands (mirror TUI verbs)
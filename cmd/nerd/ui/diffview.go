@@ -6,70 +6,54 @@ import (
 	"fmt"
 	"strings"
 
+	"codenerd/internal/diff"
+
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
-// DiffLine represents a single line in the diff
-type DiffLine struct {
-	LineNum int
-	Content string
-	Type    DiffLineType
-}
-
-// DiffLineType represents the type of diff line
-type DiffLineType int
+// DiffLine, DiffLineType, DiffHunk, and FileDiff are aliases onto package
+// diff's types, so this view can render diffs produced by diff.ComputeDiff
+// (and its word-level/rename-aware siblings) directly, without a conversion
+// step, while keeping these names stable for existing callers in this package.
+type DiffLine = diff.Line
+type DiffLineType = diff.LineType
+type DiffHunk = diff.Hunk
+type FileDiff = diff.FileDiff
 
 const (
-	DiffLineContext  DiffLineType = iota // Unchanged context line
-	DiffLineAdded                        // Added line
-	DiffLineRemoved                      // Removed line
-	DiffLineHeader                       // Diff header line
+	DiffLineContext = diff.LineContext // Unchanged context line
+	DiffLineAdded   = diff.LineAdded   // Added line
+	DiffLineRemoved = diff.LineRemoved // Removed line
+	DiffLineHeader  = diff.LineHeader  // Diff header line
 )
 
-// DiffHunk represents a group of changes
-type DiffHunk struct {
-	OldStart int
-	OldCount int
-	NewStart int
-	NewCount int
-	Lines    []DiffLine
-}
-
-// FileDiff represents changes to a single file
-type FileDiff struct {
-	OldPath  string
-	NewPath  string
-	Hunks    []DiffHunk
-	IsNew    bool
-	IsDelete bool
-	IsBinary bool
-}
-
 // PendingMutation represents a mutation awaiting approval
 type PendingMutation struct {
 	ID          string
 	Description string
 	FilePath    string
 	Diff        *FileDiff
-	Reason      string    // Why approval is needed
-	Warnings    []string  // Safety warnings
+	Reason      string   // Why approval is needed
+	Warnings    []string // Safety warnings
 	Approved    bool
 	Rejected    bool
-	Comment     string    // User's comment
+	Comment     string // User's comment
 }
 
 // DiffApprovalView handles interactive diff approval
 type DiffApprovalView struct {
-	Styles         Styles
-	Viewport       viewport.Model
-	Mutations      []*PendingMutation
-	CurrentIndex   int
-	Width          int
-	Height         int
-	ShowWarnings   bool
-	SelectedHunk   int
-	ApprovalMode   ApprovalMode
+	Styles        Styles
+	Viewport      viewport.Model
+	Mutations     []*PendingMutation
+	CurrentIndex  int
+	Width         int
+	Height        int
+	ShowWarnings  bool
+	SelectedHunk  int
+	ApprovalMode  ApprovalMode
+	WordLevelDiff bool // Highlight intra-line word changes within removed/added pairs
 }
 
 // ApprovalMode represents the current approval state
@@ -88,18 +72,25 @@ func NewDiffApprovalView(styles Styles, width, height int) DiffApprovalView {
 	vp.SetContent("")
 
 	return DiffApprovalView{
-		Styles:       styles,
-		Viewport:     vp,
-		Mutations:    make([]*PendingMutation, 0),
-		CurrentIndex: 0,
-		Width:        width,
-		Height:       height,
-		ShowWarnings: true,
-		SelectedHunk: 0,
-		ApprovalMode: ModeReview,
+		Styles:        styles,
+		Viewport:      vp,
+		Mutations:     make([]*PendingMutation, 0),
+		CurrentIndex:  0,
+		Width:         width,
+		Height:        height,
+		ShowWarnings:  true,
+		SelectedHunk:  0,
+		ApprovalMode:  ModeReview,
+		WordLevelDiff: true,
 	}
 }
 
+// ToggleWordLevelDiff toggles intra-line word-change highlighting.
+func (d *DiffApprovalView) ToggleWordLevelDiff() {
+	d.WordLevelDiff = !d.WordLevelDiff
+	d.updateContent()
+}
+
 // SetSize updates dimensions
 func (d *DiffApprovalView) SetSize(width, height int) {
 	d.Width = width
@@ -286,7 +277,7 @@ func (d *DiffApprovalView) renderHeader(m *PendingMutation) string {
 		Foreground(d.Styles.Theme.Primary).
 		Border(lipgloss.NormalBorder(), false, false, true, false).
 		BorderForeground(d.Styles.Theme.Border).
-		Width(d.Width - 4).
+		Width(d.Width-4).
 		Padding(0, 1)
 
 	// Status indicator
@@ -362,42 +353,44 @@ func (d *DiffApprovalView) renderDiff(diff *FileDiff) string {
 		sb.WriteString(hunkStyle.Render(hunkHeader))
 		sb.WriteString("\n")
 
-		// Render lines
-		for _, line := range hunk.Lines {
-			sb.WriteString(d.renderDiffLine(line))
-			sb.WriteString("\n")
-		}
+		// Render lines, pairing adjacent removed/added lines for word-level
+		// highlighting when enabled.
+		sb.WriteString(d.renderHunkLines(hunk.Lines))
 		sb.WriteString("\n")
 	}
 
 	return sb.String()
 }
 
-// renderDiffLine renders a single diff line with appropriate styling
-func (d *DiffApprovalView) renderDiffLine(line DiffLine) string {
-	var style lipgloss.Style
-	var prefix string
-
-	switch line.Type {
+// lineStyleAndPrefix returns the lipgloss style and line prefix for
+// lineType, shared by renderDiffLine and the word-level highlighting in
+// word_diff.go.
+func (d *DiffApprovalView) lineStyleAndPrefix(lineType DiffLineType) (lipgloss.Style, string) {
+	switch lineType {
 	case DiffLineAdded:
-		style = lipgloss.NewStyle().
+		return lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#22c55e")).
-			Background(lipgloss.Color("#052e16"))
-		prefix = "+ "
+			Background(lipgloss.Color("#052e16")), "+ "
 	case DiffLineRemoved:
-		style = lipgloss.NewStyle().
+		return lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#ef4444")).
-			Background(lipgloss.Color("#2d0a0a"))
-		prefix = "- "
-	case DiffLineContext:
-		style = d.Styles.Body
-		prefix = "  "
+			Background(lipgloss.Color("#2d0a0a")), "- "
 	case DiffLineHeader:
-		style = d.Styles.Bold
-		prefix = ""
+		return d.Styles.Bold, ""
+	default:
+		return d.Styles.Body, "  "
 	}
+}
 
-	return style.Render(fmt.Sprintf("%s%s", prefix, line.Content))
+// renderDiffLine renders a single diff line with appropriate styling. When
+// wordDiffs is non-empty, it highlights just the substrings identified as
+// changed rather than the whole line (see renderLineWithWordHighlights).
+func (d *DiffApprovalView) renderDiffLine(line DiffLine, wordDiffs []diffmatchpatch.Diff) string {
+	if len(wordDiffs) == 0 {
+		style, prefix := d.lineStyleAndPrefix(line.Type)
+		return style.Render(fmt.Sprintf("%s%s", prefix, line.Content))
+	}
+	return d.renderLineWithWordHighlights(line, wordDiffs, line.Type == DiffLineRemoved)
 }
 
 // renderControls renders the approval controls
@@ -0,0 +1,78 @@
+// Package ui provides the Interactive Diff Approval component.
+// This file adds intra-line (word-level) highlighting on top of
+// DiffApprovalView's line-level rendering.
+package ui
+
+import (
+	"strings"
+
+	"codenerd/internal/diff"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// renderHunkLines renders a hunk's lines, pairing up adjacent
+// LineRemoved/LineAdded lines for word-level highlighting when
+// d.WordLevelDiff is enabled, as in GitHub's split diff view. Lines that
+// don't form such a pair render plainly via renderDiffLine.
+func (d *DiffApprovalView) renderHunkLines(lines []DiffLine) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if d.WordLevelDiff && line.Type == DiffLineRemoved && i+1 < len(lines) && lines[i+1].Type == DiffLineAdded {
+			sb.WriteString(d.renderWordDiffPair(line, lines[i+1]))
+			sb.WriteString("\n")
+			i++ // consumed the paired added line
+			continue
+		}
+		sb.WriteString(d.renderDiffLine(line, nil))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// renderWordDiffPair renders a removed/added line pair with their intra-line
+// differences highlighted: the word-level diff between the two lines is
+// computed once and each side renders only its own changed substrings.
+func (d *DiffApprovalView) renderWordDiffPair(removed, added DiffLine) string {
+	wordDiffs := diff.DefaultEngine.ComputeWordLevelDiff(removed.Content, added.Content)
+
+	var sb strings.Builder
+	sb.WriteString(d.renderLineWithWordHighlights(removed, wordDiffs, true))
+	sb.WriteString("\n")
+	sb.WriteString(d.renderLineWithWordHighlights(added, wordDiffs, false))
+	return sb.String()
+}
+
+// renderLineWithWordHighlights renders line using its normal line-level
+// style for unchanged substrings and a bolded/underlined variant for the
+// substrings wordDiffs identifies as changed. isOld selects which side of
+// wordDiffs (DiffDelete vs DiffInsert) belongs to this line.
+func (d *DiffApprovalView) renderLineWithWordHighlights(line DiffLine, wordDiffs []diffmatchpatch.Diff, isOld bool) string {
+	style, prefix := d.lineStyleAndPrefix(line.Type)
+	if len(wordDiffs) == 0 {
+		return style.Render(prefix + line.Content)
+	}
+
+	highlightStyle := style.Bold(true).Underline(true)
+
+	var sb strings.Builder
+	sb.WriteString(style.Render(prefix))
+	for _, wd := range wordDiffs {
+		switch wd.Type {
+		case diffmatchpatch.DiffEqual:
+			sb.WriteString(style.Render(wd.Text))
+		case diffmatchpatch.DiffDelete:
+			if isOld {
+				sb.WriteString(highlightStyle.Render(wd.Text))
+			}
+		case diffmatchpatch.DiffInsert:
+			if !isOld {
+				sb.WriteString(highlightStyle.Render(wd.Text))
+			}
+		}
+	}
+	return sb.String()
+}
@@ -3,7 +3,6 @@ package antigravity
 import (
 	"context"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -25,8 +24,6 @@ const (
 	ClientSecret = "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"
 	AuthURL      = "https://accounts.google.com/o/oauth2/v2/auth"
 	TokenURL     = "https://oauth2.googleapis.com/token"
-	RedirectURL  = "http://localhost:51121/oauth-callback"
-	CallbackPort = ":51121"
 )
 
 var Scopes = []string{
@@ -190,24 +187,16 @@ func (tm *TokenManager) RefreshToken(ctx context.Context) error {
 
 // AuthFlowResult holds the result of the auth flow.
 type AuthFlowResult struct {
-	Verifier string
-	State    string
-	AuthURL  string
+	State   string
+	AuthURL string
 }
 
-// StartAuth generates the PKCE challenge and authorization URL.
-func StartAuth() (*AuthFlowResult, error) {
-	// Generate PKCE Verifier
-	verifierBytes := make([]byte, 32)
-	if _, err := rand.Read(verifierBytes); err != nil {
-		return nil, err
-	}
-	verifier := base64.RawURLEncoding.EncodeToString(verifierBytes)
-
-	// Generate Challenge (S256)
-	hash := sha256.Sum256([]byte(verifier))
-	challenge := base64.RawURLEncoding.EncodeToString(hash[:])
-
+// StartAuth builds the authorization URL for redirectURI/challenge -
+// normally a CallbackServer's RedirectURI/Challenge (see
+// StartCallbackServer in server.go), which is what actually owns the PKCE
+// verifier and the loopback port the provider redirects back to - plus a
+// fresh anti-CSRF state value.
+func StartAuth(redirectURI, challenge string) (*AuthFlowResult, error) {
 	// Generate State
 	stateBytes := make([]byte, 16)
 	if _, err := rand.Read(stateBytes); err != nil {
@@ -223,7 +212,7 @@ func StartAuth() (*AuthFlowResult, error) {
 	q := u.Query()
 	q.Set("client_id", ClientID)
 	q.Set("response_type", "code")
-	q.Set("redirect_uri", RedirectURL)
+	q.Set("redirect_uri", redirectURI)
 	q.Set("scope", strings.Join(Scopes, " "))
 	q.Set("code_challenge", challenge)
 	q.Set("code_challenge_method", "S256")
@@ -233,20 +222,21 @@ func StartAuth() (*AuthFlowResult, error) {
 	u.RawQuery = q.Encode()
 
 	return &AuthFlowResult{
-		Verifier: verifier,
-		State:    state,
-		AuthURL:  u.String(),
+		State:   state,
+		AuthURL: u.String(),
 	}, nil
 }
 
-// ExchangeCode executes the code exchange for tokens.
-func (tm *TokenManager) ExchangeCode(ctx context.Context, code, verifier string) (*Token, error) {
+// ExchangeCode executes the code exchange for tokens. redirectURI must
+// match the one StartAuth built the authorization URL with (Google
+// validates the two are identical).
+func (tm *TokenManager) ExchangeCode(ctx context.Context, code, verifier, redirectURI string) (*Token, error) {
 	data := url.Values{}
 	data.Set("client_id", ClientID)
 	data.Set("client_secret", ClientSecret)
 	data.Set("code", code)
 	data.Set("grant_type", "authorization_code")
-	data.Set("redirect_uri", RedirectURL)
+	data.Set("redirect_uri", redirectURI)
 	data.Set("code_verifier", verifier)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", TokenURL, strings.NewReader(data.Encode()))
@@ -341,60 +331,3 @@ func RefreshToken(ctx context.Context, refreshToken string) (*Token, error) {
 	token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
 	return &token, nil
 }
-
-// WaitForCallback starts a local HTTP server to listen for the OAuth callback.
-// Returns the code and state, or an error.
-func WaitForCallback(ctx context.Context, expectedState string) (string, error) {
-	codeChan := make(chan string)
-	errChan := make(chan error)
-
-	mux := http.NewServeMux()
-	mux.HandleFunc("/oauth-callback", func(w http.ResponseWriter, r *http.Request) {
-		q := r.URL.Query()
-		state := q.Get("state")
-		code := q.Get("code")
-		errStr := q.Get("error")
-
-		if state != expectedState {
-			http.Error(w, "Invalid state", http.StatusBadRequest)
-			errChan <- fmt.Errorf("invalid state received")
-			return
-		}
-
-		if errStr != "" {
-			http.Error(w, "Auth failed: "+errStr, http.StatusBadRequest)
-			errChan <- fmt.Errorf("auth failed: %s", errStr)
-			return
-		}
-
-		if code == "" {
-			http.Error(w, "No code received", http.StatusBadRequest)
-			errChan <- fmt.Errorf("no code received")
-			return
-		}
-
-		// Success
-		w.Write([]byte("Authentication successful! You can close this window and return to the terminal."))
-		codeChan <- code
-	})
-
-	server := &http.Server{Addr: CallbackPort, Handler: mux}
-
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- err
-		}
-	}()
-
-	// Cleanup server on return
-	defer server.Close()
-
-	select {
-	case code := <-codeChan:
-		return code, nil
-	case err := <-errChan:
-		return "", err
-	case <-ctx.Done():
-		return "", ctx.Err()
-	}
-}
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -169,17 +170,17 @@ func TestGetToken_Expired_Refresh(t *testing.T) {
 }
 
 func TestStartAuth(t *testing.T) {
-	res, err := StartAuth()
+	res, err := StartAuth("http://127.0.0.1:1234/oauth-callback", "fake-challenge")
 	if err != nil {
 		t.Fatalf("StartAuth failed: %v", err)
 	}
 
-	if res.Verifier == "" {
-		t.Error("Verifier is empty")
-	}
 	if res.State == "" {
 		t.Error("State is empty")
 	}
+	if !strings.Contains(res.AuthURL, "code_challenge=fake-challenge") {
+		t.Error("expected AuthURL to carry the given code_challenge")
+	}
 }
 
 func TestExchangeCode(t *testing.T) {
@@ -212,7 +213,7 @@ func TestExchangeCode(t *testing.T) {
 	defer os.RemoveAll(tmpDir)
 
 	tm := &TokenManager{tokenFile: filepath.Join(tmpDir, "t.json")}
-	token, err := tm.ExchangeCode(context.Background(), "fake-code", "fake-verifier")
+	token, err := tm.ExchangeCode(context.Background(), "fake-code", "fake-verifier", "http://127.0.0.1:1234/oauth-callback")
 	if err != nil {
 		t.Fatalf("ExchangeCode failed: %v", err)
 	}
@@ -225,49 +226,6 @@ func TestExchangeCode(t *testing.T) {
 	}
 }
 
-func TestWaitForCallback(t *testing.T) {
-	// Start the wait in a goroutine
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-
-	resultChan := make(chan string)
-	errChan := make(chan error)
-
-	go func() {
-		code, err := WaitForCallback(ctx, "test-state")
-		if err != nil {
-			errChan <- err
-			return
-		}
-		resultChan <- code
-	}()
-
-	// Give server time to start
-	time.Sleep(100 * time.Millisecond)
-
-	// Simulate callback
-	resp, err := http.Get("http://localhost:51121/oauth-callback?state=test-state&code=test-code")
-	if err != nil {
-		t.Fatalf("Failed to make callback request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("Callback returned status %d", resp.StatusCode)
-	}
-
-	select {
-	case code := <-resultChan:
-		if code != "test-code" {
-			t.Errorf("Expected code test-code, got %s", code)
-		}
-	case err := <-errChan:
-		t.Fatalf("WaitForCallback failed: %v", err)
-	case <-ctx.Done():
-		t.Fatal("WaitForCallback timed out")
-	}
-}
-
 // RoundTripFunc .
 type RoundTripFunc func(req *http.Request) (*http.Response, error)
 
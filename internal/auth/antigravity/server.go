@@ -2,19 +2,132 @@ package antigravity
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 )
 
-// StartCallbackServer starts a local HTTP server to listen for the OAuth callback.
-// Returns the code and state, or an error.
-func StartCallbackServer(ctx context.Context, expectedState string) (string, error) {
+// pkceCharset is the RFC 7636 "unreserved" character set a code_verifier is
+// built from: ALPHA / DIGIT / "-" / "." / "_" / "~".
+const pkceCharset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~"
+
+// pkceVerifierLength sits inside RFC 7636's required 43-128 character range.
+const pkceVerifierLength = 64
+
+// GeneratePKCE returns a fresh PKCE code_verifier and its S256
+// code_challenge (base64url, unpadded), per RFC 7636.
+func GeneratePKCE() (verifier, challenge string, err error) {
+	raw := make([]byte, pkceVerifierLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("generate verifier: %w", err)
+	}
+	for i, b := range raw {
+		raw[i] = pkceCharset[int(b)%len(pkceCharset)]
+	}
+	verifier = string(raw)
+
+	hash := sha256.Sum256([]byte(verifier))
+	challenge = base64.RawURLEncoding.EncodeToString(hash[:])
+	return verifier, challenge, nil
+}
+
+// CallbackOptions customizes the loopback server StartCallbackServer binds.
+type CallbackOptions struct {
+	// RedirectPath is the HTTP path the OAuth provider redirects back to.
+	// Defaults to "/oauth-callback".
+	RedirectPath string
+	// SuccessHTML is served on a successful callback. Defaults to a plain
+	// "you can close this tab" page.
+	SuccessHTML string
+}
+
+// defaultSuccessHTML is served when CallbackOptions.SuccessHTML is unset.
+const defaultSuccessHTML = `
+<html>
+<head><title>Success</title></head>
+<body style="font-family: sans-serif; text-align: center; padding: 50px;">
+	<h1 style="color: #4CAF50;">Authentication Successful!</h1>
+	<p>You can now close this tab and return to the CLI.</p>
+	<script>window.close();</script>
+</body>
+</html>
+`
+
+// CallbackServer is a loopback HTTP server bound to an OS-assigned free
+// port, generated by StartCallbackServer. Its RedirectURI/Challenge are
+// known as soon as it's constructed - before the caller builds the
+// authorization URL and opens a browser - and Wait then blocks for the
+// provider's redirect.
+type CallbackServer struct {
+	listener    net.Listener
+	server      *http.Server
+	path        string
+	successHTML string
+
+	// RedirectURI is the redirect_uri to use when building the
+	// authorization URL, pointing back at this server's assigned port.
+	RedirectURI string
+	// Verifier is this flow's PKCE code_verifier; pass it to the token
+	// exchange request.
+	Verifier string
+	// Challenge is this flow's PKCE code_challenge (S256); include it as
+	// code_challenge/code_challenge_method=S256 in the authorization URL.
+	Challenge string
+}
+
+// StartCallbackServer binds a loopback HTTP server on 127.0.0.1:0 (an
+// OS-assigned free port) and generates a PKCE code_verifier/code_challenge
+// pair, returning before it waits for any request. Binding to port 0 instead
+// of a fixed port removes the "port already in use" failure mode when
+// multiple codenerd instances run an auth flow concurrently; callers inject
+// the returned RedirectURI and Challenge into their authorization URL, open
+// a browser, and then call Wait to block for the resulting redirect.
+func StartCallbackServer(opts CallbackOptions) (*CallbackServer, error) {
+	path := opts.RedirectPath
+	if path == "" {
+		path = "/oauth-callback"
+	}
+	successHTML := opts.SuccessHTML
+	if successHTML == "" {
+		successHTML = defaultSuccessHTML
+	}
+
+	verifier, challenge, err := GeneratePKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen on loopback: %w", err)
+	}
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	redirectURI := fmt.Sprintf("http://127.0.0.1:%d%s", port, path)
+
+	return &CallbackServer{
+		listener:    listener,
+		path:        path,
+		successHTML: successHTML,
+		RedirectURI: redirectURI,
+		Verifier:    verifier,
+		Challenge:   challenge,
+	}, nil
+}
+
+// Wait serves s's bound listener until the OAuth provider redirects back
+// with an authorization code matching expectedState, an error, or ctx is
+// done, then shuts the server down.
+func (s *CallbackServer) Wait(ctx context.Context, expectedState string) (string, error) {
 	codeChan := make(chan string, 1)
 	errChan := make(chan error, 1)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/oauth-callback", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc(s.path, func(w http.ResponseWriter, r *http.Request) {
 		q := r.URL.Query()
 		state := q.Get("state")
 		code := q.Get("code")
@@ -38,49 +151,38 @@ func StartCallbackServer(ctx context.Context, expectedState string) (string, err
 			return
 		}
 
-		// Success response with auto-close script
 		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(`
-			<html>
-			<head><title>Success</title></head>
-			<body style="font-family: sans-serif; text-align: center; padding: 50px;">
-				<h1 style="color: #4CAF50;">Authentication Successful!</h1>
-				<p>You can now close this tab and return to the CLI.</p>
-				<script>window.close();</script>
-			</body>
-			</html>
-		`))
-
+		w.Write([]byte(s.successHTML))
 		codeChan <- code
 	})
 
-	server := &http.Server{Addr: CallbackPort, Handler: mux}
-
-	// Start server
+	s.server = &http.Server{Handler: mux}
 	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := s.server.Serve(s.listener); err != nil && err != http.ErrServerClosed {
 			errChan <- err
 		}
 	}()
 
-	// Wait for result or timeout/cancellation
 	select {
 	case code := <-codeChan:
-		// Graceful shutdown
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		defer cancel()
-		server.Shutdown(shutdownCtx)
+		_ = s.server.Shutdown(shutdownCtx)
 		return code, nil
 	case err := <-errChan:
-		server.Close()
+		_ = s.Close()
 		return "", err
 	case <-ctx.Done():
-		server.Close()
+		_ = s.Close()
 		return "", ctx.Err()
 	}
 }
 
-// WaitForCallback is an alias for StartCallbackServer for backward compatibility.
-func WaitForCallback(ctx context.Context, expectedState string) (string, error) {
-	return StartCallbackServer(ctx, expectedState)
+// Close shuts the server (or, if Wait was never called, just the listener)
+// down immediately.
+func (s *CallbackServer) Close() error {
+	if s.server != nil {
+		return s.server.Close()
+	}
+	return s.listener.Close()
 }
@@ -0,0 +1,105 @@
+package antigravity
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGeneratePKCE(t *testing.T) {
+	verifier, challenge, err := GeneratePKCE()
+	if err != nil {
+		t.Fatalf("GeneratePKCE failed: %v", err)
+	}
+
+	if len(verifier) < 43 || len(verifier) > 128 {
+		t.Errorf("verifier length %d outside RFC 7636's 43-128 range", len(verifier))
+	}
+	const unreserved = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~"
+	for _, c := range verifier {
+		if !strings.ContainsRune(unreserved, c) {
+			t.Fatalf("verifier contains non-unreserved character %q", c)
+		}
+	}
+
+	if challenge == "" {
+		t.Error("challenge is empty")
+	}
+	if challenge == verifier {
+		t.Error("challenge should not equal verifier")
+	}
+
+	// Challenges should differ between calls.
+	_, challenge2, err := GeneratePKCE()
+	if err != nil {
+		t.Fatalf("GeneratePKCE failed: %v", err)
+	}
+	if challenge == challenge2 {
+		t.Error("expected two GeneratePKCE calls to produce different challenges")
+	}
+}
+
+func TestStartCallbackServer_DynamicPort(t *testing.T) {
+	srv, err := StartCallbackServer(CallbackOptions{})
+	if err != nil {
+		t.Fatalf("StartCallbackServer failed: %v", err)
+	}
+	defer srv.Close()
+
+	if srv.RedirectURI == "" {
+		t.Error("RedirectURI is empty")
+	}
+	if strings.Contains(srv.RedirectURI, ":51121") {
+		t.Error("RedirectURI should not be bound to the old fixed port")
+	}
+	if srv.Verifier == "" || srv.Challenge == "" {
+		t.Error("Verifier/Challenge should be populated")
+	}
+}
+
+func TestCallbackServer_Wait(t *testing.T) {
+	srv, err := StartCallbackServer(CallbackOptions{})
+	if err != nil {
+		t.Fatalf("StartCallbackServer failed: %v", err)
+	}
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resultChan := make(chan string, 1)
+	errChan := make(chan error, 1)
+	go func() {
+		code, err := srv.Wait(ctx, "test-state")
+		if err != nil {
+			errChan <- err
+			return
+		}
+		resultChan <- code
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	resp, err := http.Get(srv.RedirectURI + "?state=test-state&code=test-code")
+	if err != nil {
+		t.Fatalf("Failed to make callback request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Callback returned status %d", resp.StatusCode)
+	}
+
+	select {
+	case code := <-resultChan:
+		if code != "test-code" {
+			t.Errorf("Expected code test-code, got %s", code)
+		}
+	case err := <-errChan:
+		t.Fatalf("Wait failed: %v", err)
+	case <-ctx.Done():
+		t.Fatal("Wait timed out")
+	}
+}
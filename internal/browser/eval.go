@@ -0,0 +1,61 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-rod/rod"
+)
+
+// maxEvalOutputBytes bounds how much JSON-encoded result EvaluateExpr will
+// return before truncating - arbitrary page scripts can return arbitrarily
+// large structures (e.g. the full DOM serialized to JSON).
+const maxEvalOutputBytes = 256 * 1024
+
+// EvaluateExpr runs expression (a JS expression, not a statement list) in
+// sessionID's page, making argsJSON (a JSON-encoded value, or "" for none)
+// available to it as a local `args` binding, and returns the result
+// JSON-encoded. The page's promises are awaited before the result is read.
+//
+// world selects the JS execution context: "main" (default) runs alongside
+// the page's own scripts; "isolated" is accepted for forward compatibility
+// with chromedp-style isolated-world evaluation but currently behaves the
+// same as "main" - this rod version doesn't expose CDP's
+// Page.createIsolatedWorld through EvalOptions, so there is no separate
+// context to isolate into yet.
+func (m *SessionManager) EvaluateExpr(ctx context.Context, sessionID, expression, argsJSON, world string) (string, error) {
+	if err := m.ensureStarted(ctx); err != nil {
+		return "", err
+	}
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return "", fmt.Errorf("unknown session: %s", sessionID)
+	}
+	_ = world // see doc comment: isolated-world execution is not yet supported
+
+	argsExpr := argsJSON
+	if argsExpr == "" {
+		argsExpr = "undefined"
+	}
+
+	res, err := page.Context(ctx).Evaluate(&rod.EvalOptions{
+		JS:           fmt.Sprintf("() => { const args = %s; return (%s); }", argsExpr, expression),
+		ByValue:      true,
+		AwaitPromise: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("evaluate failed: %w", err)
+	}
+	if res == nil || res.Value.Nil() {
+		return "null", nil
+	}
+
+	raw, err := res.Value.MarshalJSON()
+	if err != nil {
+		return "", fmt.Errorf("marshal eval result: %w", err)
+	}
+	if len(raw) <= maxEvalOutputBytes {
+		return string(raw), nil
+	}
+	return fmt.Sprintf("%s... [truncated, result exceeded %d bytes]", raw[:maxEvalOutputBytes], maxEvalOutputBytes), nil
+}
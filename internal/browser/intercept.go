@@ -0,0 +1,321 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// InterceptRule describes one request-matching rule applied by
+// EnableIntercept: requests matching URLGlob (via filepath.Match, the same
+// glob convention used elsewhere in the repo) or one of ResourceTypes are
+// either blocked outright or have SetHeaders merged into their request
+// headers before continuing.
+type InterceptRule struct {
+	URLGlob       string            `json:"url_glob,omitempty"`
+	ResourceTypes []string          `json:"resource_types,omitempty"`
+	Block         bool              `json:"block,omitempty"`
+	SetHeaders    map[string]string `json:"set_headers,omitempty"`
+}
+
+func (r InterceptRule) matches(url string, resourceType proto.NetworkResourceType) bool {
+	if r.URLGlob != "" {
+		if ok, _ := filepath.Match(r.URLGlob, url); ok {
+			return true
+		}
+	}
+	for _, rt := range r.ResourceTypes {
+		if strings.EqualFold(rt, string(resourceType)) {
+			return true
+		}
+	}
+	return false
+}
+
+// HAREntry is one request/response pair captured while interception is
+// enabled for a session, in roughly HAR 1.2 "entry" shape.
+type HAREntry struct {
+	StartedAt    time.Time         `json:"startedDateTime"`
+	URL          string            `json:"url"`
+	Method       string            `json:"method"`
+	Status       int               `json:"status"`
+	MimeType     string            `json:"mimeType"`
+	RequestSize  int               `json:"requestSize"`
+	ResponseSize int               `json:"responseSize"`
+	TimeMs       int64             `json:"timeMs"`
+	Blocked      bool              `json:"blocked"`
+	RequestBody  string            `json:"requestBody,omitempty"`
+	ReqHeaders   map[string]string `json:"requestHeaders,omitempty"`
+	ResHeaders   map[string]string `json:"responseHeaders,omitempty"`
+}
+
+// maxHARBodyBytes bounds how large a POST body EnableIntercept will buffer
+// into a HAREntry.RequestBody - large uploads are recorded by size only.
+const maxHARBodyBytes = 8192
+
+type interceptRecord struct {
+	mu      sync.Mutex
+	rules   []InterceptRule
+	entries []HAREntry
+	cancel  context.CancelFunc
+}
+
+// EnableIntercept turns on CDP request interception for sessionID: matching
+// requests are blocked or have headers rewritten per rules, and every
+// request/response is buffered (timings, status, mimeType, sizes, and a
+// truncated POST body) for later export via ExportHAR. Calling it again for
+// the same session replaces the active rule set without restarting capture.
+func (m *SessionManager) EnableIntercept(ctx context.Context, sessionID string, rules []InterceptRule) error {
+	if err := m.ensureStarted(ctx); err != nil {
+		return err
+	}
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return fmt.Errorf("unknown session: %s", sessionID)
+	}
+
+	m.mu.Lock()
+	if m.intercepts == nil {
+		m.intercepts = make(map[string]*interceptRecord)
+	}
+	rec, exists := m.intercepts[sessionID]
+	if exists {
+		rec.mu.Lock()
+		rec.rules = rules
+		rec.mu.Unlock()
+		m.mu.Unlock()
+		return nil
+	}
+	rec = &interceptRecord{rules: rules}
+	m.intercepts[sessionID] = rec
+	m.mu.Unlock()
+
+	if err := (proto.NetworkEnable{}).Call(page); err != nil {
+		return fmt.Errorf("network enable: %w", err)
+	}
+	if err := (proto.FetchEnable{
+		Patterns: []*proto.FetchRequestPattern{{URLPattern: "*"}},
+	}).Call(page); err != nil {
+		return fmt.Errorf("fetch enable: %w", err)
+	}
+
+	interceptCtx, cancel := context.WithCancel(ctx)
+	rec.cancel = cancel
+
+	go m.runIntercept(interceptCtx, sessionID, page, rec)
+	return nil
+}
+
+// DisableIntercept stops request interception for sessionID, if active, and
+// discards the session's buffered HAR entries.
+func (m *SessionManager) DisableIntercept(sessionID string) {
+	m.mu.Lock()
+	rec, ok := m.intercepts[sessionID]
+	if ok {
+		delete(m.intercepts, sessionID)
+	}
+	m.mu.Unlock()
+	if ok && rec.cancel != nil {
+		rec.cancel()
+	}
+}
+
+// ExportHAR returns the buffered HAR entries for sessionID as HAR 1.2 JSON.
+// Entries are available only while interception is active for the session;
+// after DisableIntercept, ExportHAR returns an error.
+func (m *SessionManager) ExportHAR(sessionID string) ([]byte, error) {
+	m.mu.RLock()
+	rec, ok := m.intercepts[sessionID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no intercept log for session: %s", sessionID)
+	}
+
+	rec.mu.Lock()
+	entries := make([]HAREntry, len(rec.entries))
+	copy(entries, rec.entries)
+	rec.mu.Unlock()
+
+	har := map[string]interface{}{
+		"log": map[string]interface{}{
+			"version": "1.2",
+			"creator": map[string]string{"name": "codenerd", "version": "1.0"},
+			"entries": harEntriesToHAR(entries),
+		},
+	}
+	return json.MarshalIndent(har, "", "  ")
+}
+
+func harEntriesToHAR(entries []HAREntry) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, map[string]interface{}{
+			"startedDateTime": e.StartedAt.Format(time.RFC3339Nano),
+			"time":            e.TimeMs,
+			"request": map[string]interface{}{
+				"method":   e.Method,
+				"url":      e.URL,
+				"headers":  headersToHARList(e.ReqHeaders),
+				"bodySize": e.RequestSize,
+				"postData": e.RequestBody,
+			},
+			"response": map[string]interface{}{
+				"status":  e.Status,
+				"content": map[string]interface{}{"mimeType": e.MimeType, "size": e.ResponseSize},
+				"headers": headersToHARList(e.ResHeaders),
+			},
+			"blocked": e.Blocked,
+		})
+	}
+	return out
+}
+
+func headersToHARList(headers map[string]string) []map[string]string {
+	out := make([]map[string]string, 0, len(headers))
+	for k, v := range headers {
+		out = append(out, map[string]string{"name": k, "value": v})
+	}
+	return out
+}
+
+// runIntercept drains Fetch.requestPaused/Network.responseReceived events
+// for page, applying rec's current rules and recording each request into
+// rec.entries. Runs until ctx is cancelled (via DisableIntercept or session
+// teardown).
+func (m *SessionManager) runIntercept(ctx context.Context, sessionID string, page *rod.Page, rec *interceptRecord) {
+	pending := make(map[proto.NetworkRequestID]*HAREntry)
+	var pendingMu sync.Mutex
+
+	wait := page.Context(ctx).EachEvent(
+		func(ev *proto.FetchRequestPaused) {
+			rec.mu.Lock()
+			rules := rec.rules
+			rec.mu.Unlock()
+
+			var blockRule, headerRule *InterceptRule
+			for i := range rules {
+				if !rules[i].matches(ev.Request.URL, ev.ResourceType) {
+					continue
+				}
+				if rules[i].Block && blockRule == nil {
+					r := rules[i]
+					blockRule = &r
+				}
+				if len(rules[i].SetHeaders) > 0 && headerRule == nil {
+					r := rules[i]
+					headerRule = &r
+				}
+			}
+
+			started := time.Now()
+			reqHeaders := flattenHeaders(ev.Request.Headers)
+			entry := &HAREntry{
+				StartedAt:   started,
+				URL:         ev.Request.URL,
+				Method:      ev.Request.Method,
+				RequestSize: len(ev.Request.PostData),
+				ReqHeaders:  reqHeaders,
+			}
+			if len(ev.Request.PostData) > 0 {
+				body := ev.Request.PostData
+				if len(body) > maxHARBodyBytes {
+					body = body[:maxHARBodyBytes]
+				}
+				entry.RequestBody = body
+			}
+
+			if blockRule != nil {
+				entry.Blocked = true
+				entry.TimeMs = time.Since(started).Milliseconds()
+				rec.mu.Lock()
+				rec.entries = append(rec.entries, *entry)
+				rec.mu.Unlock()
+				if err := (proto.FetchFailRequest{
+					RequestID:   ev.RequestID,
+					ErrorReason: proto.NetworkErrorReasonBlockedByClient,
+				}).Call(page); err != nil {
+					log.Printf("[session:%s] fetch fail request: %v", sessionID, err)
+				}
+				return
+			}
+
+			pendingMu.Lock()
+			pending[proto.NetworkRequestID(ev.RequestID)] = entry
+			pendingMu.Unlock()
+
+			continueReq := proto.FetchContinueRequest{RequestID: ev.RequestID}
+			if headerRule != nil {
+				merged := make(map[string]string, len(reqHeaders)+len(headerRule.SetHeaders))
+				for k, v := range reqHeaders {
+					merged[k] = v
+				}
+				for k, v := range headerRule.SetHeaders {
+					merged[k] = v
+				}
+				entry.ReqHeaders = merged
+				headers := make([]*proto.FetchHeaderEntry, 0, len(merged))
+				for k, v := range merged {
+					headers = append(headers, &proto.FetchHeaderEntry{Name: k, Value: v})
+				}
+				continueReq.Headers = headers
+			}
+			if err := continueReq.Call(page); err != nil {
+				log.Printf("[session:%s] fetch continue request: %v", sessionID, err)
+			}
+		},
+		func(ev *proto.NetworkResponseReceived) {
+			pendingMu.Lock()
+			entry, ok := pending[ev.RequestID]
+			if ok {
+				delete(pending, ev.RequestID)
+			}
+			pendingMu.Unlock()
+			if !ok || ev.Response == nil {
+				return
+			}
+			resHeaders := flattenHeaders(ev.Response.Headers)
+			entry.Status = ev.Response.Status
+			entry.MimeType = ev.Response.MimeType
+			entry.ResponseSize = contentLength(resHeaders)
+			entry.ResHeaders = resHeaders
+			entry.TimeMs = time.Since(entry.StartedAt).Milliseconds()
+
+			rec.mu.Lock()
+			rec.entries = append(rec.entries, *entry)
+			rec.mu.Unlock()
+		},
+	)
+
+	wait()
+}
+
+func flattenHeaders(headers proto.NetworkHeaders) map[string]string {
+	out := make(map[string]string, len(headers))
+	for k, v := range headers {
+		out[k] = fmt.Sprintf("%v", v)
+	}
+	return out
+}
+
+// contentLength reads a best-effort response size from a Content-Length
+// header; CDP's Network.responseReceived doesn't carry the transferred byte
+// count directly, only the headers the server sent.
+func contentLength(headers map[string]string) int {
+	for k, v := range headers {
+		if strings.EqualFold(k, "content-length") {
+			if n, err := strconv.Atoi(v); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,256 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// FileEntry is one row of a parsed directory-listing page.
+type FileEntry struct {
+	Name    string `json:"name"`
+	IsDir   bool   `json:"is_dir"`
+	Size    int64  `json:"size,omitempty"`
+	ModTime string `json:"mod_time,omitempty"`
+	Href    string `json:"href"`
+}
+
+// autoindexRowPattern pulls the trailing "DD-Mon-YYYY HH:MM   <size>" (or
+// "-" for a directory) that Apache's mod_autoindex and nginx's autoindex
+// both append as plain text after each <a> in a directory listing.
+var autoindexRowPattern = regexp.MustCompile(`(\d{2}-\w{3}-\d{4} \d{2}:\d{2})\s+(-|\d+)`)
+
+// skippedHref matches links a directory-listing page renders that aren't
+// actual entries: parent-directory links and Apache's column-sort links.
+func skippedHref(href string) bool {
+	return href == "" || href == "../" || href == "." || strings.HasPrefix(href, "?") || strings.HasPrefix(href, "#")
+}
+
+// ParseListingHTML parses an Apache mod_autoindex / nginx autoindex style
+// directory-listing page into FileEntry records. baseURL resolves each
+// entry's Href to an absolute URL; pass "" to leave Href as found in the
+// page.
+func ParseListingHTML(htmlContent, baseURL string) ([]FileEntry, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("parse listing HTML: %w", err)
+	}
+
+	base, _ := url.Parse(baseURL)
+
+	var entries []FileEntry
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			href := getAttr(n, "href")
+			if !skippedHref(href) {
+				entries = append(entries, entryFromAnchor(n, href, base))
+			}
+			return // entries don't nest anchors
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return entries, nil
+}
+
+// entryFromAnchor builds a FileEntry from a directory-listing <a> tag,
+// reading its name from the link text and its size/mtime from the plain
+// text autoindexRowPattern expects immediately after the link.
+func entryFromAnchor(a *html.Node, href string, base *url.URL) FileEntry {
+	name := strings.TrimSpace(nodeText(a))
+	if name == "" {
+		name = href
+	}
+	isDir := strings.HasSuffix(href, "/")
+
+	entry := FileEntry{
+		Name:  strings.TrimSuffix(name, "/"),
+		IsDir: isDir,
+		Href:  href,
+	}
+	if base != nil {
+		if resolved, err := base.Parse(href); err == nil {
+			entry.Href = resolved.String()
+		}
+	}
+
+	if m := autoindexRowPattern.FindStringSubmatch(trailingRowText(a)); m != nil {
+		entry.ModTime = m[1]
+		if m[2] != "-" {
+			if size, err := strconv.ParseInt(m[2], 10, 64); err == nil {
+				entry.Size = size
+			}
+		}
+	}
+	return entry
+}
+
+// trailingRowText collects the plain text that follows a immediately after
+// the anchor (its next siblings, stopping at the next link), which is where
+// Apache/nginx autoindex put the modification time and size.
+func trailingRowText(a *html.Node) string {
+	var sb strings.Builder
+	for s := a.NextSibling; s != nil; s = s.NextSibling {
+		if s.Type == html.ElementNode && s.Data == "a" {
+			break
+		}
+		sb.WriteString(nodeText(s))
+	}
+	return sb.String()
+}
+
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(nodeText(c))
+	}
+	return sb.String()
+}
+
+// jsonListingEntry is the shape nginx's "autoindex_format json" emits.
+type jsonListingEntry struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"` // "directory" or "file"
+	MTime string `json:"mtime"`
+	Size  int64  `json:"size"`
+}
+
+// ParseListingJSON parses nginx's "autoindex_format json;" style array
+// output into FileEntry records.
+func ParseListingJSON(data, baseURL string) ([]FileEntry, error) {
+	var raw []jsonListingEntry
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("parse listing JSON: %w", err)
+	}
+
+	base, _ := url.Parse(baseURL)
+	entries := make([]FileEntry, 0, len(raw))
+	for _, r := range raw {
+		isDir := r.Type == "directory"
+		href := r.Name
+		if isDir {
+			href += "/"
+		}
+		entry := FileEntry{Name: r.Name, IsDir: isDir, Size: r.Size, ModTime: r.MTime, Href: href}
+		if base != nil {
+			if resolved, err := base.Parse(href); err == nil {
+				entry.Href = resolved.String()
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ParseListing parses content as a directory listing, trying the nginx
+// JSON format first and falling back to HTML - the two formats a server
+// returns for the same listing depending on its autoindex_format setting.
+func ParseListing(content, baseURL string) ([]FileEntry, error) {
+	if trimmed := strings.TrimSpace(content); strings.HasPrefix(trimmed, "[") {
+		if entries, err := ParseListingJSON(trimmed, baseURL); err == nil {
+			return entries, nil
+		}
+	}
+	return ParseListingHTML(content, baseURL)
+}
+
+// MatchesGlobs reports whether name satisfies include (entry allowed if it
+// matches any pattern, or if include is empty) and exclude (entry rejected
+// if it matches any pattern) glob filters.
+func MatchesGlobs(name string, include, exclude []string) bool {
+	for _, pat := range exclude {
+		if ok, _ := filepath.Match(pat, name); ok {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, pat := range include {
+		if ok, _ := filepath.Match(pat, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ListDirectory parses sessionID's current page as a directory listing and,
+// for maxDepth > 0, recurses into each subdirectory entry by navigating the
+// session to it and parsing again, prefixing nested entries' Name with
+// their path from the root. include/exclude are filepath.Match glob
+// patterns applied to each entry's Name (exclude wins on conflict);
+// directories always pass the filter themselves since they must be
+// descended into to reach anything they filter.
+func (m *SessionManager) ListDirectory(ctx context.Context, sessionID string, maxDepth int, include, exclude []string) ([]FileEntry, error) {
+	if err := m.ensureStarted(ctx); err != nil {
+		return nil, err
+	}
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return nil, fmt.Errorf("unknown session: %s", sessionID)
+	}
+
+	info, err := page.Info()
+	if err != nil {
+		return nil, fmt.Errorf("get page info: %w", err)
+	}
+
+	return m.listDirectoryAt(ctx, sessionID, info.URL, "", maxDepth, include, exclude)
+}
+
+func (m *SessionManager) listDirectoryAt(ctx context.Context, sessionID, pageURL, prefix string, depthLeft int, include, exclude []string) ([]FileEntry, error) {
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return nil, fmt.Errorf("unknown session: %s", sessionID)
+	}
+	content, err := page.Context(ctx).HTML()
+	if err != nil {
+		return nil, fmt.Errorf("read page HTML: %w", err)
+	}
+
+	rows, err := ParseListing(content, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []FileEntry
+	for _, row := range rows {
+		row.Name = path.Join(prefix, row.Name)
+		if row.IsDir {
+			row.Name += "/"
+		}
+
+		if !row.IsDir && !MatchesGlobs(row.Name, include, exclude) {
+			continue
+		}
+
+		if row.IsDir && depthLeft > 0 {
+			if err := m.Navigate(ctx, sessionID, row.Href); err != nil {
+				return nil, fmt.Errorf("navigate to %s: %w", row.Href, err)
+			}
+			nested, err := m.listDirectoryAt(ctx, sessionID, row.Href, strings.TrimSuffix(row.Name, "/"), depthLeft-1, include, exclude)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, nested...)
+			continue
+		}
+
+		entries = append(entries, row)
+	}
+	return entries, nil
+}
@@ -0,0 +1,196 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// FieldSpec describes how to pull one named field out of the page for
+// Scrape: Selector locates the element(s); Attr/HTML/Text (Text is the
+// default when neither is set) choose what to read off them; Multiple
+// collects every match instead of just the first; Transform post-processes
+// the raw string (see applyScrapeTransform); Children, if set, is evaluated
+// relative to each matched element instead of reading a value directly -
+// the way a list of product cards nests title/price/link under one
+// repeated selector.
+type FieldSpec struct {
+	Selector  string               `json:"selector"`
+	Attr      string               `json:"attr,omitempty"`
+	HTML      bool                 `json:"html,omitempty"`
+	Text      bool                 `json:"text,omitempty"`
+	Multiple  bool                 `json:"multiple,omitempty"`
+	Transform string               `json:"transform,omitempty"`
+	Children  map[string]FieldSpec `json:"children,omitempty"`
+}
+
+// numberPattern strips everything but digits, '.', and '-' so "number"
+// transforms can pull a value out of text like "$12.99" or "1,234 reviews".
+var numberPattern = regexp.MustCompile(`[^0-9.\-]+`)
+
+// Scrape walks schema against sessionID's current page and returns the
+// extracted fields, the names of any top-level fields that found no
+// matching element, and the page's URL/title.
+func (m *SessionManager) Scrape(ctx context.Context, sessionID string, schema map[string]FieldSpec) (fields map[string]interface{}, missing []string, pageURL, pageTitle string, err error) {
+	if err = m.ensureStarted(ctx); err != nil {
+		return nil, nil, "", "", err
+	}
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return nil, nil, "", "", fmt.Errorf("unknown session: %s", sessionID)
+	}
+
+	info, infoErr := page.Info()
+	if infoErr == nil && info != nil {
+		pageURL = info.URL
+		pageTitle = info.Title
+	}
+
+	bound := page.Context(ctx)
+	fields = make(map[string]interface{}, len(schema))
+	for name, spec := range schema {
+		val, found := scrapeFromPage(bound, pageURL, spec)
+		if !found {
+			missing = append(missing, name)
+			continue
+		}
+		fields[name] = val
+	}
+	return fields, missing, pageURL, pageTitle, nil
+}
+
+func scrapeFromPage(page *rod.Page, pageURL string, spec FieldSpec) (interface{}, bool) {
+	if spec.Multiple {
+		elements, err := page.Elements(spec.Selector)
+		if err != nil || len(elements) == 0 {
+			return nil, false
+		}
+		return scrapeElementList(elements, pageURL, spec), true
+	}
+	el, err := page.Element(spec.Selector)
+	if err != nil {
+		return nil, false
+	}
+	return scrapeOneElement(el, pageURL, spec)
+}
+
+func scrapeFromElement(root *rod.Element, pageURL string, spec FieldSpec) (interface{}, bool) {
+	if spec.Multiple {
+		elements, err := root.Elements(spec.Selector)
+		if err != nil || len(elements) == 0 {
+			return nil, false
+		}
+		return scrapeElementList(elements, pageURL, spec), true
+	}
+	el, err := root.Element(spec.Selector)
+	if err != nil {
+		return nil, false
+	}
+	return scrapeOneElement(el, pageURL, spec)
+}
+
+func scrapeElementList(elements rod.Elements, pageURL string, spec FieldSpec) []interface{} {
+	results := make([]interface{}, 0, len(elements))
+	for _, el := range elements {
+		val, found := scrapeOneElement(el, pageURL, spec)
+		if found {
+			results = append(results, val)
+		}
+	}
+	return results
+}
+
+func scrapeOneElement(el *rod.Element, pageURL string, spec FieldSpec) (interface{}, bool) {
+	if len(spec.Children) > 0 {
+		child := make(map[string]interface{}, len(spec.Children))
+		for name, childSpec := range spec.Children {
+			val, found := scrapeFromElement(el, pageURL, childSpec)
+			if found {
+				child[name] = val
+			}
+		}
+		return child, true
+	}
+	return extractElementValue(el, pageURL, spec)
+}
+
+func extractElementValue(el *rod.Element, pageURL string, spec FieldSpec) (interface{}, bool) {
+	var raw string
+	switch {
+	case spec.Attr != "":
+		val, err := el.Attribute(spec.Attr)
+		if err != nil || val == nil {
+			return nil, false
+		}
+		raw = *val
+	case spec.HTML:
+		html, err := el.HTML()
+		if err != nil {
+			return nil, false
+		}
+		raw = html
+	default:
+		text, err := el.Text()
+		if err != nil {
+			return nil, false
+		}
+		raw = text
+	}
+	return applyScrapeTransform(raw, spec.Transform, pageURL), true
+}
+
+// applyScrapeTransform post-processes a raw extracted string. Supported
+// transforms: "trim", "number" (strips non-numeric characters and parses a
+// float64), "url_absolute" (resolves against pageURL), and
+// "regex:<pattern>:<group>" (the first match's capture group, default 0 -
+// the whole match). An unrecognized or failing transform returns raw
+// unchanged rather than dropping the field.
+func applyScrapeTransform(raw, transform, pageURL string) interface{} {
+	switch {
+	case transform == "":
+		return raw
+	case transform == "trim":
+		return strings.TrimSpace(raw)
+	case transform == "number":
+		cleaned := numberPattern.ReplaceAllString(raw, "")
+		n, err := strconv.ParseFloat(cleaned, 64)
+		if err != nil {
+			return raw
+		}
+		return n
+	case transform == "url_absolute":
+		base, err := url.Parse(pageURL)
+		if err != nil {
+			return raw
+		}
+		ref, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			return raw
+		}
+		return base.ResolveReference(ref).String()
+	case strings.HasPrefix(transform, "regex:"):
+		spec := strings.SplitN(strings.TrimPrefix(transform, "regex:"), ":", 2)
+		group := 0
+		if len(spec) > 1 {
+			if g, err := strconv.Atoi(spec[1]); err == nil {
+				group = g
+			}
+		}
+		re, err := regexp.Compile(spec[0])
+		if err != nil {
+			return raw
+		}
+		m := re.FindStringSubmatch(raw)
+		if m == nil || group >= len(m) {
+			return raw
+		}
+		return m[group]
+	default:
+		return raw
+	}
+}
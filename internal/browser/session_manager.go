@@ -17,15 +17,17 @@ import (
 	"codenerd/internal/mangle"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/google/uuid"
 )
 
-// Session describes the public metadata for a tracked browser context.
+// Session describes the public metadata for a tracked page/target.
 type Session struct {
 	ID         string    `json:"id"`
+	ContextID  string    `json:"context_id,omitempty"`
 	TargetID   string    `json:"target_id,omitempty"`
 	URL        string    `json:"url,omitempty"`
 	Title      string    `json:"title,omitempty"`
@@ -34,11 +36,24 @@ type Session struct {
 	LastActive time.Time `json:"last_active"`
 }
 
+// BrowserContext describes an isolated incognito browser context - its own
+// cookie jar and storage - that can host multiple pages/targets.
+type BrowserContext struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type sessionRecord struct {
 	meta Session
 	page *rod.Page
 }
 
+type contextRecord struct {
+	meta      BrowserContext
+	incognito *rod.Browser
+	pageIDs   map[string]bool
+}
+
 type eventThrottler struct {
 	interval time.Duration
 	mu       sync.Mutex
@@ -84,6 +99,7 @@ type Config struct {
 	EnableDOMIngestion    bool     `json:"enable_dom_ingestion"`
 	EnableHeaderIngestion bool     `json:"enable_header_ingestion"`
 	EventThrottleMs       int      `json:"event_throttle_ms"`
+	MaxContexts           int      `json:"max_contexts"`
 }
 
 // DefaultConfig returns sensible defaults.
@@ -96,9 +112,19 @@ func DefaultConfig() Config {
 		EventLoggingLevel:   "normal",
 		EnableDOMIngestion:  true,
 		EventThrottleMs:     100,
+		MaxContexts:         8,
 	}
 }
 
+// GetMaxContexts returns the configured context limit, falling back to
+// DefaultConfig's value if unset.
+func (c Config) GetMaxContexts() int {
+	if c.MaxContexts <= 0 {
+		return 8
+	}
+	return c.MaxContexts
+}
+
 // IsHeadless returns the headless setting.
 func (c Config) IsHeadless() bool {
 	return c.Headless
@@ -142,13 +168,18 @@ func (a *engineAdapter) AddFacts(facts []mangle.Fact) error {
 	return a.engine.AddFacts(facts)
 }
 
-// SessionManager owns the detached Chrome instance and tracks active sessions.
+// SessionManager owns the detached Chrome instance and tracks active
+// sessions, each grouped under an isolated incognito BrowserContext so
+// concurrent research tasks (parallel scrapes, tab-per-domain logins) don't
+// bleed cookies/storage into one another.
 type SessionManager struct {
 	cfg        Config
 	engine     EngineSink
 	mu         sync.RWMutex
 	browser    *rod.Browser
 	sessions   map[string]*sessionRecord
+	contexts   map[string]*contextRecord
+	intercepts map[string]*interceptRecord
 	controlURL string // WebSocket URL for DevTools
 }
 
@@ -159,18 +190,22 @@ func NewSessionManager(cfg Config, engine *mangle.Engine) *SessionManager {
 		sink = &engineAdapter{engine: engine}
 	}
 	return &SessionManager{
-		cfg:      cfg,
-		engine:   sink,
-		sessions: make(map[string]*sessionRecord),
+		cfg:        cfg,
+		engine:     sink,
+		sessions:   make(map[string]*sessionRecord),
+		contexts:   make(map[string]*contextRecord),
+		intercepts: make(map[string]*interceptRecord),
 	}
 }
 
 // NewSessionManagerWithSink creates a session manager with a custom sink.
 func NewSessionManagerWithSink(cfg Config, sink EngineSink) *SessionManager {
 	return &SessionManager{
-		cfg:      cfg,
-		engine:   sink,
-		sessions: make(map[string]*sessionRecord),
+		cfg:        cfg,
+		engine:     sink,
+		sessions:   make(map[string]*sessionRecord),
+		contexts:   make(map[string]*contextRecord),
+		intercepts: make(map[string]*interceptRecord),
 	}
 }
 
@@ -190,6 +225,8 @@ func (m *SessionManager) Start(ctx context.Context) error {
 		m.browser = nil
 		m.controlURL = ""
 		m.sessions = make(map[string]*sessionRecord)
+		m.contexts = make(map[string]*contextRecord)
+		m.intercepts = make(map[string]*interceptRecord)
 	}
 
 	if err := m.loadSessionsLocked(); err != nil {
@@ -280,6 +317,8 @@ func (m *SessionManager) Shutdown(ctx context.Context) error {
 		}
 		delete(m.sessions, id)
 	}
+	m.contexts = make(map[string]*contextRecord)
+	m.intercepts = make(map[string]*interceptRecord)
 
 	var err error
 	if m.browser != nil {
@@ -303,21 +342,61 @@ func (m *SessionManager) List() []Session {
 	return results
 }
 
-// CreateSession opens a new page and tracks it.
-func (m *SessionManager) CreateSession(ctx context.Context, url string) (*Session, error) {
+// NewContext opens a fresh isolated incognito browser context - its own
+// cookie jar and storage - that NewPage can then populate with one or more
+// pages. Bounded by Config.MaxContexts so parallel research agents can't
+// collide on (and exhaust) a single global browser process.
+func (m *SessionManager) NewContext(ctx context.Context) (*BrowserContext, error) {
 	if err := m.ensureStarted(ctx); err != nil {
 		return nil, err
 	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.browser == nil {
 		return nil, errors.New("browser not connected")
 	}
 
+	if max := m.cfg.GetMaxContexts(); len(m.contexts) >= max {
+		return nil, fmt.Errorf("max contexts reached (%d); close an existing context first", max)
+	}
+
 	incognito, err := m.browser.Incognito()
 	if err != nil {
 		return nil, fmt.Errorf("incognito context: %w", err)
 	}
 
-	page, err := incognito.Page(proto.TargetCreateTarget{URL: url})
+	meta := BrowserContext{ID: uuid.NewString(), CreatedAt: time.Now()}
+	m.contexts[meta.ID] = &contextRecord{meta: meta, incognito: incognito, pageIDs: make(map[string]bool)}
+	return &meta, nil
+}
+
+// ListContexts returns metadata for all known browser contexts.
+func (m *SessionManager) ListContexts() []BrowserContext {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	results := make([]BrowserContext, 0, len(m.contexts))
+	for _, rec := range m.contexts {
+		results = append(results, rec.meta)
+	}
+	return results
+}
+
+// NewPage opens a new page/target inside contextID and tracks it as a
+// session, addressable by its own Session.ID within that context.
+func (m *SessionManager) NewPage(ctx context.Context, contextID, url string) (*Session, error) {
+	if err := m.ensureStarted(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	rec, ok := m.contexts[contextID]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown context: %s", contextID)
+	}
+
+	page, err := rec.incognito.Page(proto.TargetCreateTarget{URL: url})
 	if err != nil {
 		return nil, fmt.Errorf("create page: %w", err)
 	}
@@ -337,6 +416,7 @@ func (m *SessionManager) CreateSession(ctx context.Context, url string) (*Sessio
 
 	meta := Session{
 		ID:         uuid.NewString(),
+		ContextID:  contextID,
 		TargetID:   string(page.TargetID),
 		URL:        url,
 		Status:     "active",
@@ -346,6 +426,7 @@ func (m *SessionManager) CreateSession(ctx context.Context, url string) (*Sessio
 
 	m.mu.Lock()
 	m.sessions[meta.ID] = &sessionRecord{meta: meta, page: page}
+	rec.pageIDs[meta.ID] = true
 	m.mu.Unlock()
 
 	m.startEventStream(ctx, meta.ID, page)
@@ -354,6 +435,35 @@ func (m *SessionManager) CreateSession(ctx context.Context, url string) (*Sessio
 	return &meta, nil
 }
 
+// ListPages returns metadata for every page tracked under contextID.
+func (m *SessionManager) ListPages(contextID string) ([]Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rec, ok := m.contexts[contextID]
+	if !ok {
+		return nil, fmt.Errorf("unknown context: %s", contextID)
+	}
+	results := make([]Session, 0, len(rec.pageIDs))
+	for id := range rec.pageIDs {
+		if s, ok := m.sessions[id]; ok {
+			results = append(results, s.meta)
+		}
+	}
+	return results, nil
+}
+
+// CreateSession opens a new page in its own fresh browser context and
+// tracks it. This is the single-page-per-context shorthand most callers
+// want; use NewContext/NewPage directly to host several pages in one
+// context (shared cookies/storage).
+func (m *SessionManager) CreateSession(ctx context.Context, url string) (*Session, error) {
+	bctx, err := m.NewContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.NewPage(ctx, bctx.ID, url)
+}
+
 // Attach binds to an existing target by TargetID.
 func (m *SessionManager) Attach(ctx context.Context, targetID string) (*Session, error) {
 	if err := m.ensureStarted(ctx); err != nil {
@@ -603,21 +713,7 @@ func (m *SessionManager) ForkSession(ctx context.Context, sessionID, url string)
 	}
 
 	// Restore cookies
-	params := make([]*proto.NetworkCookieParam, 0, len(cookiesRes.Cookies))
-	for _, c := range cookiesRes.Cookies {
-		params = append(params, &proto.NetworkCookieParam{
-			Name:     c.Name,
-			Value:    c.Value,
-			Domain:   c.Domain,
-			Path:     c.Path,
-			Expires:  c.Expires,
-			HTTPOnly: c.HTTPOnly,
-			Secure:   c.Secure,
-			SameSite: c.SameSite,
-			Priority: c.Priority,
-		})
-	}
-	if len(params) > 0 {
+	if params := cookiesToParams(cookiesRes.Cookies); len(params) > 0 {
 		_ = destPage.SetCookies(params)
 	}
 
@@ -632,6 +728,27 @@ func (m *SessionManager) ForkSession(ctx context.Context, sessionID, url string)
 	return dest, nil
 }
 
+// cookiesToParams converts CDP cookies (as returned by
+// Network.getAllCookies) into the NetworkCookieParam shape Network.setCookies
+// expects, for replaying a cookie snapshot into a different page/context.
+func cookiesToParams(cookies []*proto.NetworkCookie) []*proto.NetworkCookieParam {
+	params := make([]*proto.NetworkCookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		params = append(params, &proto.NetworkCookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  c.Expires,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			SameSite: c.SameSite,
+			Priority: c.Priority,
+		})
+	}
+	return params
+}
+
 // Navigate navigates to a URL.
 func (m *SessionManager) Navigate(ctx context.Context, sessionID, url string) error {
 	if err := m.ensureStarted(ctx); err != nil {
@@ -676,6 +793,173 @@ func (m *SessionManager) Type(ctx context.Context, sessionID, selector, text str
 	return el.Input(text)
 }
 
+// WaitForSelector blocks until selector appears in the DOM, or ctx is done.
+func (m *SessionManager) WaitForSelector(ctx context.Context, sessionID, selector string) error {
+	if err := m.ensureStarted(ctx); err != nil {
+		return err
+	}
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return fmt.Errorf("unknown session: %s", sessionID)
+	}
+	_, err := page.Context(ctx).Element(selector)
+	if err != nil {
+		return fmt.Errorf("selector %s did not appear: %w", selector, err)
+	}
+	return nil
+}
+
+// WaitForNavigation blocks until the page's load event fires, or ctx is done.
+func (m *SessionManager) WaitForNavigation(ctx context.Context, sessionID string) error {
+	if err := m.ensureStarted(ctx); err != nil {
+		return err
+	}
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return fmt.Errorf("unknown session: %s", sessionID)
+	}
+	return page.Context(ctx).WaitLoad()
+}
+
+// Select sets a <select> element's value and dispatches a change event.
+func (m *SessionManager) Select(ctx context.Context, sessionID, selector, value string) error {
+	if err := m.ensureStarted(ctx); err != nil {
+		return err
+	}
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return fmt.Errorf("unknown session: %s", sessionID)
+	}
+	_, err := page.Context(ctx).Evaluate(&rod.EvalOptions{
+		JS: `
+		(sel, val) => {
+			const el = document.querySelector(sel);
+			if (!el) throw new Error('element not found: ' + sel);
+			el.value = val;
+			el.dispatchEvent(new Event('change', { bubbles: true }));
+		}
+		`,
+		JSArgs:       []interface{}{selector, value},
+		ByValue:      true,
+		AwaitPromise: true,
+	})
+	if err != nil {
+		return fmt.Errorf("select %s=%s: %w", selector, value, err)
+	}
+	return nil
+}
+
+// pressKeys maps the key names BrowserRunActionsTool's "press" step accepts
+// to rod's input.Key constants. Deliberately a small, named subset rather
+// than the full keyboard - an honest approximation covering the keys page
+// actions actually need (Enter to submit, Tab/Escape to navigate focus),
+// not every key on a keyboard.
+var pressKeys = map[string]input.Key{
+	"Enter":      input.Enter,
+	"Tab":        input.Tab,
+	"Escape":     input.Escape,
+	"Backspace":  input.Backspace,
+	"Space":      input.Space,
+	"ArrowUp":    input.ArrowUp,
+	"ArrowDown":  input.ArrowDown,
+	"ArrowLeft":  input.ArrowLeft,
+	"ArrowRight": input.ArrowRight,
+}
+
+// Press sends a named key press (see pressKeys for supported names) to the page.
+func (m *SessionManager) Press(ctx context.Context, sessionID, key string) error {
+	if err := m.ensureStarted(ctx); err != nil {
+		return err
+	}
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return fmt.Errorf("unknown session: %s", sessionID)
+	}
+	k, ok := pressKeys[key]
+	if !ok {
+		return fmt.Errorf("unsupported key: %s", key)
+	}
+	return page.Context(ctx).Keyboard.Type(k)
+}
+
+// Evaluate runs script (wrapped in a function so a bare `return` works) and
+// returns its result coerced to a string.
+func (m *SessionManager) Evaluate(ctx context.Context, sessionID, script string) (string, error) {
+	if err := m.ensureStarted(ctx); err != nil {
+		return "", err
+	}
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return "", fmt.Errorf("unknown session: %s", sessionID)
+	}
+	res, err := page.Context(ctx).Evaluate(&rod.EvalOptions{
+		JS:           fmt.Sprintf("() => { return (function() {\n%s\n})(); }", script),
+		ByValue:      true,
+		AwaitPromise: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("evaluate failed: %w", err)
+	}
+	if res == nil || res.Value.Nil() {
+		return "", nil
+	}
+	return res.Value.String(), nil
+}
+
+// ExtractAttribute returns the named attribute of the first element matching
+// selector, or its text content if attribute is empty.
+func (m *SessionManager) ExtractAttribute(ctx context.Context, sessionID, selector, attribute string) (string, error) {
+	if err := m.ensureStarted(ctx); err != nil {
+		return "", err
+	}
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return "", fmt.Errorf("unknown session: %s", sessionID)
+	}
+	el, err := page.Context(ctx).Element(selector)
+	if err != nil {
+		return "", fmt.Errorf("element not found: %s", selector)
+	}
+	if attribute == "" {
+		return el.Text()
+	}
+	val, err := el.Attribute(attribute)
+	if err != nil {
+		return "", fmt.Errorf("attribute %s: %w", attribute, err)
+	}
+	if val == nil {
+		return "", nil
+	}
+	return *val, nil
+}
+
+// AssertSelector reports whether selector exists on the page and, if
+// wantText is non-empty, whether the matched element's text contains it.
+func (m *SessionManager) AssertSelector(ctx context.Context, sessionID, selector, wantText string) (bool, error) {
+	if err := m.ensureStarted(ctx); err != nil {
+		return false, err
+	}
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return false, fmt.Errorf("unknown session: %s", sessionID)
+	}
+	has, el, err := page.Context(ctx).Has(selector)
+	if err != nil {
+		return false, err
+	}
+	if !has {
+		return false, nil
+	}
+	if wantText == "" {
+		return true, nil
+	}
+	text, err := el.Text()
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(text, wantText), nil
+}
+
 // Screenshot captures a screenshot.
 func (m *SessionManager) Screenshot(ctx context.Context, sessionID string, fullPage bool) ([]byte, error) {
 	if err := m.ensureStarted(ctx); err != nil {
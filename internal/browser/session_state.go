@@ -0,0 +1,89 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// StorageState is a Playwright-style snapshot of a session's cookies and
+// per-origin localStorage/sessionStorage, serializable to JSON so it can be
+// persisted across process runs and replayed into a fresh session later.
+type StorageState struct {
+	URL            string                 `json:"url,omitempty"`
+	Cookies        []*proto.NetworkCookie `json:"cookies"`
+	LocalStorage   string                 `json:"local_storage"`
+	SessionStorage string                 `json:"session_storage"`
+	SavedAt        time.Time              `json:"saved_at"`
+}
+
+// ExportState dumps sessionID's cookies and localStorage/sessionStorage into
+// a StorageState, JSON-encoded, so it can be written to disk and replayed
+// later via CreateSessionWithState.
+func (m *SessionManager) ExportState(sessionID string) ([]byte, error) {
+	page, ok := m.Page(sessionID)
+	if !ok {
+		return nil, fmt.Errorf("unknown session: %s", sessionID)
+	}
+	meta, _ := m.GetSession(sessionID)
+
+	cookiesRes, err := proto.NetworkGetCookies{}.Call(page)
+	if err != nil {
+		return nil, fmt.Errorf("get cookies: %w", err)
+	}
+
+	state := StorageState{
+		URL:            meta.URL,
+		Cookies:        cookiesRes.Cookies,
+		LocalStorage:   snapshotStorage(page, "localStorage"),
+		SessionStorage: snapshotStorage(page, "sessionStorage"),
+		SavedAt:        time.Now(),
+	}
+
+	return json.MarshalIndent(state, "", "  ")
+}
+
+// CreateSessionWithState creates a new session navigated to url (falling
+// back to the state's saved URL, then about:blank), then replays stateJSON's
+// cookies and localStorage/sessionStorage into it before returning - so an
+// interactive login performed once can be re-entered headlessly later.
+func (m *SessionManager) CreateSessionWithState(ctx context.Context, url string, stateJSON []byte) (*Session, error) {
+	var state StorageState
+	if err := json.Unmarshal(stateJSON, &state); err != nil {
+		return nil, fmt.Errorf("decode storage state: %w", err)
+	}
+
+	targetURL := url
+	if targetURL == "" {
+		targetURL = state.URL
+		if targetURL == "" {
+			targetURL = "about:blank"
+		}
+	}
+
+	dest, err := m.CreateSession(ctx, targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+
+	destPage, ok := m.Page(dest.ID)
+	if !ok {
+		return dest, nil
+	}
+
+	if params := cookiesToParams(state.Cookies); len(params) > 0 {
+		_ = destPage.SetCookies(params)
+	}
+	restoreStorage(destPage, state.LocalStorage, state.SessionStorage)
+
+	m.UpdateMetadata(dest.ID, func(s Session) Session {
+		s.Status = "restored"
+		return s
+	})
+	_ = m.persistSessions()
+
+	return dest, nil
+}
@@ -0,0 +1,182 @@
+package checkpoint
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"codenerd/internal/logging"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLiteCheckpointStore persists shard checkpoints to a SQLite database.
+//
+// Storage location: .nerd/checkpoints.db
+type SQLiteCheckpointStore struct {
+	db     *sql.DB
+	mu     sync.RWMutex
+	dbPath string
+}
+
+// NewSQLiteCheckpointStore creates a new checkpoint store at the given path.
+func NewSQLiteCheckpointStore(dbPath string) (*SQLiteCheckpointStore, error) {
+	logging.StoreDebug("Initializing SQLiteCheckpointStore at path: %s", dbPath)
+
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logging.Get(logging.CategoryStore).Error("Failed to create checkpoint store directory %s: %v", dir, err)
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		logging.Get(logging.CategoryStore).Error("Failed to open checkpoint store database at %s: %v", dbPath, err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	if _, err := db.Exec("PRAGMA busy_timeout = 5000"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to set busy_timeout: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA journal_mode = WAL"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to set journal_mode: %w", err)
+	}
+
+	store := &SQLiteCheckpointStore{db: db, dbPath: dbPath}
+	if err := store.initialize(); err != nil {
+		logging.Get(logging.CategoryStore).Error("Failed to initialize checkpoint store schema: %v", err)
+		db.Close()
+		return nil, err
+	}
+
+	logging.Store("SQLiteCheckpointStore initialized at %s", dbPath)
+	return store, nil
+}
+
+// initialize creates the database schema.
+func (s *SQLiteCheckpointStore) initialize() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS checkpoints (
+		shard_id TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value BLOB NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (shard_id, key)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_checkpoints_shard ON checkpoints(shard_id);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// Save writes (or overwrites) the checkpoint at (shardID, key).
+func (s *SQLiteCheckpointStore) Save(shardID, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO checkpoints (shard_id, key, value, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(shard_id, key) DO UPDATE SET
+			value = excluded.value,
+			updated_at = excluded.updated_at`,
+		shardID, key, value,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint %s/%s: %w", shardID, key, err)
+	}
+	return nil
+}
+
+// Load returns the checkpoint at (shardID, key), or ok=false if none has
+// been saved.
+func (s *SQLiteCheckpointStore) Load(shardID, key string) ([]byte, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var value []byte
+	err := s.db.QueryRow(`SELECT value FROM checkpoints WHERE shard_id = ? AND key = ?`, shardID, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load checkpoint %s/%s: %w", shardID, key, err)
+	}
+	return value, true, nil
+}
+
+// List returns every checkpoint saved for shardID, keyed by key.
+func (s *SQLiteCheckpointStore) List(shardID string) (map[string][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT key, value FROM checkpoints WHERE shard_id = ?`, shardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints for %s: %w", shardID, err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]byte)
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan checkpoint row for %s: %w", shardID, err)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// Delete removes every checkpoint saved for shardID.
+func (s *SQLiteCheckpointStore) Delete(shardID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.db.Exec(`DELETE FROM checkpoints WHERE shard_id = ?`, shardID); err != nil {
+		return fmt.Errorf("failed to delete checkpoints for %s: %w", shardID, err)
+	}
+	return nil
+}
+
+// ListShards returns every shardID with at least one persisted checkpoint.
+func (s *SQLiteCheckpointStore) ListShards() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`SELECT DISTINCT shard_id FROM checkpoints`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpointed shards: %w", err)
+	}
+	defer rows.Close()
+
+	var shardIDs []string
+	for rows.Next() {
+		var shardID string
+		if err := rows.Scan(&shardID); err != nil {
+			return nil, fmt.Errorf("failed to scan shard row: %w", err)
+		}
+		shardIDs = append(shardIDs, shardID)
+	}
+	return shardIDs, nil
+}
+
+// Close closes the database connection.
+func (s *SQLiteCheckpointStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.db != nil {
+		logging.Store("Closing SQLiteCheckpointStore at %s", s.dbPath)
+		return s.db.Close()
+	}
+	return nil
+}
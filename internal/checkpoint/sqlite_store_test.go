@@ -0,0 +1,107 @@
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *SQLiteCheckpointStore {
+	t.Helper()
+	store, err := NewSQLiteCheckpointStore(filepath.Join(t.TempDir(), "checkpoints.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteCheckpointStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteCheckpointStore_SaveLoadRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.Save("shard-1", "progress", []byte(`{"step":3}`)); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	value, ok, err := store.Load("shard-1", "progress")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected the checkpoint to be found")
+	}
+	if string(value) != `{"step":3}` {
+		t.Fatalf("unexpected value: %s", value)
+	}
+
+	if _, ok, err := store.Load("shard-1", "missing"); err != nil || ok {
+		t.Fatalf("expected ok=false, err=nil for a missing key, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestSQLiteCheckpointStore_SaveOverwritesExistingKey(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.Save("shard-1", "progress", []byte("first")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Save("shard-1", "progress", []byte("second")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	value, ok, err := store.Load("shard-1", "progress")
+	if err != nil || !ok {
+		t.Fatalf("Load: ok=%v err=%v", ok, err)
+	}
+	if string(value) != "second" {
+		t.Fatalf("expected the second write to win, got %q", value)
+	}
+}
+
+func TestSQLiteCheckpointStore_ListAndDelete(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.Save("shard-1", "a", []byte("1")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Save("shard-1", "b", []byte("2")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	listed, err := store.List("shard-1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(listed) != 2 || string(listed["a"]) != "1" || string(listed["b"]) != "2" {
+		t.Fatalf("unexpected List result: %v", listed)
+	}
+
+	if err := store.Delete("shard-1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	listed, err = store.List("shard-1")
+	if err != nil {
+		t.Fatalf("List after Delete: %v", err)
+	}
+	if len(listed) != 0 {
+		t.Fatalf("expected no checkpoints after Delete, got %v", listed)
+	}
+}
+
+func TestSQLiteCheckpointStore_ListShards(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.Save("shard-1", "a", []byte("1")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Save("shard-2", "a", []byte("1")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	shardIDs, err := store.ListShards()
+	if err != nil {
+		t.Fatalf("ListShards: %v", err)
+	}
+	if len(shardIDs) != 2 {
+		t.Fatalf("expected 2 shards, got %v", shardIDs)
+	}
+}
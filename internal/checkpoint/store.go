@@ -0,0 +1,27 @@
+// Package checkpoint persists shard checkpoint state so cooperative
+// suspend/resume (see internal/core/api_scheduler.go) survives a process
+// crash or restart, not just a yield within the same process.
+package checkpoint
+
+// CheckpointStore persists opaque per-shard key/value checkpoints.
+// Values are caller-encoded byte blobs; CheckpointStore doesn't interpret
+// them. Implementations must be safe for concurrent use.
+type CheckpointStore interface {
+	// Save writes (or overwrites) the checkpoint at (shardID, key).
+	Save(shardID, key string, value []byte) error
+
+	// Load returns the checkpoint at (shardID, key), or ok=false if none
+	// has been saved.
+	Load(shardID, key string) (value []byte, ok bool, err error)
+
+	// List returns every checkpoint saved for shardID, keyed by key.
+	List(shardID string) (map[string][]byte, error)
+
+	// Delete removes every checkpoint saved for shardID.
+	Delete(shardID string) error
+
+	// ListShards returns every shardID with at least one persisted
+	// checkpoint, so a caller can reattach to in-flight work after
+	// restarting (see APIScheduler.ResumeShards).
+	ListShards() ([]string, error)
+}
@@ -0,0 +1,51 @@
+package config
+
+// PersistenceConfig controls whether a kernel's persistent-category facts
+// (see core.IsPersistent) survive a process restart via an on-disk
+// storage.Store, and whether that store encrypts sensitive predicates at
+// rest.
+type PersistenceConfig struct {
+	// Enabled opts into a Badger-backed storage.Store under
+	// <workspace>/.nerd/store. Defaults to true - most workspaces want
+	// persistent facts to survive a restart. Set false to keep every
+	// session's persistent facts in-memory only.
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// SealSensitive wraps the store in a core.SealedStore so predicates
+	// the classifier marks sensitive are AEAD-encrypted at rest instead of
+	// written in plaintext. Requires a keyring file at
+	// <workspace>/.nerd/keyring.json (see core.FileKeyProvider) and a
+	// passphrase in the SealPassphraseEnv environment variable; if either
+	// is missing, persistence falls back to unsealed and a warning is
+	// logged, rather than failing boot.
+	SealSensitive bool `yaml:"seal_sensitive" json:"seal_sensitive,omitempty"`
+	// SealPassphraseEnv names the environment variable SealSensitive reads
+	// the unlocking passphrase from. Defaults to CODENERD_SEAL_PASSPHRASE.
+	SealPassphraseEnv string `yaml:"seal_passphrase_env,omitempty" json:"seal_passphrase_env,omitempty"`
+}
+
+// DefaultPersistenceConfig returns persistence defaults: enabled, sealing
+// off.
+func DefaultPersistenceConfig() PersistenceConfig {
+	enabled := true
+	return PersistenceConfig{
+		Enabled:           &enabled,
+		SealPassphraseEnv: "CODENERD_SEAL_PASSPHRASE",
+	}
+}
+
+// GetPersistence returns persistence settings with defaults applied.
+func (c *UserConfig) GetPersistence() PersistenceConfig {
+	def := DefaultPersistenceConfig()
+	if c == nil || c.Persistence == nil {
+		return def
+	}
+
+	cfg := *c.Persistence
+	if cfg.Enabled == nil {
+		cfg.Enabled = def.Enabled
+	}
+	if cfg.SealPassphraseEnv == "" {
+		cfg.SealPassphraseEnv = def.SealPassphraseEnv
+	}
+	return cfg
+}
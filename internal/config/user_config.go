@@ -101,6 +101,10 @@ type UserConfig struct {
 	// World model scanning/AST parsing configuration
 	World *WorldConfig `json:"world,omitempty"`
 
+	// Persistent-fact storage configuration (on-disk survival across
+	// restarts, optional at-rest encryption for sensitive predicates)
+	Persistence *PersistenceConfig `json:"persistence,omitempty"`
+
 	// =========================================================================
 	// INTEGRATIONS
 	// =========================================================================
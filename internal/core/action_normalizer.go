@@ -0,0 +1,337 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// indirectExecutors maps the basename of a command that can run an arbitrary
+// nested command string to the flag that introduces it, so NormalizeCommand
+// can recurse into whatever argument follows that flag instead of treating
+// the whole line as an opaque, unparsed string.
+var indirectExecutors = map[string]string{
+	"sh":      "-c",
+	"bash":    "-c",
+	"zsh":     "-c",
+	"python":  "-c",
+	"python3": "-c",
+	"node":    "-e",
+	"perl":    "-e",
+}
+
+// NormalizedCommand is the canonical form of a shell-like command string, as
+// produced by ActionNormalizer.NormalizeCommand. Two commands that differ
+// only in whitespace, flag grouping, or flag order (e.g.
+// "rm -rf /", "rm -fr /", "rm  -r -f /") normalize to the same Argv0 and
+// Flags, so Mangle rules can match on the parsed shape instead of a raw
+// substring. Flag case is kept as written, so "-r" and "-R" stay distinct.
+type NormalizedCommand struct {
+	Raw      string
+	Argv0    string
+	Args     []string
+	Flags    []string
+	Indirect bool
+	Nested   *NormalizedCommand
+}
+
+// String renders the canonical form used for the projected_action fact:
+// argv0, then the deduplicated sorted flags, then the remaining positional
+// arguments in their original order.
+func (n NormalizedCommand) String() string {
+	parts := append([]string{n.Argv0}, n.Flags...)
+	parts = append(parts, n.Args...)
+	return strings.Join(parts, " ")
+}
+
+// IsDangerous reports whether n (or any command it indirectly invokes)
+// matches one of a small set of known-destructive argv0/flag/argument
+// shapes. Unlike a raw substring scan, this survives flag grouping,
+// reordering, and the extra whitespace that defeats substring matching.
+func (n NormalizedCommand) IsDangerous() bool {
+	hasFlag := func(f string) bool {
+		for _, x := range n.Flags {
+			if x == f {
+				return true
+			}
+		}
+		return false
+	}
+	hasArg := func(a string) bool {
+		for _, x := range n.Args {
+			if x == a {
+				return true
+			}
+		}
+		return false
+	}
+	argPrefixed := func(prefix string) bool {
+		for _, x := range n.Args {
+			if strings.HasPrefix(x, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch n.Argv0 {
+	case "rm":
+		if hasFlag("-r") || hasFlag("-R") {
+			return true
+		}
+	case "git":
+		if hasArg("reset") && hasFlag("--hard") {
+			return true
+		}
+	case "terraform":
+		if hasArg("destroy") {
+			return true
+		}
+	case "dd":
+		if argPrefixed("if=") {
+			return true
+		}
+	}
+
+	if n.Indirect {
+		return true
+	}
+	if n.Nested != nil {
+		return n.Nested.IsDangerous()
+	}
+	return false
+}
+
+// ActionNormalizer canonicalizes command strings and file paths before they
+// reach projectEffects, so that Mangle policy written against the
+// normalized shape can't be bypassed by whitespace, flag-grouping, path, or
+// Unicode tricks that still mean the same thing to a real shell or
+// filesystem.
+type ActionNormalizer struct {
+	workspaceRoot string
+}
+
+// NewActionNormalizer creates an ActionNormalizer that rejects paths
+// escaping workspaceRoot. workspaceRoot may be "" to skip the escape check
+// (e.g. when no workspace is known yet).
+func NewActionNormalizer(workspaceRoot string) *ActionNormalizer {
+	return &ActionNormalizer{workspaceRoot: workspaceRoot}
+}
+
+// NormalizeCommand tokenizes raw as a POSIX-ish shell command and returns
+// its canonical form: argv[0] reduced to a lowercased basename, grouped
+// short flags expanded (-rf -> -r -f), flags deduplicated and sorted, and
+// indirect execution (sh -c, eval, python -c, a base64 | sh pipeline, ...)
+// followed into a recursively normalized Nested command.
+func (n *ActionNormalizer) NormalizeCommand(raw string) NormalizedCommand {
+	tokens := tokenizeShellCommand(raw)
+	if len(tokens) == 0 {
+		return NormalizedCommand{Raw: raw}
+	}
+
+	argv0 := strings.ToLower(filepath.Base(tokens[0]))
+	result := NormalizedCommand{Raw: raw, Argv0: argv0}
+
+	flagSet := make(map[string]bool)
+	for _, tok := range tokens[1:] {
+		switch {
+		case strings.HasPrefix(tok, "--"):
+			flagSet[tok] = true
+		case strings.HasPrefix(tok, "-") && len(tok) > 1:
+			for _, f := range expandGroupedFlags(tok) {
+				flagSet[f] = true
+			}
+		default:
+			result.Args = append(result.Args, tok)
+		}
+	}
+	flags := make([]string, 0, len(flagSet))
+	for f := range flagSet {
+		flags = append(flags, f)
+	}
+	sort.Strings(flags)
+	result.Flags = flags
+
+	if argv0 == "eval" {
+		result.Indirect = true
+		nestedRaw := strings.Join(tokens[1:], " ")
+		// eval's argument is conventionally wrapped in "$( ... )"; strip that
+		// so the nested command normalizes the same as if it had been run
+		// directly, instead of parsing "$(rm" as an unrecognized argv0.
+		nestedRaw = strings.TrimSuffix(strings.TrimPrefix(nestedRaw, "$("), ")")
+		nested := n.NormalizeCommand(strings.TrimSpace(nestedRaw))
+		result.Nested = &nested
+		return result
+	}
+
+	// Look up the indirect flag in flagSet (the already-expanded set), not
+	// by re-scanning raw tokens: a grouped short flag like "-ic" is only
+	// ever expanded into flagSet/result.Flags, never substituted back into
+	// tokens, so "bash -ic 'rm -rf /'" would otherwise never match "-c"
+	// and silently skip the Indirect/Nested recursion.
+	if flag, ok := indirectExecutors[argv0]; ok && flagSet[flag] && len(result.Args) > 0 {
+		result.Indirect = true
+		nested := n.NormalizeCommand(result.Args[0])
+		result.Nested = &nested
+		return result
+	}
+
+	lc := strings.ToLower(raw)
+	if strings.Contains(lc, "base64") {
+		for _, pipe := range []string{"|sh", "| sh", "|bash", "| bash"} {
+			if strings.Contains(lc, pipe) {
+				result.Indirect = true
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// tokenizeShellCommand splits s the way a POSIX shell would for the
+// purposes of normalization: single- and double-quoted spans are kept
+// intact (quotes stripped), a backslash escapes the following character,
+// and any run of unquoted whitespace - including multiple consecutive
+// spaces - separates tokens without producing empty ones.
+func tokenizeShellCommand(s string) []string {
+	var tokens []string
+	var cur strings.Builder
+	haveToken := false
+	inSingle, inDouble, escaped := false, false, false
+
+	flush := func() {
+		if haveToken {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+			haveToken = false
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			haveToken = true
+			escaped = false
+		case r == '\\' && !inSingle:
+			escaped = true
+		case inSingle:
+			if r == '\'' {
+				inSingle = false
+			} else {
+				cur.WriteRune(r)
+				haveToken = true
+			}
+		case inDouble:
+			if r == '"' {
+				inDouble = false
+			} else {
+				cur.WriteRune(r)
+				haveToken = true
+			}
+		case r == '\'':
+			inSingle = true
+			haveToken = true
+		case r == '"':
+			inDouble = true
+			haveToken = true
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			cur.WriteRune(r)
+			haveToken = true
+		}
+	}
+	flush()
+	return tokens
+}
+
+// expandGroupedFlags splits a single-dash, multi-letter flag token (e.g.
+// "-rf") into one flag per letter ("-r", "-f"). Long-form ("--") flags and
+// single-letter flags pass through unchanged.
+func expandGroupedFlags(tok string) []string {
+	if !strings.HasPrefix(tok, "-") || strings.HasPrefix(tok, "--") || len(tok) <= 2 {
+		return []string{tok}
+	}
+	letters := tok[1:]
+	flags := make([]string, 0, len(letters))
+	for _, r := range letters {
+		flags = append(flags, "-"+string(r))
+	}
+	return flags
+}
+
+// NormalizedPath is the canonical form of a file path target, as produced
+// by ActionNormalizer.NormalizePath.
+type NormalizedPath struct {
+	Raw         string
+	Normalized  string
+	Escapes     bool
+	MixedScript bool
+}
+
+// NormalizePath NFC-normalizes raw's Unicode, runs filepath.Clean, and
+// flags two conditions a naive strings.Contains check on the raw path would
+// miss: Escapes (the cleaned path climbs above workspaceRoot via "..") and
+// MixedScript (the path mixes letters from more than one Unicode script,
+// e.g. Cyrillic "а" standing in for Latin "a").
+func (n *ActionNormalizer) NormalizePath(raw string) NormalizedPath {
+	nfc := norm.NFC.String(raw)
+	cleaned := filepath.Clean(nfc)
+
+	escapes := cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator))
+	if !escapes && n.workspaceRoot != "" && filepath.IsAbs(cleaned) {
+		rel, err := filepath.Rel(n.workspaceRoot, cleaned)
+		if err == nil && (rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			escapes = true
+		}
+	}
+
+	return NormalizedPath{
+		Raw:         raw,
+		Normalized:  cleaned,
+		Escapes:     escapes,
+		MixedScript: hasMixedScript(cleaned),
+	}
+}
+
+// hasMixedScript reports whether s's letters are drawn from more than one
+// Unicode script (Latin, Cyrillic, Greek, Han, Hiragana, Katakana, Arabic,
+// Hebrew, or a catch-all "other"), the hallmark of a homoglyph substitution
+// attack on a path that otherwise looks ASCII-identical to a trusted one.
+func hasMixedScript(s string) bool {
+	scripts := map[string]bool{}
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			scripts["latin"] = true
+		case unicode.Is(unicode.Cyrillic, r):
+			scripts["cyrillic"] = true
+		case unicode.Is(unicode.Greek, r):
+			scripts["greek"] = true
+		case unicode.Is(unicode.Han, r):
+			scripts["han"] = true
+		case unicode.Is(unicode.Hiragana, r):
+			scripts["hiragana"] = true
+		case unicode.Is(unicode.Katakana, r):
+			scripts["katakana"] = true
+		case unicode.Is(unicode.Arabic, r):
+			scripts["arabic"] = true
+		case unicode.Is(unicode.Hebrew, r):
+			scripts["hebrew"] = true
+		default:
+			scripts["other"] = true
+		}
+		if len(scripts) > 1 {
+			return true
+		}
+	}
+	return false
+}
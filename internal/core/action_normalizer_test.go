@@ -0,0 +1,176 @@
+package core
+
+import "testing"
+
+func TestNormalizeCommand_FlagBypasses(t *testing.T) {
+	n := NewActionNormalizer("")
+
+	cases := []struct {
+		name string
+		cmd  string
+	}{
+		{"double_space", "rm  -rf /"},
+		{"reversed_flags", "rm -fr /"},
+		{"split_flags", "rm -r -f /"},
+	}
+
+	var want string
+	for i, tc := range cases {
+		got := n.NormalizeCommand(tc.cmd)
+		if i == 0 {
+			want = got.String()
+		} else if got.String() != want {
+			t.Errorf("%s: normalized to %q, want %q (same as %s)", tc.name, got.String(), want, cases[0].name)
+		}
+		if !got.IsDangerous() {
+			t.Errorf("%s: expected IsDangerous() true for %q", tc.name, tc.cmd)
+		}
+	}
+}
+
+func TestNormalizeCommand_IndirectExecution(t *testing.T) {
+	n := NewActionNormalizer("")
+
+	cases := []struct {
+		name string
+		cmd  string
+	}{
+		{"sh_dash_c", `sh -c "rm -rf /"`},
+		{"python_dash_c", `python -c "rm -rf /"`},
+		{"eval", `eval $(rm -rf /)`},
+	}
+
+	for _, tc := range cases {
+		got := n.NormalizeCommand(tc.cmd)
+		if !got.Indirect {
+			t.Errorf("%s: expected Indirect=true for %q", tc.name, tc.cmd)
+		}
+		if got.Nested == nil {
+			t.Fatalf("%s: expected a Nested command for %q", tc.name, tc.cmd)
+		}
+		if !got.Nested.IsDangerous() {
+			t.Errorf("%s: expected nested command %q to be dangerous", tc.name, got.Nested.Raw)
+		}
+		if !got.IsDangerous() {
+			t.Errorf("%s: expected outer command to inherit IsDangerous() via Indirect/Nested", tc.name)
+		}
+	}
+}
+
+func TestNormalizeCommand_IndirectExecutionWithGroupedFlags(t *testing.T) {
+	n := NewActionNormalizer("")
+
+	// "-ic" groups the indirect-execution flag ("-c") together with "-i";
+	// the bypass this guards against is relying on an exact "-c" token
+	// match that grouping silently defeats.
+	got := n.NormalizeCommand(`bash -ic "rm -rf /"`)
+	if !got.Indirect {
+		t.Error("expected Indirect=true for a grouped -ic flag")
+	}
+	if got.Nested == nil {
+		t.Fatal("expected a Nested command for a grouped -ic flag")
+	}
+	if !got.Nested.IsDangerous() {
+		t.Errorf("expected nested command %q to be dangerous", got.Nested.Raw)
+	}
+	if !got.IsDangerous() {
+		t.Error("expected outer command to inherit IsDangerous() via Indirect/Nested")
+	}
+}
+
+func TestNormalizeCommand_Base64Pipeline(t *testing.T) {
+	n := NewActionNormalizer("")
+	got := n.NormalizeCommand("echo cm0gLXJmIC8= | base64 -d | sh")
+	if !got.Indirect {
+		t.Error("expected a base64-into-sh pipeline to be flagged Indirect")
+	}
+}
+
+func TestNormalizePath_Traversal(t *testing.T) {
+	n := NewActionNormalizer("/workspace")
+
+	cases := []struct {
+		name    string
+		path    string
+		escapes bool
+	}{
+		{"clean_relative", "internal/core/kernel.go", false},
+		{"dot_dot_relative", "internal/core/../../../etc/passwd", true}, // climbs above the relative start itself
+		{"double_slash", "internal//core", false},
+		{"abs_escape", "/workspace/../etc/passwd", true},
+		{"abs_within", "/workspace/internal/core", false},
+	}
+
+	for _, tc := range cases {
+		got := n.NormalizePath(tc.path)
+		if got.Escapes != tc.escapes {
+			t.Errorf("%s: NormalizePath(%q).Escapes = %v, want %v (normalized=%q)", tc.name, tc.path, got.Escapes, tc.escapes, got.Normalized)
+		}
+	}
+}
+
+func TestNormalizePath_DoubleSlash(t *testing.T) {
+	n := NewActionNormalizer("")
+
+	if got := n.NormalizePath("internal//core/kernel.go").Normalized; got != "internal/core/kernel.go" {
+		t.Errorf("expected double slash collapsed, got %q", got)
+	}
+}
+
+func TestNormalizePath_MixedScript(t *testing.T) {
+	n := NewActionNormalizer("")
+
+	// "pаth.go" where the second letter is Cyrillic "а" (U+0430), not Latin "a".
+	homoglyph := "pаth.go"
+	if got := n.NormalizePath(homoglyph); !got.MixedScript {
+		t.Errorf("expected MixedScript=true for %q", homoglyph)
+	}
+
+	if got := n.NormalizePath("path.go"); got.MixedScript {
+		t.Error("expected MixedScript=false for a pure-ASCII path")
+	}
+}
+
+func TestTokenizeShellCommand_WhitespaceAndQuotes(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"rm  -rf /", []string{"rm", "-rf", "/"}},
+		{`sh -c "echo hi"`, []string{"sh", "-c", "echo hi"}},
+		{"rm -rf 'my file'", []string{"rm", "-rf", "my file"}},
+	}
+	for _, tc := range cases {
+		got := tokenizeShellCommand(tc.in)
+		if len(got) != len(tc.want) {
+			t.Fatalf("tokenizeShellCommand(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("tokenizeShellCommand(%q)[%d] = %q, want %q", tc.in, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestExpandGroupedFlags(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"-rf", []string{"-r", "-f"}},
+		{"-r", []string{"-r"}},
+		{"--force", []string{"--force"}},
+	}
+	for _, tc := range cases {
+		got := expandGroupedFlags(tc.in)
+		if len(got) != len(tc.want) {
+			t.Fatalf("expandGroupedFlags(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("expandGroupedFlags(%q)[%d] = %q, want %q", tc.in, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
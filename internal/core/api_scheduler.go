@@ -1,12 +1,16 @@
 package core
 
 import (
+	"container/heap"
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"codenerd/internal/checkpoint"
+	"codenerd/internal/failpoint"
 	"codenerd/internal/logging"
 )
 
@@ -83,9 +87,61 @@ type ShardExecutionState struct {
 
 // APISchedulerConfig configures the scheduler.
 type APISchedulerConfig struct {
-	MaxConcurrentAPICalls int           // Max simultaneous API calls (matches LLM provider limit)
+	MaxConcurrentAPICalls int           // Hard ceiling on simultaneous API calls (matches LLM provider limit)
 	SlotAcquireTimeout    time.Duration // Max time to wait for a slot
 	EnableMetrics         bool          // Track detailed metrics
+
+	// MinConcurrentAPICalls is the floor the adaptive scaler will not shrink
+	// below. Set equal to MaxConcurrentAPICalls to disable scaling down.
+	MinConcurrentAPICalls int
+
+	// ScaleUpThreshold/ScaleDownThreshold are the samplesIn/samplesOut (or
+	// inverse) ratios that trigger a one-slot scaling step, following
+	// Prometheus's remote-write queue manager pattern.
+	ScaleUpThreshold   float64
+	ScaleDownThreshold float64
+
+	// ScaleInterval is how often the EWMA samplers tick and the scaler
+	// re-evaluates the active slot count. Zero disables adaptive scaling
+	// (the scheduler behaves as a fixed-size semaphore, as before).
+	ScaleInterval time.Duration
+
+	// MaxStarvationDelay is how long a waiter can sit in the priority queue
+	// before the dispatcher promotes it to PriorityCritical, so a steady
+	// stream of high-priority shards can't starve a low-priority one
+	// forever. Zero disables promotion.
+	MaxStarvationDelay time.Duration
+
+	// RateLimitCooldown is the fallback pause before the dispatcher resumes
+	// handing out slots after NotifyRateLimit, used when the provider
+	// didn't advertise a Retry-After duration.
+	RateLimitCooldown time.Duration
+
+	// RateLimitRecoveryInterval is how often, after a rate-limit cooldown
+	// elapses, activeSlotCount is additively grown back by one slot toward
+	// MaxConcurrentAPICalls (the AIMD "additive increase" half of the
+	// 429/529 backpressure loop; see NotifyRateLimit).
+	RateLimitRecoveryInterval time.Duration
+
+	// RequestsPerMinute and TokensPerMinute model the provider's RPM/TPM
+	// account caps, layered on top of the concurrency semaphore above --
+	// MaxConcurrentAPICalls alone can't stop N concurrent shards from
+	// blowing through a 60 RPM cap in the first second. Zero disables the
+	// corresponding bucket.
+	RequestsPerMinute int
+	TokensPerMinute   int
+
+	// EstimateTokens estimates the token cost of a prompt for TPM budgeting
+	// (see AcquireAPISlotForCall). Defaults to a chars/4 heuristic, matching
+	// prompt.EstimateTokens, if left nil.
+	EstimateTokens func(prompt string) int
+
+	// CheckpointStore, if set, makes shard checkpoints durable across
+	// process restarts. RegisterShard preloads any persisted checkpoint for
+	// the shard; SaveCheckpoint write-throughs asynchronously via a bounded
+	// worker pool (see runCheckpointWriter). Nil disables persistence and
+	// the scheduler behaves as before (in-memory only).
+	CheckpointStore checkpoint.CheckpointStore
 }
 
 // DefaultAPISchedulerConfig returns sensible defaults.
@@ -94,6 +150,14 @@ func DefaultAPISchedulerConfig() APISchedulerConfig {
 		MaxConcurrentAPICalls: 5,               // Z.AI limit
 		SlotAcquireTimeout:    5 * time.Minute, // Match typical API timeout
 		EnableMetrics:         true,
+		MinConcurrentAPICalls: 5,
+		ScaleUpThreshold:      1.3,
+		ScaleDownThreshold:    1.3,
+		ScaleInterval:         0, // adaptive scaling opt-in; see ConfigureGlobalAPIScheduler
+		MaxStarvationDelay:    30 * time.Second,
+
+		RateLimitCooldown:         2 * time.Second,
+		RateLimitRecoveryInterval: 1 * time.Second,
 	}
 }
 
@@ -105,7 +169,8 @@ type APIScheduler struct {
 	// State tracking
 	mu          sync.RWMutex
 	shardStates map[string]*ShardExecutionState
-	waitQueue   []*waitingEntry // Shards waiting for slots (for logging/metrics)
+	waitQueue   priorityWaitQueue // Shards waiting for slots, ordered by (priority desc, waitStart asc)
+	dispatchCh  chan struct{}     // non-blocking wake signal for runDispatcher
 
 	// Metrics
 	totalAPICalls      int64
@@ -113,25 +178,233 @@ type APIScheduler struct {
 	currentlyWaiting   int32
 	currentlyExecuting int32
 
+	// Adaptive slot scaling (EWMA-driven, see startAutoScaler).
+	// activeSlotCount is the current effective limit, always <=
+	// config.MaxConcurrentAPICalls; AcquireAPISlot gates on it before
+	// drawing from the (fixed-size) slots semaphore so resizing never
+	// requires closing/recreating the channel.
+	activeSlotCount int32
+	tickAcquires    int64 // acquire attempts since the last scaler tick
+	tickCompletions int64 // completions since the last scaler tick
+
+	scaleMu                   sync.Mutex
+	samplesInPerSec           float64
+	samplesOutPerSec          float64
+	consecutiveScaleDownTicks int
+
+	// Rate-limit backpressure (see NotifyRateLimit). cooldownUntilNanos is a
+	// Unix-nanosecond deadline the dispatcher won't hand out new slots
+	// before; rateLimitGeneration is bumped on every NotifyRateLimit call so
+	// a stale recovery goroutine from an earlier rate limit can tell it's
+	// been superseded and exit instead of fighting a newer one.
+	cooldownUntilNanos  int64
+	rateLimitGeneration int64
+
+	// RPM/TPM account caps (see AcquireAPISlotForCall). Nil when the
+	// corresponding config field is zero, i.e. that budget is unbounded.
+	rpmBucket *tokenBucket
+	tpmBucket *tokenBucket
+
 	// Lifecycle
-	stopCh chan struct{}
+	stopCh          chan struct{}
+	scalerStopped   chan struct{} // non-nil and closed when runAutoScaler exits, if scaling is enabled
+	dispatchStopped chan struct{}
+
+	// Durable checkpoint write-through (see SaveCheckpoint, runCheckpointWriter).
+	// checkpointWrites is non-nil only when config.CheckpointStore is set.
+	checkpointWrites  chan checkpointWrite
+	checkpointWorkers sync.WaitGroup
 }
 
+// checkpointWrite is one pending write-through to config.CheckpointStore.
+type checkpointWrite struct {
+	shardID string
+	key     string
+	value   []byte
+}
+
+const (
+	// checkpointWriteWorkers is the number of goroutines draining
+	// checkpointWrites into the configured CheckpointStore.
+	checkpointWriteWorkers = 4
+	// checkpointWriteQueueSize bounds how many pending writes SaveCheckpoint
+	// will buffer before dropping new ones (logging the drop) rather than
+	// blocking the caller on disk IO.
+	checkpointWriteQueueSize = 256
+)
+
+// waitingEntry is one shard's place in line for an API slot.
 type waitingEntry struct {
 	shardID   string
 	shardType string
 	waitStart time.Time
 	priority  SpawnPriority
+	ready     chan struct{} // closed by runDispatcher when this waiter wins a slot
+	index     int           // heap.Interface bookkeeping; -1 once popped
+}
+
+// priorityWaitQueue is a heap.Interface ordering waiters by
+// (priority desc, waitStart asc): highest priority first, FIFO within a
+// priority tier.
+type priorityWaitQueue []*waitingEntry
+
+func (q priorityWaitQueue) Len() int { return len(q) }
+func (q priorityWaitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].waitStart.Before(q[j].waitStart)
+}
+func (q priorityWaitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *priorityWaitQueue) Push(x interface{}) {
+	entry := x.(*waitingEntry)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+func (q *priorityWaitQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	entry.index = -1
+	*q = old[:n-1]
+	return entry
+}
+
+// -----------------------------------------------------------------------------
+// RPM/TPM Token Buckets
+// -----------------------------------------------------------------------------
+
+// tokenBucket is a simple refilling-rate limiter: capacity tokens refill
+// continuously at ratePerMinute/60 tokens per second, up to capacity. It
+// models a provider's per-minute RPM/TPM account cap, which the concurrency
+// semaphore (s.slots) can't enforce on its own.
+type tokenBucket struct {
+	mu          sync.Mutex
+	capacity    float64
+	tokens      float64
+	refillPerNs float64
+	lastRefill  time.Time
+
+	used       int64 // atomic; tokens consumed since the bucket was created
+	stallNanos int64 // atomic; cumulative time callers spent waiting for refill
+}
+
+func newTokenBucket(capacityPerMinute float64) *tokenBucket {
+	return &tokenBucket{
+		capacity:    capacityPerMinute,
+		tokens:      capacityPerMinute,
+		refillPerNs: capacityPerMinute / float64(time.Minute),
+		lastRefill:  time.Now(),
+	}
+}
+
+// acquire blocks until n tokens are available (refilling as needed) or ctx
+// is cancelled.
+func (b *tokenBucket) acquire(ctx context.Context, n float64) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		elapsed := now.Sub(b.lastRefill)
+		b.tokens = minFloat64(b.capacity, b.tokens+float64(elapsed)*b.refillPerNs)
+		b.lastRefill = now
+
+		if b.tokens >= n {
+			b.tokens -= n
+			b.mu.Unlock()
+			atomic.AddInt64(&b.used, int64(n))
+			return nil
+		}
+
+		deficit := n - b.tokens
+		wait := time.Duration(deficit / b.refillPerNs)
+		b.mu.Unlock()
+
+		stallStart := time.Now()
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+			atomic.AddInt64(&b.stallNanos, int64(time.Since(stallStart)))
+		case <-ctx.Done():
+			timer.Stop()
+			atomic.AddInt64(&b.stallNanos, int64(time.Since(stallStart)))
+			return ctx.Err()
+		}
+	}
+}
+
+func minFloat64(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
 }
 
 // NewAPIScheduler creates a new scheduler.
 func NewAPIScheduler(config APISchedulerConfig) *APIScheduler {
-	return &APIScheduler{
-		config:      config,
-		slots:       make(chan struct{}, config.MaxConcurrentAPICalls),
-		shardStates: make(map[string]*ShardExecutionState),
-		waitQueue:   make([]*waitingEntry, 0),
-		stopCh:      make(chan struct{}),
+	if config.MinConcurrentAPICalls <= 0 {
+		config.MinConcurrentAPICalls = config.MaxConcurrentAPICalls
+	}
+
+	s := &APIScheduler{
+		config:          config,
+		slots:           make(chan struct{}, config.MaxConcurrentAPICalls),
+		shardStates:     make(map[string]*ShardExecutionState),
+		waitQueue:       make(priorityWaitQueue, 0),
+		dispatchCh:      make(chan struct{}, 1),
+		activeSlotCount: int32(config.MaxConcurrentAPICalls),
+		stopCh:          make(chan struct{}),
+		dispatchStopped: make(chan struct{}),
+	}
+
+	go s.runDispatcher()
+
+	if config.ScaleInterval > 0 {
+		s.scalerStopped = make(chan struct{})
+		go s.runAutoScaler()
+	}
+
+	if config.CheckpointStore != nil {
+		s.checkpointWrites = make(chan checkpointWrite, checkpointWriteQueueSize)
+		for i := 0; i < checkpointWriteWorkers; i++ {
+			s.checkpointWorkers.Add(1)
+			go s.runCheckpointWriter()
+		}
+	}
+
+	if config.RequestsPerMinute > 0 {
+		s.rpmBucket = newTokenBucket(float64(config.RequestsPerMinute))
+	}
+	if config.TokensPerMinute > 0 {
+		s.tpmBucket = newTokenBucket(float64(config.TokensPerMinute))
+	}
+
+	return s
+}
+
+// runCheckpointWriter drains checkpointWrites into config.CheckpointStore
+// until the channel is closed by Stop.
+func (s *APIScheduler) runCheckpointWriter() {
+	defer s.checkpointWorkers.Done()
+
+	for write := range s.checkpointWrites {
+		if err := s.config.CheckpointStore.Save(write.shardID, write.key, write.value); err != nil {
+			logging.Get(logging.CategoryShards).Error("APIScheduler: failed to persist checkpoint %s/%s: %v",
+				write.shardID, write.key, err)
+		}
+	}
+}
+
+// signalDispatch wakes runDispatcher without blocking. Safe to call from
+// any goroutine holding or not holding s.mu.
+func (s *APIScheduler) signalDispatch() {
+	select {
+	case s.dispatchCh <- struct{}{}:
+	default:
 	}
 }
 
@@ -150,6 +423,22 @@ func (s *APIScheduler) RegisterShard(shardID, shardType string) *ShardExecutionS
 	s.shardStates[shardID] = state
 
 	logging.Shards("APIScheduler: registered shard %s (type=%s)", shardID, shardType)
+
+	if s.config.CheckpointStore != nil {
+		persisted, err := s.config.CheckpointStore.List(shardID)
+		if err != nil {
+			logging.Get(logging.CategoryShards).Error("APIScheduler: failed to preload checkpoint for shard %s: %v", shardID, err)
+		}
+		for key, raw := range persisted {
+			var value interface{}
+			if err := json.Unmarshal(raw, &value); err != nil {
+				logging.Get(logging.CategoryShards).Error("APIScheduler: failed to decode checkpoint %s/%s: %v", shardID, key, err)
+				continue
+			}
+			state.Checkpoint[key] = value
+		}
+	}
+
 	return state
 }
 
@@ -166,10 +455,81 @@ func (s *APIScheduler) UnregisterShard(shardID string) {
 	}
 }
 
-// AcquireAPISlot acquires permission to make an API call.
+// AcquireAPISlot acquires permission to make an API call at PriorityNormal.
 // Blocks until a slot is available or context is cancelled.
 // The shard enters PhaseWaitingForSlot while waiting.
 func (s *APIScheduler) AcquireAPISlot(ctx context.Context, shardID string) error {
+	return s.AcquireAPISlotWithPriority(ctx, shardID, PriorityNormal)
+}
+
+// AcquireAPISlotWithPriority acquires permission to make an API call,
+// ordering this waiter against others by (priority desc, waitStart asc).
+// Slot handoff is performed by runDispatcher rather than a race on the
+// underlying semaphore, so a low-priority shard waiting behind a flood of
+// high-priority ones won't win a slot out of turn -- unless it has been
+// waiting longer than config.MaxStarvationDelay, in which case the
+// dispatcher promotes it (see promoteStarvedWaiters).
+//
+// If config.RequestsPerMinute is set, this also reserves an RPM token,
+// sleeping until the bucket refills if the account's per-minute request cap
+// is currently exhausted -- callers who also want TPM budgeting should use
+// AcquireAPISlotForCall instead.
+func (s *APIScheduler) AcquireAPISlotWithPriority(ctx context.Context, shardID string, priority SpawnPriority) error {
+	if s.rpmBucket != nil {
+		if err := s.rpmBucket.acquire(ctx, 1); err != nil {
+			return err
+		}
+	}
+	return s.acquireSlot(ctx, shardID, priority)
+}
+
+// TokenEstimate is the caller's estimated token cost of an upcoming API
+// call, used to reserve TPM budget in AcquireAPISlotForCall.
+type TokenEstimate struct {
+	Tokens int
+}
+
+// AcquireAPISlotForCall acquires a slot at PriorityNormal, additionally
+// reserving est.Tokens from the TPM bucket. See AcquireAPISlotForCallWithPriority.
+func (s *APIScheduler) AcquireAPISlotForCall(ctx context.Context, shardID string, est TokenEstimate) error {
+	return s.AcquireAPISlotForCallWithPriority(ctx, shardID, PriorityNormal, est)
+}
+
+// AcquireAPISlotForCallWithPriority behaves like AcquireAPISlotWithPriority,
+// but also reserves est.Tokens from the TPM bucket (if config.TokensPerMinute
+// is set), so a handful of large-context calls can't blow through the
+// account's per-minute token cap even while under the RPM/concurrency caps.
+func (s *APIScheduler) AcquireAPISlotForCallWithPriority(ctx context.Context, shardID string, priority SpawnPriority, est TokenEstimate) error {
+	if s.rpmBucket != nil {
+		if err := s.rpmBucket.acquire(ctx, 1); err != nil {
+			return err
+		}
+	}
+	if s.tpmBucket != nil && est.Tokens > 0 {
+		if err := s.tpmBucket.acquire(ctx, float64(est.Tokens)); err != nil {
+			return err
+		}
+	}
+	return s.acquireSlot(ctx, shardID, priority)
+}
+
+// EstimateTokens estimates the token cost of a prompt for TPM budgeting,
+// using config.EstimateTokens if set, or a chars/4 heuristic otherwise.
+func (s *APIScheduler) EstimateTokens(prompt string) int {
+	if s.config.EstimateTokens != nil {
+		return s.config.EstimateTokens(prompt)
+	}
+	return (len(prompt) + 3) / 4
+}
+
+// acquireSlot performs the concurrency-slot acquisition shared by
+// AcquireAPISlotWithPriority and AcquireAPISlotForCall, once any RPM/TPM
+// budget has already been reserved.
+func (s *APIScheduler) acquireSlot(ctx context.Context, shardID string, priority SpawnPriority) error {
+	if action, ok := failpoint.Eval("apischeduler/beforeAcquire"); ok && action.IsReturn() {
+		return fmt.Errorf("apischeduler/beforeAcquire: %s", action.ReturnValue())
+	}
+
 	s.mu.Lock()
 	state, ok := s.shardStates[shardID]
 	if !ok {
@@ -179,23 +539,25 @@ func (s *APIScheduler) AcquireAPISlot(ctx context.Context, shardID string) error
 	state.Phase = PhaseWaitingForSlot
 	waitStart := time.Now()
 
-	// Add to wait queue for visibility
 	entry := &waitingEntry{
 		shardID:   shardID,
 		shardType: state.ShardType,
 		waitStart: waitStart,
+		priority:  priority,
+		ready:     make(chan struct{}),
 	}
-	s.waitQueue = append(s.waitQueue, entry)
+	heap.Push(&s.waitQueue, entry)
 	s.mu.Unlock()
 
 	atomic.AddInt32(&s.currentlyWaiting, 1)
 	defer atomic.AddInt32(&s.currentlyWaiting, -1)
+	atomic.AddInt64(&s.tickAcquires, 1)
 
 	// Log if we're actually waiting
 	activeSlots := len(s.slots)
 	if activeSlots >= s.config.MaxConcurrentAPICalls {
-		logging.Shards("APIScheduler: shard %s waiting for slot (active=%d/%d, waiting=%d)",
-			shardID, activeSlots, s.config.MaxConcurrentAPICalls, atomic.LoadInt32(&s.currentlyWaiting))
+		logging.Shards("APIScheduler: shard %s waiting for slot (priority=%s, active=%d/%d, waiting=%d)",
+			shardID, priority, activeSlots, s.config.MaxConcurrentAPICalls, atomic.LoadInt32(&s.currentlyWaiting))
 	}
 
 	waitCtx := ctx
@@ -209,46 +571,52 @@ func (s *APIScheduler) AcquireAPISlot(ctx context.Context, shardID string) error
 		defer waitCancel()
 	}
 
-	// Try to acquire slot
+	s.signalDispatch()
+
+	if action, ok := failpoint.Eval("apischeduler/forceCancelWait"); ok && action.IsReturn() {
+		// Simulate the waiter's context being cancelled out from under it,
+		// e.g. to test partial-cancel cleanup without a real deadline race.
+		s.removeWaiter(entry)
+
+		s.mu.Lock()
+		state.Phase = PhaseFailed
+		state.Error = context.Canceled
+		s.mu.Unlock()
+
+		return context.Canceled
+	}
+
 	select {
-	case s.slots <- struct{}{}:
-		// Got a slot
+	case <-entry.ready:
+		// The dispatcher already incremented currentlyExecuting and drew a
+		// token from s.slots on our behalf; just record bookkeeping.
 		waitDuration := time.Since(waitStart)
 
+		if action, ok := failpoint.Eval("apischeduler/afterAcquire"); ok && action.IsSleep() {
+			if d, err := action.SleepDuration(); err == nil {
+				time.Sleep(d)
+			}
+		}
+
 		s.mu.Lock()
 		state.Phase = PhaseExecutingAPI
 		state.TotalWaitTime += waitDuration
 		state.LastAPICall = time.Now()
-
-		// Remove from wait queue
-		for i, e := range s.waitQueue {
-			if e.shardID == shardID {
-				s.waitQueue = append(s.waitQueue[:i], s.waitQueue[i+1:]...)
-				break
-			}
-		}
 		s.mu.Unlock()
 
 		atomic.AddInt64(&s.totalWaitTime, int64(waitDuration))
-		atomic.AddInt32(&s.currentlyExecuting, 1)
 
 		if waitDuration > 100*time.Millisecond {
-			logging.Shards("APIScheduler: shard %s acquired slot after %v", shardID, waitDuration)
+			logging.Shards("APIScheduler: shard %s acquired slot after %v (priority=%s)", shardID, waitDuration, priority)
 		}
 		return nil
 
 	case <-waitCtx.Done():
-		// Context cancelled while waiting
+		s.removeWaiter(entry)
+
 		s.mu.Lock()
 		state.Phase = PhaseFailed
 		state.Error = waitCtx.Err()
-		// Remove from wait queue
-		for i, e := range s.waitQueue {
-			if e.shardID == shardID {
-				s.waitQueue = append(s.waitQueue[:i], s.waitQueue[i+1:]...)
-				break
-			}
-		}
 		s.mu.Unlock()
 
 		logging.Get(logging.CategoryShards).Warn("APIScheduler: shard %s cancelled while waiting for slot (waited %v)",
@@ -256,22 +624,32 @@ func (s *APIScheduler) AcquireAPISlot(ctx context.Context, shardID string) error
 		return waitCtx.Err()
 
 	case <-s.stopCh:
-		// Clean up wait queue on scheduler stop
-		s.mu.Lock()
-		for i, e := range s.waitQueue {
-			if e.shardID == shardID {
-				s.waitQueue = append(s.waitQueue[:i], s.waitQueue[i+1:]...)
-				break
-			}
-		}
-		s.mu.Unlock()
+		s.removeWaiter(entry)
 		return fmt.Errorf("scheduler stopped")
 	}
 }
 
+// removeWaiter drops entry from the wait queue if the dispatcher hasn't
+// already popped it. Safe to call even if entry already won its slot (in
+// which case it's a no-op, guarded by entry.index).
+func (s *APIScheduler) removeWaiter(entry *waitingEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry.index >= 0 {
+		heap.Remove(&s.waitQueue, entry.index)
+	}
+}
+
 // ReleaseAPISlot releases the API slot after call completes.
 // The shard enters PhaseProcessingResult and can do local work before next API call.
 func (s *APIScheduler) ReleaseAPISlot(shardID string) {
+	if action, ok := failpoint.Eval("apischeduler/beforeRelease"); ok && action.IsReturn() {
+		// Simulate a slot leak: the shard believes it released, but the
+		// semaphore token and currentlyExecuting count are never reclaimed.
+		logging.Get(logging.CategoryShards).Warn("APIScheduler: shard %s release skipped by failpoint (%s)", shardID, action.ReturnValue())
+		return
+	}
+
 	// Release the slot
 	select {
 	case <-s.slots:
@@ -284,6 +662,7 @@ func (s *APIScheduler) ReleaseAPISlot(shardID string) {
 
 	atomic.AddInt32(&s.currentlyExecuting, -1)
 	atomic.AddInt64(&s.totalAPICalls, 1)
+	atomic.AddInt64(&s.tickCompletions, 1)
 
 	s.mu.Lock()
 	if state, ok := s.shardStates[shardID]; ok {
@@ -293,15 +672,37 @@ func (s *APIScheduler) ReleaseAPISlot(shardID string) {
 	s.mu.Unlock()
 
 	logging.ShardsDebug("APIScheduler: shard %s released slot (total_calls=%d)", shardID, atomic.LoadInt64(&s.totalAPICalls))
+
+	// A slot just freed up; let the dispatcher hand it to the next waiter.
+	s.signalDispatch()
 }
 
-// SaveCheckpoint stores shard-specific state for resume after yielding.
+// SaveCheckpoint stores shard-specific state for resume after yielding. If a
+// CheckpointStore is configured, the value is also write-through persisted
+// asynchronously; a full write queue is logged and dropped rather than
+// blocking the caller on disk IO.
 func (s *APIScheduler) SaveCheckpoint(shardID string, key string, value interface{}) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	_, ok := s.shardStates[shardID]
+	if ok {
+		s.shardStates[shardID].Checkpoint[key] = value
+	}
+	s.mu.Unlock()
 
-	if state, ok := s.shardStates[shardID]; ok {
-		state.Checkpoint[key] = value
+	if !ok || s.checkpointWrites == nil {
+		return
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		logging.Get(logging.CategoryShards).Error("APIScheduler: failed to encode checkpoint %s/%s: %v", shardID, key, err)
+		return
+	}
+
+	select {
+	case s.checkpointWrites <- checkpointWrite{shardID: shardID, key: key, value: raw}:
+	default:
+		logging.Get(logging.CategoryShards).Error("APIScheduler: checkpoint write queue full, dropping %s/%s", shardID, key)
 	}
 }
 
@@ -350,6 +751,11 @@ func (s *APIScheduler) GetMetrics() APISchedulerMetrics {
 	}
 	s.mu.RUnlock()
 
+	s.scaleMu.Lock()
+	samplesIn := s.samplesInPerSec
+	samplesOut := s.samplesOutPerSec
+	s.scaleMu.Unlock()
+
 	return APISchedulerMetrics{
 		MaxSlots:          s.config.MaxConcurrentAPICalls,
 		ActiveSlots:       int(atomic.LoadInt32(&s.currentlyExecuting)),
@@ -359,7 +765,31 @@ func (s *APIScheduler) GetMetrics() APISchedulerMetrics {
 		RegisteredShards:  activeShards,
 		WaitingShards:     waitingShards,
 		PhaseDistribution: phases,
+		CurrentSlotLimit:  int(atomic.LoadInt32(&s.activeSlotCount)),
+		SamplesInPerSec:   samplesIn,
+		SamplesOutPerSec:  samplesOut,
+		RPMUsed:           s.bucketUsed(s.rpmBucket),
+		TPMUsed:           s.bucketUsed(s.tpmBucket),
+		RPMStallNs:        s.bucketStallNanos(s.rpmBucket),
+	}
+}
+
+// bucketUsed returns the cumulative tokens consumed from bucket, or 0 if
+// bucket is nil (the corresponding RPM/TPM cap is disabled).
+func (s *APIScheduler) bucketUsed(bucket *tokenBucket) int64 {
+	if bucket == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&bucket.used)
+}
+
+// bucketStallNanos returns the cumulative time callers spent blocked
+// waiting for bucket to refill, or 0 if bucket is nil.
+func (s *APIScheduler) bucketStallNanos(bucket *tokenBucket) int64 {
+	if bucket == nil {
+		return 0
 	}
+	return atomic.LoadInt64(&bucket.stallNanos)
 }
 
 // APISchedulerMetrics provides observability into scheduler state.
@@ -372,6 +802,24 @@ type APISchedulerMetrics struct {
 	RegisteredShards  int
 	WaitingShards     int
 	PhaseDistribution map[ShardPhase]int
+
+	// CurrentSlotLimit is the adaptive scaler's current effective ceiling
+	// (between MinConcurrentAPICalls and MaxConcurrentAPICalls).
+	CurrentSlotLimit int
+	// SamplesInPerSec/SamplesOutPerSec are the EWMA-smoothed acquire and
+	// completion rates the scaler uses to decide scale direction.
+	SamplesInPerSec  float64
+	SamplesOutPerSec float64
+
+	// RPMUsed/TPMUsed are the cumulative requests/tokens consumed from the
+	// RPM/TPM buckets (0 if the corresponding cap is disabled), and
+	// RPMStallNs is the cumulative time callers spent blocked waiting for
+	// the RPM bucket to refill -- a non-zero value here (with headroom
+	// remaining on CurrentSlotLimit) means the bottleneck is the per-minute
+	// request cap, not concurrency.
+	RPMUsed    int64
+	TPMUsed    int64
+	RPMStallNs int64
 }
 
 // String returns a human-readable summary.
@@ -384,9 +832,335 @@ func (m APISchedulerMetrics) String() string {
 		m.ActiveSlots, m.MaxSlots, m.WaitingForSlot, m.TotalAPICalls, avgWait, m.RegisteredShards)
 }
 
+// ResumeShards re-registers every shard with a persisted checkpoint so a
+// shard runner can reattach to in-flight work after a process restart.
+// Shards already registered are left untouched. Returns the shard IDs that
+// were (re)registered by this call.
+func (s *APIScheduler) ResumeShards(ctx context.Context) ([]string, error) {
+	if s.config.CheckpointStore == nil {
+		return nil, fmt.Errorf("APIScheduler.ResumeShards: no CheckpointStore configured")
+	}
+
+	shardIDs, err := s.config.CheckpointStore.ListShards()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpointed shards: %w", err)
+	}
+
+	var resumed []string
+	for _, shardID := range shardIDs {
+		select {
+		case <-ctx.Done():
+			return resumed, ctx.Err()
+		default:
+		}
+
+		s.mu.RLock()
+		_, alreadyRegistered := s.shardStates[shardID]
+		s.mu.RUnlock()
+		if alreadyRegistered {
+			continue
+		}
+
+		s.RegisterShard(shardID, "unknown")
+		resumed = append(resumed, shardID)
+	}
+
+	logging.Shards("APIScheduler: resumed %d shard(s) from checkpoint store", len(resumed))
+	return resumed, nil
+}
+
 // Stop shuts down the scheduler.
 func (s *APIScheduler) Stop() {
 	close(s.stopCh)
+	<-s.dispatchStopped
+	if s.scalerStopped != nil {
+		<-s.scalerStopped
+	}
+	if s.checkpointWrites != nil {
+		close(s.checkpointWrites)
+		s.checkpointWorkers.Wait()
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Priority Dispatch
+// -----------------------------------------------------------------------------
+
+// runDispatcher hands free slots to the highest-priority, longest-waiting
+// entry in s.waitQueue instead of letting every waiter race on s.slots
+// directly. It also periodically promotes starved waiters.
+func (s *APIScheduler) runDispatcher() {
+	defer close(s.dispatchStopped)
+
+	starvationCheck := time.NewTicker(1 * time.Second)
+	defer starvationCheck.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-s.dispatchCh:
+			s.dispatchReady()
+		case <-starvationCheck.C:
+			s.promoteStarvedWaiters()
+			s.dispatchReady()
+		}
+	}
+}
+
+// dispatchReady assigns as many free slots as are available to waiters at
+// the front of the priority queue.
+func (s *APIScheduler) dispatchReady() {
+	if time.Now().UnixNano() < atomic.LoadInt64(&s.cooldownUntilNanos) {
+		// Still cooling down from a provider rate-limit/overload response;
+		// refuse new grants until the cooldown elapses (see NotifyRateLimit).
+		return
+	}
+
+	if action, ok := failpoint.Eval("apischeduler/slotGrantDelay"); ok && action.IsSleep() {
+		if d, err := action.SleepDuration(); err == nil {
+			time.Sleep(d)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.waitQueue.Len() > 0 && atomic.LoadInt32(&s.currentlyExecuting) < atomic.LoadInt32(&s.activeSlotCount) {
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			// Semaphore is momentarily full relative to currentlyExecuting
+			// (a release is mid-flight); try again on the next signal.
+			return
+		}
+
+		entry := heap.Pop(&s.waitQueue).(*waitingEntry)
+		atomic.AddInt32(&s.currentlyExecuting, 1)
+		close(entry.ready)
+	}
+}
+
+// promoteStarvedWaiters bumps any waiter older than MaxStarvationDelay to
+// PriorityCritical so a steady stream of higher-priority work can't starve
+// it indefinitely.
+func (s *APIScheduler) promoteStarvedWaiters() {
+	if s.config.MaxStarvationDelay <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	promoted := false
+	for _, entry := range s.waitQueue {
+		if entry.priority < PriorityCritical && now.Sub(entry.waitStart) > s.config.MaxStarvationDelay {
+			entry.priority = PriorityCritical
+			promoted = true
+			logging.Shards("APIScheduler: promoting starved shard %s to critical priority after %v wait",
+				entry.shardID, now.Sub(entry.waitStart))
+		}
+	}
+	if promoted {
+		heap.Init(&s.waitQueue)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Adaptive Slot Scaling
+// -----------------------------------------------------------------------------
+
+// ewmaWeight is the smoothing factor for the samplesIn/samplesOut EWMAs.
+// 0.2 means each tick's observed rate contributes 20% to the running
+// average, matching Prometheus's remote-write queue manager defaults.
+const ewmaWeight = 0.2
+
+// runAutoScaler periodically samples AcquireAPISlot/ReleaseAPISlot activity
+// and adjusts activeSlotCount between MinConcurrentAPICalls and
+// MaxConcurrentAPICalls. It never touches s.slots itself -- AcquireAPISlot
+// gates on activeSlotCount before drawing from the semaphore, so resizing
+// is just an atomic store.
+func (s *APIScheduler) runAutoScaler() {
+	defer close(s.scalerStopped)
+
+	ticker := time.NewTicker(s.config.ScaleInterval)
+	defer ticker.Stop()
+
+	lastTick := time.Now()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C:
+			elapsed := now.Sub(lastTick).Seconds()
+			lastTick = now
+			if elapsed <= 0 {
+				continue
+			}
+
+			in := float64(atomic.SwapInt64(&s.tickAcquires, 0)) / elapsed
+			out := float64(atomic.SwapInt64(&s.tickCompletions, 0)) / elapsed
+
+			s.scaleMu.Lock()
+			s.samplesInPerSec = ewmaWeight*in + (1-ewmaWeight)*s.samplesInPerSec
+			s.samplesOutPerSec = ewmaWeight*out + (1-ewmaWeight)*s.samplesOutPerSec
+			samplesIn := s.samplesInPerSec
+			samplesOut := s.samplesOutPerSec
+			s.scaleMu.Unlock()
+
+			s.evaluateScaling(samplesIn, samplesOut)
+		}
+	}
+}
+
+// evaluateScaling applies the AIMD-style scale-up/scale-down rule against
+// the current EWMA samples and adjusts activeSlotCount by at most one slot
+// per tick.
+func (s *APIScheduler) evaluateScaling(samplesIn, samplesOut float64) {
+	// Scale up: demand (acquires) is outpacing completions and shards are
+	// actually waiting -- growing the slot count should relieve pressure.
+	if samplesOut > 0 && samplesIn/samplesOut > s.config.ScaleUpThreshold && atomic.LoadInt32(&s.currentlyWaiting) > 0 {
+		s.scaleMu.Lock()
+		s.consecutiveScaleDownTicks = 0
+		s.scaleMu.Unlock()
+
+		if grown, newCount := s.growSlotCount(); grown {
+			s.signalDispatch() // a higher ceiling may let a waiter in immediately
+			logging.Shards("APIScheduler: scaling up to %d/%d slots (in=%.2f/s, out=%.2f/s)",
+				newCount, s.config.MaxConcurrentAPICalls, samplesIn, samplesOut)
+		}
+		return
+	}
+
+	// Scale down: completions are comfortably outpacing new demand,
+	// sustained across two ticks so a momentary lull doesn't cause thrash.
+	if samplesIn > 0 && samplesOut/samplesIn > s.config.ScaleDownThreshold {
+		s.scaleMu.Lock()
+		s.consecutiveScaleDownTicks++
+		sustained := s.consecutiveScaleDownTicks >= 2
+		if sustained {
+			s.consecutiveScaleDownTicks = 0
+		}
+		s.scaleMu.Unlock()
+
+		if sustained {
+			if shrunk, newCount := s.shrinkSlotCount(); shrunk {
+				logging.Shards("APIScheduler: scaling down to %d/%d slots (in=%.2f/s, out=%.2f/s)",
+					newCount, s.config.MaxConcurrentAPICalls, samplesIn, samplesOut)
+			}
+		}
+		return
+	}
+
+	s.scaleMu.Lock()
+	s.consecutiveScaleDownTicks = 0
+	s.scaleMu.Unlock()
+}
+
+// growSlotCount adds one slot to activeSlotCount via a CAS retry loop,
+// bailing out once another goroutine (here or in recoverFromRateLimit) has
+// already pushed it to MaxConcurrentAPICalls. An unsynchronized
+// load-check-then-add would let two concurrent callers each observe room
+// for one more slot and both add, overshooting the configured ceiling.
+func (s *APIScheduler) growSlotCount() (grew bool, newCount int32) {
+	for {
+		cur := atomic.LoadInt32(&s.activeSlotCount)
+		if int(cur) >= s.config.MaxConcurrentAPICalls {
+			return false, cur
+		}
+		if atomic.CompareAndSwapInt32(&s.activeSlotCount, cur, cur+1) {
+			return true, cur + 1
+		}
+	}
+}
+
+// shrinkSlotCount removes one slot from activeSlotCount via a CAS retry
+// loop, bailing out once activeSlotCount has already reached
+// MinConcurrentAPICalls.
+func (s *APIScheduler) shrinkSlotCount() (shrank bool, newCount int32) {
+	for {
+		cur := atomic.LoadInt32(&s.activeSlotCount)
+		if int(cur) <= s.config.MinConcurrentAPICalls {
+			return false, cur
+		}
+		if atomic.CompareAndSwapInt32(&s.activeSlotCount, cur, cur-1) {
+			return true, cur - 1
+		}
+	}
+}
+
+// NotifyRateLimit reports that shardID's underlying LLMClient call hit a
+// provider rate-limit or overload response. It applies the "multiplicative
+// decrease" half of an AIMD congestion controller -- halving
+// activeSlotCount (floored at 1) and refusing new AcquireAPISlot grants
+// until retryAfter elapses (or config.RateLimitCooldown, if the provider
+// didn't advertise one) -- then spawns a goroutine that additively grows
+// activeSlotCount back toward MaxConcurrentAPICalls one slot per
+// config.RateLimitRecoveryInterval, the same one-slot step evaluateScaling
+// takes per ScaleInterval tick when it scales up.
+func (s *APIScheduler) NotifyRateLimit(shardID string, retryAfter time.Duration) {
+	cooldown := retryAfter
+	if cooldown <= 0 {
+		cooldown = s.config.RateLimitCooldown
+	}
+	cooldownUntil := time.Now().Add(cooldown)
+	atomic.StoreInt64(&s.cooldownUntilNanos, cooldownUntil.UnixNano())
+
+	current := atomic.LoadInt32(&s.activeSlotCount)
+	reduced := current / 2
+	if reduced < 1 {
+		reduced = 1
+	}
+	atomic.StoreInt32(&s.activeSlotCount, reduced)
+
+	generation := atomic.AddInt64(&s.rateLimitGeneration, 1)
+
+	logging.Shards("APIScheduler: rate limit reported by shard %s, scaling down %d -> %d slots, cooldown=%v",
+		shardID, current, reduced, cooldown)
+
+	go s.recoverFromRateLimit(generation, cooldownUntil)
+}
+
+// recoverFromRateLimit waits out cooldownUntil, then additively restores
+// activeSlotCount toward MaxConcurrentAPICalls, bailing out early if a
+// newer NotifyRateLimit call (higher generation) has superseded this one.
+func (s *APIScheduler) recoverFromRateLimit(generation int64, cooldownUntil time.Time) {
+	timer := time.NewTimer(time.Until(cooldownUntil))
+	defer timer.Stop()
+
+	select {
+	case <-s.stopCh:
+		return
+	case <-timer.C:
+	}
+
+	interval := s.config.RateLimitRecoveryInterval
+	if interval <= 0 {
+		interval = 1 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.signalDispatch() // cooldown just lifted; let waiters in at the reduced limit immediately
+
+	for {
+		if atomic.LoadInt64(&s.rateLimitGeneration) != generation {
+			return // a newer rate limit took over recovery
+		}
+
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			if grew, _ := s.growSlotCount(); grew {
+				s.signalDispatch()
+			} else {
+				return // already at MaxConcurrentAPICalls
+			}
+		}
+	}
 }
 
 // -----------------------------------------------------------------------------
@@ -400,16 +1174,40 @@ type ScheduledLLMCall struct {
 	Scheduler *APIScheduler
 	ShardID   string
 	Client    LLMClient
+
+	priority int32 // atomic SpawnPriority; defaults to PriorityNormal, see NewScheduledLLMCall
 }
 
 // Compile-time assertion that ScheduledLLMCall implements LLMClient
 var _ LLMClient = (*ScheduledLLMCall)(nil)
 
+// Compile-time assertion that ScheduledLLMCall implements PriorityAwareLLMClient
+var _ PriorityAwareLLMClient = (*ScheduledLLMCall)(nil)
+
+// PriorityAwareLLMClient lets a caller mark which SpawnPriority a wrapped
+// client's scheduled calls should carry through APIScheduler's priority
+// queue, instead of always competing at PriorityNormal.
+type PriorityAwareLLMClient interface {
+	LLMClient
+	SetShardPriority(priority SpawnPriority)
+}
+
+// SetShardPriority sets the SpawnPriority used for calls made through this
+// wrapper from now on.
+func (c *ScheduledLLMCall) SetShardPriority(priority SpawnPriority) {
+	atomic.StoreInt32(&c.priority, int32(priority))
+}
+
+// Priority returns the SpawnPriority currently configured for this wrapper.
+func (c *ScheduledLLMCall) Priority() SpawnPriority {
+	return SpawnPriority(atomic.LoadInt32(&c.priority))
+}
+
 // Complete makes an LLM call with cooperative scheduling (single prompt).
 // Acquires a slot, makes the call, releases the slot.
 func (c *ScheduledLLMCall) Complete(ctx context.Context, prompt string) (string, error) {
 	// Acquire slot (blocks until available)
-	if err := c.Scheduler.AcquireAPISlot(ctx, c.ShardID); err != nil {
+	if err := c.Scheduler.AcquireAPISlotWithPriority(ctx, c.ShardID, c.Priority()); err != nil {
 		return "", fmt.Errorf("failed to acquire API slot: %w", err)
 	}
 
@@ -424,7 +1222,7 @@ func (c *ScheduledLLMCall) Complete(ctx context.Context, prompt string) (string,
 // Acquires a slot, makes the call, releases the slot.
 func (c *ScheduledLLMCall) CompleteWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
 	// Acquire slot (blocks until available)
-	if err := c.Scheduler.AcquireAPISlot(ctx, c.ShardID); err != nil {
+	if err := c.Scheduler.AcquireAPISlotWithPriority(ctx, c.ShardID, c.Priority()); err != nil {
 		return "", fmt.Errorf("failed to acquire API slot: %w", err)
 	}
 
@@ -470,7 +1268,7 @@ func (c *ScheduledLLMCall) CompleteWithStreaming(ctx context.Context, systemProm
 	errorChan := make(chan error, 1)
 
 	// Acquire slot (blocks until available)
-	if err := c.Scheduler.AcquireAPISlot(ctx, c.ShardID); err != nil {
+	if err := c.Scheduler.AcquireAPISlotWithPriority(ctx, c.ShardID, c.Priority()); err != nil {
 		close(contentChan)
 		errorChan <- fmt.Errorf("failed to acquire API slot: %w", err)
 		close(errorChan)
@@ -537,50 +1335,30 @@ func (c *ScheduledLLMCall) CompleteWithStreaming(ctx context.Context, systemProm
 
 // CompleteWithRetry makes an LLM call with retries and cooperative scheduling.
 func (c *ScheduledLLMCall) CompleteWithRetry(ctx context.Context, systemPrompt, userPrompt string, maxRetries int) (string, error) {
-	var lastErr error
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		// Acquire slot for this attempt
-		if err := c.Scheduler.AcquireAPISlot(ctx, c.ShardID); err != nil {
-			return "", fmt.Errorf("failed to acquire API slot (attempt %d): %w", attempt+1, err)
-		}
-
-		// Make the call and guarantee slot release even on panic
-		result, err := func() (res string, callErr error) {
-			defer func() {
-				if r := recover(); r != nil {
-					callErr = fmt.Errorf("panic during LLM call: %v", r)
-				}
-				c.Scheduler.ReleaseAPISlot(c.ShardID)
-			}()
-			return c.Client.CompleteWithSystem(ctx, systemPrompt, userPrompt)
-		}()
-
-		if err == nil {
-			return result, nil
-		}
-
-		lastErr = err
-
-		// Check if we should retry
-		if attempt < maxRetries {
-			// Brief pause before retry (exponential backoff)
-			backoff := time.Duration(1<<attempt) * 100 * time.Millisecond
-			if backoff > 5*time.Second {
-				backoff = 5 * time.Second
-			}
+	// The acquire/release-per-attempt and exponential-backoff loop this
+	// method used to inline now lives in the composable middleware chain
+	// (see llm_client_middleware.go) so it's available to any LLMClient,
+	// not just this wrapper. isTransient also feeds rate-limit responses
+	// back into the scheduler's congestion controller so other shards
+	// sharing this scheduler back off too, instead of each one discovering
+	// the 429 independently.
+	sched := &schedulingLLMClient{
+		underlying: c.Client,
+		scheduler:  c.Scheduler,
+		shardID:    c.ShardID,
+		priority:   int32(c.Priority()),
+	}
 
-			select {
-			case <-ctx.Done():
-				return "", ctx.Err()
-			case <-time.After(backoff):
-				logging.ShardsDebug("ScheduledLLMCall: retrying after error (attempt %d/%d): %v",
-					attempt+1, maxRetries, err)
-			}
+	isTransient := func(err error) bool {
+		if isRateLimit, retryAfter := classifyLLMError(err); isRateLimit {
+			c.Scheduler.NotifyRateLimit(c.ShardID, retryAfter)
+			return true
 		}
+		return IsTransientLLMError(err)
 	}
 
-	return "", fmt.Errorf("all %d attempts failed, last error: %w", maxRetries+1, lastErr)
+	chain := NewRetryableLLMClient(DefaultExponentialRetryPolicy(maxRetries), isTransient)(sched)
+	return chain.CompleteWithSystem(ctx, systemPrompt, userPrompt)
 }
 
 // -----------------------------------------------------------------------------
@@ -649,5 +1427,6 @@ func NewScheduledLLMCall(shardID string, client LLMClient) *ScheduledLLMCall {
 		Scheduler: scheduler,
 		ShardID:   shardID,
 		Client:    client,
+		priority:  int32(PriorityNormal),
 	}
 }
@@ -2,10 +2,15 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"codenerd/internal/checkpoint"
+	"codenerd/internal/failpoint"
 )
 
 // MockLLMClient for testing
@@ -462,3 +467,342 @@ func TestNoDoubleLimiting(t *testing.T) {
 		t.Fatalf("Expected near-5 concurrent calls, got %d (possible double-limiting)", maxConcurrent)
 	}
 }
+
+// testRateLimitError implements core.RateLimitClassifier, standing in for
+// perception.RateLimitError without creating an import cycle in the test.
+type testRateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (e *testRateLimitError) Error() string                 { return "provider rate limit exceeded" }
+func (e *testRateLimitError) RetryAfterHint() time.Duration { return e.retryAfter }
+
+// TestClassifyLLMError verifies both the structured (errors.As) and
+// message-pattern classification paths.
+func TestClassifyLLMError(t *testing.T) {
+	isRL, retryAfter := classifyLLMError(&testRateLimitError{retryAfter: 3 * time.Second})
+	if !isRL || retryAfter != 3*time.Second {
+		t.Fatalf("expected structured rate-limit error to classify with its RetryAfterHint, got isRateLimit=%v retryAfter=%v", isRL, retryAfter)
+	}
+
+	isRL, retryAfter = classifyLLMError(fmt.Errorf("rate limit exceeded (429)"))
+	if !isRL || retryAfter != 0 {
+		t.Fatalf("expected bare 429 message to classify as rate-limited with no known retryAfter, got isRateLimit=%v retryAfter=%v", isRL, retryAfter)
+	}
+
+	if isRL, _ := classifyLLMError(fmt.Errorf("connection reset by peer")); isRL {
+		t.Fatalf("expected an unrelated error not to classify as rate-limited")
+	}
+
+	if isRL, _ := classifyLLMError(nil); isRL {
+		t.Fatalf("expected nil error not to classify as rate-limited")
+	}
+}
+
+// TestAPIScheduler_NotifyRateLimit verifies the AIMD backoff: activeSlotCount
+// halves immediately, new grants are refused during the cooldown, and the
+// limit additively climbs back to the configured max once it lifts.
+func TestAPIScheduler_NotifyRateLimit(t *testing.T) {
+	scheduler := NewAPIScheduler(APISchedulerConfig{
+		MaxConcurrentAPICalls:     4,
+		SlotAcquireTimeout:        5 * time.Second,
+		RateLimitCooldown:         50 * time.Millisecond,
+		RateLimitRecoveryInterval: 20 * time.Millisecond,
+	})
+	defer scheduler.Stop()
+
+	scheduler.NotifyRateLimit("some-shard", 0)
+
+	if got := scheduler.GetMetrics().CurrentSlotLimit; got != 2 {
+		t.Fatalf("expected activeSlotCount to halve 4 -> 2, got %d", got)
+	}
+
+	scheduler.RegisterShard("shard-1", "test")
+	shortCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := scheduler.AcquireAPISlot(shortCtx, "shard-1"); err != context.DeadlineExceeded {
+		t.Fatalf("expected acquire to be refused during cooldown, got: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if scheduler.GetMetrics().CurrentSlotLimit >= 4 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected activeSlotCount to additively recover to 4, got %d", scheduler.GetMetrics().CurrentSlotLimit)
+}
+
+// TestAPIScheduler_CheckpointStorePersistsAndResumes verifies SaveCheckpoint
+// write-throughs survive into a fresh scheduler instance and that
+// ResumeShards reattaches a shard from its persisted checkpoint alone.
+func TestAPIScheduler_CheckpointStorePersistsAndResumes(t *testing.T) {
+	store, err := checkpoint.NewSQLiteCheckpointStore(filepath.Join(t.TempDir(), "checkpoints.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteCheckpointStore: %v", err)
+	}
+	defer store.Close()
+
+	scheduler := NewAPIScheduler(APISchedulerConfig{
+		MaxConcurrentAPICalls: 4,
+		SlotAcquireTimeout:    5 * time.Second,
+		CheckpointStore:       store,
+	})
+	scheduler.RegisterShard("shard-1", "test")
+	scheduler.SaveCheckpoint("shard-1", "progress", map[string]interface{}{"step": float64(3)})
+	scheduler.Stop() // drains the async write-through worker pool
+
+	resumed := NewAPIScheduler(APISchedulerConfig{
+		MaxConcurrentAPICalls: 4,
+		SlotAcquireTimeout:    5 * time.Second,
+		CheckpointStore:       store,
+	})
+	defer resumed.Stop()
+
+	shardIDs, err := resumed.ResumeShards(context.Background())
+	if err != nil {
+		t.Fatalf("ResumeShards: %v", err)
+	}
+	if len(shardIDs) != 1 || shardIDs[0] != "shard-1" {
+		t.Fatalf("expected shard-1 to be resumed, got %v", shardIDs)
+	}
+
+	state, ok := resumed.GetShardState("shard-1")
+	if !ok {
+		t.Fatal("expected shard-1 to be registered after ResumeShards")
+	}
+	progress, ok := state.Checkpoint["progress"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a decoded map checkpoint, got %T", state.Checkpoint["progress"])
+	}
+	if progress["step"] != float64(3) {
+		t.Fatalf("expected step=3, got %v", progress["step"])
+	}
+}
+
+// TestAPIScheduler_RequestsPerMinuteThrottles verifies the RPM bucket stalls
+// a caller once its per-minute request budget is exhausted, and that the
+// stall is reflected in RPMStallNs.
+func TestAPIScheduler_RequestsPerMinuteThrottles(t *testing.T) {
+	scheduler := NewAPIScheduler(APISchedulerConfig{
+		MaxConcurrentAPICalls: 4,
+		SlotAcquireTimeout:    5 * time.Second,
+		RequestsPerMinute:     2, // refills at 1 token/30s -- far slower than the test timeout below
+	})
+	defer scheduler.Stop()
+
+	scheduler.RegisterShard("shard-1", "test")
+	scheduler.RegisterShard("shard-2", "test")
+	scheduler.RegisterShard("shard-3", "test")
+
+	if err := scheduler.AcquireAPISlot(context.Background(), "shard-1"); err != nil {
+		t.Fatalf("Failed to acquire slot 1: %v", err)
+	}
+	if err := scheduler.AcquireAPISlot(context.Background(), "shard-2"); err != nil {
+		t.Fatalf("Failed to acquire slot 2: %v", err)
+	}
+
+	shortCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := scheduler.AcquireAPISlot(shortCtx, "shard-3"); err != context.DeadlineExceeded {
+		t.Fatalf("expected the RPM bucket to stall the 3rd acquire, got: %v", err)
+	}
+
+	metrics := scheduler.GetMetrics()
+	if metrics.RPMUsed != 2 {
+		t.Fatalf("expected RPMUsed=2, got %d", metrics.RPMUsed)
+	}
+	if metrics.RPMStallNs <= 0 {
+		t.Fatalf("expected a non-zero RPM stall, got %d", metrics.RPMStallNs)
+	}
+}
+
+// TestAPIScheduler_AcquireAPISlotForCall_TokensPerMinuteThrottles verifies
+// the TPM bucket stalls a caller whose estimated token cost exceeds the
+// remaining per-minute token budget.
+func TestAPIScheduler_AcquireAPISlotForCall_TokensPerMinuteThrottles(t *testing.T) {
+	scheduler := NewAPIScheduler(APISchedulerConfig{
+		MaxConcurrentAPICalls: 4,
+		SlotAcquireTimeout:    5 * time.Second,
+		TokensPerMinute:       100,
+	})
+	defer scheduler.Stop()
+
+	scheduler.RegisterShard("shard-1", "test")
+	scheduler.RegisterShard("shard-2", "test")
+
+	if err := scheduler.AcquireAPISlotForCallWithPriority(context.Background(), "shard-1", PriorityNormal, TokenEstimate{Tokens: 90}); err != nil {
+		t.Fatalf("Failed to acquire slot 1: %v", err)
+	}
+
+	shortCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := scheduler.AcquireAPISlotForCallWithPriority(shortCtx, "shard-2", PriorityNormal, TokenEstimate{Tokens: 50}); err != context.DeadlineExceeded {
+		t.Fatalf("expected the TPM bucket to stall the 2nd acquire, got: %v", err)
+	}
+
+	if got := scheduler.GetMetrics().TPMUsed; got != 90 {
+		t.Fatalf("expected TPMUsed=90, got %d", got)
+	}
+}
+
+// TestAPIScheduler_EstimateTokens verifies the default chars/4 heuristic and
+// that a configured EstimateTokens hook overrides it.
+func TestAPIScheduler_EstimateTokens(t *testing.T) {
+	scheduler := NewAPIScheduler(APISchedulerConfig{MaxConcurrentAPICalls: 1})
+	defer scheduler.Stop()
+
+	if got := scheduler.EstimateTokens("abcd"); got != 1 {
+		t.Fatalf("expected the default heuristic to estimate 1 token for 4 chars, got %d", got)
+	}
+
+	custom := NewAPIScheduler(APISchedulerConfig{
+		MaxConcurrentAPICalls: 1,
+		EstimateTokens:        func(prompt string) int { return 42 },
+	})
+	defer custom.Stop()
+	if got := custom.EstimateTokens("anything"); got != 42 {
+		t.Fatalf("expected the configured EstimateTokens hook to be used, got %d", got)
+	}
+}
+
+// TestAPIScheduler_FailpointBeforeAcquire verifies the
+// "apischeduler/beforeAcquire" failpoint can deterministically inject an
+// acquire failure without needing to engineer a real race.
+func TestAPIScheduler_FailpointBeforeAcquire(t *testing.T) {
+	if err := failpoint.Enable("apischeduler/beforeAcquire", `return("injected")`); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	defer failpoint.Disable("apischeduler/beforeAcquire")
+
+	scheduler := NewAPIScheduler(APISchedulerConfig{MaxConcurrentAPICalls: 2, SlotAcquireTimeout: time.Second})
+	defer scheduler.Stop()
+	scheduler.RegisterShard("shard-1", "test")
+
+	if err := scheduler.AcquireAPISlot(context.Background(), "shard-1"); err == nil {
+		t.Fatal("expected the beforeAcquire failpoint to inject an error")
+	}
+}
+
+// TestAPIScheduler_FailpointSlotGrantDelay verifies the
+// "apischeduler/slotGrantDelay" failpoint injects latency into the
+// dispatcher's slot-granting path, for simulating a slow-slot provider.
+func TestAPIScheduler_FailpointSlotGrantDelay(t *testing.T) {
+	if err := failpoint.Enable("apischeduler/slotGrantDelay", "sleep(50ms)"); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	defer failpoint.Disable("apischeduler/slotGrantDelay")
+
+	scheduler := NewAPIScheduler(APISchedulerConfig{MaxConcurrentAPICalls: 2, SlotAcquireTimeout: time.Second})
+	defer scheduler.Stop()
+	scheduler.RegisterShard("shard-1", "test")
+
+	start := time.Now()
+	if err := scheduler.AcquireAPISlot(context.Background(), "shard-1"); err != nil {
+		t.Fatalf("AcquireAPISlot: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected the slotGrantDelay failpoint to add at least 50ms, got %v", elapsed)
+	}
+}
+
+// TestAPIScheduler_FailpointForceCancelWait verifies the
+// "apischeduler/forceCancelWait" failpoint can deterministically simulate a
+// waiter being cancelled, for testing wait-queue cleanup.
+func TestAPIScheduler_FailpointForceCancelWait(t *testing.T) {
+	if err := failpoint.Enable("apischeduler/forceCancelWait", `return("injected")`); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	defer failpoint.Disable("apischeduler/forceCancelWait")
+
+	scheduler := NewAPIScheduler(APISchedulerConfig{MaxConcurrentAPICalls: 1, SlotAcquireTimeout: time.Second})
+	defer scheduler.Stop()
+	scheduler.RegisterShard("shard-1", "test")
+
+	if err := scheduler.AcquireAPISlot(context.Background(), "shard-1"); err != context.Canceled {
+		t.Fatalf("expected context.Canceled from the forceCancelWait failpoint, got: %v", err)
+	}
+
+	metrics := scheduler.GetMetrics()
+	if metrics.WaitingShards != 0 {
+		t.Fatalf("expected the forced waiter to be cleaned up, got %d waiting", metrics.WaitingShards)
+	}
+}
+
+// TestAPIScheduler_FailpointBeforeRelease verifies the
+// "apischeduler/beforeRelease" failpoint can simulate a slot leak by
+// skipping the actual release.
+func TestAPIScheduler_FailpointBeforeRelease(t *testing.T) {
+	if err := failpoint.Enable("apischeduler/beforeRelease", `return("injected")`); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	defer failpoint.Disable("apischeduler/beforeRelease")
+
+	scheduler := NewAPIScheduler(APISchedulerConfig{MaxConcurrentAPICalls: 1, SlotAcquireTimeout: 50 * time.Millisecond})
+	defer scheduler.Stop()
+	scheduler.RegisterShard("shard-1", "test")
+	scheduler.RegisterShard("shard-2", "test")
+
+	if err := scheduler.AcquireAPISlot(context.Background(), "shard-1"); err != nil {
+		t.Fatalf("AcquireAPISlot: %v", err)
+	}
+	scheduler.ReleaseAPISlot("shard-1") // skipped by the failpoint -- the slot leaks
+
+	if err := scheduler.AcquireAPISlot(context.Background(), "shard-2"); err != context.DeadlineExceeded {
+		t.Fatalf("expected the leaked slot to starve shard-2, got: %v", err)
+	}
+}
+
+// TestAPIScheduler_GrowSlotCountNeverOvershootsCeiling races many
+// concurrent growSlotCount callers (standing in for evaluateScaling's
+// scale-up and recoverFromRateLimit's additive recovery, which both drive
+// activeSlotCount up toward MaxConcurrentAPICalls) against each other and
+// asserts the CAS retry loop lands exactly on the ceiling instead of
+// overshooting it, which an unsynchronized load-check-then-add would do
+// under this much contention.
+func TestAPIScheduler_GrowSlotCountNeverOvershootsCeiling(t *testing.T) {
+	const maxSlots = 8
+	scheduler := NewAPIScheduler(APISchedulerConfig{MaxConcurrentAPICalls: maxSlots, MinConcurrentAPICalls: 1})
+	defer scheduler.Stop()
+	atomic.StoreInt32(&scheduler.activeSlotCount, 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scheduler.growSlotCount()
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&scheduler.activeSlotCount); got != maxSlots {
+		t.Fatalf("expected activeSlotCount to settle at ceiling %d, got %d", maxSlots, got)
+	}
+}
+
+// TestAPIScheduler_ShrinkSlotCountNeverUndershootsFloor is the mirror of
+// the above for the scale-down path: concurrent shrinkSlotCount callers
+// must not push activeSlotCount below MinConcurrentAPICalls.
+func TestAPIScheduler_ShrinkSlotCountNeverUndershootsFloor(t *testing.T) {
+	const maxSlots = 8
+	const minSlots = 2
+	scheduler := NewAPIScheduler(APISchedulerConfig{MaxConcurrentAPICalls: maxSlots, MinConcurrentAPICalls: minSlots})
+	defer scheduler.Stop()
+	atomic.StoreInt32(&scheduler.activeSlotCount, maxSlots)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scheduler.shrinkSlotCount()
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&scheduler.activeSlotCount); got != minSlots {
+		t.Fatalf("expected activeSlotCount to settle at floor %d, got %d", minSlots, got)
+	}
+}
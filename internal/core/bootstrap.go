@@ -0,0 +1,282 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"codenerd/internal/logging"
+)
+
+// BootstrapPhase is a step in the kernel's quiescent-boot lifecycle - the
+// contract fact_categories.go describes informally (start clean, with no
+// stale ephemeral state from a previous session) made into an explicit,
+// observable state machine.
+type BootstrapPhase int
+
+const (
+	// PhaseLoadSchema is active while schema/policy .mg files are parsed.
+	PhaseLoadSchema BootstrapPhase = iota
+	// PhaseLoadPersistent is active while IsPersistent facts are replayed
+	// from the attached storage.Store (a no-op phase if none is attached).
+	PhaseLoadPersistent
+	// PhaseReconcile is active while IsEphemeral facts left over from a
+	// previous session (e.g. a prior LoadFacts(bootFacts) call) are
+	// dropped - the current session must not inherit another one's
+	// pending_action or user_intent.
+	PhaseReconcile
+	// PhaseActivate is active while the zero-ephemeral-facts invariant is
+	// checked, immediately before the kernel declares itself Ready.
+	PhaseActivate
+	// PhaseReady is the kernel's steady state: schema and persistent facts
+	// loaded, no stale ephemeral facts present, ready to serve queries.
+	PhaseReady
+	// PhaseHibernate suspends a session: persistent facts are flushed to
+	// the store and ephemeral facts are dropped, but the kernel keeps
+	// running and can Resume back to PhaseReady.
+	PhaseHibernate
+	// PhaseDrain marks the kernel as winding down ahead of PhaseShutdown.
+	// Entering it only records the phase: new facts are still accepted and
+	// work in flight is not waited for.
+	PhaseDrain
+	// PhaseShutdown is the kernel's terminal phase: persistent facts are
+	// flushed one last time and the store is closed.
+	PhaseShutdown
+)
+
+// String returns the phase's name.
+func (p BootstrapPhase) String() string {
+	switch p {
+	case PhaseLoadSchema:
+		return "load_schema"
+	case PhaseLoadPersistent:
+		return "load_persistent"
+	case PhaseReconcile:
+		return "reconcile"
+	case PhaseActivate:
+		return "activate"
+	case PhaseReady:
+		return "ready"
+	case PhaseHibernate:
+		return "hibernate"
+	case PhaseDrain:
+		return "drain"
+	case PhaseShutdown:
+		return "shutdown"
+	default:
+		return "unknown"
+	}
+}
+
+// BootstrapHook is a plugin callback fired at a Bootstrapper lifecycle
+// event. A hook returning an error aborts the phase transition that
+// triggered it.
+type BootstrapHook func(ctx context.Context, k *RealKernel) error
+
+// Bootstrapper drives a RealKernel through the quiescent-boot lifecycle
+// phases, firing named hooks at each transition so plugins (the virtual
+// store, self-healing, shard manager, or anything else loaded into the
+// kernel) can observe or react to the persistent/ephemeral boundary
+// without bespoke calls threaded through NewRealKernel.
+type Bootstrapper struct {
+	kernel *RealKernel
+
+	mu    sync.Mutex
+	phase BootstrapPhase
+
+	beforeLoadPersistent []BootstrapHook
+	afterLoadPersistent  []BootstrapHook
+	ephemeralReset       []BootstrapHook
+	quiescent            []BootstrapHook
+}
+
+// NewBootstrapper creates a Bootstrapper for kernel, starting at
+// PhaseLoadSchema.
+func NewBootstrapper(kernel *RealKernel) *Bootstrapper {
+	return &Bootstrapper{kernel: kernel, phase: PhaseLoadSchema}
+}
+
+// Phase returns the bootstrapper's current phase.
+func (b *Bootstrapper) Phase() BootstrapPhase {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.phase
+}
+
+func (b *Bootstrapper) setPhase(phase BootstrapPhase) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	logging.KernelDebug("Bootstrapper: %s -> %s", b.phase, phase)
+	b.phase = phase
+}
+
+// OnBeforeLoadPersistent registers a hook fired just before persistent
+// facts are replayed from disk.
+func (b *Bootstrapper) OnBeforeLoadPersistent(hook BootstrapHook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.beforeLoadPersistent = append(b.beforeLoadPersistent, hook)
+}
+
+// OnAfterLoadPersistent registers a hook fired just after persistent facts
+// have been replayed from disk.
+func (b *Bootstrapper) OnAfterLoadPersistent(hook BootstrapHook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.afterLoadPersistent = append(b.afterLoadPersistent, hook)
+}
+
+// OnEphemeralReset registers a hook fired after leftover ephemeral facts
+// from a previous session have been dropped.
+func (b *Bootstrapper) OnEphemeralReset(hook BootstrapHook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ephemeralReset = append(b.ephemeralReset, hook)
+}
+
+// OnQuiescent registers a hook fired once the kernel reaches PhaseReady
+// with the zero-ephemeral-facts invariant confirmed.
+func (b *Bootstrapper) OnQuiescent(hook BootstrapHook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.quiescent = append(b.quiescent, hook)
+}
+
+func (b *Bootstrapper) fire(ctx context.Context, hooks []BootstrapHook) error {
+	b.mu.Lock()
+	snapshot := append([]BootstrapHook(nil), hooks...)
+	b.mu.Unlock()
+
+	for _, hook := range snapshot {
+		if err := hook(ctx, b.kernel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run drives the kernel from PhaseLoadSchema through PhaseReady:
+// PhaseLoadPersistent replays IsPersistent facts from the kernel's
+// attached storage.Store (see SetFactStore; a no-op if none is attached),
+// PhaseReconcile drops any IsEphemeral facts already present (left over
+// from whatever built the kernel's boot facts), and PhaseActivate refuses
+// to declare PhaseReady if any ephemeral fact survived reconciliation.
+// Schema/policy loading itself (PhaseLoadSchema) happens in NewRealKernel*
+// before a Bootstrapper is constructed; this phase exists as a named,
+// hookable point in the sequence rather than duplicating that work.
+func (b *Bootstrapper) Run(ctx context.Context) error {
+	b.setPhase(PhaseLoadPersistent)
+	if err := b.fire(ctx, b.beforeLoadPersistent); err != nil {
+		return fmt.Errorf("OnBeforeLoadPersistent hook failed: %w", err)
+	}
+	if err := b.kernel.LoadPersistentFacts(); err != nil {
+		return fmt.Errorf("load persistent facts: %w", err)
+	}
+	if err := b.fire(ctx, b.afterLoadPersistent); err != nil {
+		return fmt.Errorf("OnAfterLoadPersistent hook failed: %w", err)
+	}
+
+	b.setPhase(PhaseReconcile)
+	if _, err := b.kernel.ResetEphemeralFacts(); err != nil {
+		return fmt.Errorf("reset ephemeral facts: %w", err)
+	}
+	if err := b.fire(ctx, b.ephemeralReset); err != nil {
+		return fmt.Errorf("OnEphemeralReset hook failed: %w", err)
+	}
+
+	b.setPhase(PhaseActivate)
+	if err := b.kernel.checkZeroEphemeral(); err != nil {
+		return fmt.Errorf("zero-ephemeral-facts invariant violated, refusing to activate: %w", err)
+	}
+
+	b.setPhase(PhaseReady)
+	if err := b.fire(ctx, b.quiescent); err != nil {
+		return fmt.Errorf("OnQuiescent hook failed: %w", err)
+	}
+	return nil
+}
+
+// Hibernate suspends the session: persistent facts are flushed to the
+// attached store (without closing it - Resume needs it open) and ephemeral
+// facts are dropped, leaving the kernel at PhaseHibernate.
+func (b *Bootstrapper) Hibernate(ctx context.Context) error {
+	b.kernel.mu.Lock()
+	store := b.kernel.persistentStore
+	facts := append([]Fact(nil), b.kernel.facts...)
+	b.kernel.mu.Unlock()
+
+	if store != nil {
+		b.kernel.mu.Lock()
+		b.kernel.persistFactsLocked(facts)
+		b.kernel.mu.Unlock()
+	}
+
+	if _, err := b.kernel.ResetEphemeralFacts(); err != nil {
+		return fmt.Errorf("hibernate: reset ephemeral facts: %w", err)
+	}
+
+	b.setPhase(PhaseHibernate)
+	return nil
+}
+
+// Resume reloads persistent facts and re-runs reconcile/activate to bring
+// a hibernated (or already-ready) kernel back to PhaseReady. Calling
+// Resume when the kernel is already PhaseReady is a no-op, making it safe
+// to call idempotently.
+func (b *Bootstrapper) Resume(ctx context.Context) error {
+	if b.Phase() == PhaseReady {
+		return nil
+	}
+	return b.Run(ctx)
+}
+
+// Drain transitions to PhaseDrain ahead of Shutdown. It only records the
+// phase; it does not refuse new ephemeral facts or wait for work in flight.
+func (b *Bootstrapper) Drain(ctx context.Context) error {
+	b.setPhase(PhaseDrain)
+	return nil
+}
+
+// Shutdown transitions to PhaseShutdown and delegates to RealKernel.Shutdown
+// for the final persistent-fact flush and store close.
+func (b *Bootstrapper) Shutdown(ctx context.Context) error {
+	b.setPhase(PhaseShutdown)
+	return b.kernel.Shutdown()
+}
+
+// ResetEphemeralFacts retracts every predicate in the EDB that IsEphemeral,
+// returning how many distinct predicates were retracted. Used by
+// Bootstrapper's PhaseReconcile and Hibernate; exported so callers that
+// want the reset without the rest of the lifecycle (e.g. a long-running
+// process recycling sessions without restarting the kernel) can call it
+// directly.
+func (k *RealKernel) ResetEphemeralFacts() (int, error) {
+	k.mu.RLock()
+	predicates := make(map[string]bool)
+	for _, f := range k.facts {
+		if IsEphemeral(f.Predicate) {
+			predicates[f.Predicate] = true
+		}
+	}
+	k.mu.RUnlock()
+
+	for predicate := range predicates {
+		if err := k.Retract(predicate); err != nil {
+			return 0, fmt.Errorf("retract ephemeral predicate %s: %w", predicate, err)
+		}
+	}
+	return len(predicates), nil
+}
+
+// checkZeroEphemeral returns an error naming the first ephemeral predicate
+// still present in the EDB, or nil if none remain.
+func (k *RealKernel) checkZeroEphemeral() error {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, f := range k.facts {
+		if IsEphemeral(f.Predicate) {
+			return fmt.Errorf("ephemeral predicate %s still present", f.Predicate)
+		}
+	}
+	return nil
+}
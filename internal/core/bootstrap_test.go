@@ -0,0 +1,145 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func newBootstrapTestKernel(t *testing.T) *RealKernel {
+	t.Helper()
+	kernel, err := NewRealKernel()
+	if err != nil {
+		t.Fatalf("NewRealKernel() error = %v", err)
+	}
+	return kernel
+}
+
+func TestBootstrapper_RunReachesReady(t *testing.T) {
+	kernel := newBootstrapTestKernel(t)
+	b := NewBootstrapper(kernel)
+
+	if err := b.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if b.Phase() != PhaseReady {
+		t.Errorf("expected PhaseReady, got %v", b.Phase())
+	}
+}
+
+func TestBootstrapper_RunDropsLeftoverEphemeralFacts(t *testing.T) {
+	kernel := newBootstrapTestKernel(t)
+	if err := kernel.Assert(Fact{Predicate: "user_intent", Args: []interface{}{"stale"}}); err != nil {
+		t.Fatalf("Assert() error = %v", err)
+	}
+
+	b := NewBootstrapper(kernel)
+	if err := b.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if err := kernel.checkZeroEphemeral(); err != nil {
+		t.Errorf("expected no ephemeral facts after Run(), got: %v", err)
+	}
+}
+
+func TestBootstrapper_HooksFireInOrder(t *testing.T) {
+	kernel := newBootstrapTestKernel(t)
+	b := NewBootstrapper(kernel)
+
+	var calls []string
+	b.OnBeforeLoadPersistent(func(ctx context.Context, k *RealKernel) error {
+		calls = append(calls, "before")
+		return nil
+	})
+	b.OnAfterLoadPersistent(func(ctx context.Context, k *RealKernel) error {
+		calls = append(calls, "after")
+		return nil
+	})
+	b.OnEphemeralReset(func(ctx context.Context, k *RealKernel) error {
+		calls = append(calls, "reset")
+		return nil
+	})
+	b.OnQuiescent(func(ctx context.Context, k *RealKernel) error {
+		calls = append(calls, "quiescent")
+		return nil
+	})
+
+	if err := b.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := []string{"before", "after", "reset", "quiescent"}
+	if len(calls) != len(want) {
+		t.Fatalf("expected %v, got %v", want, calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, calls)
+			break
+		}
+	}
+}
+
+func TestBootstrapper_HookErrorAbortsRun(t *testing.T) {
+	kernel := newBootstrapTestKernel(t)
+	b := NewBootstrapper(kernel)
+
+	b.OnBeforeLoadPersistent(func(ctx context.Context, k *RealKernel) error {
+		return context.DeadlineExceeded
+	})
+
+	if err := b.Run(context.Background()); err == nil {
+		t.Fatal("expected Run() to fail when a hook returns an error")
+	}
+	if b.Phase() == PhaseReady {
+		t.Error("expected Run() not to reach PhaseReady when a hook fails")
+	}
+}
+
+func TestBootstrapper_HibernateThenResume(t *testing.T) {
+	kernel := newBootstrapTestKernel(t)
+	b := NewBootstrapper(kernel)
+	if err := b.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if err := b.Hibernate(context.Background()); err != nil {
+		t.Fatalf("Hibernate() error = %v", err)
+	}
+	if b.Phase() != PhaseHibernate {
+		t.Errorf("expected PhaseHibernate, got %v", b.Phase())
+	}
+
+	if err := b.Resume(context.Background()); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if b.Phase() != PhaseReady {
+		t.Errorf("expected PhaseReady after Resume(), got %v", b.Phase())
+	}
+
+	// Resume is idempotent when already Ready.
+	if err := b.Resume(context.Background()); err != nil {
+		t.Fatalf("second Resume() error = %v", err)
+	}
+	if b.Phase() != PhaseReady {
+		t.Errorf("expected PhaseReady after idempotent Resume(), got %v", b.Phase())
+	}
+}
+
+func TestRealKernel_ResetEphemeralFacts(t *testing.T) {
+	kernel := newBootstrapTestKernel(t)
+	if err := kernel.Assert(Fact{Predicate: "pending_action", Args: []interface{}{"do_thing"}}); err != nil {
+		t.Fatalf("Assert() error = %v", err)
+	}
+
+	n, err := kernel.ResetEphemeralFacts()
+	if err != nil {
+		t.Fatalf("ResetEphemeralFacts() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 ephemeral predicate retracted, got %d", n)
+	}
+	if err := kernel.checkZeroEphemeral(); err != nil {
+		t.Errorf("expected no ephemeral facts remaining, got: %v", err)
+	}
+}
@@ -0,0 +1,340 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/mangle/ast"
+	"gopkg.in/yaml.v3"
+)
+
+// Classifier decides which FactCategory a predicate belongs to. It exists
+// so the IsEphemeral/IsDerived/IsPersistent/GetCategory package functions
+// (fact_categories.go) aren't hard-wired to EphemeralPredicates/
+// DerivedPredicates forever - a project can layer its own overrides (see
+// CompositeClassifier) without forking this file.
+type Classifier interface {
+	// Category returns predicate's FactCategory.
+	Category(predicate string) FactCategory
+	// RegisterEphemeral marks predicate (or, if it contains a glob
+	// metacharacter, a glob pattern like "dream_*") as ephemeral.
+	RegisterEphemeral(predicate string)
+	// RegisterDerived marks predicate (or a glob pattern) as derived.
+	RegisterDerived(predicate string)
+	// RegisterPersistent marks predicate (or a glob pattern) as
+	// persistent.
+	RegisterPersistent(predicate string)
+	// EncryptionRequired reports whether predicate's persisted records
+	// must be sealed at rest (SealedStore, sealed_store.go). Orthogonal to
+	// Category: a predicate can be persistent and sensitive at once.
+	EncryptionRequired(predicate string) bool
+	// RegisterSensitive marks predicate (or a glob pattern) as requiring
+	// at-rest encryption.
+	RegisterSensitive(predicate string)
+}
+
+// MapClassifier is a Classifier backed by exact-match sets and glob
+// patterns for each category, checked in derived -> ephemeral ->
+// persistent order so a predicate registered in more than one category
+// resolves predictably.
+type MapClassifier struct {
+	mu sync.RWMutex
+
+	ephemeral  map[string]bool
+	derived    map[string]bool
+	persistent map[string]bool
+	sensitive  map[string]bool
+
+	ephemeralGlobs  []string
+	derivedGlobs    []string
+	persistentGlobs []string
+	sensitiveGlobs  []string
+}
+
+// NewMapClassifier creates an empty MapClassifier.
+func NewMapClassifier() *MapClassifier {
+	return &MapClassifier{
+		ephemeral:  make(map[string]bool),
+		derived:    make(map[string]bool),
+		persistent: make(map[string]bool),
+		sensitive:  make(map[string]bool),
+	}
+}
+
+// isGlobPattern reports whether predicate should be treated as a glob
+// pattern (e.g. "dream_*", "tool_*") rather than an exact predicate name.
+func isGlobPattern(predicate string) bool {
+	return strings.ContainsAny(predicate, "*?[")
+}
+
+func (c *MapClassifier) RegisterEphemeral(predicate string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if isGlobPattern(predicate) {
+		c.ephemeralGlobs = append(c.ephemeralGlobs, predicate)
+		return
+	}
+	c.ephemeral[predicate] = true
+}
+
+func (c *MapClassifier) RegisterDerived(predicate string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if isGlobPattern(predicate) {
+		c.derivedGlobs = append(c.derivedGlobs, predicate)
+		return
+	}
+	c.derived[predicate] = true
+}
+
+func (c *MapClassifier) RegisterPersistent(predicate string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if isGlobPattern(predicate) {
+		c.persistentGlobs = append(c.persistentGlobs, predicate)
+		return
+	}
+	c.persistent[predicate] = true
+}
+
+// RegisterSensitive marks predicate (or a glob pattern) as requiring
+// at-rest encryption. Orthogonal to RegisterEphemeral/Derived/Persistent -
+// a predicate can be registered in both.
+func (c *MapClassifier) RegisterSensitive(predicate string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if isGlobPattern(predicate) {
+		c.sensitiveGlobs = append(c.sensitiveGlobs, predicate)
+		return
+	}
+	c.sensitive[predicate] = true
+}
+
+// EncryptionRequired implements Classifier.
+func (c *MapClassifier) EncryptionRequired(predicate string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sensitive[predicate] || matchesAnyGlob(c.sensitiveGlobs, predicate)
+}
+
+// Category implements Classifier.
+func (c *MapClassifier) Category(predicate string) FactCategory {
+	cat, _ := c.lookup(predicate)
+	return cat
+}
+
+// lookup returns predicate's category and whether this classifier actually
+// has an opinion about it (false means "fall through to the next layer",
+// not "this predicate is persistent").
+func (c *MapClassifier) lookup(predicate string) (FactCategory, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.derived[predicate] || matchesAnyGlob(c.derivedGlobs, predicate) {
+		return FactCategoryDerived, true
+	}
+	if c.ephemeral[predicate] || matchesAnyGlob(c.ephemeralGlobs, predicate) {
+		return FactCategoryEphemeral, true
+	}
+	if c.persistent[predicate] || matchesAnyGlob(c.persistentGlobs, predicate) {
+		return FactCategoryPersistent, true
+	}
+	return FactCategoryPersistent, false
+}
+
+// matchesAnyGlob reports whether predicate matches any pattern in globs,
+// using filepath.Match (this repo's established glob convention - see
+// browser.MatchesGlobs).
+func matchesAnyGlob(globs []string, predicate string) bool {
+	for _, pattern := range globs {
+		if ok, err := filepath.Match(pattern, predicate); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CompositeClassifier layers three Classifier sources, checked in this
+// order: runtime (highest priority - predicates registered this session,
+// e.g. from Mangle Decl annotations seen while loading schemas), project
+// (loaded once from .codenerd/predicates.yaml), and builtins
+// (EphemeralPredicates/DerivedPredicates, fact_categories.go). The first
+// layer with an opinion about a predicate wins; a predicate none of them
+// know about defaults to FactCategoryPersistent, same as GetCategory
+// always has.
+type CompositeClassifier struct {
+	runtime  *MapClassifier
+	project  *MapClassifier
+	builtins *MapClassifier
+}
+
+// NewCompositeClassifier creates a CompositeClassifier with an empty
+// runtime layer, an empty project layer, and builtins as its base layer.
+func NewCompositeClassifier(builtins *MapClassifier) *CompositeClassifier {
+	return &CompositeClassifier{
+		runtime:  NewMapClassifier(),
+		project:  NewMapClassifier(),
+		builtins: builtins,
+	}
+}
+
+// SetProjectLayer replaces this classifier's project layer, e.g. with the
+// result of LoadProjectClassifierConfig.
+func (c *CompositeClassifier) SetProjectLayer(project *MapClassifier) {
+	c.project = project
+}
+
+// Category implements Classifier.
+func (c *CompositeClassifier) Category(predicate string) FactCategory {
+	for _, layer := range []*MapClassifier{c.runtime, c.project, c.builtins} {
+		if layer == nil {
+			continue
+		}
+		if cat, ok := layer.lookup(predicate); ok {
+			return cat
+		}
+	}
+	return FactCategoryPersistent
+}
+
+// RegisterEphemeral registers predicate in the runtime layer.
+func (c *CompositeClassifier) RegisterEphemeral(predicate string) {
+	c.runtime.RegisterEphemeral(predicate)
+}
+
+// RegisterDerived registers predicate in the runtime layer.
+func (c *CompositeClassifier) RegisterDerived(predicate string) {
+	c.runtime.RegisterDerived(predicate)
+}
+
+// RegisterPersistent registers predicate in the runtime layer.
+func (c *CompositeClassifier) RegisterPersistent(predicate string) {
+	c.runtime.RegisterPersistent(predicate)
+}
+
+// EncryptionRequired implements Classifier. Unlike Category, sensitivity is
+// additive rather than first-layer-wins: any layer marking predicate
+// sensitive is enough, since under-encrypting a secret is the more
+// dangerous failure mode.
+func (c *CompositeClassifier) EncryptionRequired(predicate string) bool {
+	for _, layer := range []*MapClassifier{c.runtime, c.project, c.builtins} {
+		if layer != nil && layer.EncryptionRequired(predicate) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterSensitive registers predicate in the runtime layer.
+func (c *CompositeClassifier) RegisterSensitive(predicate string) {
+	c.runtime.RegisterSensitive(predicate)
+}
+
+// defaultClassifier is what IsEphemeral/IsDerived/IsPersistent/GetCategory
+// (fact_categories.go) delegate to. Its builtins layer is seeded from
+// EphemeralPredicates/DerivedPredicates so existing behavior is unchanged
+// for any predicate nobody has registered an override for.
+var defaultClassifier = NewCompositeClassifier(builtinMapClassifier())
+
+// builtinMapClassifier builds the builtins layer from the package-level
+// EphemeralPredicates/DerivedPredicates maps.
+func builtinMapClassifier() *MapClassifier {
+	c := NewMapClassifier()
+	for predicate := range EphemeralPredicates {
+		c.RegisterEphemeral(predicate)
+	}
+	for predicate := range DerivedPredicates {
+		c.RegisterDerived(predicate)
+	}
+	return c
+}
+
+// DefaultClassifier returns the package-level Classifier that
+// IsEphemeral/IsDerived/IsPersistent/GetCategory use. Call its
+// RegisterEphemeral/RegisterDerived/RegisterPersistent methods to add
+// runtime overrides (e.g. from a custom schema loader), or SetProjectLayer
+// after LoadProjectClassifierConfig to apply a project's
+// .codenerd/predicates.yaml.
+func DefaultClassifier() *CompositeClassifier {
+	return defaultClassifier
+}
+
+// predicateConfigFile is the on-disk shape of .codenerd/predicates.yaml: a
+// project's per-predicate classification overrides, each entry either an
+// exact predicate name or a glob pattern like "dream_*".
+type predicateConfigFile struct {
+	Ephemeral  []string `yaml:"ephemeral"`
+	Derived    []string `yaml:"derived"`
+	Persistent []string `yaml:"persistent"`
+	Sensitive  []string `yaml:"sensitive"`
+}
+
+// LoadProjectClassifierConfig reads workspaceRoot/.codenerd/predicates.yaml
+// and returns a MapClassifier seeded from it, suitable for
+// CompositeClassifier.SetProjectLayer. A missing file is not an error: it
+// returns an empty MapClassifier, so the composite just falls through to
+// its other layers.
+func LoadProjectClassifierConfig(workspaceRoot string) (*MapClassifier, error) {
+	classifier := NewMapClassifier()
+
+	path := filepath.Join(workspaceRoot, ".codenerd", "predicates.yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return classifier, nil
+		}
+		return nil, fmt.Errorf("LoadProjectClassifierConfig: read %s: %w", path, err)
+	}
+
+	var cfg predicateConfigFile
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("LoadProjectClassifierConfig: parse %s: %w", path, err)
+	}
+	for _, predicate := range cfg.Ephemeral {
+		classifier.RegisterEphemeral(predicate)
+	}
+	for _, predicate := range cfg.Derived {
+		classifier.RegisterDerived(predicate)
+	}
+	for _, predicate := range cfg.Persistent {
+		classifier.RegisterPersistent(predicate)
+	}
+	for _, predicate := range cfg.Sensitive {
+		classifier.RegisterSensitive(predicate)
+	}
+	return classifier, nil
+}
+
+// declAnnotationCategories maps a Mangle Decl descr annotation atom's
+// predicate symbol to the FactCategory it declares, e.g.
+// "Decl dream_hypothesis(X) descr [ephemeral()]." registers
+// dream_hypothesis as ephemeral the first time that Decl is loaded.
+var declAnnotationCategories = map[string]func(Classifier, string){
+	"ephemeral":  Classifier.RegisterEphemeral,
+	"derived":    Classifier.RegisterDerived,
+	"persistent": Classifier.RegisterPersistent,
+	"sensitive":  Classifier.RegisterSensitive,
+}
+
+// RegisterFromSchemaDecls scans every Decl in decls for an "ephemeral()",
+// "derived()", "persistent()", or "sensitive()" descr annotation atom and
+// registers the declared predicate with classifier accordingly. Called after loading
+// schemas so a policy author can classify a predicate right next to where
+// it's declared instead of editing fact_categories.go.
+func RegisterFromSchemaDecls(classifier Classifier, decls map[ast.PredicateSym]*ast.Decl) {
+	for sym, decl := range decls {
+		if decl == nil {
+			continue
+		}
+		for _, annotation := range decl.Descr {
+			register, ok := declAnnotationCategories[annotation.Predicate.Symbol]
+			if !ok {
+				continue
+			}
+			register(classifier, sym.Symbol)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMapClassifier_ExactMatch(t *testing.T) {
+	c := NewMapClassifier()
+	c.RegisterEphemeral("user_intent")
+	c.RegisterDerived("permitted")
+	c.RegisterPersistent("goal")
+
+	if cat := c.Category("user_intent"); cat != FactCategoryEphemeral {
+		t.Errorf("user_intent: got %v, want ephemeral", cat)
+	}
+	if cat := c.Category("permitted"); cat != FactCategoryDerived {
+		t.Errorf("permitted: got %v, want derived", cat)
+	}
+	if cat := c.Category("goal"); cat != FactCategoryPersistent {
+		t.Errorf("goal: got %v, want persistent", cat)
+	}
+}
+
+func TestMapClassifier_GlobPattern(t *testing.T) {
+	c := NewMapClassifier()
+	c.RegisterEphemeral("dream_*")
+
+	if cat := c.Category("dream_hypothesis"); cat != FactCategoryEphemeral {
+		t.Errorf("dream_hypothesis: got %v, want ephemeral", cat)
+	}
+	if cat := c.Category("dream_simulation"); cat != FactCategoryEphemeral {
+		t.Errorf("dream_simulation: got %v, want ephemeral", cat)
+	}
+	if cat := c.Category("other_fact"); cat != FactCategoryPersistent {
+		t.Errorf("other_fact: got %v, want persistent (no opinion)", cat)
+	}
+}
+
+func TestMapClassifier_UnknownDefaultsPersistentWithoutOpinion(t *testing.T) {
+	c := NewMapClassifier()
+	if _, ok := c.lookup("never_registered"); ok {
+		t.Error("expected lookup to report no opinion for an unregistered predicate")
+	}
+}
+
+func TestCompositeClassifier_LayerPrecedence(t *testing.T) {
+	builtins := NewMapClassifier()
+	builtins.RegisterPersistent("shared_fact")
+
+	project := NewMapClassifier()
+	project.RegisterDerived("shared_fact")
+
+	c := NewCompositeClassifier(builtins)
+	c.SetProjectLayer(project)
+
+	if cat := c.Category("shared_fact"); cat != FactCategoryDerived {
+		t.Errorf("expected project layer to win over builtins, got %v", cat)
+	}
+
+	c.RegisterEphemeral("shared_fact")
+	if cat := c.Category("shared_fact"); cat != FactCategoryEphemeral {
+		t.Errorf("expected runtime layer to win over project, got %v", cat)
+	}
+}
+
+func TestCompositeClassifier_FallsThroughToPersistent(t *testing.T) {
+	c := NewCompositeClassifier(NewMapClassifier())
+	if cat := c.Category("nobody_knows_this"); cat != FactCategoryPersistent {
+		t.Errorf("expected default persistent for unknown predicate, got %v", cat)
+	}
+}
+
+func TestLoadProjectClassifierConfig_MissingFileIsNotError(t *testing.T) {
+	dir := t.TempDir()
+	c, err := LoadProjectClassifierConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadProjectClassifierConfig() error = %v", err)
+	}
+	if cat := c.Category("anything"); cat != FactCategoryPersistent {
+		t.Errorf("expected empty classifier to have no opinion, got %v", cat)
+	}
+}
+
+func TestLoadProjectClassifierConfig_ParsesYAML(t *testing.T) {
+	dir := t.TempDir()
+	codenerdDir := filepath.Join(dir, ".codenerd")
+	if err := os.MkdirAll(codenerdDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	yaml := []byte("ephemeral:\n  - scratch_*\nderived:\n  - computed_total\npersistent:\n  - project_goal\n")
+	if err := os.WriteFile(filepath.Join(codenerdDir, "predicates.yaml"), yaml, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	c, err := LoadProjectClassifierConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadProjectClassifierConfig() error = %v", err)
+	}
+	if cat := c.Category("scratch_pad"); cat != FactCategoryEphemeral {
+		t.Errorf("scratch_pad: got %v, want ephemeral", cat)
+	}
+	if cat := c.Category("computed_total"); cat != FactCategoryDerived {
+		t.Errorf("computed_total: got %v, want derived", cat)
+	}
+	if cat := c.Category("project_goal"); cat != FactCategoryPersistent {
+		t.Errorf("project_goal: got %v, want persistent", cat)
+	}
+}
+
+func TestGetCategory_MatchesExistingBuiltinMaps(t *testing.T) {
+	for predicate := range EphemeralPredicates {
+		if !IsEphemeral(predicate) {
+			t.Errorf("IsEphemeral(%q) = false, want true", predicate)
+		}
+	}
+	for predicate := range DerivedPredicates {
+		if !IsDerived(predicate) {
+			t.Errorf("IsDerived(%q) = false, want true", predicate)
+		}
+	}
+	if !IsPersistent("some_unregistered_predicate") {
+		t.Error(`IsPersistent("some_unregistered_predicate") = false, want true`)
+	}
+}
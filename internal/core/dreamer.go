@@ -3,13 +3,22 @@ package core
 import (
 	"codenerd/internal/logging"
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrKernelUnavailable is returned (as DreamResult.Reason, with Unsafe=true)
+// when a Dreamer has no kernel to simulate against. Dreamer fails closed: an
+// action can't be proven safe without a kernel to evaluate it against, so
+// the absence of one is treated the same as an unsafe verdict rather than a
+// silent pass-through.
+var ErrKernelUnavailable = errors.New("dreamer: no kernel available")
+
 // DreamResult captures the speculative evaluation of a single action.
 type DreamResult struct {
 	ActionID       string
@@ -19,81 +28,282 @@ type DreamResult struct {
 	Reason         string
 }
 
-// DreamCache is a threadsafe cache of dream results (action -> verdict).
-type DreamCache struct {
-	mu      sync.RWMutex
-	results map[string]DreamResult
+// cloneLatency is a small atomic histogram-of-one (count + total duration)
+// for Dreamer's kernel deep-copy path, snapshotted by Stats.
+type cloneLatency struct {
+	count         int64
+	totalDuration int64 // nanoseconds
 }
 
-// NewDreamCache creates an empty dream cache.
-func NewDreamCache() *DreamCache {
-	logging.DreamDebug("Creating new DreamCache")
-	return &DreamCache{
-		results: make(map[string]DreamResult),
-	}
+func (c *cloneLatency) record(start time.Time) {
+	atomic.AddInt64(&c.count, 1)
+	atomic.AddInt64(&c.totalDuration, int64(time.Since(start)))
 }
 
-// Store saves a result.
-func (c *DreamCache) Store(result DreamResult) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.results[result.ActionID] = result
-	logging.DreamDebug("DreamCache: stored result for action %s (unsafe=%v)", result.ActionID, result.Unsafe)
+// CloneLatencyStats is a point-in-time snapshot of Dreamer's kernel
+// deep-copy latency.
+type CloneLatencyStats struct {
+	Count         int64
+	TotalDuration time.Duration
 }
 
-// Get retrieves a result by action ID.
-func (c *DreamCache) Get(actionID string) (DreamResult, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	res, ok := c.results[actionID]
-	if ok {
-		logging.DreamDebug("DreamCache: cache hit for action %s", actionID)
-	} else {
-		logging.DreamDebug("DreamCache: cache miss for action %s", actionID)
+func (c *cloneLatency) snapshot() CloneLatencyStats {
+	return CloneLatencyStats{
+		Count:         atomic.LoadInt64(&c.count),
+		TotalDuration: time.Duration(atomic.LoadInt64(&c.totalDuration)),
 	}
-	return res, ok
+}
+
+// DreamerStats is a point-in-time snapshot of a Dreamer's runtime state,
+// returned by Dreamer.Stats().
+type DreamerStats struct {
+	InFlightSimulations int64
+	KernelEpoch         uint64
+	CloneLatency        CloneLatencyStats
+	CacheHits           int64
+	CacheMisses         int64
 }
 
 // Dreamer simulates the impact of actions before execution.
+//
+// The kernel reference is held behind an atomic.Pointer so SimulateAction
+// (reader) and SwapKernel/SetKernel (writer) never race: SimulateAction
+// loads the pointer exactly once per call, pinning that call to a single
+// kernel generation - analogous to opening a read transaction against OPA's
+// storage layer - rather than re-reading a shared field that a concurrent
+// swap could mutate mid-simulation.
 type Dreamer struct {
-	kernel *RealKernel
+	kernelPtr atomic.Pointer[RealKernel]
+	epoch     atomic.Uint64
+	inFlight  atomic.Int64
+
+	// drainMu/drainCond back SwapKernel's drain-to-zero wait. A
+	// sync.WaitGroup won't do here: SimulateAction's Add-equivalent
+	// (enterSimulation) can happen concurrently with SwapKernel's
+	// Wait-equivalent (drain) while inFlight is at zero, which is exactly
+	// the "Add with a positive delta concurrent with Wait" pattern
+	// sync.WaitGroup documents as a misuse (and panics/races on under
+	// -race). A Cond just rechecks the atomic counter under a lock instead,
+	// so there's no such ordering requirement.
+	drainMu   sync.Mutex
+	drainCond *sync.Cond
+
+	cloneStats cloneLatency
+	cache      *DreamCache
+	metrics    atomic.Pointer[DreamerMetrics]
+	normalizer atomic.Pointer[ActionNormalizer]
 }
 
-// NewDreamer creates a Dreamer backed by the provided kernel.
+// NewDreamer creates a Dreamer backed by the provided kernel, with its
+// DreamCache bounded by DefaultDreamCacheOptions(). kernel may be nil;
+// SimulateAction then fails closed until SetKernel/SwapKernel supplies one.
 func NewDreamer(kernel *RealKernel) *Dreamer {
+	return NewDreamerWithCacheOptions(kernel, DefaultDreamCacheOptions())
+}
+
+// NewDreamerWithCacheOptions creates a Dreamer whose DreamCache is bounded
+// by cacheOpts instead of the defaults.
+func NewDreamerWithCacheOptions(kernel *RealKernel, cacheOpts DreamCacheOptions) *Dreamer {
 	logging.Dream("Creating new Dreamer instance")
-	return &Dreamer{kernel: kernel}
+	d := &Dreamer{}
+	d.drainCond = sync.NewCond(&d.drainMu)
+	d.kernelPtr.Store(kernel)
+	var noop DreamerMetrics = noopDreamerMetrics{}
+	d.metrics.Store(&noop)
+	d.normalizer.Store(NewActionNormalizer(""))
+	d.cache = NewDreamCache(cacheOpts)
+	return d
+}
+
+// SetNormalizer installs n as this Dreamer's ActionNormalizer, replacing the
+// default (which has no workspace root, so it never flags a path as
+// escaping). Pass a normalizer built with NewActionNormalizer(workspaceRoot)
+// once the workspace root is known.
+func (d *Dreamer) SetNormalizer(n *ActionNormalizer) {
+	if n == nil {
+		n = NewActionNormalizer("")
+	}
+	d.normalizer.Store(n)
+}
+
+func (d *Dreamer) normalizerSink() *ActionNormalizer {
+	if n := d.normalizer.Load(); n != nil {
+		return n
+	}
+	return NewActionNormalizer("")
+}
+
+// SetMetrics installs m as this Dreamer's instrumentation sink, replacing
+// the default no-op, and also installs it on the Dreamer's DreamCache so
+// cache hit/miss/eviction events reach the same sink.
+func (d *Dreamer) SetMetrics(m DreamerMetrics) {
+	if m == nil {
+		m = noopDreamerMetrics{}
+	}
+	d.metrics.Store(&m)
+	d.cache.SetMetrics(m)
 }
 
-// SetKernel updates the kernel reference (used when the virtual store swaps kernels).
+func (d *Dreamer) metricsSink() DreamerMetrics {
+	if m := d.metrics.Load(); m != nil {
+		return *m
+	}
+	return noopDreamerMetrics{}
+}
+
+// SetKernel updates the kernel reference (used when the virtual store swaps
+// kernels). It is a thin wrapper over SwapKernel for callers that don't need
+// the previous kernel back.
 func (d *Dreamer) SetKernel(kernel *RealKernel) {
-	d.kernel = kernel
-	logging.DreamDebug("Dreamer: kernel reference updated")
+	d.SwapKernel(kernel)
+}
+
+// SwapKernel atomically publishes newKernel, bumps the kernel epoch (which
+// is baked into every DreamCache key, so entries from a prior generation
+// are never matched again), and blocks until every simulation in flight at
+// the moment of the call has finished, before returning the previous kernel
+// (nil if none was set).
+func (d *Dreamer) SwapKernel(newKernel *RealKernel) *RealKernel {
+	old := d.kernelPtr.Swap(newKernel)
+	d.epoch.Add(1)
+	logging.DreamDebug("Dreamer: kernel swapped (epoch=%d)", d.epoch.Load())
+
+	// Drain simulations that started against the previous kernel (and any
+	// that raced in against the new one) before handing control back, so a
+	// caller that just swapped in a corrected kernel doesn't observe stale
+	// in-flight verdicts as "done".
+	d.drainMu.Lock()
+	for d.inFlight.Load() != 0 {
+		d.drainCond.Wait()
+	}
+	d.drainMu.Unlock()
+
+	// The epoch bump already makes every existing entry unreachable; purge
+	// reclaims their memory immediately instead of waiting for TTL/size
+	// eviction to get around to them.
+	d.cache.Purge()
+	return old
+}
+
+// Epoch returns the current kernel generation, bumped once per SwapKernel
+// call.
+func (d *Dreamer) Epoch() uint64 {
+	return d.epoch.Load()
+}
+
+// Stats returns a snapshot of in-flight simulations, kernel deep-copy
+// latency, and cache hit/miss counters. See CacheStats for the full cache
+// breakdown, including entry count, byte size, and evictions.
+func (d *Dreamer) Stats() DreamerStats {
+	cacheStats := d.cache.Stats()
+	return DreamerStats{
+		InFlightSimulations: d.inFlight.Load(),
+		KernelEpoch:         d.epoch.Load(),
+		CloneLatency:        d.cloneStats.snapshot(),
+		CacheHits:           cacheStats.Hits,
+		CacheMisses:         cacheStats.Misses,
+	}
+}
+
+// CacheStats returns a point-in-time snapshot of this Dreamer's bounded
+// DreamCache: entry count, total estimated bytes, and hit/miss/eviction
+// counters.
+func (d *Dreamer) CacheStats() CacheStats {
+	return d.cache.Stats()
+}
+
+// Purge discards every cached dream result. SwapKernel already purges
+// automatically on every kernel swap; exposed mainly for tests and for
+// operators who want to force a cold cache without swapping kernels.
+func (d *Dreamer) Purge() {
+	d.cache.Purge()
 }
 
 // SimulateAction performs a speculative evaluation of a single action.
 // It returns a DreamResult with any panic_state detections.
-func (d *Dreamer) SimulateAction(ctx context.Context, req ActionRequest) DreamResult {
+//
+// A nil ctx is treated as context.Background(). A Dreamer with no kernel
+// (or a nil *Dreamer) fails closed: the result is Unsafe with Reason set to
+// ErrKernelUnavailable, never a silent "safe".
+func (d *Dreamer) SimulateAction(ctx context.Context, req ActionRequest) (result DreamResult) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	timer := logging.StartTimer(logging.CategoryDream, fmt.Sprintf("SimulateAction(%s)", req.Type))
+	simulateStart := time.Now()
 	actionID := fmt.Sprintf("dream:%s:%d", req.Type, time.Now().UnixNano())
 	logging.Dream("SimulateAction: starting simulation for %s (target=%s)", req.Type, req.Target)
 	logging.DreamDebug("SimulateAction: actionID=%s", actionID)
 
-	result := DreamResult{
+	result = DreamResult{
 		ActionID: actionID,
 		Request:  req,
 	}
 
-	// No kernel available -> nothing to simulate
-	if d == nil || d.kernel == nil {
-		logging.DreamDebug("SimulateAction: no kernel available, returning safe (no simulation)")
+	if d == nil {
+		logging.Get(logging.CategoryDream).Warn("SimulateAction: nil Dreamer, failing closed")
+		result.Unsafe = true
+		result.Reason = ErrKernelUnavailable.Error()
+		timer.Stop()
+		return result
+	}
+
+	defer func() {
+		d.metricsSink().ObserveSimulateDuration(simulateOutcome(result), time.Since(simulateStart))
+	}()
+
+	// Pin this call to a single kernel generation for its entire duration -
+	// a concurrent SwapKernel must not change what this simulation sees
+	// partway through.
+	d.inFlight.Add(1)
+	defer func() {
+		if d.inFlight.Add(-1) == 0 {
+			// Wake any SwapKernel blocked in drain - it rechecks
+			// inFlight.Load() itself, so a spurious wakeup (or one that
+			// loses a race to another simulation starting) is harmless.
+			d.drainMu.Lock()
+			d.drainCond.Broadcast()
+			d.drainMu.Unlock()
+		}
+	}()
+	kernel := d.kernelPtr.Load()
+
+	if kernel == nil {
+		logging.Get(logging.CategoryDream).Warn("SimulateAction: no kernel available, failing closed")
+		result.Unsafe = true
+		result.Reason = ErrKernelUnavailable.Error()
 		timer.Stop()
 		return result
 	}
 
+	select {
+	case <-ctx.Done():
+		result.Unsafe = true
+		result.Reason = ctx.Err().Error()
+		timer.Stop()
+		return result
+	default:
+	}
+
+	// A repeat of the same (type, normalized target, payload) against this
+	// kernel generation needs no fresh clone+evaluate - return the cached
+	// verdict with this call's own ActionID/Request, keeping the cached
+	// ProjectedFacts' embedded ActionID as originally projected.
+	epoch := d.epoch.Load()
+	normalizedTarget, _ := normalizeTarget(d.normalizerSink(), req)
+	cacheKey := dreamCacheKey(req, normalizedTarget, epoch)
+	if cached, ok := d.cache.Get(cacheKey); ok {
+		logging.DreamDebug("SimulateAction: cache hit for action %s (key=%s)", actionID, cacheKey)
+		cached.ActionID = actionID
+		cached.Request = req
+		timer.Stop()
+		return cached
+	}
+
 	// Build projected facts for this action
 	logging.DreamDebug("SimulateAction: projecting effects for action %s", actionID)
-	projected := d.projectEffects(actionID, req)
+	projected := d.projectEffects(ctx, actionID, req, kernel)
 	result.ProjectedFacts = projected
 	logging.DreamDebug("SimulateAction: projected %d facts", len(projected))
 
@@ -101,6 +311,7 @@ func (d *Dreamer) SimulateAction(ctx context.Context, req ActionRequest) DreamRe
 	select {
 	case <-ctx.Done():
 		logging.Get(logging.CategoryDream).Warn("SimulateAction: context canceled for %s", actionID)
+		result.Unsafe = true
 		result.Reason = ctx.Err().Error()
 		timer.Stop()
 		return result
@@ -108,7 +319,7 @@ func (d *Dreamer) SimulateAction(ctx context.Context, req ActionRequest) DreamRe
 	}
 
 	logging.DreamDebug("SimulateAction: evaluating projection for safety")
-	unsafe, reason := d.evaluateProjection(actionID, projected)
+	unsafe, reason := d.evaluateProjection(actionID, projected, kernel)
 	result.Unsafe = unsafe
 	result.Reason = reason
 
@@ -118,15 +329,35 @@ func (d *Dreamer) SimulateAction(ctx context.Context, req ActionRequest) DreamRe
 		logging.Dream("SimulateAction: action %s deemed safe", req.Type)
 	}
 
+	d.cache.Store(cacheKey, result, epoch)
+
 	timer.Stop()
 	return result
 }
 
+// simulateOutcome classifies a DreamResult for the
+// dreamer_simulate_duration_seconds{outcome=...} metric: "error" for
+// cancellation or a missing kernel, "unsafe" for a policy-driven
+// panic_state, "safe" otherwise.
+func simulateOutcome(result DreamResult) string {
+	switch result.Reason {
+	case ErrKernelUnavailable.Error(), context.Canceled.Error(), context.DeadlineExceeded.Error():
+		return "error"
+	}
+	if result.Unsafe {
+		return "unsafe"
+	}
+	return "safe"
+}
+
 // evaluateProjection loads projected facts into a sandboxed kernel and queries panic_state.
-func (d *Dreamer) evaluateProjection(actionID string, projected []Fact) (bool, string) {
+func (d *Dreamer) evaluateProjection(actionID string, projected []Fact, kernel *RealKernel) (bool, string) {
 	timer := logging.StartTimer(logging.CategoryDream, "evaluateProjection")
 	logging.DreamDebug("evaluateProjection: cloning kernel for sandbox evaluation")
-	clone := d.kernel.Clone()
+	cloneStart := time.Now()
+	clone := kernel.Clone()
+	d.cloneStats.record(cloneStart)
+	d.metricsSink().ObserveKernelCloneBytes(approxFactSetBytes(clone.facts))
 
 	// Batch-assert projections for performance
 	logging.DreamDebug("evaluateProjection: asserting %d projected facts", len(projected))
@@ -173,12 +404,44 @@ func (d *Dreamer) evaluateProjection(actionID string, projected []Fact) (bool, s
 	return false, ""
 }
 
+// normalizeTarget canonicalizes req.Target through n: as a command line for
+// ActionExecCmd (returning the parsed NormalizedCommand alongside its
+// canonical string), or as a path otherwise. Shared by projectEffects and
+// SimulateAction's cache key so both agree on what "the same action" means.
+func normalizeTarget(n *ActionNormalizer, req ActionRequest) (string, NormalizedCommand) {
+	raw := strings.TrimSpace(req.Target)
+	if req.Type == ActionExecCmd {
+		cmd := n.NormalizeCommand(raw)
+		return cmd.String(), cmd
+	}
+	return n.NormalizePath(raw).Normalized, NormalizedCommand{}
+}
+
 // projectEffects converts an ActionRequest into a set of projected facts.
-func (d *Dreamer) projectEffects(actionID string, req ActionRequest) []Fact {
+//
+// The target (a path, or a command line for ActionExecCmd) is run through
+// the Dreamer's ActionNormalizer first: projected_action carries the
+// canonical form, while projected_action_raw preserves the original for
+// anyone that needs it. This is what closes the whitespace/flag-grouping/
+// path-traversal/homoglyph bypasses of the old string.Contains checks
+// below - a policy rule written against the normalized shape can't be
+// dodged by "rm  -rf /" or "rm -fr /" the way one written against the raw
+// string could.
+func (d *Dreamer) projectEffects(ctx context.Context, actionID string, req ActionRequest, kernel *RealKernel) []Fact {
 	logging.DreamDebug("projectEffects: projecting effects for action %s (type=%s, target=%s)", actionID, req.Type, req.Target)
 
-	path := strings.TrimSpace(req.Target)
+	raw := strings.TrimSpace(req.Target)
+	path, normCmd := normalizeTarget(d.normalizerSink(), req)
+
 	projected := []Fact{
+		{
+			Predicate: "projected_action_raw",
+			Args: []interface{}{
+				actionID,
+				string(req.Type),
+				raw,
+			},
+		},
 		{
 			Predicate: "projected_action",
 			Args: []interface{}{
@@ -187,6 +450,22 @@ func (d *Dreamer) projectEffects(actionID string, req ActionRequest) []Fact {
 				path,
 			},
 		},
+		{
+			Predicate: "projected_action_type_atom",
+			Args: []interface{}{
+				actionID,
+				MangleAtom("/" + string(req.Type)),
+			},
+		},
+	}
+
+	if req.Type == ActionExecCmd {
+		for _, flag := range normCmd.Flags {
+			projected = append(projected, Fact{
+				Predicate: "projected_action_flags",
+				Args:      []interface{}{actionID, flag},
+			})
+		}
 	}
 
 	switch req.Type {
@@ -211,7 +490,7 @@ func (d *Dreamer) projectEffects(actionID string, req ActionRequest) []Fact {
 				},
 			})
 		}
-		projected = append(projected, d.codeGraphProjections(actionID, path)...)
+		projected = append(projected, d.codeGraphProjections(ctx, actionID, path, kernel)...)
 
 	case ActionWriteFile, ActionEditFile, ActionEditLines, ActionInsertLines, ActionDeleteLines:
 		logging.DreamDebug("projectEffects: projecting file modification effects for %s", path)
@@ -242,7 +521,7 @@ func (d *Dreamer) projectEffects(actionID string, req ActionRequest) []Fact {
 				},
 			})
 		}
-		projected = append(projected, d.codeGraphProjections(actionID, path)...)
+		projected = append(projected, d.codeGraphProjections(ctx, actionID, path, kernel)...)
 
 	case ActionExecCmd:
 		logging.DreamDebug("projectEffects: projecting exec_cmd effects for command: %s", path)
@@ -254,7 +533,7 @@ func (d *Dreamer) projectEffects(actionID string, req ActionRequest) []Fact {
 				path,
 			},
 		})
-		if isDangerousCommand(path) {
+		if isDangerousCommand(raw) || normCmd.IsDangerous() {
 			logging.Dream("projectEffects: DANGEROUS COMMAND detected: %s", path)
 			projected = append(projected, Fact{
 				Predicate: "projected_fact",
@@ -277,16 +556,16 @@ func (d *Dreamer) projectEffects(actionID string, req ActionRequest) []Fact {
 // codeGraphProjections emits projections based on the code graph for a file path:
 // - touches_symbol(Symbol)
 // - impacts_test(TestSymbol) when a touched symbol is called by a test
-func (d *Dreamer) codeGraphProjections(actionID, path string) []Fact {
+func (d *Dreamer) codeGraphProjections(ctx context.Context, actionID, path string, kernel *RealKernel) []Fact {
 	logging.DreamDebug("codeGraphProjections: analyzing code graph for %s", path)
 
-	if d == nil || d.kernel == nil {
+	if kernel == nil {
 		logging.DreamDebug("codeGraphProjections: no kernel, skipping")
 		return nil
 	}
 
 	// Collect symbols defined in the target file
-	defs, err := d.kernel.Query("code_defines")
+	defs, err := kernel.Query("code_defines")
 	if err != nil || len(defs) == 0 {
 		logging.DreamDebug("codeGraphProjections: no code_defines found (err=%v, count=%d)", err, len(defs))
 		return nil
@@ -330,8 +609,15 @@ func (d *Dreamer) codeGraphProjections(actionID, path string) []Fact {
 		})
 	}
 
+	select {
+	case <-ctx.Done():
+		logging.DreamDebug("codeGraphProjections: context canceled before code_calls query")
+		return projected
+	default:
+	}
+
 	// Find tests that call touched symbols
-	callFacts, err := d.kernel.Query("code_calls")
+	callFacts, err := kernel.Query("code_calls")
 	if err != nil || len(callFacts) == 0 {
 		logging.DreamDebug("codeGraphProjections: no code_calls found, returning %d symbol projections", len(projected))
 		return projected
@@ -378,7 +664,10 @@ func (d *Dreamer) codeGraphProjections(actionID, path string) []Fact {
 	return projected
 }
 
-// isDangerousCommand flags obviously destructive commands.
+// isDangerousCommand flags obviously destructive commands by raw substring.
+// It predates ActionNormalizer and is kept as a cheap first pass; the
+// normalized NormalizedCommand.IsDangerous() check alongside it is what
+// actually survives whitespace, flag-grouping, and flag-order bypasses.
 func isDangerousCommand(cmd string) bool {
 	lc := strings.ToLower(cmd)
 	dangerous := []string{
@@ -413,6 +702,17 @@ func criticalPrefix(path string) string {
 	return ""
 }
 
+// approxFactSetBytes estimates the serialized size of facts by summing each
+// fact's String() length, for the dreamer_kernel_clone_bytes metric. It's an
+// approximation, not an exact byte count of Clone()'s internal allocations.
+func approxFactSetBytes(facts []Fact) int64 {
+	var total int64
+	for _, f := range facts {
+		total += int64(len(f.String()))
+	}
+	return total
+}
+
 // toString converts a fact argument to string, handling MangleAtom.
 func toString(arg interface{}) string {
 	switch v := arg.(type) {
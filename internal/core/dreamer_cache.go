@@ -0,0 +1,259 @@
+package core
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"codenerd/internal/logging"
+)
+
+// CachePolicy selects which entry DreamCache evicts first once it's over
+// one of its bounds.
+type CachePolicy string
+
+const (
+	// CachePolicyLRU evicts the least-recently-used entry.
+	CachePolicyLRU CachePolicy = "lru"
+	// CachePolicyLFU evicts the least-frequently-used entry.
+	CachePolicyLFU CachePolicy = "lfu"
+	// CachePolicyTinyLFU evicts the least-frequently-used entry using a
+	// plain frequency count - an honest approximation of TinyLFU, not the
+	// count-min-sketch admission filter a full TinyLFU implementation uses.
+	CachePolicyTinyLFU CachePolicy = "tinylfu"
+)
+
+// DreamCacheOptions bounds a DreamCache. A zero-valued field falls back to
+// DefaultDreamCacheOptions()'s value for that field (see NewDreamCache).
+type DreamCacheOptions struct {
+	MaxEntries int
+	MaxBytes   int64
+	TTL        time.Duration
+	Policy     CachePolicy
+}
+
+// DefaultDreamCacheOptions returns the bounds NewDreamer uses when no
+// DreamCacheOptions are given: 10,000 entries, 64MiB, no TTL, plain LRU.
+func DefaultDreamCacheOptions() DreamCacheOptions {
+	return DreamCacheOptions{
+		MaxEntries: 10000,
+		MaxBytes:   64 << 20,
+		Policy:     CachePolicyLRU,
+	}
+}
+
+// CacheStats is a point-in-time snapshot of a DreamCache, returned by
+// DreamCache.Stats and Dreamer.CacheStats.
+type CacheStats struct {
+	Entries   int
+	Bytes     int64
+	Hits      int64
+	Misses    int64
+	Evictions int64
+}
+
+type dreamCacheEntry struct {
+	key       string
+	result    DreamResult
+	bytes     int64
+	storedAt  time.Time
+	frequency int64
+	elem      *list.Element
+}
+
+// DreamCache is a threadsafe, size/count/TTL-bounded cache of dream
+// results, keyed by dreamCacheKey - a stable hash of (ActionRequest.Type,
+// normalized target, canonicalized payload, kernel epoch). Because the
+// kernel epoch is part of the key, a SwapKernel makes every previously
+// cached entry unreachable even before Dreamer.SwapKernel's Purge call
+// reclaims their memory.
+type DreamCache struct {
+	mu      sync.Mutex
+	opts    DreamCacheOptions
+	entries map[string]*dreamCacheEntry
+	order   *list.List // front = most recently used; only reordered under CachePolicyLRU
+	bytes   int64
+	metrics DreamerMetrics
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+// NewDreamCache creates an empty, bounded dream cache. Any zero-valued
+// field in opts falls back to the matching DefaultDreamCacheOptions() value.
+func NewDreamCache(opts DreamCacheOptions) *DreamCache {
+	def := DefaultDreamCacheOptions()
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = def.MaxEntries
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = def.MaxBytes
+	}
+	if opts.Policy == "" {
+		opts.Policy = def.Policy
+	}
+	logging.DreamDebug("Creating new DreamCache (maxEntries=%d, maxBytes=%d, policy=%s)", opts.MaxEntries, opts.MaxBytes, opts.Policy)
+	return &DreamCache{
+		opts:    opts,
+		entries: make(map[string]*dreamCacheEntry),
+		order:   list.New(),
+		metrics: noopDreamerMetrics{},
+	}
+}
+
+// SetMetrics installs m as this DreamCache's instrumentation sink,
+// replacing the default no-op.
+func (c *DreamCache) SetMetrics(m DreamerMetrics) {
+	if m == nil {
+		m = noopDreamerMetrics{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics = m
+}
+
+// dreamCacheKey computes the stable lookup key for req: its type, the
+// already-normalized target (see normalizeTarget), req.Payload
+// JSON-canonicalized (json.Marshal sorts map keys, so argument order never
+// changes the key), and the kernel epoch the result is only valid against.
+func dreamCacheKey(req ActionRequest, normalizedTarget string, epoch uint64) string {
+	payloadJSON, _ := json.Marshal(req.Payload)
+	h := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%d", req.Type, normalizedTarget, payloadJSON, epoch)))
+	return hex.EncodeToString(h[:])
+}
+
+// Get looks up a cached DreamResult by key, honoring TTL expiry and
+// recording a hit or miss. A hit on a CachePolicyLRU cache moves the entry
+// to the front of the eviction order; any hit bumps its frequency counter
+// for CachePolicyLFU/CachePolicyTinyLFU.
+func (c *DreamCache) Get(key string) (DreamResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		c.recordMissLocked()
+		return DreamResult{}, false
+	}
+	if c.opts.TTL > 0 && time.Since(entry.storedAt) > c.opts.TTL {
+		c.removeLocked(entry)
+		c.recordMissLocked()
+		return DreamResult{}, false
+	}
+
+	entry.frequency++
+	if c.opts.Policy == CachePolicyLRU {
+		c.order.MoveToFront(entry.elem)
+	}
+	c.hits.Add(1)
+	c.metrics.IncCacheHit()
+	return entry.result, true
+}
+
+func (c *DreamCache) recordMissLocked() {
+	c.misses.Add(1)
+	c.metrics.IncCacheMiss()
+}
+
+// Store saves result under key (computed by dreamCacheKey, so epoch is
+// already baked in), then evicts entries by Policy until the cache is back
+// within MaxEntries and MaxBytes.
+func (c *DreamCache) Store(key string, result DreamResult, epoch uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	size := int64(len(key)) + approxFactSetBytes(result.ProjectedFacts) + int64(len(result.Reason))
+	if existing, ok := c.entries[key]; ok {
+		c.removeLocked(existing)
+	}
+
+	entry := &dreamCacheEntry{
+		key:      key,
+		result:   result,
+		bytes:    size,
+		storedAt: time.Now(),
+	}
+	entry.elem = c.order.PushFront(entry)
+	c.entries[key] = entry
+	c.bytes += size
+
+	for (c.opts.MaxEntries > 0 && len(c.entries) > c.opts.MaxEntries) ||
+		(c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes) {
+		victim := c.selectVictimLocked()
+		if victim == nil {
+			break
+		}
+		c.removeLocked(victim)
+		c.evictions.Add(1)
+		c.metrics.IncCacheEviction()
+	}
+}
+
+// selectVictimLocked picks the next entry to evict under c.opts.Policy.
+// c.mu must be held.
+func (c *DreamCache) selectVictimLocked() *dreamCacheEntry {
+	if len(c.entries) == 0 {
+		return nil
+	}
+	if c.opts.Policy == CachePolicyLRU {
+		back := c.order.Back()
+		if back == nil {
+			return nil
+		}
+		return back.Value.(*dreamCacheEntry)
+	}
+
+	// CachePolicyLFU and CachePolicyTinyLFU: evict the least-frequently-used
+	// entry, oldest first on a tie.
+	var victim *dreamCacheEntry
+	for _, e := range c.entries {
+		if victim == nil || e.frequency < victim.frequency ||
+			(e.frequency == victim.frequency && e.storedAt.Before(victim.storedAt)) {
+			victim = e
+		}
+	}
+	return victim
+}
+
+// removeLocked deletes entry from both the map and the eviction list and
+// adjusts the running byte total. c.mu must be held.
+func (c *DreamCache) removeLocked(entry *dreamCacheEntry) {
+	delete(c.entries, entry.key)
+	if entry.elem != nil {
+		c.order.Remove(entry.elem)
+	}
+	c.bytes -= entry.bytes
+}
+
+// Purge discards every cached entry. Dreamer.SwapKernel calls this
+// automatically so entries keyed against the previous kernel epoch don't
+// linger in memory waiting for TTL/size eviction to get around to them.
+func (c *DreamCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*dreamCacheEntry)
+	c.order = list.New()
+	c.bytes = 0
+}
+
+// Stats returns a point-in-time snapshot of this cache's size and
+// hit/miss/eviction counters.
+func (c *DreamCache) Stats() CacheStats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	bytes := c.bytes
+	c.mu.Unlock()
+	return CacheStats{
+		Entries:   entries,
+		Bytes:     bytes,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
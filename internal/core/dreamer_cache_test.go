@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestDreamCache_EvictsOverMaxEntries(t *testing.T) {
+	c := NewDreamCache(DreamCacheOptions{MaxEntries: 3, Policy: CachePolicyLRU})
+
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		c.Store(key, DreamResult{ActionID: key}, 0)
+	}
+
+	stats := c.Stats()
+	if stats.Entries != 3 {
+		t.Errorf("expected 3 entries after exceeding MaxEntries, got %d", stats.Entries)
+	}
+	if stats.Evictions != 2 {
+		t.Errorf("expected 2 evictions, got %d", stats.Evictions)
+	}
+
+	// The 3 most recently stored entries should still be present.
+	for i := 2; i < 5; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("expected %s to still be cached", key)
+		}
+	}
+	if _, ok := c.Get("key-0"); ok {
+		t.Error("expected key-0 to have been evicted")
+	}
+}
+
+func TestDreamCache_LRUKeepsRecentlyUsed(t *testing.T) {
+	c := NewDreamCache(DreamCacheOptions{MaxEntries: 2, Policy: CachePolicyLRU})
+
+	c.Store("a", DreamResult{ActionID: "a"}, 0)
+	c.Store("b", DreamResult{ActionID: "b"}, 0)
+
+	// Touch "a" so "b" becomes the least-recently-used entry.
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	c.Store("c", DreamResult{ActionID: "c"}, 0)
+
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected a to survive eviction (recently used)")
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to have been evicted (least recently used)")
+	}
+}
+
+func TestDreamCache_TTLExpires(t *testing.T) {
+	c := NewDreamCache(DreamCacheOptions{MaxEntries: 10, TTL: time.Millisecond})
+	c.Store("k", DreamResult{ActionID: "k"}, 0)
+
+	if _, ok := c.Get("k"); !ok {
+		t.Fatal("expected immediate hit before TTL expiry")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.Get("k"); ok {
+		t.Error("expected miss after TTL expiry")
+	}
+	if c.Stats().Misses == 0 {
+		t.Error("expected the TTL-expired lookup to count as a miss")
+	}
+}
+
+func TestDreamCacheKey_StableAcrossPayloadOrder(t *testing.T) {
+	req1 := ActionRequest{Type: ActionExecCmd, Payload: map[string]interface{}{"a": 1, "b": 2}}
+	req2 := ActionRequest{Type: ActionExecCmd, Payload: map[string]interface{}{"b": 2, "a": 1}}
+
+	if dreamCacheKey(req1, "rm -f /", 0) != dreamCacheKey(req2, "rm -f /", 0) {
+		t.Error("expected identical keys regardless of payload map insertion order")
+	}
+}
+
+func TestDreamCacheKey_DiffersByEpoch(t *testing.T) {
+	req := ActionRequest{Type: ActionExecCmd}
+	if dreamCacheKey(req, "rm -f /", 0) == dreamCacheKey(req, "rm -f /", 1) {
+		t.Error("expected keys to differ across kernel epochs")
+	}
+}
+
+func TestDreamer_SwapKernelPurgesCache(t *testing.T) {
+	d, _ := setupTestDreamer(t)
+	req := ActionRequest{Type: ActionReadFile, Target: "a.txt"}
+
+	d.SimulateAction(context.Background(), req)
+	if d.CacheStats().Entries == 0 {
+		t.Fatal("expected SimulateAction to populate the cache")
+	}
+
+	k2, err := NewRealKernel()
+	if err != nil {
+		t.Fatalf("Failed to create second kernel: %v", err)
+	}
+	d.SwapKernel(k2)
+
+	if d.CacheStats().Entries != 0 {
+		t.Errorf("expected SwapKernel to purge the cache, got %d entries", d.CacheStats().Entries)
+	}
+}
+
+func TestDreamer_SimulateAction_CacheHitOnRepeat(t *testing.T) {
+	d, _ := setupTestDreamer(t)
+	req := ActionRequest{Type: ActionReadFile, Target: "a.txt"}
+	ctx := context.Background()
+
+	d.SimulateAction(ctx, req)
+	before := d.CacheStats()
+	d.SimulateAction(ctx, req)
+	after := d.CacheStats()
+
+	if after.Hits != before.Hits+1 {
+		t.Errorf("expected a cache hit on the repeated identical request, hits went from %d to %d", before.Hits, after.Hits)
+	}
+}
+
+// TestDreamer_SimulateAction_MillionCallSteadyStateMemory runs 1M
+// SimulateAction calls against random targets and asserts heap growth stays
+// bounded by the DreamCache's MaxEntries, rather than scaling with the call
+// count the way the old unbounded map-based cache did. Skipped in -short
+// mode since 1M simulations takes real wall-clock time.
+func TestDreamer_SimulateAction_MillionCallSteadyStateMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping 1M-call soak test in -short mode")
+	}
+
+	d, _ := setupTestDreamer(t)
+	ctx := context.Background()
+
+	const n = 1_000_000
+	const maxHeapBytes = 512 << 20 // 512MiB: generous bound for a 10k-entry cache
+
+	for i := 0; i < n; i++ {
+		req := ActionRequest{
+			Type:   ActionReadFile,
+			Target: fmt.Sprintf("random_file_%d.go", i),
+		}
+		d.SimulateAction(ctx, req)
+	}
+
+	stats := d.CacheStats()
+	if stats.Entries > DefaultDreamCacheOptions().MaxEntries {
+		t.Errorf("expected cache entries bounded by MaxEntries=%d, got %d", DefaultDreamCacheOptions().MaxEntries, stats.Entries)
+	}
+	if stats.Evictions == 0 {
+		t.Error("expected 1M distinct targets to trigger evictions against a 10k-entry cache")
+	}
+
+	var mem runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&mem)
+	if mem.HeapAlloc > maxHeapBytes {
+		t.Errorf("heap grew to %d bytes after 1M calls, want <= %d", mem.HeapAlloc, maxHeapBytes)
+	}
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestDreamer_ConcurrentSimulateAndSwap runs 10 concurrent SimulateAction
+// readers against a single writer that repeatedly swaps the kernel for 5
+// seconds. It exists to exercise the atomic.Pointer-backed kernel field
+// under `go test -race`: any direct read/write of a shared *RealKernel
+// field (rather than through kernelPtr) would be flagged as a data race
+// under this load.
+func TestDreamer_ConcurrentSimulateAndSwap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping concurrency stress test in -short mode")
+	}
+
+	d, k := setupTestDreamer(t)
+	k2, err := NewRealKernel()
+	if err != nil {
+		t.Fatalf("Failed to create second kernel: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var simulations int64
+	var swaps int64
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := ActionRequest{Type: ActionReadFile, Target: "readme.md"}
+			for ctx.Err() == nil {
+				result := d.SimulateAction(ctx, req)
+				if result.ActionID == "" {
+					t.Errorf("expected a non-empty ActionID from SimulateAction")
+				}
+				atomic.AddInt64(&simulations, 1)
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		kernels := []*RealKernel{k, k2}
+		i := 0
+		for ctx.Err() == nil {
+			d.SwapKernel(kernels[i%len(kernels)])
+			atomic.AddInt64(&swaps, 1)
+			i++
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	wg.Wait()
+
+	if atomic.LoadInt64(&simulations) == 0 {
+		t.Error("expected at least one simulation to complete")
+	}
+	if atomic.LoadInt64(&swaps) == 0 {
+		t.Error("expected at least one kernel swap to complete")
+	}
+	if d.Stats().InFlightSimulations != 0 {
+		t.Errorf("expected no in-flight simulations after all goroutines finished, got %d", d.Stats().InFlightSimulations)
+	}
+}
@@ -0,0 +1,30 @@
+package core
+
+import "time"
+
+// DreamerMetrics receives instrumentation events from a Dreamer and its
+// DreamCache. It lets operators watch simulation latency and kernel
+// deep-copy cost the way OPA users watch per-query storage metrics. The
+// zero value a Dreamer/DreamCache is constructed with is noopDreamerMetrics,
+// which discards everything.
+type DreamerMetrics interface {
+	// ObserveSimulateDuration records how long one SimulateAction call
+	// took. outcome is one of "safe", "unsafe", "error".
+	ObserveSimulateDuration(outcome string, d time.Duration)
+	// ObserveKernelCloneBytes records an estimate of how many bytes of
+	// fact data a single RealKernel.Clone() call copied.
+	ObserveKernelCloneBytes(n int64)
+	// IncCacheHit/IncCacheMiss/IncCacheEviction count DreamCache lookups
+	// and size/count/TTL-bound evictions.
+	IncCacheHit()
+	IncCacheMiss()
+	IncCacheEviction()
+}
+
+type noopDreamerMetrics struct{}
+
+func (noopDreamerMetrics) ObserveSimulateDuration(string, time.Duration) {}
+func (noopDreamerMetrics) ObserveKernelCloneBytes(int64)                 {}
+func (noopDreamerMetrics) IncCacheHit()                                  {}
+func (noopDreamerMetrics) IncCacheMiss()                                 {}
+func (noopDreamerMetrics) IncCacheEviction()                             {}
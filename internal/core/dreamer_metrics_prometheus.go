@@ -0,0 +1,73 @@
+package core
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// prometheusDreamerMetrics is the DreamerMetrics implementation returned by
+// RegisterDreamerPrometheus. It mirrors the per-outcome simulation
+// durations and kernel clone cost that OPA exposes for its own
+// storage/query layer.
+type prometheusDreamerMetrics struct {
+	simulateDuration *prometheus.HistogramVec
+	kernelCloneBytes prometheus.Histogram
+	cacheHits        prometheus.Counter
+	cacheMisses      prometheus.Counter
+	cacheEvictions   prometheus.Counter
+}
+
+// RegisterDreamerPrometheus builds a Prometheus-backed DreamerMetrics
+// implementation, registers its collectors against reg, installs it on d
+// (which also installs it on d's DreamCache - see Dreamer.SetMetrics), and
+// returns it so callers can reuse it elsewhere.
+func RegisterDreamerPrometheus(reg prometheus.Registerer, d *Dreamer) (DreamerMetrics, error) {
+	m := &prometheusDreamerMetrics{
+		simulateDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "dreamer_simulate_duration_seconds",
+			Help:    "Duration of Dreamer.SimulateAction calls, by outcome.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		kernelCloneBytes: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "dreamer_kernel_clone_bytes",
+			Help:    "Estimated size in bytes of each kernel deep-copy performed during simulation.",
+			Buckets: prometheus.ExponentialBuckets(1024, 4, 10),
+		}),
+		cacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "dreamer_cache_hits",
+			Help: "DreamCache lookups that found a cached verdict.",
+		}),
+		cacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "dreamer_cache_misses",
+			Help: "DreamCache lookups that found no cached verdict.",
+		}),
+		cacheEvictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "dreamer_cache_evictions",
+			Help: "DreamCache entries evicted to stay within its size bound.",
+		}),
+	}
+
+	collectors := []prometheus.Collector{m.simulateDuration, m.kernelCloneBytes, m.cacheHits, m.cacheMisses, m.cacheEvictions}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if d != nil {
+		d.SetMetrics(m)
+	}
+	return m, nil
+}
+
+func (m *prometheusDreamerMetrics) ObserveSimulateDuration(outcome string, d time.Duration) {
+	m.simulateDuration.WithLabelValues(outcome).Observe(d.Seconds())
+}
+
+func (m *prometheusDreamerMetrics) ObserveKernelCloneBytes(n int64) {
+	m.kernelCloneBytes.Observe(float64(n))
+}
+func (m *prometheusDreamerMetrics) IncCacheHit()      { m.cacheHits.Inc() }
+func (m *prometheusDreamerMetrics) IncCacheMiss()     { m.cacheMisses.Inc() }
+func (m *prometheusDreamerMetrics) IncCacheEviction() { m.cacheEvictions.Inc() }
@@ -173,15 +173,12 @@ func TestDreamer_ProjectEffects(t *testing.T) {
 // AssertWithoutEval can panic on malformed inputs.
 // Fuzz test needed with random types in Fact Args.
 
-// TODO: TEST_GAP: Null/Undefined - Nil Context
-// SimulateAction accepts a context.Context which might be nil.
-// If code inside attempts to use it (e.g. ctx.Done()), it will panic.
-// A test case passing nil context is required to ensure graceful handling.
+// Null/Undefined - Nil Context: SimulateAction treats a nil ctx as
+// context.Background(), see TestDreamer_SimulateAction_NilContext below.
 
-// TODO: TEST_GAP: Null/Undefined - Nil Kernel
-// Dreamer constructor allows nil kernel, or SetKernel(nil) can be called.
-// SimulateAction currently returns "Safe" (fail-open) if kernel is nil.
-// A test case is needed to verify this behavior and argue for fail-closed logic.
+// Null/Undefined - Nil Kernel: SimulateAction now fails closed (Unsafe=true,
+// Reason=ErrKernelUnavailable) rather than fail-open, see
+// TestDreamer_SimulateAction_NilKernel below.
 
 // TODO: TEST_GAP: Null/Undefined - Empty ActionRequest Fields
 // ActionRequest.Type or ActionRequest.Target can be empty strings.
@@ -203,12 +200,36 @@ func TestDreamer_ProjectEffects(t *testing.T) {
 // Could trigger stack overflows in recursive Mangle rules or regex performance issues.
 // A test case with deep nesting is needed.
 
-// TODO: TEST_GAP: Concurrency - Race Condition: SetKernel vs SimulateAction
-// Dreamer.kernel pointer is accessed without locking.
-// While one goroutine calls SetKernel, another calling SimulateAction might see an inconsistent state or crash.
-// A concurrent test case running SetKernel and SimulateAction in parallel is needed to verify thread safety.
+// Concurrency - Race Condition: SetKernel vs SimulateAction. Dreamer.kernel
+// is now held behind an atomic.Pointer and SwapKernel drains in-flight
+// simulations before returning, see dreamer_concurrency_test.go.
 
 // TODO: TEST_GAP: Type Coercion - Atom vs String Dissonance
 // projectEffects converts ActionRequest.Type (string) to a Mangle string literal.
 // Mangle rules often expect atoms (e.g., /read_file) instead of strings ("read_file").
 // A test case is needed to verify that projected facts use consistent types (Atoms or Strings) matching the schema.
+
+func TestDreamer_SimulateAction_NilKernel(t *testing.T) {
+	d := NewDreamer(nil)
+	req := ActionRequest{Type: ActionReadFile, Target: "secret.txt"}
+
+	result := d.SimulateAction(context.Background(), req)
+
+	if !result.Unsafe {
+		t.Error("Expected a nil-kernel Dreamer to fail closed (Unsafe=true)")
+	}
+	if result.Reason != ErrKernelUnavailable.Error() {
+		t.Errorf("Expected Reason %q, got %q", ErrKernelUnavailable.Error(), result.Reason)
+	}
+}
+
+func TestDreamer_SimulateAction_NilContext(t *testing.T) {
+	d, _ := setupTestDreamer(t)
+	req := ActionRequest{Type: ActionReadFile, Target: "readme.md"}
+
+	result := d.SimulateAction(nil, req) //nolint:staticcheck // verifying nil ctx is handled gracefully
+
+	if result.Unsafe {
+		t.Errorf("Expected a nil context to be treated as context.Background(), got Unsafe with reason %q", result.Reason)
+	}
+}
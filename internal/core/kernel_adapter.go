@@ -4,6 +4,8 @@
 package core
 
 import (
+	"sync"
+
 	"codenerd/internal/autopoiesis"
 )
 
@@ -12,6 +14,19 @@ import (
 // creating import cycles.
 type KernelAdapter struct {
 	kernel *RealKernel
+
+	statsMu sync.Mutex
+	stats   KernelAdapterStats
+}
+
+// KernelAdapterStats reports how much of a KernelAdapter's work went
+// through the incremental delta-evaluation path (see AssertFacts) versus a
+// full re-evaluation of the whole program (see RetractFact), so callers can
+// verify the incremental path is actually being used.
+type KernelAdapterStats struct {
+	RulesFired    int64 // rule clauses fired by the incremental delta path, cumulative
+	AtomsDerived  int64 // facts newly derived by the incremental delta path, cumulative
+	FullEvalCount int64 // full-program re-evaluations performed (always true of RetractFact today)
 }
 
 // NewKernelAdapter creates an adapter that implements autopoiesis.KernelInterface.
@@ -19,14 +34,37 @@ func NewKernelAdapter(kernel *RealKernel) *KernelAdapter {
 	return &KernelAdapter{kernel: kernel}
 }
 
-// AssertFact implements autopoiesis.KernelInterface.
-// Converts autopoiesis.KernelFact to core.Fact and asserts to kernel.
+// AssertFact implements autopoiesis.KernelInterface. It delegates to
+// AssertFacts so a single fact still goes through the incremental
+// delta-evaluation path instead of a full re-evaluation.
 func (ka *KernelAdapter) AssertFact(fact autopoiesis.KernelFact) error {
-	coreFact := Fact{
-		Predicate: fact.Predicate,
-		Args:      fact.Args,
+	return ka.AssertFacts([]autopoiesis.KernelFact{fact})
+}
+
+// AssertFacts adds a batch of facts and fires only the rules whose bodies
+// (transitively) depend on the asserted predicates, via
+// RealKernel.AssertDeltaBatch, instead of re-evaluating the whole program
+// once per fact - the naive pattern that makes bulk loads quadratic.
+func (ka *KernelAdapter) AssertFacts(facts []autopoiesis.KernelFact) error {
+	if len(facts) == 0 {
+		return nil
 	}
-	return ka.kernel.Assert(coreFact)
+	coreFacts := make([]Fact, len(facts))
+	for i, f := range facts {
+		coreFacts[i] = Fact{
+			Predicate: f.Predicate,
+			Args:      f.Args,
+		}
+	}
+	fired, derived, err := ka.kernel.AssertDeltaBatch(coreFacts)
+	if err != nil {
+		return err
+	}
+	ka.statsMu.Lock()
+	ka.stats.RulesFired += int64(fired)
+	ka.stats.AtomsDerived += int64(derived)
+	ka.statsMu.Unlock()
+	return nil
 }
 
 // QueryPredicate implements autopoiesis.KernelInterface.
@@ -58,12 +96,30 @@ func (ka *KernelAdapter) QueryBool(predicate string) bool {
 }
 
 // RetractFact implements autopoiesis.KernelInterface.
+//
+// Retraction falls back to RealKernel's full fixpoint re-evaluation as its
+// repair pass: Mangle's engine exposes no provenance API to identify only
+// the derivations a single retracted fact supported, so recomputing from
+// scratch is the safe, correct option rather than risking stale derived
+// facts surviving a partial invalidation.
 func (ka *KernelAdapter) RetractFact(fact autopoiesis.KernelFact) error {
 	coreFact := Fact{
 		Predicate: fact.Predicate,
 		Args:      fact.Args,
 	}
-	return ka.kernel.RetractFact(coreFact)
+	err := ka.kernel.RetractFact(coreFact)
+	ka.statsMu.Lock()
+	ka.stats.FullEvalCount++
+	ka.statsMu.Unlock()
+	return err
+}
+
+// Stats returns the cumulative counters distinguishing incremental
+// delta-evaluation work from full re-evaluations.
+func (ka *KernelAdapter) Stats() KernelAdapterStats {
+	ka.statsMu.Lock()
+	defer ka.statsMu.Unlock()
+	return ka.stats
 }
 
 // Ensure KernelAdapter implements KernelInterface at compile time.
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"testing"
+
+	"codenerd/internal/autopoiesis"
+)
+
+func TestKernelAdapter_AssertFactsUsesIncrementalPath(t *testing.T) {
+	k := setupMockKernel(t)
+	k.AppendPolicy(`
+	Decl foo(Name).
+	Decl baz(Name).
+	baz(X) :- foo(X).
+	`)
+	if err := k.Evaluate(); err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+
+	adapter := NewKernelAdapter(k)
+	if err := adapter.AssertFacts([]autopoiesis.KernelFact{
+		{Predicate: "foo", Args: []interface{}{"bar"}},
+	}); err != nil {
+		t.Fatalf("AssertFacts failed: %v", err)
+	}
+
+	stats := adapter.Stats()
+	if stats.RulesFired == 0 {
+		t.Errorf("expected RulesFired > 0 after asserting a fact that feeds baz, got %+v", stats)
+	}
+	if stats.AtomsDerived == 0 {
+		t.Errorf("expected AtomsDerived > 0, got %+v", stats)
+	}
+	if stats.FullEvalCount != 0 {
+		t.Errorf("expected FullEvalCount to stay 0 on the incremental path, got %d", stats.FullEvalCount)
+	}
+
+	facts, err := adapter.QueryPredicate("baz")
+	if err != nil {
+		t.Fatalf("QueryPredicate failed: %v", err)
+	}
+	if len(facts) != 1 {
+		t.Fatalf("expected 1 baz fact, got %d", len(facts))
+	}
+}
+
+func TestKernelAdapter_RetractFactCountsAsFullEval(t *testing.T) {
+	k := setupMockKernel(t)
+	k.AppendPolicy(`Decl foo(Name).`)
+
+	adapter := NewKernelAdapter(k)
+	if err := adapter.AssertFact(autopoiesis.KernelFact{Predicate: "foo", Args: []interface{}{"bar"}}); err != nil {
+		t.Fatalf("AssertFact failed: %v", err)
+	}
+
+	if err := adapter.RetractFact(autopoiesis.KernelFact{Predicate: "foo", Args: []interface{}{"bar"}}); err != nil {
+		t.Fatalf("RetractFact failed: %v", err)
+	}
+
+	stats := adapter.Stats()
+	if stats.FullEvalCount != 1 {
+		t.Errorf("expected FullEvalCount == 1 after one retract, got %d", stats.FullEvalCount)
+	}
+
+	ok := adapter.QueryBool("foo")
+	if ok {
+		t.Errorf("expected foo to be retracted")
+	}
+}
@@ -122,40 +122,40 @@ func (k *RealKernel) evaluate() error {
 		}
 	}
 
-	// Use cached atoms (fast path - no conversions!)
-	for _, atom := range k.cachedAtoms {
-		baseStore.Add(atom)
-	}
-	evalStore := factstore.FactStore(baseStore)
-	if k.virtualStore != nil {
-		evalStore = newVirtualFactStore(baseStore, k.virtualStore)
-	}
-
-	// Evaluate to fixpoint using cached programInfo
-	// BUG #17 FIX: Add gas limits to prevent halting problem in learned rules
-	// Prevent fact explosions from recursive learned rules
-	const derivedFactLimit = 500000
-	logging.KernelDebug("evaluate: running fixpoint evaluation (derivedFactLimit=%d)", derivedFactLimit)
-
-	evalTimer := logging.StartTimer(logging.CategoryKernel, "evaluate.fixpoint")
-	stats, err := engine.EvalProgramWithStats(k.programInfo, evalStore,
-		engine.WithCreatedFactLimit(derivedFactLimit)) // Hard cap: max 500K derived facts
-	evalDuration := evalTimer.Stop()
-
-	if err != nil {
+	// Use cached atoms (fast path - no conversions!)
+	for _, atom := range k.cachedAtoms {
+		baseStore.Add(atom)
+	}
+	evalStore := factstore.FactStore(baseStore)
+	if k.virtualStore != nil {
+		evalStore = newVirtualFactStore(baseStore, k.virtualStore)
+	}
+
+	// Evaluate to fixpoint using cached programInfo
+	// BUG #17 FIX: Add gas limits to prevent halting problem in learned rules
+	// Prevent fact explosions from recursive learned rules
+	const derivedFactLimit = 500000
+	logging.KernelDebug("evaluate: running fixpoint evaluation (derivedFactLimit=%d)", derivedFactLimit)
+
+	evalTimer := logging.StartTimer(logging.CategoryKernel, "evaluate.fixpoint")
+	stats, err := engine.EvalProgramWithStats(k.programInfo, evalStore,
+		engine.WithCreatedFactLimit(derivedFactLimit)) // Hard cap: max 500K derived facts
+	evalDuration := evalTimer.Stop()
+
+	if err != nil {
 		logging.Get(logging.CategoryKernel).Error("evaluate: fixpoint evaluation failed: %v", err)
 		// Check if this is a derived fact limit error
 		if strings.Contains(err.Error(), "limit") || strings.Contains(err.Error(), "exceeded") {
 			logging.Get(logging.CategoryKernel).Warn("evaluate: POSSIBLE FACT EXPLOSION - derived facts exceeded %d limit", derivedFactLimit)
-		}
-		return fmt.Errorf("failed to evaluate program: %w", err)
-	}
-
-	k.store = baseStore
-	k.wrapStoreLocked()
-
-	// Log evaluation stats
-	totalDuration := time.Duration(0)
+		}
+		return fmt.Errorf("failed to evaluate program: %w", err)
+	}
+
+	k.store = baseStore
+	k.wrapStoreLocked()
+
+	// Log evaluation stats
+	totalDuration := time.Duration(0)
 	for _, d := range stats.Duration {
 		totalDuration += d
 	}
@@ -164,6 +164,7 @@ func (k *RealKernel) evaluate() error {
 		strataCount, totalDuration, evalDuration)
 
 	k.initialized = true
+	k.recordRuleFiringsLocked()
 	timer.Stop()
 	logging.KernelDebug("evaluate: complete, kernel initialized")
 	return nil
@@ -149,6 +149,9 @@ func (k *RealKernel) Assert(fact Fact) error {
 		logging.Get(logging.CategoryKernel).Error("Assert: evaluation failed after asserting %s: %v", fact.Predicate, err)
 		return err
 	}
+	k.persistFactsLocked([]Fact{fact})
+	k.recordAssertsLocked([]Fact{fact})
+	k.invalidateObligationsLocked(fact.Predicate)
 	logging.KernelDebug("Assert: fact added successfully, total facts=%d", len(k.facts))
 	return nil
 }
@@ -195,6 +198,11 @@ func (k *RealKernel) AssertBatch(facts []Fact) error {
 		logging.Get(logging.CategoryKernel).Error("AssertBatch: evaluation failed after adding %d facts: %v", len(facts), err)
 		return err
 	}
+	k.persistFactsLocked(sanitized)
+	k.recordAssertsLocked(sanitized)
+	for _, f := range sanitized {
+		k.invalidateObligationsLocked(f.Predicate)
+	}
 
 	timer.Stop()
 	logging.KernelDebug("AssertBatch: added %d/%d facts, EDB: %d -> %d facts", added, len(facts), prevCount, len(k.facts))
@@ -286,6 +294,9 @@ func (k *RealKernel) Retract(predicate string) error {
 		logging.Get(logging.CategoryKernel).Error("Retract: rebuild failed after retracting %s: %v", predicate, err)
 		return err
 	}
+	k.retractPredicateFromStoreLocked(predicate)
+	k.recordRetractLocked(predicate)
+	k.invalidateObligationsLocked(predicate)
 	return nil
 }
 
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"fmt"
+
+	"codenerd/internal/logging"
+
+	"github.com/google/mangle/ast"
+	"github.com/google/mangle/engine"
+	"github.com/google/mangle/factstore"
+)
+
+// =============================================================================
+// DELTA (SEMI-NAIVE-STYLE) EVALUATION
+//
+// evaluate() in kernel_eval.go is intentionally naive: every Assert/Retract
+// rebuilds a fresh store from k.cachedAtoms and runs every rule in
+// k.programInfo to fixpoint, regardless of how small the change was. That is
+// correct but O(program) per call. AssertDeltaBatch below adds a bounded
+// optimization on top, following the same "swap ProgramInfo.Rules for a
+// subset, evaluate against the full store" technique already used by
+// mangle.DifferentialEngine.ApplyDelta for per-stratum evaluation: skip rule
+// clauses that cannot possibly fire as a result of the new facts, but still
+// scan the full EDB (Mangle's engine exposes no way to seed an iteration
+// from only a prior round's delta). It is a real, measurable reduction in
+// work for programs with multiple unrelated rule families, not a full
+// textbook semi-naive evaluator.
+// =============================================================================
+
+// affectedRuleClauses returns the subset of rules whose bodies reference a
+// predicate in seedPredicates, transitively closed over rule heads - a rule
+// that depends on another affected rule's derived predicate is affected too.
+func affectedRuleClauses(rules []ast.Clause, seedPredicates map[ast.PredicateSym]bool) []ast.Clause {
+	reached := make(map[ast.PredicateSym]bool, len(seedPredicates))
+	for p := range seedPredicates {
+		reached[p] = true
+	}
+
+	affected := make(map[int]bool)
+	for changed := true; changed; {
+		changed = false
+		for i, rule := range rules {
+			if affected[i] {
+				continue
+			}
+			if !clauseReferencesAny(rule, reached) {
+				continue
+			}
+			affected[i] = true
+			if !reached[rule.Head.Predicate] {
+				reached[rule.Head.Predicate] = true
+				changed = true
+			}
+		}
+	}
+
+	result := make([]ast.Clause, 0, len(affected))
+	for i, rule := range rules {
+		if affected[i] {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+// clauseReferencesAny reports whether any premise of rule reads a predicate
+// in preds.
+func clauseReferencesAny(rule ast.Clause, preds map[ast.PredicateSym]bool) bool {
+	for _, term := range rule.Premises {
+		if termReferencesAny(term, preds) {
+			return true
+		}
+	}
+	return false
+}
+
+// termReferencesAny handles the premise shapes that name a predicate
+// (ast.Atom, negated ast.NegAtom). Other premise kinds (comparisons,
+// transforms) don't read EDB/IDB predicates directly and are skipped.
+func termReferencesAny(term ast.Term, preds map[ast.PredicateSym]bool) bool {
+	switch t := term.(type) {
+	case ast.Atom:
+		return preds[t.Predicate]
+	case ast.NegAtom:
+		return preds[t.Atom.Predicate]
+	default:
+		return false
+	}
+}
+
+// evaluateRestricted re-evaluates only the rules affected by seedPredicates
+// against the full current fact store, instead of every rule in the
+// program. Call only while holding k.mu.
+func (k *RealKernel) evaluateRestricted(seedPredicates map[ast.PredicateSym]bool) (firedRules int, derivedAtoms int, err error) {
+	if k.policyDirty || k.programInfo == nil {
+		if err := k.rebuildProgram(); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	affected := affectedRuleClauses(k.programInfo.Rules, seedPredicates)
+	if len(affected) == 0 {
+		return 0, 0, nil
+	}
+
+	if len(k.cachedAtoms) != len(k.facts) {
+		return 0, 0, fmt.Errorf("evaluateRestricted: fact/atom cache desync (atoms=%d facts=%d)", len(k.cachedAtoms), len(k.facts))
+	}
+
+	baseStore := factstore.NewSimpleInMemoryStore()
+	for _, atom := range k.cachedAtoms {
+		baseStore.Add(atom)
+	}
+	evalStore := factstore.FactStore(baseStore)
+	if k.virtualStore != nil {
+		evalStore = newVirtualFactStore(baseStore, k.virtualStore)
+	}
+
+	before := baseStore.EstimateFactCount()
+
+	subsetInfo := *k.programInfo
+	subsetInfo.Rules = affected
+
+	const derivedFactLimit = 500000
+	if _, err := engine.EvalProgramWithStats(&subsetInfo, evalStore, engine.WithCreatedFactLimit(derivedFactLimit)); err != nil {
+		return 0, 0, fmt.Errorf("failed to evaluate restricted rule set: %w", err)
+	}
+
+	after := baseStore.EstimateFactCount()
+
+	k.store = baseStore
+	k.wrapStoreLocked()
+	k.initialized = true
+
+	k.recordRuleFiringsLocked()
+	k.refreshObligationForestLocked()
+
+	logging.KernelDebug("evaluateRestricted: fired %d/%d rules, derived %d new atoms",
+		len(affected), len(k.programInfo.Rules), after-before)
+	return len(affected), after - before, nil
+}
+
+// AssertDeltaBatch adds facts to the EDB like AssertBatch, but only
+// re-fires the rules whose bodies (transitively) depend on the asserted
+// predicates instead of running the whole program to fixpoint. This is the
+// incremental path KernelAdapter.AssertFacts uses; plain Assert/AssertBatch
+// are left untouched so existing callers keep their full-evaluation
+// guarantees.
+func (k *RealKernel) AssertDeltaBatch(facts []Fact) (firedRules int, derivedAtoms int, err error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	seed := make(map[ast.PredicateSym]bool, len(facts))
+	added := make([]Fact, 0, len(facts))
+	for _, f := range facts {
+		f = sanitizeFactForNumericPredicates(f)
+		if k.addFactIfNewLocked(f) {
+			added = append(added, f)
+			seed[ast.PredicateSym{Symbol: f.Predicate, Arity: len(f.Args)}] = true
+		}
+	}
+	if len(added) == 0 {
+		return 0, 0, nil
+	}
+
+	// Record the asserts and invalidate their obligations before
+	// evaluateRestricted runs, so a forest attached via SetObligationForest
+	// sees this batch's own WALAssert entries (and a clean cache for the
+	// predicates it just touched) when it Solve()s at the end of this call.
+	k.recordAssertsLocked(added)
+	for _, f := range added {
+		k.invalidateObligationsLocked(f.Predicate)
+	}
+
+	return k.evaluateRestricted(seed)
+}
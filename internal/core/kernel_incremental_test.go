@@ -0,0 +1,96 @@
+package core
+
+import "testing"
+
+func TestAssertDeltaBatch_OnlyFiresAffectedRules(t *testing.T) {
+	k := setupMockKernel(t)
+
+	policy := `
+	Decl foo(Name).
+	Decl num(Number).
+	Decl baz(Name).
+	Decl big(Number).
+
+	baz(X) :- foo(X).
+	big(X) :- num(N), N > 10, X = N.
+	`
+	k.AppendPolicy(policy)
+	if err := k.Evaluate(); err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+
+	fired, derived, err := k.AssertDeltaBatch([]Fact{
+		{Predicate: "foo", Args: []interface{}{"bar"}},
+	})
+	if err != nil {
+		t.Fatalf("AssertDeltaBatch failed: %v", err)
+	}
+	if fired != 1 {
+		t.Errorf("expected 1 affected rule (baz), got %d", fired)
+	}
+	if derived != 1 {
+		t.Errorf("expected 1 derived atom, got %d", derived)
+	}
+
+	results, err := k.Query("baz")
+	if err != nil {
+		t.Fatalf("Query baz failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Args[0] != "bar" {
+		t.Errorf("expected baz('bar'), got %v", results)
+	}
+}
+
+func TestAssertDeltaBatch_NoNewFactsSkipsEvaluation(t *testing.T) {
+	k := setupMockKernel(t)
+	k.AppendPolicy(`Decl foo(Name).`)
+	k.Assert(Fact{Predicate: "foo", Args: []interface{}{"bar"}})
+
+	fired, derived, err := k.AssertDeltaBatch([]Fact{
+		{Predicate: "foo", Args: []interface{}{"bar"}},
+	})
+	if err != nil {
+		t.Fatalf("AssertDeltaBatch failed: %v", err)
+	}
+	if fired != 0 || derived != 0 {
+		t.Errorf("expected no-op for duplicate fact, got fired=%d derived=%d", fired, derived)
+	}
+}
+
+func TestAffectedRuleClauses_TransitiveClosure(t *testing.T) {
+	k := setupMockKernel(t)
+	policy := `
+	Decl foo(Name).
+	Decl baz(Name).
+	Decl qux(Name).
+	Decl unrelated(Name).
+	Decl derived_unrelated(Name).
+
+	baz(X) :- foo(X).
+	qux(X) :- baz(X).
+	derived_unrelated(X) :- unrelated(X).
+	`
+	k.AppendPolicy(policy)
+	if err := k.Evaluate(); err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+
+	fired, _, err := k.AssertDeltaBatch([]Fact{
+		{Predicate: "foo", Args: []interface{}{"bar"}},
+	})
+	if err != nil {
+		t.Fatalf("AssertDeltaBatch failed: %v", err)
+	}
+	// baz(X) :- foo(X) and qux(X) :- baz(X) should both fire; derived_unrelated should not.
+	if fired != 2 {
+		t.Errorf("expected 2 transitively affected rules (baz, qux), got %d", fired)
+	}
+
+	results, err := k.Query("qux")
+	if err != nil {
+		t.Fatalf("Query qux failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Args[0] != "bar" {
+		t.Errorf("expected qux('bar'), got %v", results)
+	}
+}
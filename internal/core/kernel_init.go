@@ -108,6 +108,22 @@ func NewRealKernelWithWorkspace(workspaceRoot string) (*RealKernel, error) {
 		return nil, fmt.Errorf("kernel failed to boot embedded constitution: %w", err)
 	}
 
+	// Make the classifier this workspace actually uses reachable: runtime
+	// schema annotations (@ephemeral/@derived/@persistent/@sensitive descr
+	// atoms on a Decl) and .codenerd/predicates.yaml overrides both exist
+	// as parsing logic (classifier.go) but do nothing unless something
+	// calls them after schema load. This is that call.
+	if k.programInfo != nil {
+		RegisterFromSchemaDecls(DefaultClassifier(), k.programInfo.Decls)
+	}
+	if workspaceRoot != "" {
+		if project, err := LoadProjectClassifierConfig(workspaceRoot); err != nil {
+			logging.Get(logging.CategoryKernel).Warn("failed to load %s/.codenerd/predicates.yaml: %v", workspaceRoot, err)
+		} else {
+			DefaultClassifier().SetProjectLayer(project)
+		}
+	}
+
 	timer.StopWithInfo()
 	logging.Kernel("Kernel initialized successfully")
 	return k, nil
@@ -5,6 +5,7 @@ import (
 	"embed"
 	"sync"
 
+	"codenerd/internal/core/storage"
 	"codenerd/internal/mangle"
 	"codenerd/internal/types"
 
@@ -40,29 +41,34 @@ type LearnedRuleInterceptor interface {
 
 // RealKernel wraps the google/mangle engine with proper EDB/IDB separation.
 type RealKernel struct {
-	mu                sync.RWMutex
-	facts             []Fact
-	cachedAtoms       []ast.Atom          // OPTIMIZATION: Cached Mangle atoms to avoid O(N) ToAtom() conversions
-	factIndex         map[string]struct{} // Canonical fact set for deduplication
-	bootFacts         []Fact              // EDB facts extracted from hybrid .mg data sections
-	bootIntents       []HybridIntent      // Canonical intents extracted from hybrid .mg files
-	bootPrompts       []HybridPrompt      // Prompt atoms extracted from hybrid .mg files
-	store             factstore.FactStore
-	programInfo       *analysis.ProgramInfo
-	schemas           string
-	policy            string
-	learned           string              // Learned rules (autopoiesis) - loaded from learned.mg
-	loadedPolicyFiles map[string]struct{} // Idempotency: policy modules loaded via LoadPolicyFile (keyed by case-insensitive basename)
-	schemaValidator   *mangle.SchemaValidator
-	initialized       bool
-	manglePath        string                 // Path to mangle files directory
-	workspaceRoot     string                 // Explicit workspace root (for .nerd paths)
-	policyDirty       bool                   // True when schemas/policy changed and need reparse
-	userLearnedPath   string                 // Path to user learned.mg for self-healing persistence
-	predicateCorpus   *PredicateCorpus       // Baked-in predicate corpus for validation
-	repairInterceptor LearnedRuleInterceptor // Optional interceptor for rule repair before persistence
-	virtualStore      *VirtualStore          // Virtual predicate source for query_* handlers
-	derivedFactLimit  int                    // Configurable limit for derived facts (0 = use default)
+	mu                    sync.RWMutex
+	facts                 []Fact
+	cachedAtoms           []ast.Atom          // OPTIMIZATION: Cached Mangle atoms to avoid O(N) ToAtom() conversions
+	factIndex             map[string]struct{} // Canonical fact set for deduplication
+	bootFacts             []Fact              // EDB facts extracted from hybrid .mg data sections
+	bootIntents           []HybridIntent      // Canonical intents extracted from hybrid .mg files
+	bootPrompts           []HybridPrompt      // Prompt atoms extracted from hybrid .mg files
+	store                 factstore.FactStore
+	programInfo           *analysis.ProgramInfo
+	schemas               string
+	policy                string
+	learned               string              // Learned rules (autopoiesis) - loaded from learned.mg
+	loadedPolicyFiles     map[string]struct{} // Idempotency: policy modules loaded via LoadPolicyFile (keyed by case-insensitive basename)
+	schemaValidator       *mangle.SchemaValidator
+	initialized           bool
+	manglePath            string                  // Path to mangle files directory
+	workspaceRoot         string                  // Explicit workspace root (for .nerd paths)
+	policyDirty           bool                    // True when schemas/policy changed and need reparse
+	userLearnedPath       string                  // Path to user learned.mg for self-healing persistence
+	predicateCorpus       *PredicateCorpus        // Baked-in predicate corpus for validation
+	repairInterceptor     LearnedRuleInterceptor  // Optional interceptor for rule repair before persistence
+	virtualStore          *VirtualStore           // Virtual predicate source for query_* handlers
+	derivedFactLimit      int                     // Configurable limit for derived facts (0 = use default)
+	persistentStore       storage.Store           // Optional disk-backed store for persistent predicates; nil unless SetFactStore was called
+	wal                   *TraceWAL               // Optional derivation-trace WAL; nil unless SetTraceWAL was called
+	walSeenDerived        map[string]struct{}     // Dedup set of fact.String() already appended as WALRuleFiring entries
+	obligationForest      *ObligationForest       // Optional obligation-forest cache; nil unless SetObligationForest was called
+	lastObligationMetrics ObligationForestMetrics // Snapshot from the most recent refreshObligationForestLocked call
 }
 
 // StartupValidationResult contains statistics from startup learned rule validation.
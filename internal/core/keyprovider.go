@@ -0,0 +1,125 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// KeyProvider supplies the raw AEAD keys SealedStore uses to encrypt and
+// decrypt sensitive predicate records, indexed by key id so a record
+// written under a retired key can still be decrypted (and then rotated
+// onto the active key - see SealedStore.RotateKeys) after the active key
+// changes.
+type KeyProvider interface {
+	// ActiveKeyID returns the id of the key new records should be sealed
+	// under.
+	ActiveKeyID() string
+	// Key returns the raw key bytes for keyID, or an error if keyID is
+	// unknown to this provider.
+	Key(keyID string) ([]byte, error)
+}
+
+// keyringFile is the on-disk shape FileKeyProvider reads: a set of
+// base64-encoded AEAD keys plus which one is currently active. Rotating a
+// key means adding a new entry and changing active_key_id - the old entry
+// must stay so records sealed under it remain decryptable until
+// SealedStore.RotateKeys re-seals them.
+type keyringFile struct {
+	ActiveKeyID string            `json:"active_key_id"`
+	Keys        map[string]string `json:"keys"`
+}
+
+// FileKeyProvider is a KeyProvider backed by a JSON keyring file of the
+// form:
+//
+//	{"active_key_id": "2026-01", "keys": {"2026-01": "<base64 32 bytes>", "2025-06": "<base64 32 bytes>"}}
+type FileKeyProvider struct {
+	activeKeyID string
+	keys        map[string][]byte
+}
+
+// NewFileKeyProvider reads and parses the keyring file at path.
+func NewFileKeyProvider(path string) (*FileKeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("NewFileKeyProvider: read %s: %w", path, err)
+	}
+
+	var ring keyringFile
+	if err := json.Unmarshal(data, &ring); err != nil {
+		return nil, fmt.Errorf("NewFileKeyProvider: parse %s: %w", path, err)
+	}
+	if ring.ActiveKeyID == "" {
+		return nil, fmt.Errorf("NewFileKeyProvider: %s: active_key_id is empty", path)
+	}
+
+	keys := make(map[string][]byte, len(ring.Keys))
+	for id, encoded := range ring.Keys {
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("NewFileKeyProvider: %s: decode key %q: %w", path, id, err)
+		}
+		keys[id] = raw
+	}
+	if _, ok := keys[ring.ActiveKeyID]; !ok {
+		return nil, fmt.Errorf("NewFileKeyProvider: %s: active_key_id %q has no matching key entry", path, ring.ActiveKeyID)
+	}
+
+	return &FileKeyProvider{activeKeyID: ring.ActiveKeyID, keys: keys}, nil
+}
+
+// ActiveKeyID implements KeyProvider.
+func (p *FileKeyProvider) ActiveKeyID() string {
+	return p.activeKeyID
+}
+
+// Key implements KeyProvider.
+func (p *FileKeyProvider) Key(keyID string) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("FileKeyProvider: unknown key id %q", keyID)
+	}
+	return key, nil
+}
+
+// EnvKeyProvider is a KeyProvider backed by a single base64-encoded AEAD
+// key read from an environment variable - the simplest option for a
+// single-key deployment with no rotation history.
+type EnvKeyProvider struct {
+	keyID string
+	key   []byte
+}
+
+// NewEnvKeyProvider reads a base64-encoded key from the environment
+// variable named keyEnvVar. keyID names the key (used as its ActiveKeyID
+// and as the id SealedStore frames records with); it does not need to come
+// from the environment since an EnvKeyProvider only ever knows one key.
+func NewEnvKeyProvider(keyEnvVar, keyID string) (*EnvKeyProvider, error) {
+	encoded := os.Getenv(keyEnvVar)
+	if encoded == "" {
+		return nil, fmt.Errorf("NewEnvKeyProvider: environment variable %s is empty or unset", keyEnvVar)
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("NewEnvKeyProvider: decode %s: %w", keyEnvVar, err)
+	}
+	if keyID == "" {
+		keyID = "env"
+	}
+	return &EnvKeyProvider{keyID: keyID, key: raw}, nil
+}
+
+// ActiveKeyID implements KeyProvider.
+func (p *EnvKeyProvider) ActiveKeyID() string {
+	return p.keyID
+}
+
+// Key implements KeyProvider.
+func (p *EnvKeyProvider) Key(keyID string) ([]byte, error) {
+	if keyID != p.keyID {
+		return nil, fmt.Errorf("EnvKeyProvider: unknown key id %q", keyID)
+	}
+	return p.key, nil
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileKeyProvider_ReadsKeyring(t *testing.T) {
+	dir := t.TempDir()
+	k1 := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	k2 := base64.StdEncoding.EncodeToString(append(make([]byte, 31), 1))
+	path := filepath.Join(dir, "keyring.json")
+	content := `{"active_key_id": "2026-01", "keys": {"2026-01": "` + k1 + `", "2025-06": "` + k2 + `"}}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	provider, err := NewFileKeyProvider(path)
+	if err != nil {
+		t.Fatalf("NewFileKeyProvider() error = %v", err)
+	}
+	if provider.ActiveKeyID() != "2026-01" {
+		t.Errorf("ActiveKeyID() = %q, want %q", provider.ActiveKeyID(), "2026-01")
+	}
+	if _, err := provider.Key("2026-01"); err != nil {
+		t.Errorf("Key(2026-01) error = %v", err)
+	}
+	if _, err := provider.Key("2025-06"); err != nil {
+		t.Errorf("Key(2025-06) error = %v", err)
+	}
+	if _, err := provider.Key("missing"); err == nil {
+		t.Error("expected Key() for an unknown id to error")
+	}
+}
+
+func TestFileKeyProvider_MissingActiveKeyErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keyring.json")
+	content := `{"active_key_id": "2026-01", "keys": {"2025-06": "` + base64.StdEncoding.EncodeToString(make([]byte, 32)) + `"}}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := NewFileKeyProvider(path); err == nil {
+		t.Error("expected an error when active_key_id has no matching key entry")
+	}
+}
+
+func TestEnvKeyProvider_ReadsKey(t *testing.T) {
+	key := make([]byte, 32)
+	t.Setenv("CODENERD_TEST_ENV_KEY_PROVIDER", base64.StdEncoding.EncodeToString(key))
+
+	provider, err := NewEnvKeyProvider("CODENERD_TEST_ENV_KEY_PROVIDER", "")
+	if err != nil {
+		t.Fatalf("NewEnvKeyProvider() error = %v", err)
+	}
+	if provider.ActiveKeyID() != "env" {
+		t.Errorf("ActiveKeyID() = %q, want default %q", provider.ActiveKeyID(), "env")
+	}
+	if _, err := provider.Key("env"); err != nil {
+		t.Errorf("Key(env) error = %v", err)
+	}
+}
+
+func TestEnvKeyProvider_MissingEnvVarErrors(t *testing.T) {
+	if _, err := NewEnvKeyProvider("CODENERD_TEST_ENV_KEY_PROVIDER_MISSING", "k1"); err == nil {
+		t.Error("expected an error when the environment variable is unset")
+	}
+}
@@ -0,0 +1,561 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"codenerd/internal/logging"
+	"codenerd/internal/types"
+)
+
+// =============================================================================
+// LLM CLIENT MIDDLEWARE - COMPOSABLE DECORATOR CHAIN
+// =============================================================================
+//
+// Rather than one monolithic wrapper baking in retry/metrics/tracing/
+// scheduling concerns (as ScheduledLLMCall used to), each concern is its own
+// LLMClientMiddleware that can be stacked with BuildLLMClient -- the same
+// decorator-stack shape as Temporal's retryable/metrics/healthsignal
+// persistence clients. Tests can assemble a minimal client (just
+// NewRetryableLLMClient over a mock); production can stack all of them.
+
+// -----------------------------------------------------------------------------
+// Middleware Chain
+// -----------------------------------------------------------------------------
+
+// LLMClientMiddleware wraps an LLMClient with one additional concern,
+// without the wrapped client needing to know about it.
+type LLMClientMiddleware func(LLMClient) LLMClient
+
+// BuildLLMClient composes mws around base. mws[0] ends up outermost (the
+// first thing a call passes through), mws[len(mws)-1] innermost (the last
+// wrapper before base). For example:
+//
+//	BuildLLMClient(base,
+//	    NewRetryableLLMClient(policy, IsTransientLLMError), // outer: retries...
+//	    NewSchedulingLLMClient(scheduler, shardID),          // ...each full scheduled attempt
+//	)
+func BuildLLMClient(base LLMClient, mws ...LLMClientMiddleware) LLMClient {
+	client := base
+	for i := len(mws) - 1; i >= 0; i-- {
+		client = mws[i](client)
+	}
+	return client
+}
+
+// -----------------------------------------------------------------------------
+// Transient Error Classification
+// -----------------------------------------------------------------------------
+
+// IsTransientLLMError reports whether err is worth retrying: a provider
+// rate-limit/overload response (see classifyLLMError) or a transient
+// network/timeout condition. NewRetryableLLMClient uses this by default;
+// callers with a model whose error surface needs different handling can
+// supply their own predicate instead.
+func IsTransientLLMError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if isRateLimit, _ := classifyLLMError(err); isRateLimit {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range []string{"timeout", "timed out", "connection reset", "eof", "temporary failure", "502", "503", "504"} {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// -----------------------------------------------------------------------------
+// Retry Middleware
+// -----------------------------------------------------------------------------
+
+// RetryPolicy decides how long to wait before each retry and how many
+// retries to allow after the initial call. attempt is 0-indexed (0 is the first retry
+// after an initial failure, not the initial call itself).
+type RetryPolicy interface {
+	MaxAttempts() int
+	Backoff(attempt int) time.Duration
+}
+
+// FixedRetryPolicy retries up to Attempts times, pausing Delay between
+// each.
+type FixedRetryPolicy struct {
+	Delay    time.Duration
+	Attempts int
+}
+
+// MaxAttempts implements RetryPolicy.
+func (p FixedRetryPolicy) MaxAttempts() int { return p.Attempts }
+
+// Backoff implements RetryPolicy.
+func (p FixedRetryPolicy) Backoff(int) time.Duration { return p.Delay }
+
+// ExponentialRetryPolicy doubles its delay every attempt, starting at Base
+// and capped at Max.
+type ExponentialRetryPolicy struct {
+	Base     time.Duration
+	Max      time.Duration
+	Attempts int
+}
+
+// MaxAttempts implements RetryPolicy.
+func (p ExponentialRetryPolicy) MaxAttempts() int { return p.Attempts }
+
+// Backoff implements RetryPolicy.
+func (p ExponentialRetryPolicy) Backoff(attempt int) time.Duration {
+	d := p.Base * time.Duration(int64(1)<<uint(attempt))
+	if p.Max > 0 && d > p.Max {
+		d = p.Max
+	}
+	return d
+}
+
+// DefaultExponentialRetryPolicy matches the backoff ScheduledLLMCall used
+// to apply inline before it moved here: 100ms * 2^attempt, capped at 5s.
+func DefaultExponentialRetryPolicy(attempts int) ExponentialRetryPolicy {
+	return ExponentialRetryPolicy{Base: 100 * time.Millisecond, Max: 5 * time.Second, Attempts: attempts}
+}
+
+// JitterRetryPolicy wraps another RetryPolicy and randomizes each backoff
+// within [0.5, 1.5) of the wrapped value, so a retry storm across many
+// shards doesn't wake them all up on the same tick.
+type JitterRetryPolicy struct {
+	Policy RetryPolicy
+
+	mu  sync.Mutex
+	rng *mathrand.Rand
+}
+
+// NewJitterRetryPolicy wraps policy with randomized jitter.
+func NewJitterRetryPolicy(policy RetryPolicy) *JitterRetryPolicy {
+	return &JitterRetryPolicy{
+		Policy: policy,
+		rng:    mathrand.New(mathrand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// MaxAttempts implements RetryPolicy.
+func (p *JitterRetryPolicy) MaxAttempts() int { return p.Policy.MaxAttempts() }
+
+// Backoff implements RetryPolicy.
+func (p *JitterRetryPolicy) Backoff(attempt int) time.Duration {
+	base := p.Policy.Backoff(attempt)
+	p.mu.Lock()
+	factor := 0.5 + p.rng.Float64()
+	p.mu.Unlock()
+	return time.Duration(float64(base) * factor)
+}
+
+// retryableLLMClient retries a wrapped LLMClient's calls according to
+// policy, stopping early the first time isTransient(err) is false (a
+// permanent error isn't worth retrying).
+type retryableLLMClient struct {
+	underlying  LLMClient
+	policy      RetryPolicy
+	isTransient func(error) bool
+}
+
+var _ LLMClient = (*retryableLLMClient)(nil)
+
+// NewRetryableLLMClient builds a middleware that retries Complete/
+// CompleteWithSystem/CompleteWithTools according to policy. isTransient
+// defaults to IsTransientLLMError when nil.
+func NewRetryableLLMClient(policy RetryPolicy, isTransient func(error) bool) LLMClientMiddleware {
+	if isTransient == nil {
+		isTransient = IsTransientLLMError
+	}
+	return func(client LLMClient) LLMClient {
+		return &retryableLLMClient{underlying: client, policy: policy, isTransient: isTransient}
+	}
+}
+
+// retryLoop runs fn up to r.policy.MaxAttempts()+1 times, waiting
+// r.policy.Backoff(attempt) between attempts and giving up early on a
+// non-transient error.
+func (r *retryableLLMClient) retryLoop(ctx context.Context, fn func() error) error {
+	var lastErr error
+	attempts := r.policy.MaxAttempts()
+
+	for attempt := 0; attempt <= attempts; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if attempt == attempts || !r.isTransient(err) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.policy.Backoff(attempt)):
+			logging.ShardsDebug("retryableLLMClient: retrying after error (attempt %d/%d): %v", attempt+1, attempts, err)
+		}
+	}
+
+	return fmt.Errorf("all %d attempts failed, last error: %w", attempts+1, lastErr)
+}
+
+// Complete implements LLMClient.
+func (r *retryableLLMClient) Complete(ctx context.Context, prompt string) (string, error) {
+	var result string
+	err := r.retryLoop(ctx, func() (err error) {
+		result, err = r.underlying.Complete(ctx, prompt)
+		return err
+	})
+	return result, err
+}
+
+// CompleteWithSystem implements LLMClient.
+func (r *retryableLLMClient) CompleteWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	var result string
+	err := r.retryLoop(ctx, func() (err error) {
+		result, err = r.underlying.CompleteWithSystem(ctx, systemPrompt, userPrompt)
+		return err
+	})
+	return result, err
+}
+
+// CompleteWithTools implements LLMClient.
+func (r *retryableLLMClient) CompleteWithTools(ctx context.Context, systemPrompt, userPrompt string, tools []types.ToolDefinition) (*types.LLMToolResponse, error) {
+	var result *types.LLMToolResponse
+	err := r.retryLoop(ctx, func() (err error) {
+		result, err = r.underlying.CompleteWithTools(ctx, systemPrompt, userPrompt, tools)
+		return err
+	})
+	return result, err
+}
+
+// -----------------------------------------------------------------------------
+// Metrics Middleware
+// -----------------------------------------------------------------------------
+
+// LLMCallMetrics describes one completed call, reported to an
+// LLMMetricsHandler by NewMetricsLLMClient.
+type LLMCallMetrics struct {
+	Method   string // "Complete", "CompleteWithSystem", or "CompleteWithTools"
+	Duration time.Duration
+	Err      error
+}
+
+// LLMMetricsHandler receives a callback after every call made through a
+// NewMetricsLLMClient-wrapped client.
+type LLMMetricsHandler interface {
+	ObserveLLMCall(m LLMCallMetrics)
+}
+
+type metricsLLMClient struct {
+	underlying LLMClient
+	handler    LLMMetricsHandler
+}
+
+var _ LLMClient = (*metricsLLMClient)(nil)
+
+// NewMetricsLLMClient builds a middleware that reports every call's
+// duration and outcome to handler.
+func NewMetricsLLMClient(handler LLMMetricsHandler) LLMClientMiddleware {
+	return func(client LLMClient) LLMClient {
+		return &metricsLLMClient{underlying: client, handler: handler}
+	}
+}
+
+func (m *metricsLLMClient) observe(method string, start time.Time, err error) {
+	if m.handler != nil {
+		m.handler.ObserveLLMCall(LLMCallMetrics{Method: method, Duration: time.Since(start), Err: err})
+	}
+}
+
+// Complete implements LLMClient.
+func (m *metricsLLMClient) Complete(ctx context.Context, prompt string) (string, error) {
+	start := time.Now()
+	result, err := m.underlying.Complete(ctx, prompt)
+	m.observe("Complete", start, err)
+	return result, err
+}
+
+// CompleteWithSystem implements LLMClient.
+func (m *metricsLLMClient) CompleteWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	start := time.Now()
+	result, err := m.underlying.CompleteWithSystem(ctx, systemPrompt, userPrompt)
+	m.observe("CompleteWithSystem", start, err)
+	return result, err
+}
+
+// CompleteWithTools implements LLMClient.
+func (m *metricsLLMClient) CompleteWithTools(ctx context.Context, systemPrompt, userPrompt string, tools []types.ToolDefinition) (*types.LLMToolResponse, error) {
+	start := time.Now()
+	result, err := m.underlying.CompleteWithTools(ctx, systemPrompt, userPrompt, tools)
+	m.observe("CompleteWithTools", start, err)
+	return result, err
+}
+
+// -----------------------------------------------------------------------------
+// Tracing Middleware
+// -----------------------------------------------------------------------------
+
+// tracingLLMClient logs the duration and outcome of every call it
+// forwards, once the call returns. For durable trace persistence (shard attribution, learning
+// metadata) see perception.TracingLLMClient; this is the lightweight
+// always-on logging layer for the decorator chain.
+type tracingLLMClient struct {
+	underlying LLMClient
+}
+
+var _ LLMClient = (*tracingLLMClient)(nil)
+
+// NewTracingLLMClient builds a middleware that logs every call via
+// logging.API.
+func NewTracingLLMClient() LLMClientMiddleware {
+	return func(client LLMClient) LLMClient {
+		return &tracingLLMClient{underlying: client}
+	}
+}
+
+// Complete implements LLMClient.
+func (t *tracingLLMClient) Complete(ctx context.Context, prompt string) (string, error) {
+	start := time.Now()
+	result, err := t.underlying.Complete(ctx, prompt)
+	logging.APIDebug("LLMClient.Complete: duration=%v err=%v", time.Since(start), err)
+	return result, err
+}
+
+// CompleteWithSystem implements LLMClient.
+func (t *tracingLLMClient) CompleteWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	start := time.Now()
+	result, err := t.underlying.CompleteWithSystem(ctx, systemPrompt, userPrompt)
+	logging.APIDebug("LLMClient.CompleteWithSystem: duration=%v err=%v", time.Since(start), err)
+	return result, err
+}
+
+// CompleteWithTools implements LLMClient.
+func (t *tracingLLMClient) CompleteWithTools(ctx context.Context, systemPrompt, userPrompt string, tools []types.ToolDefinition) (*types.LLMToolResponse, error) {
+	start := time.Now()
+	result, err := t.underlying.CompleteWithTools(ctx, systemPrompt, userPrompt, tools)
+	logging.APIDebug("LLMClient.CompleteWithTools: duration=%v err=%v", time.Since(start), err)
+	return result, err
+}
+
+// -----------------------------------------------------------------------------
+// Scheduling Middleware
+// -----------------------------------------------------------------------------
+
+// schedulingLLMClient acquires an APIScheduler slot for shardID before each
+// call and releases it when the call returns, guaranteeing release even if
+// the underlying client panics. This is the same acquire/release shape
+// ScheduledLLMCall applied inline; it now lives here so it can compose with
+// retry/metrics/tracing instead of being hardcoded into one wrapper.
+type schedulingLLMClient struct {
+	underlying LLMClient
+	scheduler  *APIScheduler
+	shardID    string
+
+	priority int32 // atomic SpawnPriority; PriorityNormal by default
+}
+
+var _ LLMClient = (*schedulingLLMClient)(nil)
+var _ PriorityAwareLLMClient = (*schedulingLLMClient)(nil)
+
+// NewSchedulingLLMClient builds a middleware that schedules every call
+// through scheduler under shardID. Compose it as the innermost middleware
+// (last argument to BuildLLMClient) so outer layers like
+// NewRetryableLLMClient re-acquire a fresh slot on every attempt, matching
+// ScheduledLLMCall.CompleteWithRetry's original per-attempt behavior.
+func NewSchedulingLLMClient(scheduler *APIScheduler, shardID string) LLMClientMiddleware {
+	return func(client LLMClient) LLMClient {
+		return &schedulingLLMClient{
+			underlying: client,
+			scheduler:  scheduler,
+			shardID:    shardID,
+			priority:   int32(PriorityNormal),
+		}
+	}
+}
+
+// SetShardPriority sets the SpawnPriority used for calls made through this
+// wrapper from now on.
+func (s *schedulingLLMClient) SetShardPriority(priority SpawnPriority) {
+	atomic.StoreInt32(&s.priority, int32(priority))
+}
+
+// Priority returns the SpawnPriority currently configured for this wrapper.
+func (s *schedulingLLMClient) Priority() SpawnPriority {
+	return SpawnPriority(atomic.LoadInt32(&s.priority))
+}
+
+// withSlot acquires a slot, runs fn (recovering a panic into an error so
+// the slot is never leaked), and releases the slot.
+func (s *schedulingLLMClient) withSlot(ctx context.Context, fn func() error) (err error) {
+	if err := s.scheduler.AcquireAPISlotWithPriority(ctx, s.shardID, s.Priority()); err != nil {
+		return fmt.Errorf("failed to acquire API slot: %w", err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic during LLM call: %v", r)
+		}
+		s.scheduler.ReleaseAPISlot(s.shardID)
+	}()
+	return fn()
+}
+
+// Complete implements LLMClient.
+func (s *schedulingLLMClient) Complete(ctx context.Context, prompt string) (string, error) {
+	var result string
+	err := s.withSlot(ctx, func() (err error) {
+		result, err = s.underlying.Complete(ctx, prompt)
+		return err
+	})
+	return result, err
+}
+
+// CompleteWithSystem implements LLMClient.
+func (s *schedulingLLMClient) CompleteWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	var result string
+	err := s.withSlot(ctx, func() (err error) {
+		result, err = s.underlying.CompleteWithSystem(ctx, systemPrompt, userPrompt)
+		return err
+	})
+	return result, err
+}
+
+// CompleteWithTools implements LLMClient.
+func (s *schedulingLLMClient) CompleteWithTools(ctx context.Context, systemPrompt, userPrompt string, tools []types.ToolDefinition) (*types.LLMToolResponse, error) {
+	var result *types.LLMToolResponse
+	err := s.withSlot(ctx, func() (err error) {
+		result, err = s.underlying.CompleteWithTools(ctx, systemPrompt, userPrompt, tools)
+		return err
+	})
+	return result, err
+}
+
+// -----------------------------------------------------------------------------
+// Circuit Breaker Middleware
+// -----------------------------------------------------------------------------
+
+// circuitBreakerState enumerates the three states of a circuit breaker.
+type circuitBreakerState int
+
+const (
+	circuitClosed circuitBreakerState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreakerResetTimeout is how long a tripped breaker stays open
+// before allowing a single half-open probe call through.
+const circuitBreakerResetTimeout = 30 * time.Second
+
+// ErrCircuitOpen is returned without calling the underlying client while a
+// circuitBreakerLLMClient is open.
+var ErrCircuitOpen = errors.New("circuit breaker open: underlying LLM client is failing")
+
+// circuitBreakerLLMClient fails fast once threshold consecutive calls have
+// failed, instead of letting every caller queue up behind a dead backend.
+type circuitBreakerLLMClient struct {
+	underlying LLMClient
+	threshold  int
+
+	mu       sync.Mutex
+	state    circuitBreakerState
+	failures int
+	openedAt time.Time
+}
+
+var _ LLMClient = (*circuitBreakerLLMClient)(nil)
+
+// NewCircuitBreakerLLMClient builds a middleware that opens after threshold
+// consecutive failures, fails fast for circuitBreakerResetTimeout, then
+// allows a single half-open probe call through to decide whether to close
+// again.
+func NewCircuitBreakerLLMClient(threshold int) LLMClientMiddleware {
+	return func(client LLMClient) LLMClient {
+		return &circuitBreakerLLMClient{underlying: client, threshold: threshold}
+	}
+}
+
+// admit reports whether a call should be allowed through right now.
+func (cb *circuitBreakerLLMClient) admit() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == circuitOpen || cb.state == circuitHalfOpen {
+		if time.Since(cb.openedAt) < circuitBreakerResetTimeout {
+			return ErrCircuitOpen
+		}
+		// Admit one probe; other callers are refused until it completes
+		// (or another full cooldown passes without it completing).
+		cb.state = circuitHalfOpen
+		cb.openedAt = time.Now()
+	}
+	return nil
+}
+
+// record updates breaker state after a call completes.
+func (cb *circuitBreakerLLMClient) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err == nil {
+		cb.failures = 0
+		cb.state = circuitClosed
+		return
+	}
+
+	if cb.state == circuitHalfOpen {
+		// The probe failed; stay open for another full cooldown.
+		cb.state = circuitOpen
+		cb.openedAt = time.Now()
+		return
+	}
+
+	cb.failures++
+	if cb.failures >= cb.threshold {
+		cb.state = circuitOpen
+		cb.openedAt = time.Now()
+		logging.Shards("circuitBreakerLLMClient: opened after %d consecutive failures", cb.failures)
+	}
+}
+
+// Complete implements LLMClient.
+func (cb *circuitBreakerLLMClient) Complete(ctx context.Context, prompt string) (string, error) {
+	if err := cb.admit(); err != nil {
+		return "", err
+	}
+	result, err := cb.underlying.Complete(ctx, prompt)
+	cb.record(err)
+	return result, err
+}
+
+// CompleteWithSystem implements LLMClient.
+func (cb *circuitBreakerLLMClient) CompleteWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	if err := cb.admit(); err != nil {
+		return "", err
+	}
+	result, err := cb.underlying.CompleteWithSystem(ctx, systemPrompt, userPrompt)
+	cb.record(err)
+	return result, err
+}
+
+// CompleteWithTools implements LLMClient.
+func (cb *circuitBreakerLLMClient) CompleteWithTools(ctx context.Context, systemPrompt, userPrompt string, tools []types.ToolDefinition) (*types.LLMToolResponse, error) {
+	if err := cb.admit(); err != nil {
+		return nil, err
+	}
+	result, err := cb.underlying.CompleteWithTools(ctx, systemPrompt, userPrompt, tools)
+	cb.record(err)
+	return result, err
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"codenerd/internal/types"
+)
+
+// recordingLLMClient counts calls and can be scripted to fail a fixed
+// number of times before succeeding.
+type recordingLLMClient struct {
+	failUntil int
+	calls     int
+	err       error
+}
+
+func (c *recordingLLMClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return c.CompleteWithSystem(ctx, "", prompt)
+}
+
+func (c *recordingLLMClient) CompleteWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	c.calls++
+	if c.calls <= c.failUntil {
+		if c.err != nil {
+			return "", c.err
+		}
+		return "", errors.New("rate limit exceeded (429)")
+	}
+	return "ok", nil
+}
+
+func (c *recordingLLMClient) CompleteWithTools(ctx context.Context, systemPrompt, userPrompt string, tools []types.ToolDefinition) (*types.LLMToolResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// TestBuildLLMClient_OrderingAppliesOutermostFirst verifies mws[0] wraps
+// everything built from the rest, i.e. it observes a call before mws[1..].
+func TestBuildLLMClient_OrderingAppliesOutermostFirst(t *testing.T) {
+	var order []string
+	tag := func(name string) LLMClientMiddleware {
+		return func(client LLMClient) LLMClient {
+			return &orderTaggingClient{underlying: client, name: name, order: &order}
+		}
+	}
+
+	base := &recordingLLMClient{}
+	chain := BuildLLMClient(base, tag("outer"), tag("inner"))
+
+	if _, err := chain.Complete(context.Background(), "hi"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Fatalf("expected outer to run before inner, got %v", order)
+	}
+}
+
+type orderTaggingClient struct {
+	underlying LLMClient
+	name       string
+	order      *[]string
+}
+
+func (o *orderTaggingClient) Complete(ctx context.Context, prompt string) (string, error) {
+	*o.order = append(*o.order, o.name)
+	return o.underlying.Complete(ctx, prompt)
+}
+func (o *orderTaggingClient) CompleteWithSystem(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	*o.order = append(*o.order, o.name)
+	return o.underlying.CompleteWithSystem(ctx, systemPrompt, userPrompt)
+}
+func (o *orderTaggingClient) CompleteWithTools(ctx context.Context, systemPrompt, userPrompt string, tools []types.ToolDefinition) (*types.LLMToolResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// TestRetryableLLMClient_GivesUpOnPermanentError verifies a non-transient
+// error short-circuits the retry loop instead of burning every attempt.
+func TestRetryableLLMClient_GivesUpOnPermanentError(t *testing.T) {
+	base := &recordingLLMClient{failUntil: 5, err: errors.New("invalid API key")}
+	policy := FixedRetryPolicy{Delay: time.Millisecond, Attempts: 5}
+	client := NewRetryableLLMClient(policy, nil)(base)
+
+	_, err := client.CompleteWithSystem(context.Background(), "sys", "user")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if base.calls != 1 {
+		t.Fatalf("expected exactly 1 call for a non-transient error, got %d", base.calls)
+	}
+}
+
+// TestRetryableLLMClient_RetriesTransientError verifies a transient error
+// is retried until it succeeds, within the attempt budget.
+func TestRetryableLLMClient_RetriesTransientError(t *testing.T) {
+	base := &recordingLLMClient{failUntil: 2} // defaults to a "rate limit" error, which is transient
+	policy := FixedRetryPolicy{Delay: time.Millisecond, Attempts: 5}
+	client := NewRetryableLLMClient(policy, nil)(base)
+
+	result, err := client.CompleteWithSystem(context.Background(), "sys", "user")
+	if err != nil {
+		t.Fatalf("expected eventual success, got: %v", err)
+	}
+	if result != "ok" {
+		t.Fatalf("expected result 'ok', got %q", result)
+	}
+	if base.calls != 3 {
+		t.Fatalf("expected 3 calls (2 failures + success), got %d", base.calls)
+	}
+}
+
+// recordingMetricsHandler collects every observed call for assertions.
+type recordingMetricsHandler struct {
+	observed []LLMCallMetrics
+}
+
+func (h *recordingMetricsHandler) ObserveLLMCall(m LLMCallMetrics) {
+	h.observed = append(h.observed, m)
+}
+
+// TestMetricsLLMClient_ObservesEachCall verifies the metrics middleware
+// reports exactly one observation per call, with the outcome attached.
+func TestMetricsLLMClient_ObservesEachCall(t *testing.T) {
+	base := &recordingLLMClient{failUntil: 1}
+	handler := &recordingMetricsHandler{}
+	client := NewMetricsLLMClient(handler)(base)
+
+	_, _ = client.CompleteWithSystem(context.Background(), "sys", "user")
+	_, err := client.CompleteWithSystem(context.Background(), "sys", "user")
+	if err != nil {
+		t.Fatalf("unexpected error on second call: %v", err)
+	}
+
+	if len(handler.observed) != 2 {
+		t.Fatalf("expected 2 observations, got %d", len(handler.observed))
+	}
+	if handler.observed[0].Err == nil {
+		t.Fatalf("expected first observation to record the failure")
+	}
+	if handler.observed[1].Err != nil {
+		t.Fatalf("expected second observation to record success, got %v", handler.observed[1].Err)
+	}
+}
+
+// TestCircuitBreakerLLMClient_OpensAfterThreshold verifies the breaker
+// fails fast without calling the underlying client once it trips.
+func TestCircuitBreakerLLMClient_OpensAfterThreshold(t *testing.T) {
+	base := &recordingLLMClient{failUntil: 1000, err: errors.New("backend down")}
+	client := NewCircuitBreakerLLMClient(2)(base)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.CompleteWithSystem(context.Background(), "sys", "user"); err == nil {
+			t.Fatalf("expected call %d to fail", i+1)
+		}
+	}
+
+	callsBeforeOpen := base.calls
+	if _, err := client.CompleteWithSystem(context.Background(), "sys", "user"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen once the breaker trips, got: %v", err)
+	}
+	if base.calls != callsBeforeOpen {
+		t.Fatalf("expected the underlying client not to be called while the breaker is open")
+	}
+}
+
+// TestScheduledLLMCall_CompleteWithRetry_RateLimitNotifiesScheduler verifies
+// CompleteWithRetry's delegated middleware chain still reports rate-limit
+// errors back to the scheduler, same as the inline loop it replaced.
+func TestScheduledLLMCall_CompleteWithRetry_RateLimitNotifiesScheduler(t *testing.T) {
+	scheduler := NewAPIScheduler(APISchedulerConfig{
+		MaxConcurrentAPICalls: 4,
+		SlotAcquireTimeout:    5 * time.Second,
+		RateLimitCooldown:     10 * time.Millisecond,
+	})
+	defer scheduler.Stop()
+	scheduler.RegisterShard("rl-shard", "test")
+
+	mock := &mockLLMClient{
+		completeFunc: func(ctx context.Context, prompt string) (string, error) {
+			return "", fmt.Errorf("rate limit exceeded (429)")
+		},
+	}
+
+	call := &ScheduledLLMCall{Scheduler: scheduler, ShardID: "rl-shard", Client: mock}
+	_, err := call.CompleteWithRetry(context.Background(), "sys", "user", 1)
+	if err == nil {
+		t.Fatal("expected an error after exhausting retries")
+	}
+
+	if got := scheduler.GetMetrics().CurrentSlotLimit; got != 2 {
+		t.Fatalf("expected NotifyRateLimit to halve the slot limit to 2, got %d", got)
+	}
+}
@@ -0,0 +1,371 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ObligationForest is an additive, opt-in observability layer over the
+// derivations RealKernel already computes via the real Mangle fixpoint
+// engine (evaluate(), kernel_eval.go). It does NOT replace that engine -
+// google/mangle's evaluator is what actually proves facts, and swapping it
+// out for a bespoke solver would trade a tested Datalog implementation for
+// an untested one. Instead, ObligationForest replays a TraceWAL's recorded
+// rule firings (see trace_wal.go/recordRuleFiringsLocked) into an explicit
+// Pending/Success/Error obligation tree, wave-resolves it to a fixpoint,
+// and caches shared subgoals - giving the waves/cache-hit-rate/stalled
+// metrics and the debug dump this package's TraceQuery/TraceStore
+// (trace.go, trace_store.go) don't expose on their own.
+type ObligationForest struct {
+	mu    sync.Mutex
+	cache *ProjectionCache
+	roots []*ObligationNode
+	waves int
+}
+
+// NewObligationForest creates an empty ObligationForest with its own
+// ProjectionCache.
+func NewObligationForest() *ObligationForest {
+	return &ObligationForest{cache: NewProjectionCache()}
+}
+
+// ObligationState is an ObligationNode's resolution state.
+type ObligationState int
+
+const (
+	// ObligationPending means the obligation hasn't been resolved yet -
+	// at least one child obligation is still Pending.
+	ObligationPending ObligationState = iota
+	// ObligationSuccess means every child obligation resolved to Success
+	// (or the node has no children, i.e. it's a leaf).
+	ObligationSuccess
+	// ObligationError means a required premise predicate has no WAL
+	// entry at all, so this obligation can never be proven.
+	ObligationError
+)
+
+// String returns the state's name.
+func (s ObligationState) String() string {
+	switch s {
+	case ObligationPending:
+		return "pending"
+	case ObligationSuccess:
+		return "success"
+	case ObligationError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ObligationNode is a single "prove predicate via rule with these bindings"
+// obligation, with a child obligation for each body-atom premise the rule
+// is known to depend on (premisePredicatesForRule, trace_store.go).
+type ObligationNode struct {
+	Predicate string
+	RuleName  string
+	Bindings  string // canonical fact.String() of the bound fact, for display/keying
+	State     ObligationState
+	Err       error
+	Children  []*ObligationNode
+}
+
+// projectionCacheKey identifies a subgoal ProjectionCache can share across
+// branches of the forest: the rule producing it plus its bound arguments.
+type projectionCacheKey struct {
+	rule     string
+	bindings string
+}
+
+// ProjectionCache memoizes ObligationNodes by (rule, bindings), so the same
+// subgoal reached from two different branches of the forest is built once
+// and shared, mirroring the "ProjectionCache-style" memoization this
+// package's request asked for.
+type ProjectionCache struct {
+	mu      sync.Mutex
+	items   map[projectionCacheKey]*ObligationNode
+	hits    int64
+	lookups int64
+}
+
+// NewProjectionCache creates an empty ProjectionCache.
+func NewProjectionCache() *ProjectionCache {
+	return &ProjectionCache{items: make(map[projectionCacheKey]*ObligationNode)}
+}
+
+func (c *ProjectionCache) get(key projectionCacheKey) (*ObligationNode, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lookups++
+	node, ok := c.items[key]
+	if ok {
+		c.hits++
+	}
+	return node, ok
+}
+
+func (c *ProjectionCache) put(key projectionCacheKey, node *ObligationNode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = node
+}
+
+// invalidatePredicate drops every cached node directly produced for
+// predicate, so the next Solve rebuilds it (and, transitively, anything
+// that only reached it through a shared cache entry).
+func (c *ProjectionCache) invalidatePredicate(predicate string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, node := range c.items {
+		if node.Predicate == predicate {
+			delete(c.items, key)
+		}
+	}
+}
+
+// HitRate returns this cache's lifetime hit rate, or 0 if it's never been
+// looked up.
+func (c *ProjectionCache) HitRate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lookups == 0 {
+		return 0
+	}
+	return float64(c.hits) / float64(c.lookups)
+}
+
+// ObligationForestMetrics is a point-in-time snapshot of an
+// ObligationForest's last Solve call.
+type ObligationForestMetrics struct {
+	Waves              int
+	CacheHitRate       float64
+	StalledObligations int
+	TotalObligations   int
+}
+
+// Solve rebuilds this forest's roots from every WALRuleFiring entry in wal
+// up to upToTxnID (one root per rule firing, with a child obligation per
+// premise predicate premisePredicatesForRule names for that firing's
+// RuleName), then resolves them wave by wave: each wave walks every node
+// still Pending and promotes it to Success once all its children are
+// Success, or to Error if a child is Error. Waves repeat until a full pass
+// makes no further state changes - the fixpoint - and f.waves counts how
+// many it took. Repeated (rule, bindings) subgoals across different
+// branches resolve once and are shared via f.cache.
+func (f *ObligationForest) Solve(wal *TraceWAL, upToTxnID uint64) ObligationForestMetrics {
+	entries := wal.EntriesUpTo(upToTxnID)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.roots = f.roots[:0]
+	for _, e := range entries {
+		if e.Kind != WALRuleFiring {
+			continue
+		}
+		f.roots = append(f.roots, f.nodeForLocked(entries, e, 0))
+	}
+
+	f.waves = 0
+	for {
+		f.waves++
+		changed := false
+		seen := make(map[*ObligationNode]bool)
+		for _, root := range f.roots {
+			if resolveObligation(root, seen) {
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	return f.metricsLocked()
+}
+
+// nodeForLocked returns the (possibly cached) ObligationNode for entry,
+// building its children from entries when it's a cache miss. f.mu must be
+// held.
+func (f *ObligationForest) nodeForLocked(entries []WALEntry, entry WALEntry, depth int) *ObligationNode {
+	key := projectionCacheKey{rule: entry.RuleName, bindings: entry.Fact.String()}
+	if cached, ok := f.cache.get(key); ok {
+		return cached
+	}
+
+	node := &ObligationNode{
+		Predicate: entry.Predicate,
+		RuleName:  entry.RuleName,
+		Bindings:  entry.Fact.String(),
+		State:     ObligationPending,
+	}
+	if depth < traceRebuildDepthLimit {
+		for _, premisePredicate := range premisePredicatesForRule(entry.RuleName) {
+			premiseEntry, found := latestSurvivingEntry(entries, premisePredicate)
+			if !found {
+				node.Children = append(node.Children, &ObligationNode{
+					Predicate: premisePredicate,
+					State:     ObligationError,
+					Err:       fmt.Errorf("no surviving WAL entry recorded for premise %s", premisePredicate),
+				})
+				continue
+			}
+			node.Children = append(node.Children, f.nodeForLocked(entries, premiseEntry, depth+1))
+		}
+	}
+
+	f.cache.put(key, node)
+	return node
+}
+
+// resolveObligation resolves node (and its children) in place, returning
+// whether its state changed this call. seen prevents a shared cached node
+// reachable from multiple branches from being resolved (and double-counted
+// as "changed") more than once per wave.
+func resolveObligation(node *ObligationNode, seen map[*ObligationNode]bool) bool {
+	if seen[node] || node.State != ObligationPending {
+		return false
+	}
+	seen[node] = true
+
+	changed := false
+	allSuccess := true
+	for _, child := range node.Children {
+		if resolveObligation(child, seen) {
+			changed = true
+		}
+		switch child.State {
+		case ObligationError:
+			node.State = ObligationError
+			node.Err = fmt.Errorf("premise %s failed: %w", child.Predicate, child.Err)
+			return true
+		case ObligationPending:
+			allSuccess = false
+		}
+	}
+	if allSuccess {
+		node.State = ObligationSuccess
+		return true
+	}
+	return changed
+}
+
+// metricsLocked computes this forest's metrics over its current roots.
+// f.mu must be held.
+func (f *ObligationForest) metricsLocked() ObligationForestMetrics {
+	total := 0
+	stalled := 0
+	visited := make(map[*ObligationNode]bool)
+	var count func(n *ObligationNode)
+	count = func(n *ObligationNode) {
+		if visited[n] {
+			return
+		}
+		visited[n] = true
+		total++
+		if n.State == ObligationPending {
+			stalled++
+		}
+		for _, c := range n.Children {
+			count(c)
+		}
+	}
+	for _, root := range f.roots {
+		count(root)
+	}
+
+	return ObligationForestMetrics{
+		Waves:              f.waves,
+		CacheHitRate:       f.cache.HitRate(),
+		StalledObligations: stalled,
+		TotalObligations:   total,
+	}
+}
+
+// Invalidate drops every cached obligation directly produced for
+// predicate, so the next Solve call re-derives it instead of reusing a
+// stale cached node - the "only re-evaluate the transitive closure of
+// obligations that referenced the changed predicate" behavior Assert/
+// Retract need after a fact changes.
+func (f *ObligationForest) Invalidate(predicate string) {
+	f.cache.invalidatePredicate(predicate)
+}
+
+// DebugDump renders the forest's current roots as indented text, one line
+// per obligation with its state (and error, if any) - meant to be read
+// alongside TraceQuery/TraceStore.RebuildTrace output when diagnosing why
+// a derivation stalled or failed.
+func (f *ObligationForest) DebugDump() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var b strings.Builder
+	for _, root := range f.roots {
+		dumpObligationNode(&b, root, 0)
+	}
+	return b.String()
+}
+
+// SetObligationForest attaches forest to the kernel. Once attached, Assert/
+// AssertBatch/Retract call forest.Invalidate for every predicate they
+// touch, so a later Solve only rebuilds the obligations that referenced a
+// changed predicate instead of starting from an empty cache. A nil forest
+// (the default) makes this a no-op.
+func (k *RealKernel) SetObligationForest(forest *ObligationForest) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.obligationForest = forest
+}
+
+// ObligationForest returns the kernel's attached ObligationForest, or nil
+// if none is attached.
+func (k *RealKernel) ObligationForest() *ObligationForest {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.obligationForest
+}
+
+// invalidateObligationsLocked tells the attached ObligationForest (if any)
+// to drop its cached nodes for predicate. Caller must hold k.mu.
+func (k *RealKernel) invalidateObligationsLocked(predicate string) {
+	if k.obligationForest == nil {
+		return
+	}
+	k.obligationForest.Invalidate(predicate)
+}
+
+// refreshObligationForestLocked re-solves the attached ObligationForest (if
+// any) against the attached TraceWAL's current entries and caches the
+// resulting metrics, so evaluateRestricted's incremental delta path
+// actually drives Solve instead of leaving it reachable only from tests.
+// Caller must hold k.mu. No-op if no forest or no WAL is attached.
+func (k *RealKernel) refreshObligationForestLocked() {
+	if k.obligationForest == nil || k.wal == nil {
+		return
+	}
+	k.lastObligationMetrics = k.obligationForest.Solve(k.wal, k.wal.LatestTxnID())
+}
+
+// LastObligationForestMetrics returns the metrics from the most recent
+// refreshObligationForestLocked call (triggered by evaluateRestricted), and
+// whether an ObligationForest is attached at all.
+func (k *RealKernel) LastObligationForestMetrics() (ObligationForestMetrics, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.obligationForest == nil {
+		return ObligationForestMetrics{}, false
+	}
+	return k.lastObligationMetrics, true
+}
+
+func dumpObligationNode(b *strings.Builder, node *ObligationNode, depth int) {
+	fmt.Fprintf(b, "%s%s[%s] rule=%s state=%s", strings.Repeat("  ", depth), node.Predicate, node.Bindings, node.RuleName, node.State)
+	if node.Err != nil {
+		fmt.Fprintf(b, " err=%v", node.Err)
+	}
+	b.WriteByte('\n')
+	for _, child := range node.Children {
+		dumpObligationNode(b, child, depth+1)
+	}
+}
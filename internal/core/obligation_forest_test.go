@@ -0,0 +1,83 @@
+package core
+
+import "testing"
+
+func TestObligationForest_SolveReachesFixpoint(t *testing.T) {
+	wal := NewTraceWAL()
+	wal.Append(WALAssert, "safe_action", Fact{Predicate: "safe_action", Args: []interface{}{"go_test"}}, "")
+	firing := wal.Append(WALRuleFiring, "permitted", Fact{Predicate: "permitted", Args: []interface{}{"go_test"}}, "permission_gate")
+
+	forest := NewObligationForest()
+	metrics := forest.Solve(wal, firing.TxnID)
+
+	if metrics.Waves < 2 {
+		t.Errorf("expected at least 2 waves to resolve a 2-level tree, got %d", metrics.Waves)
+	}
+	if metrics.StalledObligations != 0 {
+		t.Errorf("expected no stalled obligations, got %d", metrics.StalledObligations)
+	}
+	if metrics.TotalObligations != 2 {
+		t.Errorf("expected 2 obligations (root + premise), got %d", metrics.TotalObligations)
+	}
+}
+
+func TestObligationForest_MissingPremiseResolvesToError(t *testing.T) {
+	wal := NewTraceWAL()
+	firing := wal.Append(WALRuleFiring, "permitted", Fact{Predicate: "permitted", Args: []interface{}{"go_test"}}, "permission_gate")
+
+	forest := NewObligationForest()
+	metrics := forest.Solve(wal, firing.TxnID)
+
+	if metrics.StalledObligations != 0 {
+		t.Errorf("expected the missing premise to resolve to Error (not stay Pending), got %d stalled", metrics.StalledObligations)
+	}
+	dump := forest.DebugDump()
+	if dump == "" {
+		t.Error("expected a non-empty debug dump")
+	}
+}
+
+func TestObligationForest_SharedSubgoalCached(t *testing.T) {
+	wal := NewTraceWAL()
+	wal.Append(WALAssert, "safe_action", Fact{Predicate: "safe_action", Args: []interface{}{"go_test"}}, "")
+	wal.Append(WALRuleFiring, "permitted", Fact{Predicate: "permitted", Args: []interface{}{"go_test"}}, "permission_gate")
+	firing2 := wal.Append(WALRuleFiring, "permitted", Fact{Predicate: "permitted", Args: []interface{}{"go_test"}}, "permission_gate")
+
+	forest := NewObligationForest()
+	forest.Solve(wal, firing2.TxnID)
+
+	if forest.cache.HitRate() <= 0 {
+		t.Error("expected the second identical (rule, bindings) subgoal to hit the cache")
+	}
+}
+
+func TestObligationForest_InvalidateForcesRebuild(t *testing.T) {
+	wal := NewTraceWAL()
+	firing := wal.Append(WALRuleFiring, "permitted", Fact{Predicate: "permitted", Args: []interface{}{"go_test"}}, "")
+
+	forest := NewObligationForest()
+	forest.Solve(wal, firing.TxnID)
+	before := forest.cache.HitRate()
+
+	forest.Invalidate("permitted")
+	forest.Solve(wal, firing.TxnID)
+
+	if forest.cache.HitRate() == before {
+		t.Error("expected Invalidate to change the cache's hit/miss ratio on the next Solve")
+	}
+}
+
+func TestRealKernel_AssertWithObligationForestAttached(t *testing.T) {
+	kernel := newBootstrapTestKernel(t)
+	forest := NewObligationForest()
+	kernel.SetObligationForest(forest)
+
+	if kernel.ObligationForest() != forest {
+		t.Fatal("expected ObligationForest() to return the attached forest")
+	}
+
+	// Assert must not panic or error when an ObligationForest is attached.
+	if err := kernel.Assert(Fact{Predicate: "safe_action", Args: []interface{}{"go_test"}}); err != nil {
+		t.Fatalf("Assert() error = %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"fmt"
+
+	"codenerd/internal/core/storage"
+	"codenerd/internal/logging"
+)
+
+// SetFactStore attaches store to the kernel. Once attached, Assert/
+// AssertBatch write each newly-added persistent-category fact through to
+// store in the same call, Retract removes a retracted predicate's facts
+// from store, and Shutdown closes store after a final flush. A nil store
+// (what every existing constructor leaves it as) makes all of this a
+// no-op, so persistence is strictly opt-in and doesn't change behavior for
+// the ephemeral, in-process kernels the rest of the test suite builds.
+func (k *RealKernel) SetFactStore(store storage.Store) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.persistentStore = store
+}
+
+// FactStore returns the kernel's attached fact store, or nil if none is
+// attached.
+func (k *RealKernel) FactStore() storage.Store {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.persistentStore
+}
+
+// persistFactsLocked writes every fact in facts whose predicate is
+// IsPersistent through to k.persistentStore in a single write txn. It's a
+// no-op when no store is attached. Caller must hold k.mu.
+func (k *RealKernel) persistFactsLocked(facts []Fact) {
+	if k.persistentStore == nil {
+		return
+	}
+	persistent := FilterPersistentFacts(facts)
+	if len(persistent) == 0 {
+		return
+	}
+
+	txn, err := k.persistentStore.BeginWrite()
+	if err != nil {
+		logging.Get(logging.CategoryKernel).Error("persistFactsLocked: BeginWrite failed: %v", err)
+		return
+	}
+	for _, f := range persistent {
+		encoded := f.String()
+		if err := txn.Put(f.Predicate, encoded, []byte(encoded)); err != nil {
+			logging.Get(logging.CategoryKernel).Error("persistFactsLocked: Put(%s) failed: %v", f.Predicate, err)
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		logging.Get(logging.CategoryKernel).Error("persistFactsLocked: Commit failed: %v", err)
+	}
+}
+
+// FilterPersistentFacts filters facts to those whose predicate IsPersistent,
+// mirroring FilterPersistent but operating on Facts instead of predicate
+// names.
+func FilterPersistentFacts(facts []Fact) []Fact {
+	result := make([]Fact, 0, len(facts))
+	for _, f := range facts {
+		if IsPersistent(f.Predicate) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// retractPredicateFromStoreLocked drops every stored fact for predicate
+// from k.persistentStore. It's a no-op when no store is attached or
+// predicate isn't persistent (ephemeral/derived predicates are never
+// written to the store in the first place). Caller must hold k.mu.
+func (k *RealKernel) retractPredicateFromStoreLocked(predicate string) {
+	if k.persistentStore == nil || !IsPersistent(predicate) {
+		return
+	}
+
+	txn, err := k.persistentStore.BeginWrite()
+	if err != nil {
+		logging.Get(logging.CategoryKernel).Error("retractPredicateFromStoreLocked: BeginWrite failed: %v", err)
+		return
+	}
+	var keys []string
+	if err := txn.Iterate(predicate, func(pred, key string, _ []byte) error {
+		if pred == predicate {
+			keys = append(keys, key)
+		}
+		return nil
+	}); err != nil {
+		logging.Get(logging.CategoryKernel).Error("retractPredicateFromStoreLocked: Iterate failed: %v", err)
+		_ = txn.Abort()
+		return
+	}
+	for _, key := range keys {
+		if err := txn.Delete(predicate, key); err != nil {
+			logging.Get(logging.CategoryKernel).Error("retractPredicateFromStoreLocked: Delete failed: %v", err)
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		logging.Get(logging.CategoryKernel).Error("retractPredicateFromStoreLocked: Commit failed: %v", err)
+	}
+}
+
+// LoadPersistentFacts reads every fact back out of the attached store and
+// loads it into the EDB via LoadFacts, so a restarted kernel picks up where
+// a prior session left off. It's a no-op (returning nil) when no store is
+// attached.
+func (k *RealKernel) LoadPersistentFacts() error {
+	k.mu.RLock()
+	store := k.persistentStore
+	k.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+
+	txn, err := store.BeginRead()
+	if err != nil {
+		return fmt.Errorf("LoadPersistentFacts: BeginRead failed: %w", err)
+	}
+	defer txn.Commit()
+
+	var facts []Fact
+	if err := txn.Iterate("", func(predicate, _ string, value []byte) error {
+		if !IsPersistent(predicate) {
+			return nil // a predicate's category may have been reclassified since it was stored
+		}
+		fact, err := ParseFactString(string(value))
+		if err != nil {
+			logging.Get(logging.CategoryKernel).Error("LoadPersistentFacts: failed to parse stored fact %q: %v", string(value), err)
+			return nil
+		}
+		facts = append(facts, fact)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("LoadPersistentFacts: Iterate failed: %w", err)
+	}
+
+	if len(facts) == 0 {
+		return nil
+	}
+	return k.LoadFacts(facts)
+}
+
+// Shutdown flushes every persistent-category fact currently in the EDB to
+// the attached store and closes it. It's a no-op when no store is
+// attached. Ephemeral and derived facts are left behind, by design - they
+// have no business surviving the session boundary.
+func (k *RealKernel) Shutdown() error {
+	k.mu.Lock()
+	store := k.persistentStore
+	facts := append([]Fact(nil), k.facts...)
+	k.mu.Unlock()
+	if store == nil {
+		return nil
+	}
+
+	k.mu.Lock()
+	k.persistFactsLocked(facts)
+	k.mu.Unlock()
+
+	if err := store.Close(); err != nil {
+		return fmt.Errorf("Shutdown: close fact store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,474 @@
+package core
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"codenerd/internal/core/storage"
+)
+
+// ErrSealed is returned by SealedStore's read paths when a sensitive
+// predicate's record exists but the store hasn't been Unseal()ed yet.
+var ErrSealed = errors.New("sealed_store: store is sealed, sensitive predicate is unreadable")
+
+// sealedRecordMarker prefixes every record SealedStore encrypts, so a plain
+// (never-encrypted) value written before a predicate was marked sensitive
+// is told apart from a framed one without needing a side index.
+var sealedRecordMarker = []byte("codenerd:sealed:v1:")
+
+// sealedRecord is the on-disk framing for an AEAD-sealed fact record: the
+// id of the key it was sealed under (for key rotation, see RotateKeys),
+// the AES-GCM nonce, and the ciphertext. The predicate name is passed as
+// AEAD associated data rather than stored in the record, so a record moved
+// or relabeled to a different predicate fails to decrypt instead of
+// silently decrypting under the wrong context.
+type sealedRecord struct {
+	KeyID      string `json:"key_id"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// isSealedRecord reports whether raw looks like a SealedStore-framed
+// record rather than a plaintext value.
+func isSealedRecord(raw []byte) bool {
+	return bytes.HasPrefix(raw, sealedRecordMarker)
+}
+
+// sealValue AEAD-encrypts plaintext under a key derived from both
+// keyProvider's active key and secret (SealedStore.recordSecret, itself
+// derived from the unlocking passphrase - see deriveSealSecret), using
+// predicate as associated data, and frames the result with
+// sealedRecordMarker.
+func sealValue(keyProvider KeyProvider, secret []byte, predicate string, plaintext []byte) ([]byte, error) {
+	keyID := keyProvider.ActiveKeyID()
+	providerKey, err := keyProvider.Key(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("sealValue: %w", err)
+	}
+	gcm, err := newGCM(deriveRecordKey(secret, providerKey))
+	if err != nil {
+		return nil, fmt.Errorf("sealValue: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("sealValue: generate nonce: %w", err)
+	}
+	ciphertext := gcm.Seal(nil, nonce, plaintext, []byte(predicate))
+
+	encoded, err := json.Marshal(sealedRecord{KeyID: keyID, Nonce: nonce, Ciphertext: ciphertext})
+	if err != nil {
+		return nil, fmt.Errorf("sealValue: marshal record: %w", err)
+	}
+	return append(append([]byte{}, sealedRecordMarker...), encoded...), nil
+}
+
+// unsealValue reverses sealValue, returning the plaintext and the key id
+// the record was actually sealed under (so the caller can detect a
+// retired key and re-seal it - see SealedStore.RotateKeys). secret must be
+// the same recordSecret sealValue was called with, or decryption fails
+// even given the right provider key id.
+func unsealValue(keyProvider KeyProvider, secret []byte, predicate string, framed []byte) (plaintext []byte, keyID string, err error) {
+	if !isSealedRecord(framed) {
+		return nil, "", fmt.Errorf("unsealValue: not a sealed record")
+	}
+	var record sealedRecord
+	if err := json.Unmarshal(framed[len(sealedRecordMarker):], &record); err != nil {
+		return nil, "", fmt.Errorf("unsealValue: unmarshal record: %w", err)
+	}
+
+	providerKey, err := keyProvider.Key(record.KeyID)
+	if err != nil {
+		return nil, "", fmt.Errorf("unsealValue: %w", err)
+	}
+	gcm, err := newGCM(deriveRecordKey(secret, providerKey))
+	if err != nil {
+		return nil, "", fmt.Errorf("unsealValue: %w", err)
+	}
+
+	plaintext, err = gcm.Open(nil, record.Nonce, record.Ciphertext, []byte(predicate))
+	if err != nil {
+		return nil, "", fmt.Errorf("unsealValue: decrypt: %w", err)
+	}
+	return plaintext, record.KeyID, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create AES cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveRecordKey combines secret (passphrase-derived, see
+// deriveSealSecret) with providerKey (KeyProvider-supplied) into the
+// AES-256 key actually used to seal/unseal a record. Binding both into the
+// key is what makes the passphrase load-bearing: reading the on-disk
+// store plus the KeyProvider's key source alone is not enough to compute
+// this key without also knowing secret, i.e. without also knowing the
+// passphrase.
+func deriveRecordKey(secret, providerKey []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(providerKey)
+	return mac.Sum(nil)
+}
+
+// sealSecretSaltPredicate is the on-disk location of a SealedStore's HKDF
+// salt (see deriveSealSecret). The salt isn't secret - it only needs to be
+// stable across restarts so the same passphrase re-derives the same
+// record key - so it's stored alongside the sealed data unencrypted,
+// under a predicate name that can never collide with a real fact
+// predicate (leading underscore, reserved by convention elsewhere in this
+// package - see walStorePredicate).
+const sealSecretSaltPredicate = "_sealed_store_salt"
+const sealSecretSaltKey = "salt"
+const sealSecretSaltSize = 16
+
+// loadOrCreateSalt returns store's persisted HKDF salt, generating and
+// persisting a fresh random one the first time store is used as a
+// SealedStore's backing store. The steady-state case (salt already exists)
+// only ever takes store's read lock; the exclusive write lock is reserved
+// for the genuinely-missing-salt path.
+func loadOrCreateSalt(store storage.Store) ([]byte, error) {
+	if existing, found, err := readSalt(store); err != nil {
+		return nil, err
+	} else if found {
+		return existing, nil
+	}
+
+	txn, err := store.BeginWrite()
+	if err != nil {
+		return nil, fmt.Errorf("loadOrCreateSalt: BeginWrite: %w", err)
+	}
+
+	if existing, found, err := txn.Get(sealSecretSaltPredicate, sealSecretSaltKey); err != nil {
+		_ = txn.Abort()
+		return nil, fmt.Errorf("loadOrCreateSalt: get: %w", err)
+	} else if found {
+		_ = txn.Abort()
+		return existing, nil
+	}
+
+	salt := make([]byte, sealSecretSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		_ = txn.Abort()
+		return nil, fmt.Errorf("loadOrCreateSalt: generate salt: %w", err)
+	}
+	if err := txn.Put(sealSecretSaltPredicate, sealSecretSaltKey, salt); err != nil {
+		_ = txn.Abort()
+		return nil, fmt.Errorf("loadOrCreateSalt: put: %w", err)
+	}
+	if err := txn.Commit(); err != nil {
+		return nil, fmt.Errorf("loadOrCreateSalt: commit: %w", err)
+	}
+	return salt, nil
+}
+
+// readSalt looks up the persisted salt under store's read lock, without
+// ever escalating to a write txn.
+func readSalt(store storage.Store) (salt []byte, found bool, err error) {
+	txn, err := store.BeginRead()
+	if err != nil {
+		return nil, false, fmt.Errorf("readSalt: BeginRead: %w", err)
+	}
+	salt, found, err = txn.Get(sealSecretSaltPredicate, sealSecretSaltKey)
+	_ = txn.Commit()
+	if err != nil {
+		return nil, false, fmt.Errorf("readSalt: get: %w", err)
+	}
+	return salt, found, nil
+}
+
+// deriveSealSecret derives two values from passphrase and salt via
+// HKDF-SHA256 (RFC 5869): secret, the material sealValue/unsealValue
+// combine with the KeyProvider's key into the actual AEAD key (see
+// deriveRecordKey), and verifier, a separate value Unseal compares a
+// candidate passphrase against so a wrong guess can be rejected without
+// ever comparing secret itself.
+func deriveSealSecret(passphrase string, salt []byte) (secret, verifier [sha256.Size]byte) {
+	prk := hkdfExtract(salt, []byte(passphrase))
+	copy(secret[:], hkdfExpand(prk, []byte("codenerd:sealed_store:key")))
+	copy(verifier[:], hkdfExpand(prk, []byte("codenerd:sealed_store:verify")))
+	return secret, verifier
+}
+
+// hkdfExtract is RFC 5869's HKDF-Extract(salt, ikm) = HMAC-Hash(salt, ikm).
+func hkdfExtract(salt, ikm []byte) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(ikm)
+	return mac.Sum(nil)
+}
+
+// hkdfExpand is RFC 5869's HKDF-Expand(prk, info) truncated to a single
+// HMAC block (32 bytes), which is all deriveSealSecret ever needs.
+func hkdfExpand(prk, info []byte) []byte {
+	mac := hmac.New(sha256.New, prk)
+	mac.Write(info)
+	mac.Write([]byte{0x01})
+	return mac.Sum(nil)
+}
+
+// SealedStore wraps a storage.Store, transparently AEAD-encrypting every
+// record whose predicate classifier.EncryptionRequired reports true,
+// before it reaches inner - and decrypting it back out on read. Records
+// for a predicate that isn't sensitive pass through unchanged.
+//
+// A SealedStore is constructed sealed: sensitive predicates can be written
+// (sealing happens on Put regardless of seal state, since sealValue only
+// needs keyProvider's key and recordSecret, both already known at
+// construction time) but not read back - Get and Iterate treat them as
+// absent/erroring - until Unseal(passphrase) succeeds. Non-sensitive
+// predicates, and therefore every ephemeral and derived query the kernel
+// serves, are unaffected by seal state: this is what lets RealKernel boot
+// and answer queries while sealed.
+type SealedStore struct {
+	inner        storage.Store
+	keyProvider  KeyProvider
+	classifier   Classifier
+	sealed       bool
+	salt         []byte            // persisted alongside inner's data; see loadOrCreateSalt
+	recordSecret [sha256.Size]byte // passphrase-derived; combined with keyProvider's key in every seal/unseal (see deriveRecordKey)
+	verifier     [sha256.Size]byte // passphrase-derived; what Unseal compares a candidate passphrase against
+}
+
+// NewSealedStore wraps inner, encrypting predicates classifier marks
+// EncryptionRequired with a key derived from both keyProvider's keys and
+// passphrase (see deriveSealSecret/deriveRecordKey) - so reading inner's
+// on-disk records plus knowing keyProvider's key source is not, on its
+// own, enough to decrypt them; the passphrase is also required. The store
+// starts sealed; Unseal(passphrase) unlocks reads of sensitive predicates
+// for the lifetime of the process (there is no re-seal-on-idle timer -
+// call Seal explicitly if that's needed).
+func NewSealedStore(inner storage.Store, keyProvider KeyProvider, classifier Classifier, passphrase string) (*SealedStore, error) {
+	salt, err := loadOrCreateSalt(inner)
+	if err != nil {
+		return nil, fmt.Errorf("NewSealedStore: %w", err)
+	}
+	secret, verifier := deriveSealSecret(passphrase, salt)
+	return &SealedStore{
+		inner:        inner,
+		keyProvider:  keyProvider,
+		classifier:   classifier,
+		sealed:       true,
+		salt:         salt,
+		recordSecret: secret,
+		verifier:     verifier,
+	}, nil
+}
+
+// Sealed reports whether sensitive predicates are currently unreadable.
+func (s *SealedStore) Sealed() bool {
+	return s.sealed
+}
+
+// Unseal unlocks reads of sensitive predicates if passphrase matches the
+// one SealedStore was constructed with, then rotates any record still
+// sealed under a retired key onto keyProvider's current active key (see
+// RotateKeys). passphrase is re-run through deriveSealSecret and compared
+// against the stored verifier - never against recordSecret itself - and
+// that comparison is constant-time to avoid leaking passphrase
+// length/prefix through timing.
+func (s *SealedStore) Unseal(passphrase string) error {
+	_, verifier := deriveSealSecret(passphrase, s.salt)
+	if subtle.ConstantTimeCompare(verifier[:], s.verifier[:]) != 1 {
+		return fmt.Errorf("sealed_store: incorrect passphrase")
+	}
+	s.sealed = false
+	if _, err := s.RotateKeys(); err != nil {
+		s.sealed = true
+		return fmt.Errorf("sealed_store: key rotation failed, store left sealed: %w", err)
+	}
+	return nil
+}
+
+// Seal re-locks sensitive predicates, requiring Unseal again to read them.
+func (s *SealedStore) Seal() {
+	s.sealed = true
+}
+
+// BeginRead implements storage.Store.
+func (s *SealedStore) BeginRead() (storage.Txn, error) {
+	txn, err := s.inner.BeginRead()
+	if err != nil {
+		return nil, err
+	}
+	return &sealedTxn{inner: txn, store: s}, nil
+}
+
+// BeginWrite implements storage.Store.
+func (s *SealedStore) BeginWrite() (storage.Txn, error) {
+	txn, err := s.inner.BeginWrite()
+	if err != nil {
+		return nil, err
+	}
+	return &sealedTxn{inner: txn, store: s}, nil
+}
+
+// Close implements storage.Store.
+func (s *SealedStore) Close() error {
+	return s.inner.Close()
+}
+
+// Metrics implements storage.Store.
+func (s *SealedStore) Metrics() storage.Metrics {
+	return s.inner.Metrics()
+}
+
+// RotateKeys re-seals every sensitive-predicate record whose key id is no
+// longer keyProvider.ActiveKeyID(), in a single write txn. Returns the
+// number of records re-sealed. Requires the store to be unsealed, since
+// re-sealing needs to decrypt the old record first.
+func (s *SealedStore) RotateKeys() (int, error) {
+	if s.sealed {
+		return 0, ErrSealed
+	}
+
+	txn, err := s.inner.BeginWrite()
+	if err != nil {
+		return 0, fmt.Errorf("RotateKeys: BeginWrite: %w", err)
+	}
+
+	type stale struct {
+		predicate, key string
+		plaintext      []byte
+	}
+	var toRotate []stale
+	activeKeyID := s.keyProvider.ActiveKeyID()
+
+	if err := txn.Iterate("", func(predicate, key string, raw []byte) error {
+		if !isSealedRecord(raw) {
+			return nil
+		}
+		plaintext, keyID, err := unsealValue(s.keyProvider, s.recordSecret[:], predicate, raw)
+		if err != nil {
+			return fmt.Errorf("RotateKeys: unseal %s/%s: %w", predicate, key, err)
+		}
+		if keyID == activeKeyID {
+			return nil
+		}
+		toRotate = append(toRotate, stale{predicate: predicate, key: key, plaintext: plaintext})
+		return nil
+	}); err != nil {
+		_ = txn.Abort()
+		return 0, err
+	}
+
+	for _, item := range toRotate {
+		framed, err := sealValue(s.keyProvider, s.recordSecret[:], item.predicate, item.plaintext)
+		if err != nil {
+			_ = txn.Abort()
+			return 0, fmt.Errorf("RotateKeys: reseal %s/%s: %w", item.predicate, item.key, err)
+		}
+		if err := txn.Put(item.predicate, item.key, framed); err != nil {
+			_ = txn.Abort()
+			return 0, fmt.Errorf("RotateKeys: put %s/%s: %w", item.predicate, item.key, err)
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return 0, fmt.Errorf("RotateKeys: commit: %w", err)
+	}
+	return len(toRotate), nil
+}
+
+// sealedTxn wraps a storage.Txn, encrypting/decrypting values for
+// predicates the attached SealedStore's classifier marks sensitive.
+type sealedTxn struct {
+	inner storage.Txn
+	store *SealedStore
+}
+
+// Get implements storage.Txn.
+func (t *sealedTxn) Get(predicate, key string) ([]byte, bool, error) {
+	raw, found, err := t.inner.Get(predicate, key)
+	if err != nil || !found {
+		return raw, found, err
+	}
+	if !isSealedRecord(raw) {
+		return raw, true, nil
+	}
+	if t.store.sealed {
+		return nil, true, ErrSealed
+	}
+	plaintext, _, err := unsealValue(t.store.keyProvider, t.store.recordSecret[:], predicate, raw)
+	if err != nil {
+		return nil, true, err
+	}
+	return plaintext, true, nil
+}
+
+// Put implements storage.Txn.
+func (t *sealedTxn) Put(predicate, key string, value []byte) error {
+	if !t.store.classifier.EncryptionRequired(predicate) {
+		return t.inner.Put(predicate, key, value)
+	}
+	framed, err := sealValue(t.store.keyProvider, t.store.recordSecret[:], predicate, value)
+	if err != nil {
+		return err
+	}
+	return t.inner.Put(predicate, key, framed)
+}
+
+// Delete implements storage.Txn.
+func (t *sealedTxn) Delete(predicate, key string) error {
+	return t.inner.Delete(predicate, key)
+}
+
+// Iterate implements storage.Txn. A sensitive predicate's records are
+// silently skipped while the store is sealed, rather than erroring the
+// whole Iterate call - this is what lets LoadPersistentFacts load every
+// non-sensitive persistent fact while sealed, sensitive ones simply
+// absent until Unseal.
+func (t *sealedTxn) Iterate(predicatePrefix string, fn func(predicate, key string, value []byte) error) error {
+	return t.inner.Iterate(predicatePrefix, func(predicate, key string, raw []byte) error {
+		if !isSealedRecord(raw) {
+			return fn(predicate, key, raw)
+		}
+		if t.store.sealed {
+			return nil
+		}
+		plaintext, _, err := unsealValue(t.store.keyProvider, t.store.recordSecret[:], predicate, raw)
+		if err != nil {
+			return err
+		}
+		return fn(predicate, key, plaintext)
+	})
+}
+
+// Commit implements storage.Txn.
+func (t *sealedTxn) Commit() error {
+	return t.inner.Commit()
+}
+
+// Abort implements storage.Txn.
+func (t *sealedTxn) Abort() error {
+	return t.inner.Abort()
+}
+
+// Unseal unlocks reads of sensitive persistent predicates, if the kernel's
+// attached fact store is a *SealedStore. It's the kernel-level entry point
+// for the sealed/unsealed boot mode: RealKernel can LoadPersistentFacts,
+// Assert, Retract, and answer ephemeral/derived queries while sealed -
+// only sensitive persistent predicates are unreadable until this
+// succeeds. Returns an error if no SealedStore is attached, or if
+// passphrase is wrong.
+func (k *RealKernel) Unseal(passphrase string) error {
+	k.mu.RLock()
+	store := k.persistentStore
+	k.mu.RUnlock()
+
+	sealed, ok := store.(*SealedStore)
+	if !ok {
+		return fmt.Errorf("Unseal: no SealedStore attached to this kernel")
+	}
+	return sealed.Unseal(passphrase)
+}
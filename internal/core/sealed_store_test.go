@@ -0,0 +1,292 @@
+package core
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"codenerd/internal/core/storage"
+)
+
+func newTestKeyProvider(t *testing.T, keyID string) *EnvKeyProvider {
+	t.Helper()
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	envVar := "CODENERD_TEST_SEALED_STORE_KEY"
+	t.Setenv(envVar, base64.StdEncoding.EncodeToString(key))
+	provider, err := NewEnvKeyProvider(envVar, keyID)
+	if err != nil {
+		t.Fatalf("NewEnvKeyProvider() error = %v", err)
+	}
+	return provider
+}
+
+func newSensitiveClassifier(predicate string) *MapClassifier {
+	c := NewMapClassifier()
+	c.RegisterSensitive(predicate)
+	return c
+}
+
+func newTestSealedStore(t *testing.T, inner storage.Store, keyProvider KeyProvider, classifier Classifier, passphrase string) *SealedStore {
+	t.Helper()
+	store, err := NewSealedStore(inner, keyProvider, classifier, passphrase)
+	if err != nil {
+		t.Fatalf("NewSealedStore() error = %v", err)
+	}
+	return store
+}
+
+func TestSealedStore_NonSensitivePredicatePassesThroughUnsealed(t *testing.T) {
+	inner := storage.NewInMemoryStore()
+	store := newTestSealedStore(t, inner, newTestKeyProvider(t, "k1"), newSensitiveClassifier("secret_token"), "hunter2")
+
+	txn, err := store.BeginWrite()
+	if err != nil {
+		t.Fatalf("BeginWrite() error = %v", err)
+	}
+	if err := txn.Put("goal", "g1", []byte("ship it")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	readTxn, err := store.BeginRead()
+	if err != nil {
+		t.Fatalf("BeginRead() error = %v", err)
+	}
+	defer readTxn.Commit()
+	value, found, err := readTxn.Get("goal", "g1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !found || string(value) != "ship it" {
+		t.Errorf("Get() = (%q, %v), want (\"ship it\", true)", value, found)
+	}
+}
+
+func TestSealedStore_SensitivePredicateUnreadableWhileSealed(t *testing.T) {
+	inner := storage.NewInMemoryStore()
+	classifier := newSensitiveClassifier("secret_token")
+	store := newTestSealedStore(t, inner, newTestKeyProvider(t, "k1"), classifier, "hunter2")
+
+	writeTxn, err := store.BeginWrite()
+	if err != nil {
+		t.Fatalf("BeginWrite() error = %v", err)
+	}
+	if err := writeTxn.Put("secret_token", "s1", []byte("sk-abc123")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := writeTxn.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	rawTxn, err := inner.BeginRead()
+	if err != nil {
+		t.Fatalf("inner.BeginRead() error = %v", err)
+	}
+	raw, found, err := rawTxn.Get("secret_token", "s1")
+	rawTxn.Commit()
+	if err != nil || !found {
+		t.Fatalf("inner Get() = (_, %v, %v)", found, err)
+	}
+	if !isSealedRecord(raw) || bytesContains(raw, []byte("sk-abc123")) {
+		t.Error("expected the underlying store to hold an encrypted record, not plaintext")
+	}
+
+	readTxn, err := store.BeginRead()
+	if err != nil {
+		t.Fatalf("BeginRead() error = %v", err)
+	}
+	defer readTxn.Commit()
+	if _, _, err := readTxn.Get("secret_token", "s1"); err != ErrSealed {
+		t.Errorf("Get() on sealed store error = %v, want ErrSealed", err)
+	}
+
+	var iterated bool
+	if err := readTxn.Iterate("secret_token", func(_, _ string, _ []byte) error {
+		iterated = true
+		return nil
+	}); err != nil {
+		t.Fatalf("Iterate() error = %v", err)
+	}
+	if iterated {
+		t.Error("expected Iterate to skip the sensitive record while sealed")
+	}
+}
+
+func TestSealedStore_UnsealRevealsSensitiveRecords(t *testing.T) {
+	inner := storage.NewInMemoryStore()
+	classifier := newSensitiveClassifier("secret_token")
+	store := newTestSealedStore(t, inner, newTestKeyProvider(t, "k1"), classifier, "hunter2")
+
+	writeTxn, _ := store.BeginWrite()
+	writeTxn.Put("secret_token", "s1", []byte("sk-abc123"))
+	writeTxn.Commit()
+
+	if err := store.Unseal("wrong passphrase"); err == nil {
+		t.Fatal("expected Unseal with the wrong passphrase to fail")
+	}
+	if store.Sealed() != true {
+		t.Fatal("expected store to remain sealed after a failed Unseal")
+	}
+
+	if err := store.Unseal("hunter2"); err != nil {
+		t.Fatalf("Unseal() error = %v", err)
+	}
+	if store.Sealed() {
+		t.Fatal("expected store to be unsealed")
+	}
+
+	readTxn, _ := store.BeginRead()
+	defer readTxn.Commit()
+	value, found, err := readTxn.Get("secret_token", "s1")
+	if err != nil || !found || string(value) != "sk-abc123" {
+		t.Errorf("Get() = (%q, %v, %v), want (\"sk-abc123\", true, nil)", value, found, err)
+	}
+}
+
+func TestSealedStore_PassphraseIsCryptographicallyRequired(t *testing.T) {
+	inner := storage.NewInMemoryStore()
+	keyProvider := newTestKeyProvider(t, "k1")
+	classifier := newSensitiveClassifier("secret_token")
+	store := newTestSealedStore(t, inner, keyProvider, classifier, "hunter2")
+	if err := store.Unseal("hunter2"); err != nil {
+		t.Fatalf("Unseal() error = %v", err)
+	}
+
+	writeTxn, _ := store.BeginWrite()
+	writeTxn.Put("secret_token", "s1", []byte("sk-abc123"))
+	writeTxn.Commit()
+
+	rawTxn, _ := inner.BeginRead()
+	raw, found, _ := rawTxn.Get("secret_token", "s1")
+	rawTxn.Commit()
+	if !found {
+		t.Fatal("expected the record to exist in the underlying store")
+	}
+
+	// Someone with only the KeyProvider's key (no knowledge of the
+	// passphrase) cannot derive the record's AEAD key: passing the
+	// provider key in place of the passphrase-derived secret must fail to
+	// decrypt, proving the passphrase is load-bearing rather than just an
+	// in-process access gate.
+	providerKey, err := keyProvider.Key(keyProvider.ActiveKeyID())
+	if err != nil {
+		t.Fatalf("Key() error = %v", err)
+	}
+	if _, _, err := unsealValue(keyProvider, providerKey, "secret_token", raw); err == nil {
+		t.Error("expected decryption to fail without the real passphrase-derived secret")
+	}
+
+	// A SealedStore opened on the same on-disk store with the wrong
+	// passphrase must fail to Unseal.
+	wrongStore := newTestSealedStore(t, inner, keyProvider, classifier, "wrong passphrase")
+	if err := wrongStore.Unseal("wrong passphrase"); err == nil {
+		t.Fatal("expected Unseal with the wrong passphrase to fail")
+	}
+}
+
+// multiKeyProvider is a test-only KeyProvider that can hold more than one
+// key, so rotation (sealing under an old key, then advancing to a new
+// active key) can be exercised directly.
+type multiKeyProvider struct {
+	active string
+	keys   map[string][]byte
+}
+
+func (p *multiKeyProvider) ActiveKeyID() string { return p.active }
+func (p *multiKeyProvider) Key(keyID string) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("multiKeyProvider: unknown key id %q", keyID)
+	}
+	return key, nil
+}
+
+func TestSealedStore_RotateKeysReencryptsUnderActiveKey(t *testing.T) {
+	inner := storage.NewInMemoryStore()
+	classifier := newSensitiveClassifier("secret_token")
+	keys := &multiKeyProvider{active: "k1", keys: map[string][]byte{
+		"k1": make([]byte, 32),
+		"k2": append(make([]byte, 31), 1),
+	}}
+	store := newTestSealedStore(t, inner, keys, classifier, "hunter2")
+	store.Unseal("hunter2")
+
+	writeTxn, _ := store.BeginWrite()
+	writeTxn.Put("secret_token", "s1", []byte("sk-abc123"))
+	writeTxn.Commit()
+
+	// Advance the active key without re-sealing the existing record.
+	keys.active = "k2"
+
+	rotated, err := store.RotateKeys()
+	if err != nil {
+		t.Fatalf("RotateKeys() error = %v", err)
+	}
+	if rotated != 1 {
+		t.Fatalf("expected 1 record rotated onto the new active key, got %d", rotated)
+	}
+
+	readTxn, _ := store.BeginRead()
+	defer readTxn.Commit()
+	value, found, err := readTxn.Get("secret_token", "s1")
+	if err != nil || !found || string(value) != "sk-abc123" {
+		t.Fatalf("Get() after rotation = (%q, %v, %v)", value, found, err)
+	}
+
+	if rotatedAgain, err := store.RotateKeys(); err != nil || rotatedAgain != 0 {
+		t.Errorf("RotateKeys() after rotation = (%d, %v), want (0, nil)", rotatedAgain, err)
+	}
+}
+
+func TestSealedStore_RotateKeysFailsWhileSealed(t *testing.T) {
+	inner := storage.NewInMemoryStore()
+	classifier := newSensitiveClassifier("secret_token")
+	store := newTestSealedStore(t, inner, newTestKeyProvider(t, "k1"), classifier, "hunter2")
+
+	if _, err := store.RotateKeys(); err != ErrSealed {
+		t.Errorf("RotateKeys() while sealed error = %v, want ErrSealed", err)
+	}
+}
+
+func TestRealKernel_UnsealWithoutSealedStoreErrors(t *testing.T) {
+	kernel := newBootstrapTestKernel(t)
+	if err := kernel.Unseal("anything"); err == nil {
+		t.Error("expected Unseal() to error when no SealedStore is attached")
+	}
+}
+
+func TestRealKernel_UnsealDelegatesToAttachedSealedStore(t *testing.T) {
+	kernel := newBootstrapTestKernel(t)
+	inner := storage.NewInMemoryStore()
+	classifier := newSensitiveClassifier("secret_token")
+	sealed := newTestSealedStore(t, inner, newTestKeyProvider(t, "k1"), classifier, "hunter2")
+	kernel.SetFactStore(sealed)
+
+	if err := kernel.Unseal("hunter2"); err != nil {
+		t.Fatalf("Unseal() error = %v", err)
+	}
+	if sealed.Sealed() {
+		t.Error("expected the attached SealedStore to be unsealed")
+	}
+}
+
+func bytesContains(haystack, needle []byte) bool {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,317 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"codenerd/internal/logging"
+)
+
+// Config configures a BadgerStore.
+type Config struct {
+	// Path is the default partition's directory.
+	Path string
+	// Partitions maps a predicate name to a distinct subdirectory name
+	// under Path, so that predicate's facts live in their own Badger
+	// instance instead of the default keyspace. See PartitionConfig.
+	Partitions map[string]string
+}
+
+// BadgerStore is the durable Store backing persistent predicates across
+// restarts, mirroring BadgerTransactionStore's structure. Keys are
+// partitioned by predicate (within a single Badger instance, every key is
+// prefixed "<predicate>/"), and predicates named in Config.Partitions get
+// their own Badger instance entirely.
+//
+// BadgerStore enforces one active write txn at a time via mu, an explicit
+// serialization on top of Badger's own optimistic concurrency control -
+// matching the locking regime this package's InMemoryStore uses, rather
+// than relying on Badger's conflict-detection-at-commit behavior. A write
+// txn that touches keys across two partitions commits each partition's
+// Badger transaction independently; there is no cross-partition atomicity,
+// which is the tradeoff PartitionConfig documents.
+type BadgerStore struct {
+	mu         sync.RWMutex
+	cfg        Config
+	defaultDB  *badger.DB
+	partitions map[string]*badger.DB // partition name -> db
+	metrics    Metrics
+}
+
+// NewBadgerStore opens (or creates) a Badger-backed store per cfg.
+func NewBadgerStore(cfg Config) (*BadgerStore, error) {
+	timer := logging.StartTimer(logging.CategoryKernel, "NewBadgerStore")
+	defer timer.Stop()
+
+	defaultDB, err := openBadger(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open default partition at %s: %w", cfg.Path, err)
+	}
+
+	s := &BadgerStore{cfg: cfg, defaultDB: defaultDB, partitions: make(map[string]*badger.DB)}
+
+	opened := make(map[string]bool)
+	for _, partition := range cfg.Partitions {
+		if opened[partition] {
+			continue
+		}
+		path := cfg.Path + "/" + partition
+		db, err := openBadger(path)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("open partition %q at %s: %w", partition, path, err)
+		}
+		s.partitions[partition] = db
+		opened[partition] = true
+	}
+
+	logging.KernelDebug("Opened Badger fact store at %s (%d partitions)", cfg.Path, len(s.partitions))
+	return s, nil
+}
+
+func openBadger(path string) (*badger.DB, error) {
+	opts := badger.DefaultOptions(path)
+	opts.Logger = nil // Badger's default logger is noisy at Info level; we log our own events.
+	return badger.Open(opts)
+}
+
+// dbFor returns the Badger instance predicate's keys live in.
+func (s *BadgerStore) dbFor(predicate string) *badger.DB {
+	if partition := s.cfg.partitionFor(predicate); partition != "" {
+		if db, ok := s.partitions[partition]; ok {
+			return db
+		}
+	}
+	return s.defaultDB
+}
+
+func badgerFactKey(predicate, key string) []byte {
+	return []byte(predicate + "/" + key)
+}
+
+// BeginRead acquires the store's read lock, held until the returned txn is
+// Committed or Aborted.
+func (s *BadgerStore) BeginRead() (Txn, error) {
+	start := time.Now()
+	s.mu.RLock()
+	atomic.AddInt64(&s.metrics.Reads, 1)
+	storeMetricDuration(&s.metrics.ReadLatency, time.Since(start))
+	return &badgerTxn{store: s, write: false, dbTxns: make(map[*badger.DB]*badger.Txn)}, nil
+}
+
+// BeginWrite acquires the store's write lock, held for the write txn's
+// entire lifetime.
+func (s *BadgerStore) BeginWrite() (Txn, error) {
+	start := time.Now()
+	s.mu.Lock()
+	atomic.AddInt64(&s.metrics.Writes, 1)
+	storeMetricDuration(&s.metrics.WriteLatency, time.Since(start))
+	return &badgerTxn{store: s, write: true, dbTxns: make(map[*badger.DB]*badger.Txn)}, nil
+}
+
+// Close releases every partition's Badger database handle.
+func (s *BadgerStore) Close() error {
+	var firstErr error
+	if err := s.defaultDB.Close(); err != nil {
+		firstErr = err
+	}
+	for _, db := range s.partitions {
+		if err := db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Metrics returns a snapshot of this store's operation counts, including
+// per-partition on-disk size (LSM + value log bytes, per badger.DB.Size).
+func (s *BadgerStore) Metrics() Metrics {
+	m := Metrics{
+		Reads:         atomic.LoadInt64(&s.metrics.Reads),
+		Writes:        atomic.LoadInt64(&s.metrics.Writes),
+		Commits:       atomic.LoadInt64(&s.metrics.Commits),
+		Aborts:        atomic.LoadInt64(&s.metrics.Aborts),
+		Gets:          atomic.LoadInt64(&s.metrics.Gets),
+		Puts:          atomic.LoadInt64(&s.metrics.Puts),
+		Deletes:       atomic.LoadInt64(&s.metrics.Deletes),
+		Errors:        atomic.LoadInt64(&s.metrics.Errors),
+		ReadLatency:   time.Duration(atomic.LoadInt64((*int64)(&s.metrics.ReadLatency))),
+		WriteLatency:  time.Duration(atomic.LoadInt64((*int64)(&s.metrics.WriteLatency))),
+		CommitLatency: time.Duration(atomic.LoadInt64((*int64)(&s.metrics.CommitLatency))),
+	}
+
+	lsm, vlog := s.defaultDB.Size()
+	m.BytesOnDisk = lsm + vlog
+	if len(s.partitions) > 0 {
+		m.PartitionSizes = make(map[string]int64, len(s.partitions))
+		for name, db := range s.partitions {
+			pLsm, pVlog := db.Size()
+			m.PartitionSizes[name] = pLsm + pVlog
+			m.BytesOnDisk += pLsm + pVlog
+		}
+	}
+	return m
+}
+
+// badgerTxn is BadgerStore's Txn. It lazily opens one underlying
+// badger.Txn per Badger instance it touches (the default db, plus any
+// partition dbs), so a txn spanning partitioned and unpartitioned
+// predicates works transparently.
+type badgerTxn struct {
+	store  *BadgerStore
+	write  bool
+	done   bool
+	dbTxns map[*badger.DB]*badger.Txn
+}
+
+func (t *badgerTxn) txnFor(db *badger.DB) *badger.Txn {
+	if dbTxn, ok := t.dbTxns[db]; ok {
+		return dbTxn
+	}
+	dbTxn := db.NewTransaction(t.write)
+	t.dbTxns[db] = dbTxn
+	return dbTxn
+}
+
+func (t *badgerTxn) Get(predicate, key string) ([]byte, bool, error) {
+	atomic.AddInt64(&t.store.metrics.Gets, 1)
+
+	dbTxn := t.txnFor(t.store.dbFor(predicate))
+	item, err := dbTxn.Get(badgerFactKey(predicate, key))
+	if err == badger.ErrKeyNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		atomic.AddInt64(&t.store.metrics.Errors, 1)
+		return nil, false, fmt.Errorf("get %s/%s: %w", predicate, key, err)
+	}
+	val, err := item.ValueCopy(nil)
+	if err != nil {
+		atomic.AddInt64(&t.store.metrics.Errors, 1)
+		return nil, false, err
+	}
+	return val, true, nil
+}
+
+func (t *badgerTxn) Put(predicate, key string, value []byte) error {
+	if !t.write {
+		return errReadOnlyTxn
+	}
+	atomic.AddInt64(&t.store.metrics.Puts, 1)
+
+	dbTxn := t.txnFor(t.store.dbFor(predicate))
+	if err := dbTxn.Set(badgerFactKey(predicate, key), value); err != nil {
+		atomic.AddInt64(&t.store.metrics.Errors, 1)
+		return fmt.Errorf("put %s/%s: %w", predicate, key, err)
+	}
+	return nil
+}
+
+func (t *badgerTxn) Delete(predicate, key string) error {
+	if !t.write {
+		return errReadOnlyTxn
+	}
+	atomic.AddInt64(&t.store.metrics.Deletes, 1)
+
+	dbTxn := t.txnFor(t.store.dbFor(predicate))
+	if err := dbTxn.Delete(badgerFactKey(predicate, key)); err != nil {
+		atomic.AddInt64(&t.store.metrics.Errors, 1)
+		return fmt.Errorf("delete %s/%s: %w", predicate, key, err)
+	}
+	return nil
+}
+
+// Iterate scans every Badger instance this txn might touch (the default db
+// plus every configured partition) for keys whose predicate matches
+// predicatePrefix. Partitions not yet opened by this txn are opened for
+// the scan in this txn's own mode (read or write).
+func (t *badgerTxn) Iterate(predicatePrefix string, fn func(predicate, key string, value []byte) error) error {
+	dbs := map[*badger.DB]bool{t.store.defaultDB: true}
+	for _, db := range t.store.partitions {
+		dbs[db] = true
+	}
+
+	for db := range dbs {
+		dbTxn := t.txnFor(db)
+		it := dbTxn.NewIterator(badger.DefaultIteratorOptions)
+		err := func() error {
+			defer it.Close()
+			for it.Rewind(); it.Valid(); it.Next() {
+				item := it.Item()
+				predicate, key, ok := splitFactKey(string(item.Key()))
+				if !ok || !hasPrefix(predicate, predicatePrefix) {
+					continue
+				}
+				val, err := item.ValueCopy(nil)
+				if err != nil {
+					return err
+				}
+				if err := fn(predicate, key, val); err != nil {
+					return err
+				}
+			}
+			return nil
+		}()
+		if err != nil {
+			atomic.AddInt64(&t.store.metrics.Errors, 1)
+			return err
+		}
+	}
+	return nil
+}
+
+// splitFactKey reverses badgerFactKey.
+func splitFactKey(raw string) (predicate, key string, ok bool) {
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '/' {
+			return raw[:i], raw[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+func (t *badgerTxn) Commit() error {
+	if t.done {
+		return errTxnAlreadyClosed
+	}
+	t.done = true
+	defer func() {
+		if t.write {
+			t.store.mu.Unlock()
+		} else {
+			t.store.mu.RUnlock()
+		}
+	}()
+
+	start := time.Now()
+	for _, dbTxn := range t.dbTxns {
+		if err := dbTxn.Commit(); err != nil {
+			atomic.AddInt64(&t.store.metrics.Errors, 1)
+			return fmt.Errorf("commit: %w", err)
+		}
+	}
+	atomic.AddInt64(&t.store.metrics.Commits, 1)
+	storeMetricDuration(&t.store.metrics.CommitLatency, time.Since(start))
+	return nil
+}
+
+func (t *badgerTxn) Abort() error {
+	if t.done {
+		return errTxnAlreadyClosed
+	}
+	t.done = true
+	for _, dbTxn := range t.dbTxns {
+		dbTxn.Discard()
+	}
+	if t.write {
+		t.store.mu.Unlock()
+	} else {
+		t.store.mu.RUnlock()
+	}
+	atomic.AddInt64(&t.store.metrics.Aborts, 1)
+	return nil
+}
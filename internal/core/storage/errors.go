@@ -0,0 +1,8 @@
+package storage
+
+import "errors"
+
+var (
+	errReadOnlyTxn      = errors.New("storage: write method called on a read-only txn")
+	errTxnAlreadyClosed = errors.New("storage: txn already committed or aborted")
+)
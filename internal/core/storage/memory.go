@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InMemoryStore is the default Store: a map guarded by a single RWMutex,
+// with no persistence across restarts. Good enough for ephemeral sessions
+// and tests; NewBadgerStore is the durable alternative.
+type InMemoryStore struct {
+	mu      sync.RWMutex
+	data    map[string]map[string][]byte // predicate -> key -> value
+	metrics Metrics
+}
+
+// NewInMemoryStore creates an empty in-memory store.
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{data: make(map[string]map[string][]byte)}
+}
+
+// BeginRead acquires the store's read lock, held until the returned txn is
+// Committed or Aborted.
+func (s *InMemoryStore) BeginRead() (Txn, error) {
+	start := time.Now()
+	s.mu.RLock()
+	atomic.AddInt64(&s.metrics.Reads, 1)
+	storeMetricDuration(&s.metrics.ReadLatency, time.Since(start))
+	return &memoryTxn{store: s, write: false}, nil
+}
+
+// BeginWrite acquires the store's write lock - exclusive of every reader
+// and any other writer - held for the write txn's entire lifetime, not
+// just its Commit call, so no reader can observe a partially-applied
+// transaction.
+func (s *InMemoryStore) BeginWrite() (Txn, error) {
+	start := time.Now()
+	s.mu.Lock()
+	atomic.AddInt64(&s.metrics.Writes, 1)
+	storeMetricDuration(&s.metrics.WriteLatency, time.Since(start))
+	return &memoryTxn{store: s, write: true, buffer: make(map[string]map[string][]byte), deletes: make(map[string]map[string]bool)}, nil
+}
+
+// Close is a no-op; InMemoryStore holds nothing that needs releasing.
+func (s *InMemoryStore) Close() error {
+	return nil
+}
+
+// Metrics returns a snapshot of this store's operation counts.
+func (s *InMemoryStore) Metrics() Metrics {
+	return Metrics{
+		Reads:         atomic.LoadInt64(&s.metrics.Reads),
+		Writes:        atomic.LoadInt64(&s.metrics.Writes),
+		Commits:       atomic.LoadInt64(&s.metrics.Commits),
+		Aborts:        atomic.LoadInt64(&s.metrics.Aborts),
+		Gets:          atomic.LoadInt64(&s.metrics.Gets),
+		Puts:          atomic.LoadInt64(&s.metrics.Puts),
+		Deletes:       atomic.LoadInt64(&s.metrics.Deletes),
+		Errors:        atomic.LoadInt64(&s.metrics.Errors),
+		ReadLatency:   time.Duration(atomic.LoadInt64((*int64)(&s.metrics.ReadLatency))),
+		WriteLatency:  time.Duration(atomic.LoadInt64((*int64)(&s.metrics.WriteLatency))),
+		CommitLatency: time.Duration(atomic.LoadInt64((*int64)(&s.metrics.CommitLatency))),
+	}
+}
+
+// storeMetricDuration stores d's nanoseconds into dst via an atomic store,
+// since time.Duration is just an int64 underneath.
+func storeMetricDuration(dst *time.Duration, d time.Duration) {
+	atomic.StoreInt64((*int64)(dst), int64(d))
+}
+
+// memoryTxn is InMemoryStore's Txn. A read txn reads straight through to
+// store.data (safe: BeginRead holds the store's read lock for the txn's
+// whole lifetime). A write txn buffers Put/Delete into buffer/deletes and
+// only applies them to store.data on Commit.
+type memoryTxn struct {
+	store   *InMemoryStore
+	write   bool
+	done    bool
+	buffer  map[string]map[string][]byte // predicate -> key -> value, pending writes
+	deletes map[string]map[string]bool   // predicate -> key -> pending delete
+}
+
+func (t *memoryTxn) Get(predicate, key string) ([]byte, bool, error) {
+	atomic.AddInt64(&t.store.metrics.Gets, 1)
+
+	if t.write {
+		if t.deletes[predicate] != nil && t.deletes[predicate][key] {
+			return nil, false, nil
+		}
+		if val, ok := t.buffer[predicate][key]; ok {
+			return val, true, nil
+		}
+	}
+	val, ok := t.store.data[predicate][key]
+	return val, ok, nil
+}
+
+func (t *memoryTxn) Put(predicate, key string, value []byte) error {
+	if !t.write {
+		return errReadOnlyTxn
+	}
+	atomic.AddInt64(&t.store.metrics.Puts, 1)
+
+	if t.buffer[predicate] == nil {
+		t.buffer[predicate] = make(map[string][]byte)
+	}
+	t.buffer[predicate][key] = value
+	if t.deletes[predicate] != nil {
+		delete(t.deletes[predicate], key)
+	}
+	return nil
+}
+
+func (t *memoryTxn) Delete(predicate, key string) error {
+	if !t.write {
+		return errReadOnlyTxn
+	}
+	atomic.AddInt64(&t.store.metrics.Deletes, 1)
+
+	if t.deletes[predicate] == nil {
+		t.deletes[predicate] = make(map[string]bool)
+	}
+	t.deletes[predicate][key] = true
+	if t.buffer[predicate] != nil {
+		delete(t.buffer[predicate], key)
+	}
+	return nil
+}
+
+func (t *memoryTxn) Iterate(predicatePrefix string, fn func(predicate, key string, value []byte) error) error {
+	seen := make(map[string]map[string]bool)
+	visit := func(predicate string, keys map[string][]byte) error {
+		for key, value := range keys {
+			if seen[predicate] != nil && seen[predicate][key] {
+				continue
+			}
+			if seen[predicate] == nil {
+				seen[predicate] = make(map[string]bool)
+			}
+			seen[predicate][key] = true
+			if err := fn(predicate, key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if t.write {
+		for predicate, keys := range t.buffer {
+			if !hasPrefix(predicate, predicatePrefix) {
+				continue
+			}
+			if err := visit(predicate, keys); err != nil {
+				return err
+			}
+		}
+	}
+	for predicate, keys := range t.store.data {
+		if !hasPrefix(predicate, predicatePrefix) {
+			continue
+		}
+		filtered := make(map[string][]byte, len(keys))
+		for key, value := range keys {
+			if t.write && t.deletes[predicate] != nil && t.deletes[predicate][key] {
+				continue
+			}
+			filtered[key] = value
+		}
+		if err := visit(predicate, filtered); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *memoryTxn) Commit() error {
+	if t.done {
+		return errTxnAlreadyClosed
+	}
+	t.done = true
+	defer func() {
+		if t.write {
+			t.store.mu.Unlock()
+		} else {
+			t.store.mu.RUnlock()
+		}
+	}()
+
+	start := time.Now()
+	if t.write {
+		for predicate, keys := range t.buffer {
+			if t.store.data[predicate] == nil {
+				t.store.data[predicate] = make(map[string][]byte)
+			}
+			for key, value := range keys {
+				t.store.data[predicate][key] = value
+			}
+		}
+		for predicate, keys := range t.deletes {
+			for key := range keys {
+				delete(t.store.data[predicate], key)
+			}
+		}
+	}
+	atomic.AddInt64(&t.store.metrics.Commits, 1)
+	storeMetricDuration(&t.store.metrics.CommitLatency, time.Since(start))
+	return nil
+}
+
+func (t *memoryTxn) Abort() error {
+	if t.done {
+		return errTxnAlreadyClosed
+	}
+	t.done = true
+	if t.write {
+		t.store.mu.Unlock()
+	} else {
+		t.store.mu.RUnlock()
+	}
+	atomic.AddInt64(&t.store.metrics.Aborts, 1)
+	return nil
+}
+
+// hasPrefix reports whether predicate equals or starts with prefix, with
+// an empty prefix matching everything.
+func hasPrefix(predicate, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	if len(predicate) < len(prefix) {
+		return false
+	}
+	return predicate[:len(prefix)] == prefix
+}
@@ -0,0 +1,104 @@
+// Package storage provides the pluggable, transactional fact-storage
+// backend behind the kernel's persistent predicates: the on-disk half of
+// the ephemeral/persistent/derived split in core.FactCategory, which only
+// carries the decision (ShouldLoadFromDisk/ShouldPersistToDisk) and leaves
+// the actual storage to this package.
+package storage
+
+import "time"
+
+// Txn is a single read or write transaction against a Store. A read txn
+// sees a consistent snapshot for its lifetime; a write txn buffers its
+// Put/Delete calls until Commit, and Abort discards them with no effect on
+// the store.
+type Txn interface {
+	// Get looks up key within predicate's keyspace.
+	Get(predicate, key string) (value []byte, found bool, err error)
+	// Put writes value for key within predicate's keyspace. Valid only on a
+	// write txn.
+	Put(predicate, key string, value []byte) error
+	// Delete removes key from predicate's keyspace, if present. Valid only
+	// on a write txn.
+	Delete(predicate, key string) error
+	// Iterate calls fn for every (predicate, key, value) whose predicate
+	// equals or starts with predicatePrefix, stopping early if fn returns
+	// an error.
+	Iterate(predicatePrefix string, fn func(predicate, key string, value []byte) error) error
+	// Commit applies a write txn's buffered changes atomically and releases
+	// the store's write lock; Commit on a read txn just releases the read
+	// lock. Commit (or Abort) must be called exactly once per txn.
+	Commit() error
+	// Abort discards a write txn's buffered changes (or just releases a
+	// read txn's lock) without touching the store.
+	Abort() error
+}
+
+// Store is a pluggable fact-storage backend. Implementations must enforce
+// the same locking regime: any number of concurrent BeginRead txns, or a
+// single BeginWrite txn that excludes every reader and every other writer -
+// the regime OPA's storage package uses for its in-memory and disk
+// (badger) backends.
+type Store interface {
+	// BeginRead starts a read-only txn, blocking until no write txn is
+	// active.
+	BeginRead() (Txn, error)
+	// BeginWrite starts a write txn, blocking until no read or write txn is
+	// active.
+	BeginWrite() (Txn, error)
+	// Close releases any resources (e.g. open file handles) the store
+	// holds. The store must not be used afterward.
+	Close() error
+	// Metrics returns a snapshot of this store's operation counters.
+	Metrics() Metrics
+}
+
+// Metrics is a snapshot of a Store's operation counts, latencies, and
+// on-disk footprint, mirroring TransactionStoreMetrics/APISchedulerMetrics's
+// snapshot style elsewhere in package core.
+type Metrics struct {
+	Reads   int64
+	Writes  int64
+	Commits int64
+	Aborts  int64
+	Gets    int64
+	Puts    int64
+	Deletes int64
+	Errors  int64
+
+	// ReadLatency/WriteLatency/CommitLatency are the most recently observed
+	// durations for each operation kind - not an average, just the last
+	// sample, which is enough to notice a backend has started stalling
+	// without the cost of maintaining a running histogram.
+	ReadLatency   time.Duration
+	WriteLatency  time.Duration
+	CommitLatency time.Duration
+
+	// BytesOnDisk is 0 for a non-durable Store (e.g. InMemoryStore).
+	BytesOnDisk int64
+	// PartitionSizes reports approximate on-disk bytes per predicate
+	// partition, for stores configured with PartitionConfig. Empty for
+	// stores with no partitioning configured.
+	PartitionSizes map[string]int64
+}
+
+// PartitionConfig assigns predicates to their own keyspace instead of the
+// store's default one, so a hot predicate (high write volume, or one an
+// operator wants to inspect/back up independently) doesn't share I/O or
+// compaction with everything else. Partitions maps a predicate name to a
+// partition name; predicates with no entry use the default partition.
+// Partitioning trades cross-partition atomicity for this isolation: a
+// write txn that touches keys in two different partitions commits each
+// partition independently, not as one atomic unit - see BadgerStore's doc
+// comment.
+type PartitionConfig struct {
+	Partitions map[string]string
+}
+
+// partitionFor returns the partition name predicate is assigned to, or ""
+// for the default partition.
+func (c PartitionConfig) partitionFor(predicate string) string {
+	if c.Partitions == nil {
+		return ""
+	}
+	return c.Partitions[predicate]
+}
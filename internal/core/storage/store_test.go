@@ -0,0 +1,143 @@
+package storage
+
+import "testing"
+
+func TestInMemoryStore_PutGet(t *testing.T) {
+	store := NewInMemoryStore()
+
+	wtxn, err := store.BeginWrite()
+	if err != nil {
+		t.Fatalf("BeginWrite failed: %v", err)
+	}
+	if err := wtxn.Put("user_intent", "k1", []byte("v1")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := wtxn.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	rtxn, err := store.BeginRead()
+	if err != nil {
+		t.Fatalf("BeginRead failed: %v", err)
+	}
+	defer rtxn.Commit()
+
+	val, found, err := rtxn.Get("user_intent", "k1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !found || string(val) != "v1" {
+		t.Errorf("expected v1, got %q (found=%v)", val, found)
+	}
+}
+
+func TestInMemoryStore_AbortDiscardsWrites(t *testing.T) {
+	store := NewInMemoryStore()
+
+	wtxn, err := store.BeginWrite()
+	if err != nil {
+		t.Fatalf("BeginWrite failed: %v", err)
+	}
+	if err := wtxn.Put("user_intent", "k1", []byte("v1")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := wtxn.Abort(); err != nil {
+		t.Fatalf("Abort failed: %v", err)
+	}
+
+	rtxn, err := store.BeginRead()
+	if err != nil {
+		t.Fatalf("BeginRead failed: %v", err)
+	}
+	defer rtxn.Commit()
+
+	if _, found, _ := rtxn.Get("user_intent", "k1"); found {
+		t.Error("expected aborted write not to be visible")
+	}
+}
+
+func TestInMemoryStore_Delete(t *testing.T) {
+	store := NewInMemoryStore()
+
+	wtxn, _ := store.BeginWrite()
+	_ = wtxn.Put("user_intent", "k1", []byte("v1"))
+	_ = wtxn.Commit()
+
+	wtxn2, _ := store.BeginWrite()
+	if err := wtxn2.Delete("user_intent", "k1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	_ = wtxn2.Commit()
+
+	rtxn, _ := store.BeginRead()
+	defer rtxn.Commit()
+	if _, found, _ := rtxn.Get("user_intent", "k1"); found {
+		t.Error("expected deleted key not to be found")
+	}
+}
+
+func TestInMemoryStore_IterateByPredicatePrefix(t *testing.T) {
+	store := NewInMemoryStore()
+
+	wtxn, _ := store.BeginWrite()
+	_ = wtxn.Put("tool_invoked", "k1", []byte("a"))
+	_ = wtxn.Put("tool_result", "k2", []byte("b"))
+	_ = wtxn.Put("user_intent", "k3", []byte("c"))
+	_ = wtxn.Commit()
+
+	rtxn, _ := store.BeginRead()
+	defer rtxn.Commit()
+
+	var predicates []string
+	if err := rtxn.Iterate("tool_", func(predicate, key string, value []byte) error {
+		predicates = append(predicates, predicate)
+		return nil
+	}); err != nil {
+		t.Fatalf("Iterate failed: %v", err)
+	}
+	if len(predicates) != 2 {
+		t.Errorf("expected 2 entries matching prefix \"tool_\", got %d: %v", len(predicates), predicates)
+	}
+}
+
+func TestInMemoryStore_WriteBlocksDuringActiveWrite(t *testing.T) {
+	store := NewInMemoryStore()
+
+	wtxn, err := store.BeginWrite()
+	if err != nil {
+		t.Fatalf("BeginWrite failed: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		second, err := store.BeginWrite()
+		if err != nil {
+			t.Errorf("second BeginWrite failed: %v", err)
+			return
+		}
+		_ = second.Commit()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected second BeginWrite to block while the first write txn is active")
+	default:
+	}
+
+	_ = wtxn.Commit()
+	<-done
+}
+
+func TestInMemoryStore_Metrics(t *testing.T) {
+	store := NewInMemoryStore()
+
+	wtxn, _ := store.BeginWrite()
+	_ = wtxn.Put("user_intent", "k1", []byte("v1"))
+	_ = wtxn.Commit()
+
+	m := store.Metrics()
+	if m.Writes != 1 || m.Puts != 1 || m.Commits != 1 {
+		t.Errorf("unexpected metrics: %+v", m)
+	}
+}
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"container/list"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// TraceNode is one node of a historical derivation tree rebuilt from a
+// TraceWAL, analogous to mangle.DerivationNode but reconstructed from WAL
+// entries as of a past txn id instead of live kernel state.
+type TraceNode struct {
+	TxnID     uint64
+	Kind      WALEntryKind
+	Predicate string
+	Fact      Fact
+	RuleName  string
+	Category  FactCategory
+	Children  []*TraceNode
+}
+
+// traceRebuildDepthLimit bounds RebuildTrace's recursion, mirroring the
+// depth<10 cycle guard buildDerivationNode uses in trace.go.
+const traceRebuildDepthLimit = 10
+
+// premisePredicatesForRule returns the predicate(s) a known rule's
+// derivation depends on, mirroring the switch in findPremises (trace.go)
+// but as pure metadata usable against historical WAL entries instead of
+// live kernel queries.
+func premisePredicatesForRule(ruleName string) []string {
+	switch ruleName {
+	case "transitive_impact":
+		return []string{"dependency_link", "modified"}
+	case "permission_gate":
+		return []string{"safe_action"}
+	case "focus_threshold":
+		return []string{"focus_resolution"}
+	case "strategy_selector":
+		return []string{"user_intent"}
+	default:
+		return nil
+	}
+}
+
+// traceCacheKey identifies a proved goal for TraceStore's LRU cache: the
+// predicate queried, a hash of its bound arguments, and the ruleset version
+// active when it was proved (so a policy/schema reload can't serve a trace
+// built under rules that no longer apply).
+type traceCacheKey struct {
+	predicate      string
+	argHash        uint64
+	rulesetVersion uint64
+}
+
+// traceCacheKeyFor computes a traceCacheKey from a predicate, its bound
+// args, and the current ruleset version.
+func traceCacheKeyFor(predicate string, args []interface{}, rulesetVersion uint64) traceCacheKey {
+	h := fnv.New64a()
+	for _, arg := range args {
+		fmt.Fprintf(h, "%v\x00", arg)
+	}
+	return traceCacheKey{predicate: predicate, argHash: h.Sum64(), rulesetVersion: rulesetVersion}
+}
+
+type traceCacheEntry struct {
+	key  traceCacheKey
+	node *TraceNode
+	elem *list.Element
+}
+
+// traceCache is a small fixed-capacity LRU of recently-proved goals, so
+// TraceStore.RebuildTrace can short-circuit repeated lookups instead of
+// replaying the WAL every time. Mirrors DreamCache's container/list-backed
+// LRU (dreamer_cache.go), minus the byte/TTL bounds that cache needs and
+// this one doesn't.
+type traceCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[traceCacheKey]*traceCacheEntry
+}
+
+func newTraceCache(capacity int) *traceCache {
+	return &traceCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[traceCacheKey]*traceCacheEntry),
+	}
+}
+
+func (c *traceCache) get(key traceCacheKey) (*TraceNode, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(entry.elem)
+	return entry.node, true
+}
+
+func (c *traceCache) put(key traceCacheKey, node *TraceNode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.items[key]; ok {
+		existing.node = node
+		c.order.MoveToFront(existing.elem)
+		return
+	}
+
+	entry := &traceCacheEntry{key: key, node: node}
+	entry.elem = c.order.PushFront(entry)
+	c.items[key] = entry
+
+	for c.capacity > 0 && len(c.items) > c.capacity {
+		back := c.order.Back()
+		if back == nil {
+			break
+		}
+		victim := back.Value.(*traceCacheEntry)
+		delete(c.items, victim.key)
+		c.order.Remove(back)
+	}
+}
+
+// defaultTraceCacheCapacity is the number of proved goals TraceStore keeps
+// cached when NewTraceStore is called without an explicit capacity.
+const defaultTraceCacheCapacity = 1000
+
+// TraceStore rebuilds historical derivation traces by replaying a TraceWAL,
+// so "why did X fire N sessions ago" can be answered even after the
+// underlying ephemeral/derived facts are gone. It complements
+// RealKernel.TraceQuery (trace.go), which only sees live kernel state.
+type TraceStore struct {
+	wal   *TraceWAL
+	cache *traceCache
+}
+
+// NewTraceStore creates a TraceStore backed by wal, with a default-sized
+// proved-goal cache.
+func NewTraceStore(wal *TraceWAL) *TraceStore {
+	return NewTraceStoreWithCacheSize(wal, defaultTraceCacheCapacity)
+}
+
+// NewTraceStoreWithCacheSize creates a TraceStore backed by wal, with a
+// proved-goal cache holding at most cacheCapacity entries (0 means
+// unbounded).
+func NewTraceStoreWithCacheSize(wal *TraceWAL, cacheCapacity int) *TraceStore {
+	return &TraceStore{wal: wal, cache: newTraceCache(cacheCapacity)}
+}
+
+// RebuildTrace replays the WAL up to and including upToTxnID and returns
+// the TraceNode tree for predicate as of that point: the most recent
+// surviving entry for predicate, with children recursively rebuilt for
+// whatever predicates RuleName's rule is known to depend on. Repeated
+// calls with the same (predicate, args, rulesetVersion) are served from
+// TraceStore's LRU cache without replaying the WAL again.
+func (s *TraceStore) RebuildTrace(predicate string, args []interface{}, rulesetVersion uint64, upToTxnID uint64) (*TraceNode, error) {
+	key := traceCacheKeyFor(predicate, args, rulesetVersion)
+	if node, ok := s.cache.get(key); ok {
+		return node, nil
+	}
+
+	entries := s.wal.EntriesUpTo(upToTxnID)
+	node, err := buildTraceNode(entries, predicate, 0)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(key, node)
+	return node, nil
+}
+
+func buildTraceNode(entries []WALEntry, predicate string, depth int) (*TraceNode, error) {
+	entry, found := latestSurvivingEntry(entries, predicate)
+	if !found {
+		return nil, fmt.Errorf("no surviving WAL entry found for predicate %s", predicate)
+	}
+
+	node := &TraceNode{
+		TxnID:     entry.TxnID,
+		Kind:      entry.Kind,
+		Predicate: entry.Predicate,
+		Fact:      entry.Fact,
+		RuleName:  entry.RuleName,
+		Category:  entry.Category,
+	}
+	if depth >= traceRebuildDepthLimit {
+		return node, nil
+	}
+
+	for _, premisePredicate := range premisePredicatesForRule(entry.RuleName) {
+		child, err := buildTraceNode(entries, premisePredicate, depth+1)
+		if err != nil {
+			continue // that premise simply wasn't recorded before this txn horizon
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
+
+// latestSurvivingEntry returns the most recent non-retract entry for
+// predicate in entries, or false if predicate was never asserted/derived,
+// or its most recent mutation was a retract.
+func latestSurvivingEntry(entries []WALEntry, predicate string) (WALEntry, bool) {
+	var latest WALEntry
+	found := false
+	for _, e := range entries {
+		if e.Predicate != predicate {
+			continue
+		}
+		if e.Kind == WALRetract {
+			found = false
+			continue
+		}
+		latest = e
+		found = true
+	}
+	return latest, found
+}
@@ -0,0 +1,303 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"codenerd/internal/core/storage"
+	"codenerd/internal/logging"
+
+	"github.com/google/mangle/ast"
+)
+
+// WALEntryKind identifies what kind of kernel mutation a WALEntry records.
+type WALEntryKind int
+
+const (
+	// WALAssert records a fact added via Assert/AssertBatch.
+	WALAssert WALEntryKind = iota
+	// WALRetract records a predicate's facts removed via Retract.
+	WALRetract
+	// WALRuleFiring records a derived (IDB) fact materializing during
+	// evaluate(), with RuleName set to the rule that produced it.
+	WALRuleFiring
+)
+
+// String returns the entry kind's name.
+func (k WALEntryKind) String() string {
+	switch k {
+	case WALAssert:
+		return "assert"
+	case WALRetract:
+		return "retract"
+	case WALRuleFiring:
+		return "rule_firing"
+	default:
+		return "unknown"
+	}
+}
+
+// WALEntry is one append-only record in a TraceWAL: a single Assert,
+// Retract, or rule firing, tagged with the FactCategory its predicate had
+// at the time (so compaction and replay don't need to re-derive it later,
+// after a predicate may have been reclassified).
+type WALEntry struct {
+	TxnID     uint64
+	Kind      WALEntryKind
+	Predicate string
+	Fact      Fact // zero value for WALRetract, which has no single fact
+	RuleName  string
+	Category  FactCategory
+	Timestamp time.Time
+}
+
+// walRecord is WALEntry's on-disk encoding: Fact is flattened to its
+// canonical Datalog-syntax string via Fact.String()/ParseFactString, the
+// same round-trip persistence.go uses, so no type fidelity is lost to JSON.
+type walRecord struct {
+	TxnID     uint64
+	Kind      WALEntryKind
+	Predicate string
+	FactStr   string
+	RuleName  string
+	Category  FactCategory
+	Timestamp time.Time
+}
+
+const walStorePredicate = "_trace_wal"
+
+// TraceWAL is the kernel's write-ahead log of Assert/Retract/rule-firing
+// events, each tagged with a monotonically increasing txn id. TraceStore
+// replays it to rebuild historical derivation traces after the facts
+// themselves (especially ephemeral and derived ones) are long gone.
+type TraceWAL struct {
+	mu      sync.Mutex
+	nextTxn uint64
+	entries []WALEntry
+	store   storage.Store // optional, nil by default; see SetStore
+}
+
+// NewTraceWAL creates an empty, in-memory TraceWAL.
+func NewTraceWAL() *TraceWAL {
+	return &TraceWAL{}
+}
+
+// SetStore attaches store so every future Append also persists a walRecord
+// to it, mirroring RealKernel.SetFactStore's opt-in persistence pattern. A
+// nil store (the default) keeps the WAL purely in-memory.
+func (w *TraceWAL) SetStore(store storage.Store) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.store = store
+}
+
+// Append records a new WAL entry and returns it with its assigned TxnID
+// filled in.
+func (w *TraceWAL) Append(kind WALEntryKind, predicate string, fact Fact, ruleName string) WALEntry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.nextTxn++
+	entry := WALEntry{
+		TxnID:     w.nextTxn,
+		Kind:      kind,
+		Predicate: predicate,
+		Fact:      fact,
+		RuleName:  ruleName,
+		Category:  GetCategory(predicate),
+		Timestamp: time.Now(),
+	}
+	w.entries = append(w.entries, entry)
+	if w.store != nil {
+		w.persistEntryLocked(entry)
+	}
+	return entry
+}
+
+// persistEntryLocked writes entry to w.store. w.mu must be held.
+func (w *TraceWAL) persistEntryLocked(entry WALEntry) {
+	factStr := ""
+	if entry.Kind != WALRetract {
+		factStr = entry.Fact.String()
+	}
+	record := walRecord{
+		TxnID:     entry.TxnID,
+		Kind:      entry.Kind,
+		Predicate: entry.Predicate,
+		FactStr:   factStr,
+		RuleName:  entry.RuleName,
+		Category:  entry.Category,
+		Timestamp: entry.Timestamp,
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		logging.Get(logging.CategoryKernel).Error("TraceWAL: failed to marshal entry %d: %v", entry.TxnID, err)
+		return
+	}
+
+	txn, err := w.store.BeginWrite()
+	if err != nil {
+		logging.Get(logging.CategoryKernel).Error("TraceWAL: BeginWrite failed: %v", err)
+		return
+	}
+	key := fmt.Sprintf("%020d", entry.TxnID)
+	if err := txn.Put(walStorePredicate, key, data); err != nil {
+		logging.Get(logging.CategoryKernel).Error("TraceWAL: Put failed: %v", err)
+		_ = txn.Abort()
+		return
+	}
+	if err := txn.Commit(); err != nil {
+		logging.Get(logging.CategoryKernel).Error("TraceWAL: Commit failed: %v", err)
+	}
+}
+
+// Entries returns a copy of every entry currently in the WAL, oldest first.
+func (w *TraceWAL) Entries() []WALEntry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]WALEntry(nil), w.entries...)
+}
+
+// LatestTxnID returns the most recently assigned txn id, or 0 if nothing
+// has been appended yet.
+func (w *TraceWAL) LatestTxnID() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.nextTxn
+}
+
+// EntriesUpTo returns a copy of every entry with TxnID <= upToTxnID, oldest
+// first.
+func (w *TraceWAL) EntriesUpTo(upToTxnID uint64) []WALEntry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	result := make([]WALEntry, 0, len(w.entries))
+	for _, e := range w.entries {
+		if e.TxnID <= upToTxnID {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Compact drops every entry older than boundary (TxnID < boundary) whose
+// predicate IsEphemeral, keeping persistent and derived entries for
+// post-mortem auditability. It returns how many entries were dropped.
+// Compaction only trims the in-memory log; any already-persisted records
+// are left on disk (the store has its own compaction/retention story).
+func (w *TraceWAL) Compact(boundary uint64) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	kept := w.entries[:0:0]
+	dropped := 0
+	for _, e := range w.entries {
+		if e.TxnID < boundary && IsEphemeral(e.Predicate) {
+			dropped++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	w.entries = kept
+	return dropped
+}
+
+// SetTraceWAL attaches wal to the kernel. Once attached, Assert/AssertBatch
+// append a WALAssert entry for every newly-added fact, Retract appends a
+// WALRetract entry for the retracted predicate, and evaluate() appends a
+// WALRuleFiring entry the first time it observes each distinct derived
+// fact. A nil wal (the default on every existing constructor) makes all of
+// this a no-op, mirroring SetFactStore's opt-in persistence pattern.
+func (k *RealKernel) SetTraceWAL(wal *TraceWAL) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.wal = wal
+}
+
+// GetTraceWAL returns the kernel's attached TraceWAL, or nil if none is
+// attached.
+func (k *RealKernel) GetTraceWAL() *TraceWAL {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.wal
+}
+
+// recordAssertsLocked appends a WALAssert entry for each of facts. Caller
+// must hold k.mu. No-op if no TraceWAL is attached.
+func (k *RealKernel) recordAssertsLocked(facts []Fact) {
+	if k.wal == nil {
+		return
+	}
+	for _, f := range facts {
+		k.wal.Append(WALAssert, f.Predicate, f, "")
+	}
+}
+
+// recordRetractLocked appends a WALRetract entry for predicate. Caller must
+// hold k.mu. No-op if no TraceWAL is attached.
+func (k *RealKernel) recordRetractLocked(predicate string) {
+	if k.wal == nil {
+		return
+	}
+	k.wal.Append(WALRetract, predicate, Fact{}, "")
+}
+
+// recordRuleFiringsLocked scans every declared IsDerived predicate in the
+// freshly-evaluated store and appends a WALRuleFiring entry the first time
+// each distinct derived fact (deduped by fact.String()) is observed. Caller
+// must hold k.mu (evaluate() always does). No-op if no TraceWAL is
+// attached.
+func (k *RealKernel) recordRuleFiringsLocked() {
+	if k.wal == nil || k.programInfo == nil {
+		return
+	}
+	if k.walSeenDerived == nil {
+		k.walSeenDerived = make(map[string]struct{})
+	}
+	for pred := range k.programInfo.Decls {
+		if !IsDerived(pred.Symbol) {
+			continue
+		}
+		ruleName := k.ruleNameForPredicateLocked(pred.Symbol)
+		_ = k.store.GetFacts(ast.NewQuery(pred), func(a ast.Atom) error {
+			fact := atomToFact(a)
+			seenKey := fact.String()
+			if _, seen := k.walSeenDerived[seenKey]; seen {
+				return nil
+			}
+			k.walSeenDerived[seenKey] = struct{}{}
+			k.wal.Append(WALRuleFiring, fact.Predicate, fact, ruleName)
+			return nil
+		})
+	}
+}
+
+// ruleNameForPredicateLocked looks up the rule_metadata(predicate, RuleName)
+// fact for predicate directly against k.store, mirroring classifyFact
+// (trace.go) but safe to call while k.mu is already held (classifyFact's
+// k.Query would deadlock there). Caller must hold k.mu.
+func (k *RealKernel) ruleNameForPredicateLocked(predicate string) string {
+	if k.programInfo == nil {
+		return ""
+	}
+	for pred := range k.programInfo.Decls {
+		if pred.Symbol != "rule_metadata" {
+			continue
+		}
+		ruleName := ""
+		_ = k.store.GetFacts(ast.NewQuery(pred), func(a ast.Atom) error {
+			fact := atomToFact(a)
+			if ruleName != "" || len(fact.Args) < 2 {
+				return nil
+			}
+			if fmt.Sprintf("%v", fact.Args[0]) == predicate {
+				ruleName = fmt.Sprintf("%v", fact.Args[1])
+			}
+			return nil
+		})
+		return ruleName
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"testing"
+
+	"codenerd/internal/core/storage"
+)
+
+func TestTraceWAL_AppendAssignsMonotonicTxnIDs(t *testing.T) {
+	wal := NewTraceWAL()
+	e1 := wal.Append(WALAssert, "user_intent", Fact{Predicate: "user_intent", Args: []interface{}{"a"}}, "")
+	e2 := wal.Append(WALAssert, "user_intent", Fact{Predicate: "user_intent", Args: []interface{}{"b"}}, "")
+
+	if e1.TxnID != 1 || e2.TxnID != 2 {
+		t.Errorf("expected txn ids 1, 2; got %d, %d", e1.TxnID, e2.TxnID)
+	}
+}
+
+func TestTraceWAL_EntriesUpTo(t *testing.T) {
+	wal := NewTraceWAL()
+	wal.Append(WALAssert, "p", Fact{Predicate: "p"}, "")
+	wal.Append(WALAssert, "p", Fact{Predicate: "p"}, "")
+	wal.Append(WALAssert, "p", Fact{Predicate: "p"}, "")
+
+	entries := wal.EntriesUpTo(2)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries up to txn 2, got %d", len(entries))
+	}
+}
+
+func TestTraceWAL_CompactDropsOldEphemeralOnly(t *testing.T) {
+	wal := NewTraceWAL()
+	wal.Append(WALAssert, "user_intent", Fact{Predicate: "user_intent"}, "") // ephemeral, txn 1
+	wal.Append(WALAssert, "permitted", Fact{Predicate: "permitted"}, "")     // derived, txn 2
+
+	dropped := wal.Compact(3)
+	if dropped != 1 {
+		t.Fatalf("expected 1 entry dropped, got %d", dropped)
+	}
+	entries := wal.Entries()
+	if len(entries) != 1 || entries[0].Predicate != "permitted" {
+		t.Errorf("expected only the permitted entry to survive compaction, got %+v", entries)
+	}
+}
+
+func TestTraceWAL_SetStorePersistsEntries(t *testing.T) {
+	store := storage.NewInMemoryStore()
+	wal := NewTraceWAL()
+	wal.SetStore(store)
+	wal.Append(WALAssert, "user_intent", Fact{Predicate: "user_intent", Args: []interface{}{"go"}}, "")
+
+	rtxn, err := store.BeginRead()
+	if err != nil {
+		t.Fatalf("BeginRead() error = %v", err)
+	}
+	defer rtxn.Commit()
+
+	count := 0
+	if err := rtxn.Iterate(walStorePredicate, func(predicate, key string, value []byte) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Fatalf("Iterate() error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 persisted WAL entry, got %d", count)
+	}
+}
+
+func TestTraceStore_RebuildTraceFollowsKnownPremises(t *testing.T) {
+	wal := NewTraceWAL()
+	wal.Append(WALAssert, "safe_action", Fact{Predicate: "safe_action", Args: []interface{}{"go_test"}}, "")
+	firing := wal.Append(WALRuleFiring, "permitted", Fact{Predicate: "permitted", Args: []interface{}{"go_test"}}, "permission_gate")
+
+	store := NewTraceStore(wal)
+	node, err := store.RebuildTrace("permitted", []interface{}{"go_test"}, 1, firing.TxnID)
+	if err != nil {
+		t.Fatalf("RebuildTrace() error = %v", err)
+	}
+	if node.Predicate != "permitted" || node.RuleName != "permission_gate" {
+		t.Fatalf("unexpected root node: %+v", node)
+	}
+	if len(node.Children) != 1 || node.Children[0].Predicate != "safe_action" {
+		t.Fatalf("expected safe_action premise, got %+v", node.Children)
+	}
+}
+
+func TestTraceStore_RebuildTraceCachesResult(t *testing.T) {
+	wal := NewTraceWAL()
+	firing := wal.Append(WALRuleFiring, "user_intent", Fact{Predicate: "user_intent", Args: []interface{}{"go_test"}}, "")
+
+	store := NewTraceStore(wal)
+	first, err := store.RebuildTrace("user_intent", []interface{}{"go_test"}, 1, firing.TxnID)
+	if err != nil {
+		t.Fatalf("RebuildTrace() error = %v", err)
+	}
+
+	wal.Compact(firing.TxnID + 1) // would make a fresh replay fail to find the entry
+	second, err := store.RebuildTrace("user_intent", []interface{}{"go_test"}, 1, firing.TxnID)
+	if err != nil {
+		t.Fatalf("expected cached RebuildTrace() to succeed after compaction, got error: %v", err)
+	}
+	if first != second {
+		t.Error("expected the second call to return the cached node")
+	}
+}
+
+func TestTraceStore_RebuildTraceMissingPredicate(t *testing.T) {
+	wal := NewTraceWAL()
+	store := NewTraceStore(wal)
+	if _, err := store.RebuildTrace("nonexistent", nil, 1, 0); err == nil {
+		t.Error("expected an error for a predicate with no WAL entries")
+	}
+}
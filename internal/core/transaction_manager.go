@@ -5,6 +5,9 @@ package core
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -21,8 +24,15 @@ type TransactionManager struct {
 	shadowMode  *ShadowMode
 	kernel      *RealKernel
 	projectRoot string
-	txns        map[string]*Transaction
+	txns        map[string]*Transaction // in-flight transactions only; terminal ones move to store
 	activeTxnID string
+	store       TransactionStore
+	validators  []Validator // extra pre-commit checks run during Prepare, in registration order
+
+	// RetryPolicy configures Transact's automatic retry-on-conflict behavior.
+	// Callers may assign a new RetryPolicy directly; DefaultRetryPolicy is
+	// used whenever MaxAttempts is zero.
+	RetryPolicy RetryPolicy
 }
 
 // Transaction represents an atomic unit of work spanning multiple files.
@@ -32,9 +42,22 @@ type Transaction struct {
 	StartTime   time.Time
 	Status      TransactionStatus
 	Edits       []FileEdit
-	Snapshots   map[string][]byte // Original file contents for rollback
+	Snapshots   map[string]string // path -> content-addressed hash of its original content, for rollback
 	Validation  *ShadowValidationResult
 	Error       error
+	Savepoints  []*Savepoint // Open nested checkpoints, outermost first
+}
+
+// Savepoint marks a checkpoint within an in-progress Transaction so a large,
+// multi-step edit (e.g. a cross-language rename) can be broken into nested
+// sub-steps. EditIndex records how far txn.Edits had grown when the
+// savepoint was created, and Snapshots holds the hash of every file first
+// touched since then, so RollbackTo can undo exactly this savepoint's work -
+// and nothing made before it - without re-reading the filesystem.
+type Savepoint struct {
+	Name      string
+	EditIndex int
+	Snapshots map[string]string
 }
 
 // TransactionStatus represents the state of a transaction in the 2PC protocol.
@@ -94,13 +117,26 @@ type SafetyBlock struct {
 	Rule   string
 }
 
-// NewTransactionManager creates a new Transaction Manager.
+// NewTransactionManager creates a new Transaction Manager backed by an
+// in-memory TransactionStore - suitable for tests and short-lived
+// processes. Long-running sessions that want committed/aborted history to
+// survive a restart should use NewTransactionManagerWithStore with a
+// BadgerTransactionStore instead.
 func NewTransactionManager(kernel *RealKernel, projectRoot string) *TransactionManager {
+	return NewTransactionManagerWithStore(kernel, projectRoot, NewInMemoryTransactionStore())
+}
+
+// NewTransactionManagerWithStore creates a Transaction Manager that persists
+// terminal (committed/aborted) transactions to store instead of keeping them
+// in memory indefinitely.
+func NewTransactionManagerWithStore(kernel *RealKernel, projectRoot string, store TransactionStore) *TransactionManager {
 	return &TransactionManager{
 		shadowMode:  NewShadowMode(kernel),
 		kernel:      kernel,
 		projectRoot: projectRoot,
 		txns:        make(map[string]*Transaction),
+		store:       store,
+		RetryPolicy: DefaultRetryPolicy(),
 	}
 }
 
@@ -121,7 +157,7 @@ func (tm *TransactionManager) Begin(ctx context.Context, description string) (*T
 		StartTime:   time.Now(),
 		Status:      TxnStatusPending,
 		Edits:       make([]FileEdit, 0),
-		Snapshots:   make(map[string][]byte),
+		Snapshots:   make(map[string]string),
 	}
 
 	tm.txns[txnID] = txn
@@ -150,14 +186,23 @@ func (tm *TransactionManager) AddEdit(ctx context.Context, edit FileEdit) error
 		return fmt.Errorf("transaction not in pending state: %s", txn.Status)
 	}
 
-	// Take snapshot of original file content for rollback
+	// Take snapshot of original file content for rollback, stored once under
+	// its content hash rather than copied into the transaction itself.
 	if edit.EditType != EditTypeCreate {
 		if _, exists := txn.Snapshots[edit.FilePath]; !exists {
 			content, err := os.ReadFile(edit.FilePath)
 			if err != nil && edit.EditType == EditTypeModify {
 				return fmt.Errorf("failed to snapshot file: %s - %w", edit.FilePath, err)
 			}
-			txn.Snapshots[edit.FilePath] = content
+			hash, err := tm.writeObject(content)
+			if err != nil {
+				return fmt.Errorf("failed to store snapshot for %s: %w", edit.FilePath, err)
+			}
+			txn.Snapshots[edit.FilePath] = hash
+			if len(txn.Savepoints) > 0 {
+				innermost := txn.Savepoints[len(txn.Savepoints)-1]
+				innermost.Snapshots[edit.FilePath] = hash
+			}
 		}
 	}
 
@@ -169,6 +214,155 @@ func (tm *TransactionManager) AddEdit(ctx context.Context, edit FileEdit) error
 	return nil
 }
 
+// BeginNested starts a checkpointed sub-step of the active transaction, so a
+// large refactor can be broken into nested stages that can each be rolled
+// back independently via RollbackTo without aborting the whole transaction.
+// If no transaction is active, it behaves exactly like Begin and starts the
+// outer transaction instead.
+func (tm *TransactionManager) BeginNested(ctx context.Context, description string) (*Transaction, error) {
+	tm.mu.RLock()
+	active := tm.activeTxnID
+	tm.mu.RUnlock()
+
+	if active == "" {
+		return tm.Begin(ctx, description)
+	}
+
+	if err := tm.Savepoint(description); err != nil {
+		return nil, err
+	}
+
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.txns[active], nil
+}
+
+// Savepoint pushes a new named checkpoint onto the active transaction,
+// recording its current edit count so a later RollbackTo(name) knows how
+// much to undo.
+func (tm *TransactionManager) Savepoint(name string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if tm.activeTxnID == "" {
+		return fmt.Errorf("no active transaction")
+	}
+
+	txn := tm.txns[tm.activeTxnID]
+	if txn == nil {
+		return fmt.Errorf("transaction not found: %s", tm.activeTxnID)
+	}
+
+	for _, sp := range txn.Savepoints {
+		if sp.Name == name {
+			return fmt.Errorf("savepoint already exists: %s", name)
+		}
+	}
+
+	txn.Savepoints = append(txn.Savepoints, &Savepoint{
+		Name:      name,
+		EditIndex: len(txn.Edits),
+		Snapshots: make(map[string]string),
+	})
+
+	logging.KernelDebug("Savepoint created: %s (txn %s, depth %d)", name, txn.ID, len(txn.Savepoints))
+
+	return nil
+}
+
+// ReleaseSavepoint drops name (and any savepoint nested inside it) without
+// undoing its edits, merging its tracked snapshots into its parent savepoint
+// (if any) so an outer RollbackTo still knows to undo them.
+func (tm *TransactionManager) ReleaseSavepoint(name string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if tm.activeTxnID == "" {
+		return fmt.Errorf("no active transaction")
+	}
+
+	txn := tm.txns[tm.activeTxnID]
+	if txn == nil {
+		return fmt.Errorf("transaction not found: %s", tm.activeTxnID)
+	}
+
+	idx := savepointIndex(txn, name)
+	if idx == -1 {
+		return fmt.Errorf("savepoint not found: %s", name)
+	}
+
+	released := txn.Savepoints[idx:]
+	txn.Savepoints = txn.Savepoints[:idx]
+	if len(txn.Savepoints) > 0 {
+		parent := txn.Savepoints[len(txn.Savepoints)-1]
+		for _, sp := range released {
+			for filePath, content := range sp.Snapshots {
+				parent.Snapshots[filePath] = content
+			}
+		}
+	}
+
+	logging.KernelDebug("Released savepoint: %s (txn %s)", name, txn.ID)
+
+	return nil
+}
+
+// RollbackTo discards every edit made since name was created - along with
+// any savepoint nested inside it - and forgets the file snapshots captured
+// during that span, restoring the transaction to exactly the state it was
+// in when Savepoint(name) was called.
+func (tm *TransactionManager) RollbackTo(name string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if tm.activeTxnID == "" {
+		return fmt.Errorf("no active transaction")
+	}
+
+	txn := tm.txns[tm.activeTxnID]
+	if txn == nil {
+		return fmt.Errorf("transaction not found: %s", tm.activeTxnID)
+	}
+
+	idx := savepointIndex(txn, name)
+	if idx == -1 {
+		return fmt.Errorf("savepoint not found: %s", name)
+	}
+
+	target := txn.Savepoints[idx]
+	for _, sp := range txn.Savepoints[idx:] {
+		for filePath := range sp.Snapshots {
+			delete(txn.Snapshots, filePath)
+		}
+	}
+	txn.Edits = txn.Edits[:target.EditIndex]
+	txn.Savepoints = txn.Savepoints[:idx]
+
+	logging.KernelDebug("Rolled back to savepoint: %s (txn %s, discarded to edit %d)", name, txn.ID, target.EditIndex)
+
+	return nil
+}
+
+// savepointIndex returns the position of the savepoint named name in
+// txn.Savepoints, or -1 if none matches.
+func savepointIndex(txn *Transaction, name string) int {
+	for i, sp := range txn.Savepoints {
+		if sp.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// RegisterValidator adds v to the chain Prepare runs against every
+// transaction, in registration order, alongside the built-in shadow-mode
+// checks.
+func (tm *TransactionManager) RegisterValidator(v Validator) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.validators = append(tm.validators, v)
+}
+
 // Prepare validates the transaction in shadow mode (Phase 1 of 2PC).
 // Returns true if all validations pass and the transaction is ready to commit.
 func (tm *TransactionManager) Prepare(ctx context.Context) (*ShadowValidationResult, error) {
@@ -284,6 +478,28 @@ func (tm *TransactionManager) Prepare(ctx context.Context) (*ShadowValidationRes
 	// Abort shadow simulation
 	tm.shadowMode.AbortSimulation("validation complete")
 
+	// Run the registered validator chain in addition to the shadow-mode
+	// checks above, aggregating into the same result - IsValid is the AND
+	// of every validator's verdict.
+	for _, v := range tm.validators {
+		vr, err := v.Validate(ctx, txn)
+		if err != nil {
+			result.IsValid = false
+			result.Warnings = append(result.Warnings, fmt.Sprintf("validator %s failed: %v", v.Name(), err))
+			continue
+		}
+		if vr == nil {
+			continue
+		}
+		if !vr.IsValid {
+			result.IsValid = false
+		}
+		result.ParseErrors = append(result.ParseErrors, vr.ParseErrors...)
+		result.SafetyBlocks = append(result.SafetyBlocks, vr.SafetyBlocks...)
+		result.Warnings = append(result.Warnings, vr.Warnings...)
+		result.AffectedRefs = append(result.AffectedRefs, vr.AffectedRefs...)
+	}
+
 	result.ValidDuration = time.Since(startTime)
 	txn.Validation = result
 
@@ -322,42 +538,44 @@ func (tm *TransactionManager) Commit(ctx context.Context) error {
 	txn.Status = TxnStatusCommitting
 	logging.KernelDebug("Committing transaction: %s", txn.ID)
 
-	// Apply all edits atomically
-	var committedFiles []string
-	for _, edit := range txn.Edits {
-		switch edit.EditType {
-		case EditTypeModify, EditTypeCreate:
-			// Ensure parent directory exists
-			dir := filepath.Dir(edit.FilePath)
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				tm.rollback(txn, committedFiles)
-				txn.Status = TxnStatusAborted
-				txn.Error = fmt.Errorf("failed to create directory: %s - %w", dir, err)
-				return txn.Error
-			}
+	// Phase 2a: durably stage every pre-image snapshot and new file content
+	// to the WAL, then write a COMMIT marker once staging is fsynced. Only
+	// after the marker is down do we touch any target file - so a crash
+	// before this point leaves every target file untouched, and a crash
+	// after it is always safe to finish via RecoverTransactions.
+	dir := tm.walDir(txn.ID)
+	if err := tm.writeWAL(txn); err != nil {
+		txn.Status = TxnStatusAborted
+		txn.Error = fmt.Errorf("failed to stage write-ahead log: %w", err)
+		return txn.Error
+	}
+	if err := tm.writeCommitMarker(dir); err != nil {
+		txn.Status = TxnStatusAborted
+		txn.Error = fmt.Errorf("failed to durably mark transaction committed: %w", err)
+		return txn.Error
+	}
 
-			// Write the file
-			if err := os.WriteFile(edit.FilePath, edit.Content, 0644); err != nil {
-				tm.rollback(txn, committedFiles)
-				txn.Status = TxnStatusAborted
-				txn.Error = fmt.Errorf("failed to write file: %s - %w", edit.FilePath, err)
-				return txn.Error
-			}
-			committedFiles = append(committedFiles, edit.FilePath)
-
-		case EditTypeDelete:
-			if err := os.Remove(edit.FilePath); err != nil && !os.IsNotExist(err) {
-				tm.rollback(txn, committedFiles)
-				txn.Status = TxnStatusAborted
-				txn.Error = fmt.Errorf("failed to delete file: %s - %w", edit.FilePath, err)
-				return txn.Error
-			}
-			committedFiles = append(committedFiles, edit.FilePath)
-		}
+	manifest, err := tm.readManifest(dir)
+	if err != nil {
+		txn.Status = TxnStatusAborted
+		txn.Error = err
+		return txn.Error
+	}
+
+	// Phase 2b: apply the staged renames/deletes. The COMMIT marker already
+	// makes this transaction durable, so on failure we don't roll back -
+	// RecoverTransactions will finish applying it on next startup.
+	if err := tm.applyWAL(dir, manifest); err != nil {
+		txn.Status = TxnStatusCommitted
+		tm.activeTxnID = ""
+		txn.Error = fmt.Errorf("committed but not fully applied, will finish on recovery: %w", err)
+		tm.archiveTransaction(txn)
+		return txn.Error
 	}
 
 	txn.Status = TxnStatusCommitted
 	tm.activeTxnID = ""
+	tm.cleanupWAL(txn.ID)
 
 	// Emit file_written facts to kernel
 	for _, edit := range txn.Edits {
@@ -369,11 +587,65 @@ func (tm *TransactionManager) Commit(ctx context.Context) error {
 		}
 	}
 
-	logging.KernelDebug("Transaction committed: %s (%d files)", txn.ID, len(committedFiles))
+	logging.KernelDebug("Transaction committed: %s (%d files)", txn.ID, len(txn.Edits))
+	tm.archiveTransaction(txn)
 
 	return nil
 }
 
+// archiveTransaction saves txn (now in a terminal status) to tm.store and
+// drops it from the in-flight txns map, so that map only ever holds
+// transactions still in progress rather than growing without bound across a
+// long-running session. Save errors are logged, not returned - the
+// transaction already succeeded or failed on its own terms, and a failure
+// to archive it shouldn't change that outcome, though it does mean
+// GetTransaction won't be able to find it again.
+func (tm *TransactionManager) archiveTransaction(txn *Transaction) {
+	if tm.store != nil {
+		if err := tm.store.Save(txn); err != nil {
+			logging.Get(logging.CategoryKernel).Error("Failed to archive transaction %s: %v", txn.ID, err)
+		}
+	}
+	delete(tm.txns, txn.ID)
+}
+
+// CommitForce commits the active transaction even though its last Prepare
+// reported validation failures, recording a force_committed audit fact so
+// the bypass is visible to kernel policy rules and later audits. It refuses
+// to run on a transaction that's already terminal (committed or aborted by
+// the caller), but otherwise accepts TxnStatusReady or TxnStatusAborted -
+// the latter being what Prepare leaves behind when validation fails.
+func (tm *TransactionManager) CommitForce(ctx context.Context, reason string) error {
+	tm.mu.Lock()
+
+	if tm.activeTxnID == "" {
+		tm.mu.Unlock()
+		return fmt.Errorf("no active transaction")
+	}
+
+	txn := tm.txns[tm.activeTxnID]
+	if txn == nil {
+		tm.mu.Unlock()
+		return fmt.Errorf("transaction not found: %s", tm.activeTxnID)
+	}
+
+	if txn.Status != TxnStatusReady && txn.Status != TxnStatusAborted {
+		tm.mu.Unlock()
+		return fmt.Errorf("transaction not forceable from state: %s", txn.Status)
+	}
+
+	tm.kernel.Assert(Fact{
+		Predicate: "force_committed",
+		Args:      []interface{}{txn.ID, reason, time.Now().Unix()},
+	})
+	txn.Status = TxnStatusReady
+
+	logging.KernelDebug("Force-committing transaction %s despite failed validation: %s", txn.ID, reason)
+	tm.mu.Unlock()
+
+	return tm.Commit(ctx)
+}
+
 // Abort cancels the active transaction without applying changes.
 func (tm *TransactionManager) Abort(ctx context.Context, reason string) error {
 	tm.mu.Lock()
@@ -397,28 +669,11 @@ func (tm *TransactionManager) Abort(ctx context.Context, reason string) error {
 	tm.activeTxnID = ""
 
 	logging.KernelDebug("Transaction aborted: %s - %s", txn.ID, reason)
+	tm.archiveTransaction(txn)
 
 	return nil
 }
 
-// rollback restores files to their original state on commit failure.
-func (tm *TransactionManager) rollback(txn *Transaction, committedFiles []string) {
-	logging.KernelDebug("Rolling back transaction: %s (%d files)", txn.ID, len(committedFiles))
-
-	for _, filePath := range committedFiles {
-		if original, exists := txn.Snapshots[filePath]; exists {
-			if len(original) > 0 {
-				if err := os.WriteFile(filePath, original, 0644); err != nil {
-					logging.Get(logging.CategoryKernel).Error("Rollback failed for %s: %v", filePath, err)
-				}
-			} else {
-				// Original was empty or didn't exist - delete the created file
-				_ = os.Remove(filePath)
-			}
-		}
-	}
-}
-
 // GetActiveTransaction returns the currently active transaction.
 func (tm *TransactionManager) GetActiveTransaction() (*Transaction, bool) {
 	tm.mu.RLock()
@@ -432,13 +687,42 @@ func (tm *TransactionManager) GetActiveTransaction() (*Transaction, bool) {
 	return txn, exists
 }
 
-// GetTransaction retrieves a transaction by ID.
+// GetTransaction retrieves a transaction by ID, checking in-flight
+// transactions first and falling back to the archived history in tm.store.
 func (tm *TransactionManager) GetTransaction(txnID string) (*Transaction, bool) {
 	tm.mu.RLock()
-	defer tm.mu.RUnlock()
-
 	txn, exists := tm.txns[txnID]
-	return txn, exists
+	tm.mu.RUnlock()
+	if exists {
+		return txn, true
+	}
+
+	if tm.store == nil {
+		return nil, false
+	}
+	archived, err := tm.store.Load(txnID)
+	if err != nil {
+		return nil, false
+	}
+	return archived, true
+}
+
+// ListTransactions queries the archived transaction history kept in
+// tm.store - e.g. every edit that touched a given file in some time window
+// - without needing to keep that history resident in tm.txns.
+func (tm *TransactionManager) ListTransactions(filter TransactionFilter) ([]*Transaction, error) {
+	if tm.store == nil {
+		return nil, fmt.Errorf("no transaction store configured")
+	}
+	return tm.store.List(filter)
+}
+
+// Close releases the underlying transaction store.
+func (tm *TransactionManager) Close() error {
+	if tm.store == nil {
+		return nil
+	}
+	return tm.store.Close()
 }
 
 // IsTransactionActive returns true if a transaction is currently in progress.
@@ -448,17 +732,20 @@ func (tm *TransactionManager) IsTransactionActive() bool {
 	return tm.activeTxnID != ""
 }
 
-// computeHash computes a hash for conflict detection.
+// computeHash returns a content-addressed identifier for content: a SHA-256
+// digest of an 8-byte big-endian length prefix followed by content itself.
+// The length prefix keeps the digest from colliding across inputs that
+// differ only in how they'd concatenate, and applies uniformly to every
+// input including the empty one, which no longer needs its own "empty"
+// sentinel.
 func computeHash(content []byte) string {
-	if len(content) == 0 {
-		return "empty"
-	}
-	// Use a simple hash for now (same as in scope.go)
-	hash := uint64(0)
-	for _, b := range content {
-		hash = hash*31 + uint64(b)
-	}
-	return fmt.Sprintf("%016x", hash)
+	var lengthPrefix [8]byte
+	binary.BigEndian.PutUint64(lengthPrefix[:], uint64(len(content)))
+
+	h := sha256.New()
+	h.Write(lengthPrefix[:])
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 // ToFacts converts transaction state to Mangle facts.
@@ -491,5 +778,15 @@ func (tm *TransactionManager) ToFacts() []Fact {
 		})
 	}
 
+	// Add savepoint facts so policy rules can reason about partial,
+	// not-yet-committed nested state. depth is 1-based since depth 0 is the
+	// outer transaction itself.
+	for depth, sp := range txn.Savepoints {
+		facts = append(facts, Fact{
+			Predicate: "savepoint",
+			Args:      []interface{}{txn.ID, sp.Name, depth + 1},
+		})
+	}
+
 	return facts
 }
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTransactionManager_BeginNestedStartsOuter tests that BeginNested
+// behaves like Begin when no transaction is active yet.
+func TestTransactionManager_BeginNestedStartsOuter(t *testing.T) {
+	tmpDir := t.TempDir()
+	tm := newTestTransactionManager(tmpDir)
+
+	txn, err := tm.BeginNested(context.Background(), "Outer refactor")
+	if err != nil {
+		t.Fatalf("BeginNested failed: %v", err)
+	}
+	if len(txn.Savepoints) != 0 {
+		t.Errorf("expected no savepoints on the outer transaction, got %d", len(txn.Savepoints))
+	}
+}
+
+// TestTransactionManager_SavepointRollbackDiscardsEdits tests that
+// RollbackTo undoes exactly the edits and snapshots added since the named
+// savepoint, leaving earlier edits untouched.
+func TestTransactionManager_SavepointRollbackDiscardsEdits(t *testing.T) {
+	tmpDir := t.TempDir()
+	fileA := filepath.Join(tmpDir, "a.go")
+	fileB := filepath.Join(tmpDir, "b.go")
+	if err := os.WriteFile(fileA, []byte("original-a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileB, []byte("original-b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := newTestTransactionManager(tmpDir)
+
+	txn, err := tm.Begin(context.Background(), "Rename userID to subID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{FilePath: fileA, Content: []byte("go-renamed"), EditType: EditTypeModify}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tm.BeginNested(context.Background(), "ts-step"); err != nil {
+		t.Fatalf("BeginNested failed: %v", err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{FilePath: fileB, Content: []byte("ts-renamed"), EditType: EditTypeModify}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(txn.Edits) != 2 {
+		t.Fatalf("expected 2 staged edits before rollback, got %d", len(txn.Edits))
+	}
+	if _, ok := txn.Snapshots[fileB]; !ok {
+		t.Fatal("expected fileB to be snapshotted before rollback")
+	}
+
+	if err := tm.RollbackTo("ts-step"); err != nil {
+		t.Fatalf("RollbackTo failed: %v", err)
+	}
+
+	if len(txn.Edits) != 1 || txn.Edits[0].FilePath != fileA {
+		t.Errorf("expected only the fileA edit to survive rollback, got %+v", txn.Edits)
+	}
+	if _, ok := txn.Snapshots[fileB]; ok {
+		t.Error("expected fileB's snapshot to be discarded by rollback")
+	}
+	if _, ok := txn.Snapshots[fileA]; !ok {
+		t.Error("expected fileA's snapshot (from before the savepoint) to survive rollback")
+	}
+	if len(txn.Savepoints) != 0 {
+		t.Errorf("expected the rolled-back savepoint to be popped, got %d remaining", len(txn.Savepoints))
+	}
+
+	// The outer transaction is untouched on disk - nothing commits until
+	// Commit() runs.
+	content, _ := os.ReadFile(fileA)
+	if string(content) != "original-a" {
+		t.Errorf("expected fileA to remain untouched on disk before commit, got %q", content)
+	}
+}
+
+// TestTransactionManager_ReleaseSavepointMergesIntoParent tests that
+// releasing a nested savepoint keeps its edits and folds its tracked
+// snapshots into the parent, so an outer RollbackTo still discards them.
+func TestTransactionManager_ReleaseSavepointMergesIntoParent(t *testing.T) {
+	tmpDir := t.TempDir()
+	fileA := filepath.Join(tmpDir, "a.go")
+	fileB := filepath.Join(tmpDir, "b.py")
+	if err := os.WriteFile(fileA, []byte("original-a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileB, []byte("original-b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := newTestTransactionManager(tmpDir)
+
+	txn, err := tm.Begin(context.Background(), "Rename userID to subID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.Savepoint("go-step"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{FilePath: fileA, Content: []byte("go-renamed"), EditType: EditTypeModify}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.Savepoint("py-step"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{FilePath: fileB, Content: []byte("py-renamed"), EditType: EditTypeModify}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tm.ReleaseSavepoint("py-step"); err != nil {
+		t.Fatalf("ReleaseSavepoint failed: %v", err)
+	}
+	if len(txn.Savepoints) != 1 || txn.Savepoints[0].Name != "go-step" {
+		t.Fatalf("expected only go-step to remain after release, got %+v", txn.Savepoints)
+	}
+	if len(txn.Edits) != 2 {
+		t.Fatalf("expected released edits to survive, got %d", len(txn.Edits))
+	}
+
+	// Rolling back the parent should now also discard the released child's
+	// edit and snapshot, since they were merged into it.
+	if err := tm.RollbackTo("go-step"); err != nil {
+		t.Fatalf("RollbackTo failed: %v", err)
+	}
+	if len(txn.Edits) != 0 {
+		t.Errorf("expected rollback of the parent to discard all edits, got %+v", txn.Edits)
+	}
+	if _, ok := txn.Snapshots[fileB]; ok {
+		t.Error("expected fileB's snapshot (merged from the released savepoint) to be discarded")
+	}
+}
+
+// TestTransactionManager_ToFactsIncludesSavepoints tests that ToFacts emits
+// a savepoint fact per open nested checkpoint.
+func TestTransactionManager_ToFactsIncludesSavepoints(t *testing.T) {
+	tmpDir := t.TempDir()
+	tm := newTestTransactionManager(tmpDir)
+
+	txn, err := tm.Begin(context.Background(), "Rename userID to subID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.Savepoint("go-step"); err != nil {
+		t.Fatal(err)
+	}
+
+	facts := tm.ToFacts()
+	found := false
+	for _, f := range facts {
+		if f.Predicate == "savepoint" && f.Args[0] == txn.ID && f.Args[1] == "go-step" && f.Args[2] == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a savepoint(%s, go-step, 1) fact, got %+v", txn.ID, facts)
+	}
+}
+
+// TestTransactionManager_SavepointDuplicateNameRejected tests that creating
+// two savepoints with the same name fails.
+func TestTransactionManager_SavepointDuplicateNameRejected(t *testing.T) {
+	tmpDir := t.TempDir()
+	tm := newTestTransactionManager(tmpDir)
+
+	if _, err := tm.Begin(context.Background(), "Dup test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.Savepoint("step"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.Savepoint("step"); err == nil {
+		t.Error("expected duplicate savepoint name to be rejected")
+	}
+}
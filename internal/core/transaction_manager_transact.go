@@ -0,0 +1,256 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"codenerd/internal/logging"
+)
+
+// RetryPolicy configures how Transact retries its closure when a commit
+// fails due to a detectable conflict. Backoff doubles each attempt starting
+// from BackoffBase, capped at BackoffMax.
+type RetryPolicy struct {
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when NewTransactionManager
+// is not given an explicit one.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BackoffBase: 50 * time.Millisecond,
+		BackoffMax:  2 * time.Second,
+	}
+}
+
+// nextDelay returns the backoff delay before retry attempt n (1-indexed).
+func (p RetryPolicy) nextDelay(attempt int) time.Duration {
+	base := p.BackoffBase
+	if base <= 0 {
+		base = 50 * time.Millisecond
+	}
+	maxDelay := p.BackoffMax
+	if maxDelay <= 0 {
+		maxDelay = 2 * time.Second
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := base * time.Duration(int64(1)<<uint(attempt-1))
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// Txn is the handle passed to the closure given to Transact and ReadTransact.
+// It wraps the active Transaction, delegating mutation to the owning
+// TransactionManager so callers don't need to juggle Begin/AddEdit/Prepare/
+// Commit themselves.
+type Txn struct {
+	tm       *TransactionManager
+	txn      *Transaction
+	readOnly bool
+}
+
+// ID returns the wrapped transaction's ID.
+func (t Txn) ID() string {
+	return t.txn.ID
+}
+
+// AddEdit stages a file edit within the transaction. It returns an error if
+// this Txn was opened via ReadTransact, which forbids mutation.
+func (t Txn) AddEdit(ctx context.Context, edit FileEdit) error {
+	if t.readOnly {
+		return fmt.Errorf("cannot add edit: transaction is read-only")
+	}
+	return t.tm.AddEdit(ctx, edit)
+}
+
+// Snapshot returns the original content captured for filePath the first time
+// it was touched by AddEdit, or ok=false if filePath hasn't been touched.
+func (t Txn) Snapshot(filePath string) (content []byte, ok bool) {
+	hash, exists := t.txn.Snapshots[filePath]
+	if !exists {
+		return nil, false
+	}
+	content, err := t.tm.readObject(hash)
+	if err != nil {
+		return nil, false
+	}
+	return content, true
+}
+
+// Transact opens a transaction, invokes fn, and commits on success or aborts
+// on error - the FoundationDB-style alternative to manually sequencing
+// Begin/AddEdit/Prepare/Commit/Abort. If the commit fails due to a
+// detectable conflict (a concurrent file_written fact touching one of the
+// transaction's files, or a snapshot hash mismatch against current on-disk
+// content), fn is retried against a fresh transaction according to
+// tm.RetryPolicy. Any other error from fn or from Prepare/Commit aborts
+// immediately without retry.
+func (tm *TransactionManager) Transact(ctx context.Context, description string, fn func(Txn) error) error {
+	policy := tm.RetryPolicy
+	if policy.MaxAttempts <= 0 {
+		policy = DefaultRetryPolicy()
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		txn, err := tm.Begin(ctx, description)
+		if err != nil {
+			return err
+		}
+
+		baseline := tm.factBaseline(txn)
+
+		if err := fn(Txn{tm: tm, txn: txn}); err != nil {
+			_ = tm.Abort(ctx, err.Error())
+			return fmt.Errorf("transact %s: closure failed: %w", txn.ID, err)
+		}
+
+		result, err := tm.Prepare(ctx)
+		if err != nil {
+			lastErr = err
+			if tm.hasConflict(txn, baseline) && attempt < policy.MaxAttempts {
+				logging.KernelDebug("Transact %s: prepare conflict, retrying (attempt %d/%d)", txn.ID, attempt, policy.MaxAttempts)
+				if sleepErr := sleepWithContext(ctx, policy.nextDelay(attempt)); sleepErr != nil {
+					return sleepErr
+				}
+				continue
+			}
+			return fmt.Errorf("transact %s: prepare failed: %w", txn.ID, err)
+		}
+		if !result.IsValid {
+			_ = tm.Abort(ctx, "shadow validation failed")
+			if tm.hasConflict(txn, baseline) && attempt < policy.MaxAttempts {
+				logging.KernelDebug("Transact %s: validation conflict, retrying (attempt %d/%d)", txn.ID, attempt, policy.MaxAttempts)
+				if sleepErr := sleepWithContext(ctx, policy.nextDelay(attempt)); sleepErr != nil {
+					return sleepErr
+				}
+				continue
+			}
+			return fmt.Errorf("transact %s: shadow validation failed: %+v", txn.ID, result.SafetyBlocks)
+		}
+
+		if err := tm.Commit(ctx); err != nil {
+			lastErr = err
+			if tm.hasConflict(txn, baseline) && attempt < policy.MaxAttempts {
+				logging.KernelDebug("Transact %s: commit conflict, retrying (attempt %d/%d)", txn.ID, attempt, policy.MaxAttempts)
+				if sleepErr := sleepWithContext(ctx, policy.nextDelay(attempt)); sleepErr != nil {
+					return sleepErr
+				}
+				continue
+			}
+			return fmt.Errorf("transact %s: commit failed: %w", txn.ID, err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("transact: exhausted %d attempts: %w", policy.MaxAttempts, lastErr)
+}
+
+// ReadTransact acquires a read-only view over the kernel's current facts and
+// snapshots for use by fn. The Txn passed to fn rejects AddEdit; no 2PC
+// Prepare/Commit cycle runs, since nothing was ever staged to write.
+func (tm *TransactionManager) ReadTransact(ctx context.Context, description string, fn func(Txn) error) error {
+	txn, err := tm.Begin(ctx, description)
+	if err != nil {
+		return err
+	}
+
+	if fnErr := fn(Txn{tm: tm, txn: txn, readOnly: true}); fnErr != nil {
+		_ = tm.Abort(ctx, fnErr.Error())
+		return fmt.Errorf("read-transact %s: closure failed: %w", txn.ID, fnErr)
+	}
+
+	return tm.Abort(ctx, "read-only transaction complete")
+}
+
+// factBaseline records, per file touched so far by txn, the count of
+// file_written facts referencing that file at the time Transact started
+// fn - the baseline hasConflict compares against after fn has staged its
+// edits.
+func (tm *TransactionManager) factBaseline(txn *Transaction) map[string]int {
+	return tm.fileWrittenCounts(tm.touchedFiles(txn))
+}
+
+// hasConflict reports whether any file touched by txn has a file_written
+// fact count higher than baseline, meaning some other transaction committed
+// a write to that file while txn was in flight.
+func (tm *TransactionManager) hasConflict(txn *Transaction, baseline map[string]int) bool {
+	current := tm.fileWrittenCounts(tm.touchedFiles(txn))
+	for filePath, before := range baseline {
+		if current[filePath] > before {
+			return true
+		}
+	}
+	return false
+}
+
+// touchedFiles returns the set of file paths staged by txn's edits so far.
+func (tm *TransactionManager) touchedFiles(txn *Transaction) []string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	seen := make(map[string]struct{}, len(txn.Edits))
+	files := make([]string, 0, len(txn.Edits))
+	for _, edit := range txn.Edits {
+		if _, ok := seen[edit.FilePath]; ok {
+			continue
+		}
+		seen[edit.FilePath] = struct{}{}
+		files = append(files, edit.FilePath)
+	}
+	return files
+}
+
+// fileWrittenCounts counts file_written facts per file path in filePaths.
+func (tm *TransactionManager) fileWrittenCounts(filePaths []string) map[string]int {
+	counts := make(map[string]int, len(filePaths))
+	if len(filePaths) == 0 || tm.kernel == nil {
+		return counts
+	}
+
+	want := make(map[string]struct{}, len(filePaths))
+	for _, fp := range filePaths {
+		want[fp] = struct{}{}
+	}
+
+	for _, fact := range tm.kernel.GetFactsSnapshot() {
+		if fact.Predicate != "file_written" || len(fact.Args) == 0 {
+			continue
+		}
+		filePath, ok := fact.Args[0].(string)
+		if !ok {
+			continue
+		}
+		if _, wanted := want[filePath]; wanted {
+			counts[filePath]++
+		}
+	}
+
+	return counts
+}
+
+// sleepWithContext blocks for d or until ctx is cancelled, whichever comes
+// first.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestRetryPolicy_NextDelay tests exponential backoff growth and capping.
+func TestRetryPolicy_NextDelay(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 5,
+		BackoffBase: 10 * time.Millisecond,
+		BackoffMax:  50 * time.Millisecond,
+	}
+
+	if got := policy.nextDelay(1); got != 10*time.Millisecond {
+		t.Errorf("expected first delay of 10ms, got %v", got)
+	}
+	if got := policy.nextDelay(2); got != 20*time.Millisecond {
+		t.Errorf("expected second delay of 20ms, got %v", got)
+	}
+	if got := policy.nextDelay(4); got != policy.BackoffMax {
+		t.Errorf("expected delay to cap at %v, got %v", policy.BackoffMax, got)
+	}
+}
+
+// TestDefaultRetryPolicy tests that the zero-value policy is usable.
+func TestDefaultRetryPolicy(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	if policy.MaxAttempts <= 0 {
+		t.Error("expected DefaultRetryPolicy to set a positive MaxAttempts")
+	}
+	if policy.BackoffBase <= 0 || policy.BackoffMax <= 0 {
+		t.Error("expected DefaultRetryPolicy to set positive backoff bounds")
+	}
+}
+
+// TestTransactionManager_TransactClosureError tests that a closure error
+// aborts without retrying.
+func TestTransactionManager_TransactClosureError(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	kernel := &RealKernel{
+		facts:       make([]Fact, 0),
+		policyDirty: true,
+		initialized: false,
+	}
+
+	tm := NewTransactionManager(kernel, tmpDir)
+
+	attempts := 0
+	err := tm.Transact(context.Background(), "Test transact error", func(txn Txn) error {
+		attempts++
+		return errors.New("closure failed")
+	})
+
+	if err == nil {
+		t.Fatal("expected Transact to return an error")
+	}
+	if attempts != 1 {
+		t.Errorf("expected exactly 1 attempt for a non-conflict closure error, got %d", attempts)
+	}
+	if tm.IsTransactionActive() {
+		t.Error("expected no active transaction after closure error")
+	}
+}
+
+// TestTransactionManager_ReadTransact tests that ReadTransact forbids
+// AddEdit and leaves no active transaction behind.
+func TestTransactionManager_ReadTransact(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	kernel := &RealKernel{
+		facts:       make([]Fact, 0),
+		policyDirty: true,
+		initialized: false,
+	}
+
+	tm := NewTransactionManager(kernel, tmpDir)
+
+	err := tm.ReadTransact(context.Background(), "Test read transact", func(txn Txn) error {
+		if addErr := txn.AddEdit(context.Background(), FileEdit{FilePath: "x", EditType: EditTypeCreate}); addErr == nil {
+			t.Error("expected AddEdit to fail on a read-only Txn")
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("ReadTransact failed: %v", err)
+	}
+	if tm.IsTransactionActive() {
+		t.Error("expected no active transaction after ReadTransact")
+	}
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTransactionManager_RegisteredValidatorBlocksPrepare tests that a
+// registered Validator's failure fails Prepare even when the built-in
+// shadow-mode checks would otherwise pass.
+func TestTransactionManager_RegisteredValidatorBlocksPrepare(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "generated.go")
+	if err := os.WriteFile(testFile, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := newTestTransactionManager(tmpDir)
+	tm.RegisterValidator(NewDiffSizeValidator(1))
+
+	if _, err := tm.Begin(context.Background(), "Oversized edit"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{
+		FilePath: testFile,
+		Content:  []byte("line1\nline2\nline3\n"),
+		EditType: EditTypeModify,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := tm.Prepare(context.Background())
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if result.IsValid {
+		t.Error("expected the registered diff-size validator to block Prepare")
+	}
+
+	foundBlock := false
+	for _, sb := range result.SafetyBlocks {
+		if sb.Rule == "diff_size_guard" {
+			foundBlock = true
+		}
+	}
+	if !foundBlock {
+		t.Errorf("expected a diff_size_guard safety block, got %+v", result.SafetyBlocks)
+	}
+
+	if err := tm.Commit(context.Background()); err == nil {
+		t.Error("expected Commit to refuse an aborted (failed-validation) transaction")
+	}
+}
+
+// TestTransactionManager_CommitForceBypassesValidation tests that
+// CommitForce commits a transaction despite failed validation and records
+// a force_committed audit fact.
+func TestTransactionManager_CommitForceBypassesValidation(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "generated.go")
+	if err := os.WriteFile(testFile, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := newTestTransactionManager(tmpDir)
+	tm.RegisterValidator(NewDiffSizeValidator(1))
+
+	txn, err := tm.Begin(context.Background(), "Oversized edit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{
+		FilePath: testFile,
+		Content:  []byte("line1\nline2\nline3\n"),
+		EditType: EditTypeModify,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.Prepare(context.Background()); err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	if err := tm.CommitForce(context.Background(), "generated file, size guard doesn't apply"); err != nil {
+		t.Fatalf("CommitForce failed: %v", err)
+	}
+
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "line1\nline2\nline3\n" {
+		t.Errorf("expected force-committed content to be applied, got %q", content)
+	}
+
+	found := false
+	for _, f := range tm.kernel.GetFactsSnapshot() {
+		if f.Predicate == "force_committed" && len(f.Args) > 0 && f.Args[0] == txn.ID {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a force_committed audit fact for the forced transaction")
+	}
+}
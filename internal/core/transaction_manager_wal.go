@@ -0,0 +1,286 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"codenerd/internal/logging"
+)
+
+// walManifestEntry mirrors one FileEdit's WAL bookkeeping: which files under
+// the transaction's WAL directory hold its pre-image snapshot and staged new
+// content.
+type walManifestEntry struct {
+	FilePath    string
+	EditType    EditType
+	NewHash     string
+	HasSnapshot bool
+}
+
+// walManifest is the durable record of a transaction's commit, persisted as
+// manifest.json under its WAL directory so RecoverTransactions can replay or
+// roll back work a crash interrupted mid-commit.
+type walManifest struct {
+	ID          string
+	Description string
+	StartTime   time.Time
+	Edits       []walManifestEntry
+}
+
+// walRootDir returns the directory holding every in-flight transaction's WAL
+// entry, under .codenerd/wal in the project root.
+func (tm *TransactionManager) walRootDir() string {
+	return filepath.Join(tm.projectRoot, ".codenerd", "wal")
+}
+
+// walDir returns the WAL directory for a single transaction.
+func (tm *TransactionManager) walDir(txnID string) string {
+	return filepath.Join(tm.walRootDir(), txnID)
+}
+
+func walManifestPath(dir string) string     { return filepath.Join(dir, "manifest.json") }
+func walCommitMarkerPath(dir string) string { return filepath.Join(dir, "COMMIT") }
+func walSnapshotPath(dir string, i int) string {
+	return filepath.Join(dir, fmt.Sprintf("%d.snapshot", i))
+}
+func walNewContentPath(dir string, i int) string { return filepath.Join(dir, fmt.Sprintf("%d.new", i)) }
+
+// writeWAL durably stages txn's commit: it writes a pre-image snapshot of
+// every modified/deleted file, the new content of every create/modify edit
+// as "<index>.new", and finally the transaction manifest tying it all
+// together - each file fsynced before writeWAL returns. Commit only applies
+// these files in place once writeWAL and writeCommitMarker have both
+// succeeded.
+func (tm *TransactionManager) writeWAL(txn *Transaction) error {
+	dir := tm.walDir(txn.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create WAL directory: %w", err)
+	}
+
+	manifest := walManifest{
+		ID:          txn.ID,
+		Description: txn.Description,
+		StartTime:   txn.StartTime,
+	}
+
+	for i, edit := range txn.Edits {
+		snapshotHash, hasSnapshot := txn.Snapshots[edit.FilePath]
+		manifest.Edits = append(manifest.Edits, walManifestEntry{
+			FilePath:    edit.FilePath,
+			EditType:    edit.EditType,
+			NewHash:     edit.NewHash,
+			HasSnapshot: hasSnapshot,
+		})
+
+		if hasSnapshot {
+			snapshot, err := tm.readObject(snapshotHash)
+			if err != nil {
+				return fmt.Errorf("failed to read snapshot object for %s: %w", edit.FilePath, err)
+			}
+			if err := writeAndSync(walSnapshotPath(dir, i), snapshot); err != nil {
+				return fmt.Errorf("failed to write WAL snapshot for %s: %w", edit.FilePath, err)
+			}
+		}
+
+		if edit.EditType == EditTypeCreate || edit.EditType == EditTypeModify {
+			if err := writeAndSync(walNewContentPath(dir, i), edit.Content); err != nil {
+				return fmt.Errorf("failed to stage WAL content for %s: %w", edit.FilePath, err)
+			}
+		}
+	}
+
+	if err := tm.writeManifest(dir, manifest); err != nil {
+		return err
+	}
+
+	logging.KernelDebug("WAL staged for transaction %s (%d edits)", txn.ID, len(txn.Edits))
+	return nil
+}
+
+func (tm *TransactionManager) writeManifest(dir string, manifest walManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal WAL manifest: %w", err)
+	}
+	if err := writeAndSync(walManifestPath(dir), data); err != nil {
+		return fmt.Errorf("failed to write WAL manifest: %w", err)
+	}
+	return nil
+}
+
+func (tm *TransactionManager) readManifest(dir string) (walManifest, error) {
+	var manifest walManifest
+	data, err := os.ReadFile(walManifestPath(dir))
+	if err != nil {
+		return manifest, fmt.Errorf("failed to read WAL manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("failed to parse WAL manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// writeCommitMarker durably records that every "<index>.new" file staged by
+// writeWAL has been written and fsynced, so recovery knows it's safe to
+// replay the renames/deletes that apply them rather than rolling back.
+func (tm *TransactionManager) writeCommitMarker(dir string) error {
+	if err := writeAndSync(walCommitMarkerPath(dir), []byte(time.Now().Format(time.RFC3339Nano))); err != nil {
+		return fmt.Errorf("failed to write WAL commit marker: %w", err)
+	}
+	return nil
+}
+
+// walCrashAfterApply, when set by tests, is invoked after each file-apply
+// step during applyWAL (rename or delete); returning true aborts the apply
+// loop immediately to simulate a process crash mid-commit so recovery tests
+// can verify WAL replay finishes the remaining files without leaving any of
+// them in a torn state. Production code leaves it nil.
+var walCrashAfterApply func(txnID string, index int) bool
+
+// applyWAL performs the filesystem side effects of a committed transaction:
+// renaming each staged "<index>.new" into place for create/modify edits, and
+// removing the target file for delete edits. It is used both by a live
+// Commit and by RecoverTransactions replaying a transaction whose COMMIT
+// marker was found on disk - in both cases, a rename/remove that a prior
+// attempt already applied is simply a no-op (the staged file is gone), so
+// it's always safe to re-run against the same manifest.
+func (tm *TransactionManager) applyWAL(dir string, manifest walManifest) error {
+	for i, entry := range manifest.Edits {
+		switch entry.EditType {
+		case EditTypeCreate, EditTypeModify:
+			newPath := walNewContentPath(dir, i)
+			if _, err := os.Stat(newPath); err == nil {
+				if err := os.MkdirAll(filepath.Dir(entry.FilePath), 0755); err != nil {
+					return fmt.Errorf("failed to create directory for %s: %w", entry.FilePath, err)
+				}
+				if err := os.Rename(newPath, entry.FilePath); err != nil {
+					return fmt.Errorf("failed to apply staged content for %s: %w", entry.FilePath, err)
+				}
+			}
+		case EditTypeDelete:
+			if err := os.Remove(entry.FilePath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to delete %s: %w", entry.FilePath, err)
+			}
+		}
+
+		if walCrashAfterApply != nil && walCrashAfterApply(manifest.ID, i) {
+			return fmt.Errorf("simulated crash mid-commit after applying edit %d of transaction %s", i, manifest.ID)
+		}
+	}
+	return nil
+}
+
+// rollbackWAL restores every file covered by manifest to its pre-transaction
+// state: snapshotted content is written back, and files the transaction
+// would have created are removed. It's used during recovery when no COMMIT
+// marker is found, meaning the crash happened before staging finished and
+// the target files were never touched - restoring is a no-op in that case,
+// but is safe and idempotent regardless.
+func (tm *TransactionManager) rollbackWAL(dir string, manifest walManifest) error {
+	for i, entry := range manifest.Edits {
+		if entry.HasSnapshot {
+			content, err := os.ReadFile(walSnapshotPath(dir, i))
+			if err != nil {
+				return fmt.Errorf("failed to read WAL snapshot for %s: %w", entry.FilePath, err)
+			}
+			// A snapshot exists whenever the edit wasn't a create (see
+			// writeWAL), so it must always be restored here - even a
+			// legitimately empty pre-image (a 0-byte file before the edit)
+			// needs to be written back, not mistaken for "no snapshot" and
+			// have its file removed.
+			if err := os.WriteFile(entry.FilePath, content, 0644); err != nil {
+				return fmt.Errorf("failed to restore %s from WAL snapshot: %w", entry.FilePath, err)
+			}
+		} else if entry.EditType == EditTypeCreate {
+			_ = os.Remove(entry.FilePath)
+		}
+	}
+	return nil
+}
+
+// cleanupWAL removes a transaction's WAL directory once it has been fully
+// committed or rolled back.
+func (tm *TransactionManager) cleanupWAL(txnID string) {
+	if err := os.RemoveAll(tm.walDir(txnID)); err != nil {
+		logging.Get(logging.CategoryKernel).Error("Failed to clean up WAL directory for %s: %v", txnID, err)
+	}
+}
+
+// RecoverTransactions scans the WAL directory for transactions left behind
+// by a process crash between Begin and Commit finishing, and resolves each
+// one: a transaction whose COMMIT marker was written is finished by
+// replaying its staged renames/deletes; one without a marker never reached
+// the durable commit point, so it's rolled back from its snapshots. It
+// returns the IDs of every transaction it resolved, and should be called
+// once at startup, before any new transaction begins.
+func (tm *TransactionManager) RecoverTransactions(ctx context.Context) ([]string, error) {
+	root := tm.walRootDir()
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read WAL directory: %w", err)
+	}
+
+	var recovered []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		txnID := entry.Name()
+		dir := tm.walDir(txnID)
+
+		manifest, err := tm.readManifest(dir)
+		if err != nil {
+			logging.Get(logging.CategoryKernel).Error("WAL recovery: skipping %s: %v", txnID, err)
+			continue
+		}
+
+		if _, err := os.Stat(walCommitMarkerPath(dir)); err == nil {
+			if err := tm.applyWAL(dir, manifest); err != nil {
+				logging.Get(logging.CategoryKernel).Error("WAL recovery: failed to replay commit for %s: %v", txnID, err)
+				continue
+			}
+			for _, e := range manifest.Edits {
+				if e.EditType != EditTypeDelete {
+					tm.kernel.Assert(Fact{
+						Predicate: "file_written",
+						Args:      []interface{}{e.FilePath, e.NewHash, manifest.ID, time.Now().Unix()},
+					})
+				}
+			}
+			logging.KernelDebug("WAL recovery: replayed committed transaction %s", txnID)
+		} else {
+			if err := tm.rollbackWAL(dir, manifest); err != nil {
+				logging.Get(logging.CategoryKernel).Error("WAL recovery: failed to roll back %s: %v", txnID, err)
+				continue
+			}
+			logging.KernelDebug("WAL recovery: rolled back incomplete transaction %s", txnID)
+		}
+
+		tm.cleanupWAL(txnID)
+		recovered = append(recovered, txnID)
+	}
+
+	return recovered, nil
+}
+
+// writeAndSync writes data to path and fsyncs it before closing, so the
+// content is durable on disk before any later WAL phase depends on it.
+func writeAndSync(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -0,0 +1,303 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestTransactionManager(tmpDir string) *TransactionManager {
+	kernel := &RealKernel{
+		facts:       make([]Fact, 0),
+		policyDirty: true,
+		initialized: false,
+	}
+	return NewTransactionManager(kernel, tmpDir)
+}
+
+// TestTransactionManager_WALStagesAndApplies tests that writeWAL stages a
+// snapshot and new content, writeCommitMarker marks it durable, and applyWAL
+// then renames the staged content into place.
+func TestTransactionManager_WALStagesAndApplies(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.go")
+	if err := os.WriteFile(testFile, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := newTestTransactionManager(tmpDir)
+
+	txn, err := tm.Begin(context.Background(), "WAL test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{
+		FilePath: testFile,
+		Content:  []byte("updated"),
+		EditType: EditTypeModify,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := tm.walDir(txn.ID)
+	if err := tm.writeWAL(txn); err != nil {
+		t.Fatalf("writeWAL failed: %v", err)
+	}
+	if _, err := os.Stat(walSnapshotPath(dir, 0)); err != nil {
+		t.Errorf("expected snapshot file to exist: %v", err)
+	}
+	if _, err := os.Stat(walNewContentPath(dir, 0)); err != nil {
+		t.Errorf("expected staged new-content file to exist: %v", err)
+	}
+
+	if err := tm.writeCommitMarker(dir); err != nil {
+		t.Fatalf("writeCommitMarker failed: %v", err)
+	}
+
+	manifest, err := tm.readManifest(dir)
+	if err != nil {
+		t.Fatalf("readManifest failed: %v", err)
+	}
+	if err := tm.applyWAL(dir, manifest); err != nil {
+		t.Fatalf("applyWAL failed: %v", err)
+	}
+
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "updated" {
+		t.Errorf("expected file content %q, got %q", "updated", content)
+	}
+}
+
+// TestTransactionManager_RecoverTransactionsReplaysCommitted tests that a
+// WAL directory left behind with a COMMIT marker is finished on recovery.
+func TestTransactionManager_RecoverTransactionsReplaysCommitted(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.go")
+	if err := os.WriteFile(testFile, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := newTestTransactionManager(tmpDir)
+
+	txn, err := tm.Begin(context.Background(), "Recover committed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{
+		FilePath: testFile,
+		Content:  []byte("recovered"),
+		EditType: EditTypeModify,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := tm.walDir(txn.ID)
+	if err := tm.writeWAL(txn); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.writeCommitMarker(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash: the transaction never actually applied its staged
+	// content, and the next Begin is impossible since activeTxnID is still
+	// set - so spin up a fresh manager the way a restarted process would.
+	tm2 := newTestTransactionManager(tmpDir)
+
+	recovered, err := tm2.RecoverTransactions(context.Background())
+	if err != nil {
+		t.Fatalf("RecoverTransactions failed: %v", err)
+	}
+	if len(recovered) != 1 || recovered[0] != txn.ID {
+		t.Fatalf("expected to recover transaction %s, got %v", txn.ID, recovered)
+	}
+
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "recovered" {
+		t.Errorf("expected recovered content %q, got %q", "recovered", content)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected WAL directory to be cleaned up after recovery")
+	}
+}
+
+// TestTransactionManager_RecoverTransactionsRollsBackUncommitted tests that
+// a WAL directory left behind without a COMMIT marker is rolled back.
+func TestTransactionManager_RecoverTransactionsRollsBackUncommitted(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.go")
+	if err := os.WriteFile(testFile, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := newTestTransactionManager(tmpDir)
+
+	txn, err := tm.Begin(context.Background(), "Recover uncommitted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{
+		FilePath: testFile,
+		Content:  []byte("should never land"),
+		EditType: EditTypeModify,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := tm.walDir(txn.ID)
+	if err := tm.writeWAL(txn); err != nil {
+		t.Fatal(err)
+	}
+	// No commit marker written - simulates a crash during staging.
+
+	tm2 := newTestTransactionManager(tmpDir)
+	recovered, err := tm2.RecoverTransactions(context.Background())
+	if err != nil {
+		t.Fatalf("RecoverTransactions failed: %v", err)
+	}
+	if len(recovered) != 1 || recovered[0] != txn.ID {
+		t.Fatalf("expected to recover transaction %s, got %v", txn.ID, recovered)
+	}
+
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "original" {
+		t.Errorf("expected untouched original content %q, got %q", "original", content)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected WAL directory to be cleaned up after recovery")
+	}
+}
+
+// TestTransactionManager_RollbackRestoresEmptySnapshot verifies that rolling
+// back an edit whose pre-image was a legitimately empty (0-byte) file
+// restores it to empty, rather than deleting it outright - len(content) == 0
+// doesn't mean "no snapshot was taken".
+func TestTransactionManager_RollbackRestoresEmptySnapshot(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "empty.go")
+	if err := os.WriteFile(testFile, []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := newTestTransactionManager(tmpDir)
+
+	txn, err := tm.Begin(context.Background(), "Rollback empty snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{
+		FilePath: testFile,
+		Content:  []byte("should never land"),
+		EditType: EditTypeModify,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := tm.walDir(txn.ID)
+	if err := tm.writeWAL(txn); err != nil {
+		t.Fatal(err)
+	}
+	// No commit marker written - simulates a crash during staging.
+
+	tm2 := newTestTransactionManager(tmpDir)
+	recovered, err := tm2.RecoverTransactions(context.Background())
+	if err != nil {
+		t.Fatalf("RecoverTransactions failed: %v", err)
+	}
+	if len(recovered) != 1 || recovered[0] != txn.ID {
+		t.Fatalf("expected to recover transaction %s, got %v", txn.ID, recovered)
+	}
+
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("expected %s to still exist after rollback, got: %v", testFile, err)
+	}
+	if len(content) != 0 {
+		t.Errorf("expected file to be restored empty, got %q", content)
+	}
+}
+
+// TestTransactionManager_CommitCrashRecovery simulates a process crash
+// mid-commit (via the walCrashAfterApply test hook, after the first of two
+// files has been applied) and verifies that RecoverTransactions finishes
+// the remaining file without leaving anything in a torn state.
+func TestTransactionManager_CommitCrashRecovery(t *testing.T) {
+	tmpDir := t.TempDir()
+	fileA := filepath.Join(tmpDir, "a.go")
+	fileB := filepath.Join(tmpDir, "b.go")
+	if err := os.WriteFile(fileA, []byte("original-a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileB, []byte("original-b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := newTestTransactionManager(tmpDir)
+
+	txn, err := tm.Begin(context.Background(), "Crash mid-commit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{FilePath: fileA, Content: []byte("new-a"), EditType: EditTypeModify}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.AddEdit(context.Background(), FileEdit{FilePath: fileB, Content: []byte("new-b"), EditType: EditTypeModify}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Skip Prepare (exercised elsewhere) and move straight to Ready so Commit
+	// will accept this transaction.
+	txn.Status = TxnStatusReady
+
+	walCrashAfterApply = func(txnID string, index int) bool {
+		return txnID == txn.ID && index == 0
+	}
+	defer func() { walCrashAfterApply = nil }()
+
+	if err := tm.Commit(context.Background()); err == nil {
+		t.Fatal("expected Commit to report the simulated crash")
+	}
+
+	// fileA was applied before the simulated crash; fileB was not.
+	contentA, _ := os.ReadFile(fileA)
+	if string(contentA) != "new-a" {
+		t.Errorf("expected fileA to already be applied, got %q", contentA)
+	}
+	contentB, _ := os.ReadFile(fileB)
+	if string(contentB) != "original-b" {
+		t.Errorf("expected fileB to be untouched before recovery, got %q", contentB)
+	}
+
+	// "Restart the process" and recover.
+	tm2 := newTestTransactionManager(tmpDir)
+	recovered, err := tm2.RecoverTransactions(context.Background())
+	if err != nil {
+		t.Fatalf("RecoverTransactions failed: %v", err)
+	}
+	if len(recovered) != 1 || recovered[0] != txn.ID {
+		t.Fatalf("expected to recover transaction %s, got %v", txn.ID, recovered)
+	}
+
+	contentA, _ = os.ReadFile(fileA)
+	contentB, _ = os.ReadFile(fileB)
+	if string(contentA) != "new-a" {
+		t.Errorf("expected fileA to remain applied after recovery, got %q", contentA)
+	}
+	if string(contentB) != "new-b" {
+		t.Errorf("expected fileB to be applied by recovery, got %q", contentB)
+	}
+
+	if _, err := os.Stat(tm.walDir(txn.ID)); !os.IsNotExist(err) {
+		t.Errorf("expected WAL directory to be cleaned up after recovery")
+	}
+}
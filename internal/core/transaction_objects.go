@@ -0,0 +1,114 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// objectsRootDir returns the directory holding every content-addressed
+// snapshot blob, under .codenerd/objects in the project root.
+func (tm *TransactionManager) objectsRootDir() string {
+	return filepath.Join(tm.projectRoot, ".codenerd", "objects")
+}
+
+// objectPath returns the on-disk path for the blob addressed by hash,
+// sharded under a two-character prefix directory so no single directory
+// ends up holding every object a long-running session ever snapshotted.
+func (tm *TransactionManager) objectPath(hash string) string {
+	prefix := hash
+	if len(hash) > 2 {
+		prefix = hash[:2]
+	}
+	return filepath.Join(tm.objectsRootDir(), prefix, hash)
+}
+
+// writeObject durably stores content under its content hash and returns
+// that hash. Writing is a no-op past the Stat check when an object with the
+// same hash already exists, so repeatedly snapshotting an unchanged large
+// generated file costs one stat, not a copy.
+func (tm *TransactionManager) writeObject(content []byte) (string, error) {
+	hash := computeHash(content)
+	path := tm.objectPath(hash)
+
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create object directory: %w", err)
+	}
+	if err := writeAndSync(path, content); err != nil {
+		return "", fmt.Errorf("failed to write object %s: %w", hash, err)
+	}
+	return hash, nil
+}
+
+// readObject loads the blob addressed by hash.
+func (tm *TransactionManager) readObject(hash string) ([]byte, error) {
+	content, err := os.ReadFile(tm.objectPath(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", hash, err)
+	}
+	return content, nil
+}
+
+// GCObjects removes every object blob not referenced by a snapshot of any
+// transaction tm still knows about - in-flight, or archived in tm.store -
+// so storage freed by pruning old transactions from the store is actually
+// reclaimed on disk.
+func (tm *TransactionManager) GCObjects() (int, error) {
+	referenced := make(map[string]bool)
+
+	tm.mu.RLock()
+	for _, txn := range tm.txns {
+		for _, hash := range txn.Snapshots {
+			referenced[hash] = true
+		}
+	}
+	tm.mu.RUnlock()
+
+	if tm.store != nil {
+		archived, err := tm.store.List(TransactionFilter{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list archived transactions: %w", err)
+		}
+		for _, txn := range archived {
+			for _, hash := range txn.Snapshots {
+				referenced[hash] = true
+			}
+		}
+	}
+
+	root := tm.objectsRootDir()
+	shards, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read objects directory: %w", err)
+	}
+
+	removed := 0
+	for _, shard := range shards {
+		if !shard.IsDir() {
+			continue
+		}
+		shardDir := filepath.Join(root, shard.Name())
+		entries, err := os.ReadDir(shardDir)
+		if err != nil {
+			return removed, fmt.Errorf("failed to read object shard %s: %w", shard.Name(), err)
+		}
+		for _, entry := range entries {
+			if referenced[entry.Name()] {
+				continue
+			}
+			if err := os.Remove(filepath.Join(shardDir, entry.Name())); err != nil {
+				return removed, fmt.Errorf("failed to remove unreferenced object %s: %w", entry.Name(), err)
+			}
+			removed++
+		}
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTransactionManager_WriteObjectDedup tests that writing identical
+// content twice stores exactly one blob on disk.
+func TestTransactionManager_WriteObjectDedup(t *testing.T) {
+	tmpDir := t.TempDir()
+	tm := newTestTransactionManager(tmpDir)
+
+	content := []byte("package main\n\nfunc main() {}\n")
+
+	hash1, err := tm.writeObject(content)
+	if err != nil {
+		t.Fatalf("writeObject failed: %v", err)
+	}
+	hash2, err := tm.writeObject(content)
+	if err != nil {
+		t.Fatalf("writeObject failed: %v", err)
+	}
+	if hash1 != hash2 {
+		t.Errorf("expected identical content to hash the same, got %q and %q", hash1, hash2)
+	}
+
+	shardDir := filepath.Join(tm.objectsRootDir(), hash1[:2])
+	entries, err := os.ReadDir(shardDir)
+	if err != nil {
+		t.Fatalf("failed to read object shard: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected exactly one stored object, got %d", len(entries))
+	}
+}
+
+// TestTransactionManager_ReadObjectRoundTrip tests that content written via
+// writeObject comes back unchanged from readObject.
+func TestTransactionManager_ReadObjectRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	tm := newTestTransactionManager(tmpDir)
+
+	content := []byte("hello, object store")
+	hash, err := tm.writeObject(content)
+	if err != nil {
+		t.Fatalf("writeObject failed: %v", err)
+	}
+
+	got, err := tm.readObject(hash)
+	if err != nil {
+		t.Fatalf("readObject failed: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("expected %q, got %q", content, got)
+	}
+}
+
+// TestTransactionManager_GCObjectsSweepsUnreferenced tests that GCObjects
+// removes a blob no in-flight or archived transaction references while
+// preserving one that's still referenced.
+func TestTransactionManager_GCObjectsSweepsUnreferenced(t *testing.T) {
+	tmpDir := t.TempDir()
+	tm := newTestTransactionManager(tmpDir)
+
+	keptHash, err := tm.writeObject([]byte("kept"))
+	if err != nil {
+		t.Fatalf("writeObject failed: %v", err)
+	}
+	orphanHash, err := tm.writeObject([]byte("orphan"))
+	if err != nil {
+		t.Fatalf("writeObject failed: %v", err)
+	}
+
+	tm.txns["fake-txn"] = &Transaction{
+		ID:        "fake-txn",
+		Snapshots: map[string]string{"some/file.go": keptHash},
+	}
+
+	removed, err := tm.GCObjects()
+	if err != nil {
+		t.Fatalf("GCObjects failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 object removed, got %d", removed)
+	}
+
+	if _, err := tm.readObject(keptHash); err != nil {
+		t.Errorf("expected referenced object to survive GC: %v", err)
+	}
+	if _, err := tm.readObject(orphanHash); err == nil {
+		t.Error("expected unreferenced object to be removed by GC")
+	}
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// TransactionStore persists Transaction records independently of
+// TransactionManager, so committed/aborted history doesn't have to live
+// forever in the in-flight txns map. Save/Load/Delete address a single
+// transaction by ID; List supports the audit-style queries callers actually
+// want ("every edit that touched auth.go last week") via TransactionFilter.
+type TransactionStore interface {
+	Save(txn *Transaction) error
+	Load(id string) (*Transaction, error)
+	List(filter TransactionFilter) ([]*Transaction, error)
+	Delete(id string) error
+	Close() error
+}
+
+// TransactionFilter narrows List to transactions matching every set field;
+// the zero value matches everything.
+type TransactionFilter struct {
+	Status   TransactionStatus // "" matches any status
+	Since    time.Time         // zero matches any start time
+	Until    time.Time         // zero matches any start time
+	FilePath string            // "" matches any file; otherwise requires an edit touching this path
+}
+
+// matches reports whether txn satisfies every field set on f.
+func (f TransactionFilter) matches(txn *Transaction) bool {
+	if f.Status != "" && txn.Status != f.Status {
+		return false
+	}
+	if !f.Since.IsZero() && txn.StartTime.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && txn.StartTime.After(f.Until) {
+		return false
+	}
+	if f.FilePath != "" {
+		touched := false
+		for _, edit := range txn.Edits {
+			if edit.FilePath == f.FilePath {
+				touched = true
+				break
+			}
+		}
+		if !touched {
+			return false
+		}
+	}
+	return true
+}
+
+// storeLock models the inmem/disk locking regime OPA's storage package uses:
+// any number of concurrent readers, or a single writer that excludes every
+// reader. Save/Delete hold the write lock for their entire operation -
+// including any post-commit bookkeeping, like emitting an audit fact - so
+// no new read (or write) transaction can start until that commit-trigger
+// work has finished.
+type storeLock struct {
+	mu sync.RWMutex
+}
+
+func (l *storeLock) rlock()   { l.mu.RLock() }
+func (l *storeLock) runlock() { l.mu.RUnlock() }
+func (l *storeLock) lock()    { l.mu.Lock() }
+func (l *storeLock) unlock()  { l.mu.Unlock() }
+
+// TransactionStoreMetrics provides observability into a TransactionStore's
+// operation counts, mirroring APISchedulerMetrics's snapshot style.
+type TransactionStoreMetrics struct {
+	Saves   int64
+	Loads   int64
+	Lists   int64
+	Deletes int64
+	Errors  int64
+}
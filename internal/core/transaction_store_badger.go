@@ -0,0 +1,246 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"codenerd/internal/logging"
+)
+
+// BadgerTransactionStore is the durable TransactionStore backing long-running
+// codenerd sessions: transactions survive restarts and accumulate without
+// needing to stay resident in TransactionManager's in-flight map. Records
+// are partitioned by status and date so List can narrow a prefix scan
+// instead of walking every transaction ever committed.
+type BadgerTransactionStore struct {
+	db      *badger.DB
+	metrics TransactionStoreMetrics
+}
+
+// NewBadgerTransactionStore opens (or creates) a Badger-backed transaction
+// store at path.
+func NewBadgerTransactionStore(path string) (*BadgerTransactionStore, error) {
+	timer := logging.StartTimer(logging.CategoryKernel, "NewBadgerTransactionStore")
+	defer timer.Stop()
+
+	opts := badger.DefaultOptions(path)
+	opts.Logger = nil // Badger's default logger is noisy at Info level; we log our own events.
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transaction store at %s: %w", path, err)
+	}
+
+	logging.KernelDebug("Opened Badger transaction store at %s", path)
+	return &BadgerTransactionStore{db: db}, nil
+}
+
+// storedTransactionRecord is the JSON-serializable form of a Transaction.
+// Error is flattened to a string since error is an interface with no
+// general-purpose JSON representation.
+type storedTransactionRecord struct {
+	ID          string
+	Description string
+	StartTime   time.Time
+	Status      TransactionStatus
+	Edits       []FileEdit
+	Snapshots   map[string]string
+	Validation  *ShadowValidationResult
+	ErrorMsg    string
+}
+
+func toStoredRecord(txn *Transaction) storedTransactionRecord {
+	rec := storedTransactionRecord{
+		ID:          txn.ID,
+		Description: txn.Description,
+		StartTime:   txn.StartTime,
+		Status:      txn.Status,
+		Edits:       txn.Edits,
+		Snapshots:   txn.Snapshots,
+		Validation:  txn.Validation,
+	}
+	if txn.Error != nil {
+		rec.ErrorMsg = txn.Error.Error()
+	}
+	return rec
+}
+
+func (r storedTransactionRecord) toTransaction() *Transaction {
+	txn := &Transaction{
+		ID:          r.ID,
+		Description: r.Description,
+		StartTime:   r.StartTime,
+		Status:      r.Status,
+		Edits:       r.Edits,
+		Snapshots:   r.Snapshots,
+		Validation:  r.Validation,
+	}
+	if r.ErrorMsg != "" {
+		txn.Error = errors.New(r.ErrorMsg)
+	}
+	return txn
+}
+
+const (
+	badgerTxnPrefix = "txn/"
+	badgerIdxPrefix = "idx/"
+)
+
+// badgerPrimaryKey partitions records by status then date, so a List call
+// scoped to a status (and optionally a date range) can seek directly to the
+// relevant slice of keys instead of scanning every transaction.
+func badgerPrimaryKey(status TransactionStatus, startTime time.Time, id string) []byte {
+	return []byte(fmt.Sprintf("%s%s/%s/%s", badgerTxnPrefix, status, startTime.Format("2006-01-02"), id))
+}
+
+func badgerIndexKey(id string) []byte {
+	return []byte(badgerIdxPrefix + id)
+}
+
+// Save persists txn, keyed by status/date/id, and records an id->primary-key
+// index entry so Load/Delete can address it directly by id.
+func (s *BadgerTransactionStore) Save(txn *Transaction) error {
+	primaryKey := badgerPrimaryKey(txn.Status, txn.StartTime, txn.ID)
+
+	data, err := json.Marshal(toStoredRecord(txn))
+	if err != nil {
+		atomic.AddInt64(&s.metrics.Errors, 1)
+		return fmt.Errorf("failed to marshal transaction %s: %w", txn.ID, err)
+	}
+
+	err = s.db.Update(func(dbTxn *badger.Txn) error {
+		if err := dbTxn.Set(primaryKey, data); err != nil {
+			return err
+		}
+		return dbTxn.Set(badgerIndexKey(txn.ID), primaryKey)
+	})
+	if err != nil {
+		atomic.AddInt64(&s.metrics.Errors, 1)
+		return fmt.Errorf("failed to save transaction %s: %w", txn.ID, err)
+	}
+
+	atomic.AddInt64(&s.metrics.Saves, 1)
+	return nil
+}
+
+// Load looks up id via the index key, then fetches and unmarshals its
+// record.
+func (s *BadgerTransactionStore) Load(id string) (*Transaction, error) {
+	atomic.AddInt64(&s.metrics.Loads, 1)
+
+	var record storedTransactionRecord
+	err := s.db.View(func(dbTxn *badger.Txn) error {
+		idxItem, err := dbTxn.Get(badgerIndexKey(id))
+		if err != nil {
+			return err
+		}
+		primaryKey, err := idxItem.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+
+		item, err := dbTxn.Get(primaryKey)
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &record)
+		})
+	})
+	if err != nil {
+		atomic.AddInt64(&s.metrics.Errors, 1)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil, fmt.Errorf("transaction not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to load transaction %s: %w", id, err)
+	}
+
+	return record.toTransaction(), nil
+}
+
+// List scans the status/date-partitioned keyspace for every record matching
+// filter, narrowing the prefix scan to filter.Status's partition when set.
+func (s *BadgerTransactionStore) List(filter TransactionFilter) ([]*Transaction, error) {
+	atomic.AddInt64(&s.metrics.Lists, 1)
+
+	prefix := []byte(badgerTxnPrefix)
+	if filter.Status != "" {
+		prefix = []byte(fmt.Sprintf("%s%s/", badgerTxnPrefix, filter.Status))
+	}
+
+	results := make([]*Transaction, 0)
+	err := s.db.View(func(dbTxn *badger.Txn) error {
+		it := dbTxn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var record storedTransactionRecord
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &record)
+			}); err != nil {
+				return err
+			}
+
+			txn := record.toTransaction()
+			if filter.matches(txn) {
+				results = append(results, txn)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		atomic.AddInt64(&s.metrics.Errors, 1)
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	return results, nil
+}
+
+// Delete removes both the primary record and index entry for id.
+func (s *BadgerTransactionStore) Delete(id string) error {
+	err := s.db.Update(func(dbTxn *badger.Txn) error {
+		idxItem, err := dbTxn.Get(badgerIndexKey(id))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return nil
+			}
+			return err
+		}
+		primaryKey, err := idxItem.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		if err := dbTxn.Delete(primaryKey); err != nil {
+			return err
+		}
+		return dbTxn.Delete(badgerIndexKey(id))
+	})
+	if err != nil {
+		atomic.AddInt64(&s.metrics.Errors, 1)
+		return fmt.Errorf("failed to delete transaction %s: %w", id, err)
+	}
+
+	atomic.AddInt64(&s.metrics.Deletes, 1)
+	return nil
+}
+
+// Close releases the underlying Badger database handle.
+func (s *BadgerTransactionStore) Close() error {
+	return s.db.Close()
+}
+
+// Metrics returns a snapshot of this store's operation counts.
+func (s *BadgerTransactionStore) Metrics() TransactionStoreMetrics {
+	return TransactionStoreMetrics{
+		Saves:   atomic.LoadInt64(&s.metrics.Saves),
+		Loads:   atomic.LoadInt64(&s.metrics.Loads),
+		Lists:   atomic.LoadInt64(&s.metrics.Lists),
+		Deletes: atomic.LoadInt64(&s.metrics.Deletes),
+		Errors:  atomic.LoadInt64(&s.metrics.Errors),
+	}
+}
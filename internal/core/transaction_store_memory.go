@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// InMemoryTransactionStore is the default TransactionStore: a map guarded
+// by storeLock, with no persistence across restarts. It's what tests and
+// NewTransactionManager use unless a durable store (e.g.
+// NewBadgerTransactionStore) is supplied via NewTransactionManagerWithStore.
+type InMemoryTransactionStore struct {
+	lock    storeLock
+	data    map[string]*Transaction
+	metrics TransactionStoreMetrics
+}
+
+// NewInMemoryTransactionStore creates an empty in-memory transaction store.
+func NewInMemoryTransactionStore() *InMemoryTransactionStore {
+	return &InMemoryTransactionStore{data: make(map[string]*Transaction)}
+}
+
+// Save persists a copy of txn, keyed by txn.ID, overwriting any prior save.
+func (s *InMemoryTransactionStore) Save(txn *Transaction) error {
+	s.lock.lock()
+	defer s.lock.unlock()
+
+	clone := *txn
+	s.data[txn.ID] = &clone
+	atomic.AddInt64(&s.metrics.Saves, 1)
+	return nil
+}
+
+// Load returns a copy of the transaction saved under id.
+func (s *InMemoryTransactionStore) Load(id string) (*Transaction, error) {
+	s.lock.rlock()
+	defer s.lock.runlock()
+
+	atomic.AddInt64(&s.metrics.Loads, 1)
+	txn, ok := s.data[id]
+	if !ok {
+		atomic.AddInt64(&s.metrics.Errors, 1)
+		return nil, fmt.Errorf("transaction not found: %s", id)
+	}
+	clone := *txn
+	return &clone, nil
+}
+
+// List returns a copy of every saved transaction matching filter.
+func (s *InMemoryTransactionStore) List(filter TransactionFilter) ([]*Transaction, error) {
+	s.lock.rlock()
+	defer s.lock.runlock()
+
+	atomic.AddInt64(&s.metrics.Lists, 1)
+	results := make([]*Transaction, 0)
+	for _, txn := range s.data {
+		if !filter.matches(txn) {
+			continue
+		}
+		clone := *txn
+		results = append(results, &clone)
+	}
+	return results, nil
+}
+
+// Delete removes the transaction saved under id, if any.
+func (s *InMemoryTransactionStore) Delete(id string) error {
+	s.lock.lock()
+	defer s.lock.unlock()
+
+	delete(s.data, id)
+	atomic.AddInt64(&s.metrics.Deletes, 1)
+	return nil
+}
+
+// Close is a no-op; InMemoryTransactionStore holds nothing that needs
+// releasing.
+func (s *InMemoryTransactionStore) Close() error {
+	return nil
+}
+
+// Metrics returns a snapshot of this store's operation counts.
+func (s *InMemoryTransactionStore) Metrics() TransactionStoreMetrics {
+	return TransactionStoreMetrics{
+		Saves:   atomic.LoadInt64(&s.metrics.Saves),
+		Loads:   atomic.LoadInt64(&s.metrics.Loads),
+		Lists:   atomic.LoadInt64(&s.metrics.Lists),
+		Deletes: atomic.LoadInt64(&s.metrics.Deletes),
+		Errors:  atomic.LoadInt64(&s.metrics.Errors),
+	}
+}
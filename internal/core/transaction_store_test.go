@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInMemoryTransactionStore_SaveLoad(t *testing.T) {
+	store := NewInMemoryTransactionStore()
+
+	txn := &Transaction{
+		ID:        "txn_1",
+		Status:    TxnStatusCommitted,
+		StartTime: time.Now(),
+	}
+
+	if err := store.Save(txn); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load("txn_1")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.ID != txn.ID || loaded.Status != txn.Status {
+		t.Errorf("expected loaded transaction to match saved one, got %+v", loaded)
+	}
+
+	// Load returns a copy, not the original pointer.
+	loaded.Status = TxnStatusAborted
+	reloaded, err := store.Load("txn_1")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if reloaded.Status != TxnStatusCommitted {
+		t.Errorf("expected mutation of loaded copy not to affect the store, got %s", reloaded.Status)
+	}
+}
+
+func TestInMemoryTransactionStore_LoadMissing(t *testing.T) {
+	store := NewInMemoryTransactionStore()
+
+	if _, err := store.Load("does_not_exist"); err == nil {
+		t.Error("expected error loading missing transaction")
+	}
+}
+
+func TestInMemoryTransactionStore_Delete(t *testing.T) {
+	store := NewInMemoryTransactionStore()
+
+	txn := &Transaction{ID: "txn_1", Status: TxnStatusAborted}
+	if err := store.Save(txn); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := store.Delete("txn_1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Load("txn_1"); err == nil {
+		t.Error("expected error loading deleted transaction")
+	}
+}
+
+func TestInMemoryTransactionStore_ListFilter(t *testing.T) {
+	store := NewInMemoryTransactionStore()
+
+	committed := &Transaction{
+		ID:        "txn_committed",
+		Status:    TxnStatusCommitted,
+		StartTime: time.Now(),
+		Edits:     []FileEdit{{FilePath: "auth.go"}},
+	}
+	aborted := &Transaction{
+		ID:        "txn_aborted",
+		Status:    TxnStatusAborted,
+		StartTime: time.Now(),
+		Edits:     []FileEdit{{FilePath: "main.go"}},
+	}
+	if err := store.Save(committed); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(aborted); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := store.List(TransactionFilter{Status: TxnStatusCommitted})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "txn_committed" {
+		t.Errorf("expected only txn_committed, got %+v", results)
+	}
+
+	results, err = store.List(TransactionFilter{FilePath: "main.go"})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "txn_aborted" {
+		t.Errorf("expected only txn_aborted, got %+v", results)
+	}
+}
+
+func TestInMemoryTransactionStore_Metrics(t *testing.T) {
+	store := NewInMemoryTransactionStore()
+
+	txn := &Transaction{ID: "txn_1", Status: TxnStatusCommitted}
+	_ = store.Save(txn)
+	_, _ = store.Load("txn_1")
+	_, _ = store.Load("missing")
+	_, _ = store.List(TransactionFilter{})
+	_ = store.Delete("txn_1")
+
+	metrics := store.Metrics()
+	if metrics.Saves != 1 {
+		t.Errorf("expected 1 save, got %d", metrics.Saves)
+	}
+	if metrics.Loads != 2 {
+		t.Errorf("expected 2 loads, got %d", metrics.Loads)
+	}
+	if metrics.Lists != 1 {
+		t.Errorf("expected 1 list, got %d", metrics.Lists)
+	}
+	if metrics.Deletes != 1 {
+		t.Errorf("expected 1 delete, got %d", metrics.Deletes)
+	}
+	if metrics.Errors != 1 {
+		t.Errorf("expected 1 error (the missing load), got %d", metrics.Errors)
+	}
+}
+
+// TestTransactionManager_ArchivesOnCommit tests that a committed transaction
+// is removed from the in-flight map but remains retrievable via
+// GetTransaction and ListTransactions, backed by the configured store.
+func TestTransactionManager_ArchivesOnCommit(t *testing.T) {
+	tmpDir := t.TempDir()
+	tm := newTestTransactionManager(tmpDir)
+
+	txn, err := tm.Begin(context.Background(), "Archive test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn.Status = TxnStatusReady // skip Prepare; exercised elsewhere
+
+	if err := tm.Commit(context.Background()); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	tm.mu.RLock()
+	_, stillInFlight := tm.txns[txn.ID]
+	tm.mu.RUnlock()
+	if stillInFlight {
+		t.Error("expected committed transaction to be archived out of the in-flight map")
+	}
+
+	archived, exists := tm.GetTransaction(txn.ID)
+	if !exists {
+		t.Fatal("expected archived transaction to still be retrievable via GetTransaction")
+	}
+	if archived.Status != TxnStatusCommitted {
+		t.Errorf("expected archived status %s, got %s", TxnStatusCommitted, archived.Status)
+	}
+
+	results, err := tm.ListTransactions(TransactionFilter{Status: TxnStatusCommitted})
+	if err != nil {
+		t.Fatalf("ListTransactions failed: %v", err)
+	}
+	found := false
+	for _, r := range results {
+		if r.ID == txn.ID {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected ListTransactions to include archived transaction %s", txn.ID)
+	}
+}
@@ -0,0 +1,19 @@
+package core
+
+import "context"
+
+// Validator is a pluggable pre-commit check run during Prepare, alongside
+// TransactionManager's built-in shadow-mode simulation. Validators let
+// callers extend what "ready to commit" means for a transaction without
+// touching TransactionManager itself - register one with RegisterValidator.
+type Validator interface {
+	// Validate inspects txn and reports any problems it finds. A non-nil
+	// error means the validator itself failed to run (e.g. a kernel query
+	// error), which Prepare treats as a failed validation rather than
+	// silently skipping the check.
+	Validate(ctx context.Context, txn *Transaction) (*ShadowValidationResult, error)
+
+	// Name identifies the validator in logs and in Prepare's aggregated
+	// warnings.
+	Name() string
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// DefaultMaxDiffLines is the per-edit line-count threshold DiffSizeValidator
+// enforces when MaxLines isn't set.
+const DefaultMaxDiffLines = 2000
+
+// DiffSizeValidator blocks transactions whose edits are implausibly large -
+// a cheap guard against a runaway generator rewriting an entire file (or
+// repo) in a single edit.
+type DiffSizeValidator struct {
+	// MaxLines is the maximum number of lines a single edit's new content
+	// may contain. Zero means DefaultMaxDiffLines.
+	MaxLines int
+}
+
+// NewDiffSizeValidator creates a DiffSizeValidator with the given per-edit
+// line limit. Pass 0 to use DefaultMaxDiffLines.
+func NewDiffSizeValidator(maxLines int) *DiffSizeValidator {
+	return &DiffSizeValidator{MaxLines: maxLines}
+}
+
+func (v *DiffSizeValidator) Name() string { return "diff_size" }
+
+func (v *DiffSizeValidator) maxLines() int {
+	if v.MaxLines > 0 {
+		return v.MaxLines
+	}
+	return DefaultMaxDiffLines
+}
+
+// Validate blocks any non-delete edit whose new content exceeds the
+// configured line limit.
+func (v *DiffSizeValidator) Validate(ctx context.Context, txn *Transaction) (*ShadowValidationResult, error) {
+	result := &ShadowValidationResult{IsValid: true, SafetyBlocks: make([]SafetyBlock, 0)}
+
+	limit := v.maxLines()
+	for _, edit := range txn.Edits {
+		if edit.EditType == EditTypeDelete {
+			continue
+		}
+		lines := bytes.Count(edit.Content, []byte("\n")) + 1
+		if lines <= limit {
+			continue
+		}
+		result.IsValid = false
+		result.SafetyBlocks = append(result.SafetyBlocks, SafetyBlock{
+			Ref:    edit.FilePath,
+			Reason: fmt.Sprintf("edit is %d lines, exceeding the %d-line diff-size guard", lines, limit),
+			Rule:   "diff_size_guard",
+		})
+	}
+
+	return result, nil
+}
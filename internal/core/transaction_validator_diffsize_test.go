@@ -0,0 +1,68 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestDiffSizeValidator_BlocksOversizedEdit(t *testing.T) {
+	v := NewDiffSizeValidator(3)
+	txn := &Transaction{
+		Edits: []FileEdit{
+			{FilePath: "generated.go", Content: []byte("line1\nline2\nline3\nline4\nline5\n"), EditType: EditTypeModify},
+		},
+	}
+
+	result, err := v.Validate(context.Background(), txn)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if result.IsValid {
+		t.Error("expected oversized edit to fail validation")
+	}
+	if len(result.SafetyBlocks) != 1 || result.SafetyBlocks[0].Rule != "diff_size_guard" {
+		t.Errorf("expected one diff_size_guard block, got %+v", result.SafetyBlocks)
+	}
+}
+
+func TestDiffSizeValidator_AllowsSmallEdit(t *testing.T) {
+	v := NewDiffSizeValidator(100)
+	txn := &Transaction{
+		Edits: []FileEdit{
+			{FilePath: "main.go", Content: []byte("package main\n"), EditType: EditTypeModify},
+		},
+	}
+
+	result, err := v.Validate(context.Background(), txn)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !result.IsValid {
+		t.Errorf("expected small edit to pass, got %+v", result.SafetyBlocks)
+	}
+}
+
+func TestDiffSizeValidator_IgnoresDeletes(t *testing.T) {
+	v := NewDiffSizeValidator(1)
+	txn := &Transaction{
+		Edits: []FileEdit{
+			{FilePath: "huge.go", Content: bytes.Repeat([]byte("line\n"), 50), EditType: EditTypeDelete},
+		},
+	}
+
+	result, err := v.Validate(context.Background(), txn)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !result.IsValid {
+		t.Errorf("expected delete edits to be exempt from the diff-size guard, got %+v", result.SafetyBlocks)
+	}
+}
+
+func TestDiffSizeValidator_DefaultLimit(t *testing.T) {
+	v := NewDiffSizeValidator(0)
+	if v.maxLines() != DefaultMaxDiffLines {
+		t.Errorf("expected default limit %d, got %d", DefaultMaxDiffLines, v.maxLines())
+	}
+}
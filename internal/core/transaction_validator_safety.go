@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// SafetyPolicyValidator blocks edits to files the kernel's Datalog policy
+// has already flagged via a deny_edit fact. It's a real-kernel complement
+// to Prepare's shadow-mode deny_edit check, which only sees rules that
+// depend on the hypothetical post-edit state. It reads deny_edit facts
+// straight out of the kernel's asserted EDB (like transact.go's
+// fileWrittenCounts does for file_written facts) rather than through
+// Query, so it works whether or not the kernel's policy has been compiled.
+type SafetyPolicyValidator struct {
+	kernel *RealKernel
+}
+
+// NewSafetyPolicyValidator creates a SafetyPolicyValidator that checks
+// kernel's asserted facts for deny_edit entries.
+func NewSafetyPolicyValidator(kernel *RealKernel) *SafetyPolicyValidator {
+	return &SafetyPolicyValidator{kernel: kernel}
+}
+
+func (v *SafetyPolicyValidator) Name() string { return "safety_policy" }
+
+// Validate blocks any edit whose FilePath matches a deny_edit fact already
+// asserted in the kernel.
+func (v *SafetyPolicyValidator) Validate(ctx context.Context, txn *Transaction) (*ShadowValidationResult, error) {
+	result := &ShadowValidationResult{IsValid: true, SafetyBlocks: make([]SafetyBlock, 0)}
+
+	denied := make(map[string]string)
+	for _, f := range v.kernel.GetFactsSnapshot() {
+		if f.Predicate != "deny_edit" || len(f.Args) == 0 {
+			continue
+		}
+		ref := fmt.Sprintf("%v", f.Args[0])
+		reason := ""
+		if len(f.Args) > 1 {
+			reason = fmt.Sprintf("%v", f.Args[1])
+		}
+		denied[ref] = reason
+	}
+
+	for _, edit := range txn.Edits {
+		reason, blocked := denied[edit.FilePath]
+		if !blocked {
+			continue
+		}
+		result.IsValid = false
+		result.SafetyBlocks = append(result.SafetyBlocks, SafetyBlock{
+			Ref:    edit.FilePath,
+			Reason: reason,
+			Rule:   "deny_edit",
+		})
+	}
+
+	return result, nil
+}
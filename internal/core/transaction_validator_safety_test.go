@@ -0,0 +1,58 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSafetyPolicyValidator_BlocksDeniedFile(t *testing.T) {
+	kernel := &RealKernel{
+		facts:       make([]Fact, 0),
+		policyDirty: true,
+		initialized: false,
+	}
+	kernel.AssertWithoutEval(Fact{
+		Predicate: "deny_edit",
+		Args:      []interface{}{"secrets.go", "protected file"},
+	})
+
+	v := NewSafetyPolicyValidator(kernel)
+	txn := &Transaction{
+		Edits: []FileEdit{
+			{FilePath: "secrets.go", EditType: EditTypeModify},
+			{FilePath: "main.go", EditType: EditTypeModify},
+		},
+	}
+
+	result, err := v.Validate(context.Background(), txn)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if result.IsValid {
+		t.Error("expected a denied edit to fail validation")
+	}
+	if len(result.SafetyBlocks) != 1 || result.SafetyBlocks[0].Ref != "secrets.go" {
+		t.Errorf("expected one safety block for secrets.go, got %+v", result.SafetyBlocks)
+	}
+}
+
+func TestSafetyPolicyValidator_NoDeniedFiles(t *testing.T) {
+	kernel := &RealKernel{
+		facts:       make([]Fact, 0),
+		policyDirty: true,
+		initialized: false,
+	}
+
+	v := NewSafetyPolicyValidator(kernel)
+	txn := &Transaction{
+		Edits: []FileEdit{{FilePath: "main.go", EditType: EditTypeModify}},
+	}
+
+	result, err := v.Validate(context.Background(), txn)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !result.IsValid {
+		t.Errorf("expected no safety blocks, got %+v", result.SafetyBlocks)
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// GoSyntaxValidator parses each edit's staged .go content with go/parser -
+// the same approach x/tools' analysis checkers use - so a transaction that
+// would commit a syntax error is caught during Prepare, before Commit ever
+// touches the filesystem.
+type GoSyntaxValidator struct{}
+
+// NewGoSyntaxValidator creates a GoSyntaxValidator.
+func NewGoSyntaxValidator() *GoSyntaxValidator {
+	return &GoSyntaxValidator{}
+}
+
+func (v *GoSyntaxValidator) Name() string { return "go_syntax" }
+
+// Validate parses the new content of every non-deleted .go edit, reporting
+// a ParseError per syntax error go/parser finds.
+func (v *GoSyntaxValidator) Validate(ctx context.Context, txn *Transaction) (*ShadowValidationResult, error) {
+	result := &ShadowValidationResult{IsValid: true, ParseErrors: make([]ParseError, 0)}
+
+	fset := token.NewFileSet()
+	for _, edit := range txn.Edits {
+		if edit.EditType == EditTypeDelete || !strings.EqualFold(filepath.Ext(edit.FilePath), ".go") {
+			continue
+		}
+
+		if _, err := parser.ParseFile(fset, edit.FilePath, edit.Content, parser.AllErrors); err != nil {
+			result.IsValid = false
+			if errList, ok := err.(scanner.ErrorList); ok {
+				for _, e := range errList {
+					result.ParseErrors = append(result.ParseErrors, ParseError{
+						FilePath: edit.FilePath,
+						Line:     e.Pos.Line,
+						Column:   e.Pos.Column,
+						Message:  e.Msg,
+					})
+				}
+				continue
+			}
+			result.ParseErrors = append(result.ParseErrors, ParseError{
+				FilePath: edit.FilePath,
+				Message:  err.Error(),
+			})
+		}
+	}
+
+	return result, nil
+}
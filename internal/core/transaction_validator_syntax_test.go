@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGoSyntaxValidator_ValidCode(t *testing.T) {
+	v := NewGoSyntaxValidator()
+	txn := &Transaction{
+		Edits: []FileEdit{
+			{FilePath: "main.go", Content: []byte("package main\n\nfunc main() {}\n"), EditType: EditTypeCreate},
+		},
+	}
+
+	result, err := v.Validate(context.Background(), txn)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !result.IsValid {
+		t.Errorf("expected valid Go code to pass, got parse errors: %+v", result.ParseErrors)
+	}
+}
+
+func TestGoSyntaxValidator_InvalidCode(t *testing.T) {
+	v := NewGoSyntaxValidator()
+	txn := &Transaction{
+		Edits: []FileEdit{
+			{FilePath: "main.go", Content: []byte("package main\n\nfunc main( {\n"), EditType: EditTypeModify},
+		},
+	}
+
+	result, err := v.Validate(context.Background(), txn)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if result.IsValid {
+		t.Error("expected invalid Go code to fail validation")
+	}
+	if len(result.ParseErrors) == 0 {
+		t.Error("expected at least one parse error")
+	}
+}
+
+func TestGoSyntaxValidator_IgnoresNonGoAndDeletedFiles(t *testing.T) {
+	v := NewGoSyntaxValidator()
+	txn := &Transaction{
+		Edits: []FileEdit{
+			{FilePath: "README.md", Content: []byte("not go code {{{"), EditType: EditTypeModify},
+			{FilePath: "old.go", Content: []byte("func broken( {"), EditType: EditTypeDelete},
+		},
+	}
+
+	result, err := v.Validate(context.Background(), txn)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !result.IsValid {
+		t.Errorf("expected non-Go and deleted edits to be skipped, got parse errors: %+v", result.ParseErrors)
+	}
+}
@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const ansiReset = "\x1b[0m"
+const ansiBold = "\x1b[1m"
+
+// RenderOptions configures FormatANSI's output colors. Each field is a 24-bit
+// hex color (e.g. "#22c55e"); zero values fall back to FormatANSI's defaults.
+type RenderOptions struct {
+	ContextColor   string
+	AddColor       string
+	RemoveColor    string
+	HighlightColor string
+}
+
+// FormatANSI renders fd as 24-bit ANSI-colored unified-diff-style text,
+// bolding any Spans intra-line highlighting identified within a line (see
+// ComputeDiffWithOptions with EngineOptions.IntralineHighlight). Lines
+// without Spans render in their plain line color. This lets terminal/log
+// consumers get code-review-style coloring without re-implementing it.
+func FormatANSI(fd *FileDiff, opts RenderOptions) string {
+	addColor := ansiForeground(orDefault(opts.AddColor, "#22c55e"))
+	removeColor := ansiForeground(orDefault(opts.RemoveColor, "#ef4444"))
+	contextColor := ansiForeground(orDefault(opts.ContextColor, "#9ca3af"))
+	highlightColor := ansiForeground(orDefault(opts.HighlightColor, "#facc15"))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", orDefault(fd.OldPath, "/dev/null"), orDefault(fd.NewPath, "/dev/null"))
+
+	for _, hunk := range fd.Hunks {
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", hunk.OldStart, hunk.OldCount, hunk.NewStart, hunk.NewCount)
+		for _, line := range hunk.Lines {
+			switch line.Type {
+			case LineAdded:
+				sb.WriteString(formatANSILine("+", line, addColor, highlightColor))
+			case LineRemoved:
+				sb.WriteString(formatANSILine("-", line, removeColor, highlightColor))
+			default:
+				sb.WriteString(formatANSILine(" ", line, contextColor, highlightColor))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// formatANSILine renders one line with its prefix, applying baseColor to the
+// whole line or, if the line has Spans, baseColor to SpanEqual ranges and a
+// bolded highlightColor to SpanChanged ranges.
+func formatANSILine(prefix string, line Line, baseColor, highlightColor string) string {
+	if len(line.Spans) == 0 {
+		return baseColor + prefix + line.Content + ansiReset
+	}
+
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	for _, span := range line.Spans {
+		if span.Start < 0 || span.End > len(line.Content) || span.Start > span.End {
+			continue
+		}
+		text := line.Content[span.Start:span.End]
+		if span.Type == SpanChanged {
+			sb.WriteString(ansiBold + highlightColor + text + ansiReset)
+		} else {
+			sb.WriteString(baseColor + text + ansiReset)
+		}
+	}
+	return sb.String()
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// ansiForeground returns the 24-bit ANSI foreground color escape for hex
+// (e.g. "#22c55e"), defaulting to white if hex isn't a valid 6-digit color.
+func ansiForeground(hex string) string {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return "\x1b[38;2;255;255;255m"
+	}
+	r, errR := strconv.ParseInt(hex[0:2], 16, 32)
+	g, errG := strconv.ParseInt(hex[2:4], 16, 32)
+	b, errB := strconv.ParseInt(hex[4:6], 16, 32)
+	if errR != nil || errG != nil || errB != nil {
+		return "\x1b[38;2;255;255;255m"
+	}
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b)
+}
@@ -24,6 +24,12 @@ type Line struct {
 	LineNum int
 	Content string
 	Type    LineType
+
+	// Spans holds intra-line word diff ranges within Content, populated by
+	// ComputeDiffWithOptions when EngineOptions.IntralineHighlight is set.
+	// Nil unless intraline highlighting was requested and this line was
+	// paired with an adjacent opposite-type line within its hunk.
+	Spans []Span
 }
 
 // Hunk represents a group of changes
@@ -43,6 +49,27 @@ type FileDiff struct {
 	IsNew    bool
 	IsDelete bool
 	IsBinary bool
+
+	// OldNoNewlineAtEOF/NewNoNewlineAtEOF record whether the old/new content
+	// passed to ComputeDiff lacked a trailing newline, so FormatUnified knows
+	// when to emit the conventional "\ No newline at end of file" marker.
+	OldNoNewlineAtEOF bool
+	NewNoNewlineAtEOF bool
+
+	// OldLineCount/NewLineCount are the total line counts of the old/new
+	// content. FormatUnified uses them to tell whether a hunk's last line is
+	// actually the last line of the file before printing the no-newline marker.
+	OldLineCount int
+	NewLineCount int
+
+	// IsRename/IsCopy/SimilarityScore are populated by ComputeTreeDiff when
+	// OldPath/NewPath were matched as a detected rename or copy rather than
+	// diffed in place. SimilarityScore is the Jaccard similarity, in [0,1],
+	// that drove the match; a score of 1.0 means identical content, and such
+	// a FileDiff carries no hunks.
+	IsRename        bool
+	IsCopy          bool
+	SimilarityScore float64
 }
 
 // Engine provides diff computation with caching
@@ -88,6 +115,11 @@ func (e *Engine) ComputeDiff(oldPath, newPath, oldContent, newContent string) *F
 		fileDiff.IsDelete = true
 	}
 
+	fileDiff.OldLineCount = countLines(oldContent)
+	fileDiff.NewLineCount = countLines(newContent)
+	fileDiff.OldNoNewlineAtEOF = oldContent != "" && !strings.HasSuffix(oldContent, "\n")
+	fileDiff.NewNoNewlineAtEOF = newContent != "" && !strings.HasSuffix(newContent, "\n")
+
 	// Check cache
 	oldHash := hash(oldContent)
 	newHash := hash(newContent)
@@ -310,6 +342,19 @@ func (e *Engine) computeHunkCounts(hunk *Hunk) {
 	}
 }
 
+// countLines returns the number of lines in content, where a trailing
+// newline ends the last line rather than starting an extra empty one.
+func countLines(content string) int {
+	if content == "" {
+		return 0
+	}
+	n := strings.Count(content, "\n")
+	if !strings.HasSuffix(content, "\n") {
+		n++
+	}
+	return n
+}
+
 // hash computes a simple hash for caching (FNV-1a algorithm)
 func hash(s string) uint64 {
 	const (
@@ -0,0 +1,421 @@
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// noNewlineMarker is the line git/diff tools print immediately after a hunk
+// line whose content is the file's last line and lacks a trailing newline.
+const noNewlineMarker = "\\ No newline at end of file\n"
+
+// hunkFuzzWindow is how many lines ApplyPatch will search forward or
+// backward from a hunk's declared position before giving up.
+const hunkFuzzWindow = 20
+
+// FormatUnified renders fd as a standard unified diff, the same format
+// produced by "diff -u" and consumed by "git apply" / "patch". Binary files
+// are rendered as a single "Binary files ... differ" line with no hunks.
+func (e *Engine) FormatUnified(fd *FileDiff) string {
+	oldLabel, newLabel := unifiedLabels(fd)
+
+	var sb strings.Builder
+	if fd.IsBinary {
+		fmt.Fprintf(&sb, "Binary files %s and %s differ\n", oldLabel, newLabel)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "--- %s\n", oldLabel)
+	fmt.Fprintf(&sb, "+++ %s\n", newLabel)
+
+	for hi := range fd.Hunks {
+		hunk := &fd.Hunks[hi]
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", formatRange(hunk.OldStart, hunk.OldCount), formatRange(hunk.NewStart, hunk.NewCount))
+
+		oldPos := hunk.OldStart
+		newPos := hunk.NewStart
+		isLastHunk := hi == len(fd.Hunks)-1
+
+		for li, line := range hunk.Lines {
+			isLastLine := isLastHunk && li == len(hunk.Lines)-1
+
+			switch line.Type {
+			case LineContext:
+				sb.WriteString(" ")
+				sb.WriteString(line.Content)
+				sb.WriteString("\n")
+				if isLastLine && oldPos == fd.OldLineCount && newPos == fd.NewLineCount &&
+					(fd.OldNoNewlineAtEOF || fd.NewNoNewlineAtEOF) {
+					sb.WriteString(noNewlineMarker)
+				}
+				oldPos++
+				newPos++
+			case LineRemoved:
+				sb.WriteString("-")
+				sb.WriteString(line.Content)
+				sb.WriteString("\n")
+				if isLastLine && fd.OldNoNewlineAtEOF && oldPos == fd.OldLineCount {
+					sb.WriteString(noNewlineMarker)
+				}
+				oldPos++
+			case LineAdded:
+				sb.WriteString("+")
+				sb.WriteString(line.Content)
+				sb.WriteString("\n")
+				if isLastLine && fd.NewNoNewlineAtEOF && newPos == fd.NewLineCount {
+					sb.WriteString(noNewlineMarker)
+				}
+				newPos++
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// FormatUnified renders fd using the default engine.
+func FormatUnified(fd *FileDiff) string {
+	return DefaultEngine.FormatUnified(fd)
+}
+
+// unifiedLabels returns the "--- "/"+++ " header labels for fd, substituting
+// /dev/null for the side of a new or deleted file.
+func unifiedLabels(fd *FileDiff) (oldLabel, newLabel string) {
+	oldLabel = "a/" + fd.OldPath
+	newLabel = "b/" + fd.NewPath
+	if fd.IsNew {
+		oldLabel = "/dev/null"
+	}
+	if fd.IsDelete {
+		newLabel = "/dev/null"
+	}
+	return oldLabel, newLabel
+}
+
+// formatRange renders a hunk's "start,count" range, omitting the count when
+// it is 1 and using "start,0" when the range is empty, matching the
+// conventions of "diff -u".
+func formatRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// ApplyPatch re-materializes the content fd describes by applying its hunks
+// to original. Each hunk is located by its declared OldStart first; if the
+// surrounding content has shifted, ApplyPatch searches up to hunkFuzzWindow
+// lines in either direction for the hunk's context+removed lines before
+// giving up, the same fuzz tolerance "patch" offers. A hunk whose context
+// can't be found anywhere in that window is reported as an error identifying
+// which hunk failed.
+func (e *Engine) ApplyPatch(original string, fd *FileDiff) (string, error) {
+	if fd.IsBinary {
+		return "", fmt.Errorf("diff: ApplyPatch: cannot apply a binary patch for %s", fd.NewPath)
+	}
+
+	originalLines := splitLines(original)
+	var result []string
+	cursor := 0
+
+	for _, hunk := range fd.Hunks {
+		oldSide := make([]string, 0, len(hunk.Lines))
+		for _, l := range hunk.Lines {
+			if l.Type == LineContext || l.Type == LineRemoved {
+				oldSide = append(oldSide, l.Content)
+			}
+		}
+
+		var pos int
+		if len(oldSide) == 0 {
+			pos = cursor
+		} else {
+			var err error
+			pos, err = locateHunk(originalLines, cursor, hunk.OldStart-1, oldSide)
+			if err != nil {
+				return "", fmt.Errorf("diff: ApplyPatch: hunk @@ -%s +%s @@ for %s: %w",
+					formatRange(hunk.OldStart, hunk.OldCount), formatRange(hunk.NewStart, hunk.NewCount), fd.NewPath, err)
+			}
+		}
+
+		result = append(result, originalLines[cursor:pos]...)
+		for _, l := range hunk.Lines {
+			if l.Type == LineContext || l.Type == LineAdded {
+				result = append(result, l.Content)
+			}
+		}
+		cursor = pos + len(oldSide)
+	}
+
+	result = append(result, originalLines[cursor:]...)
+
+	newContent := strings.Join(result, "\n")
+	if len(result) > 0 && !fd.NewNoNewlineAtEOF {
+		newContent += "\n"
+	}
+	return newContent, nil
+}
+
+// ApplyPatch applies fd to original using the default engine.
+func ApplyPatch(original string, fd *FileDiff) (string, error) {
+	return DefaultEngine.ApplyPatch(original, fd)
+}
+
+// locateHunk finds where in lines (at or after cursor) the sequence want
+// occurs, preferring the declared position wantStart and otherwise searching
+// outward within hunkFuzzWindow lines.
+func locateHunk(lines []string, cursor, wantStart int, want []string) (int, error) {
+	if matchesAt(lines, wantStart, want) {
+		return wantStart, nil
+	}
+
+	for offset := 1; offset <= hunkFuzzWindow; offset++ {
+		if pos := wantStart + offset; matchesAt(lines, pos, want) {
+			return pos, nil
+		}
+		if pos := wantStart - offset; pos >= cursor && matchesAt(lines, pos, want) {
+			return pos, nil
+		}
+	}
+
+	return 0, fmt.Errorf("context mismatch: expected %d line(s) starting near line %d, found no match within %d lines",
+		len(want), wantStart+1, hunkFuzzWindow)
+}
+
+// matchesAt reports whether want occurs in lines starting at start.
+func matchesAt(lines []string, start int, want []string) bool {
+	if start < 0 || start+len(want) > len(lines) {
+		return false
+	}
+	for i, w := range want {
+		if lines[start+i] != w {
+			return false
+		}
+	}
+	return true
+}
+
+// splitLines splits content into lines without their trailing newlines. An
+// empty string yields no lines.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.Split(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// ParseUnified parses one or more unified diffs from r, such as the output
+// of FormatUnified, "diff -u", or "git diff", so patches produced by
+// external tools can be round-tripped through this package.
+func ParseUnified(r io.Reader) ([]*FileDiff, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	var result []*FileDiff
+	var current *FileDiff
+	var currentHunk *Hunk
+	var oldPos, newPos int
+	var lastLineType LineType
+	var haveLastLine bool
+
+	finishHunk := func() {
+		if current != nil && currentHunk != nil {
+			current.Hunks = append(current.Hunks, *currentHunk)
+			currentHunk = nil
+		}
+	}
+	finishFile := func() {
+		finishHunk()
+		if current != nil {
+			current.OldLineCount = oldPos
+			current.NewLineCount = newPos
+		}
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "), strings.HasPrefix(line, "index "):
+			continue
+
+		case strings.HasPrefix(line, "Binary files "):
+			finishFile()
+			fd, err := parseBinaryLine(line)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, fd)
+			current = nil
+
+		case strings.HasPrefix(line, "--- "):
+			finishFile()
+			current = &FileDiff{}
+			oldLabel := strings.TrimPrefix(line, "--- ")
+			if oldLabel == "/dev/null" {
+				current.IsNew = true
+			} else {
+				current.OldPath = stripGitPrefix(oldLabel)
+			}
+			result = append(result, current)
+			oldPos, newPos = 0, 0
+
+		case strings.HasPrefix(line, "+++ "):
+			if current == nil {
+				return nil, fmt.Errorf("diff: ParseUnified: found \"+++\" header without a preceding \"---\" header")
+			}
+			newLabel := strings.TrimPrefix(line, "+++ ")
+			if newLabel == "/dev/null" {
+				current.IsDelete = true
+			} else {
+				current.NewPath = stripGitPrefix(newLabel)
+			}
+
+		case strings.HasPrefix(line, "@@ "):
+			if current == nil {
+				return nil, fmt.Errorf("diff: ParseUnified: found a hunk header outside of a file diff")
+			}
+			finishHunk()
+			h, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			currentHunk = h
+			oldPos, newPos = h.OldStart, h.NewStart
+			haveLastLine = false
+
+		case strings.HasPrefix(line, "\\ No newline at end of file"):
+			if current != nil && haveLastLine {
+				switch lastLineType {
+				case LineContext:
+					current.OldNoNewlineAtEOF = true
+					current.NewNoNewlineAtEOF = true
+				case LineRemoved:
+					current.OldNoNewlineAtEOF = true
+				case LineAdded:
+					current.NewNoNewlineAtEOF = true
+				}
+			}
+
+		default:
+			if currentHunk == nil {
+				continue
+			}
+			marker, content := byte(' '), line
+			if len(line) > 0 {
+				marker, content = line[0], line[1:]
+			}
+			switch marker {
+			case ' ':
+				currentHunk.Lines = append(currentHunk.Lines, Line{LineNum: oldPos + 1, Content: content, Type: LineContext})
+				oldPos++
+				newPos++
+				lastLineType = LineContext
+			case '+':
+				currentHunk.Lines = append(currentHunk.Lines, Line{LineNum: newPos + 1, Content: content, Type: LineAdded})
+				newPos++
+				lastLineType = LineAdded
+			case '-':
+				currentHunk.Lines = append(currentHunk.Lines, Line{LineNum: oldPos + 1, Content: content, Type: LineRemoved})
+				oldPos++
+				lastLineType = LineRemoved
+			default:
+				return nil, fmt.Errorf("diff: ParseUnified: unrecognized hunk line %q", line)
+			}
+			haveLastLine = true
+		}
+	}
+	finishFile()
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("diff: ParseUnified: failed to read patch: %w", err)
+	}
+
+	return result, nil
+}
+
+// parseHunkHeader parses a "@@ -oldStart[,oldCount] +newStart[,newCount] @@" line.
+func parseHunkHeader(line string) (*Hunk, error) {
+	body := strings.TrimPrefix(line, "@@ ")
+	if idx := strings.Index(body, " @@"); idx >= 0 {
+		body = body[:idx]
+	}
+
+	fields := strings.Fields(body)
+	if len(fields) != 2 || !strings.HasPrefix(fields[0], "-") || !strings.HasPrefix(fields[1], "+") {
+		return nil, fmt.Errorf("diff: ParseUnified: malformed hunk header %q", line)
+	}
+
+	oldStart, oldCount, err := parseRange(fields[0][1:])
+	if err != nil {
+		return nil, fmt.Errorf("diff: ParseUnified: malformed hunk header %q: %w", line, err)
+	}
+	newStart, newCount, err := parseRange(fields[1][1:])
+	if err != nil {
+		return nil, fmt.Errorf("diff: ParseUnified: malformed hunk header %q: %w", line, err)
+	}
+
+	return &Hunk{OldStart: oldStart, OldCount: oldCount, NewStart: newStart, NewCount: newCount}, nil
+}
+
+// parseRange parses a "start[,count]" hunk range, where a missing count
+// means 1 (the inverse of formatRange's count-omitted-when-1 rule).
+func parseRange(s string) (start, count int, err error) {
+	parts := strings.SplitN(s, ",", 2)
+	start, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(parts) == 1 {
+		return start, 1, nil
+	}
+	count, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, count, nil
+}
+
+// parseBinaryLine parses a "Binary files a/path and b/path differ" line.
+func parseBinaryLine(line string) (*FileDiff, error) {
+	body := strings.TrimSuffix(strings.TrimPrefix(line, "Binary files "), " differ")
+	oldLabel, newLabel, ok := strings.Cut(body, " and ")
+	if !ok {
+		return nil, fmt.Errorf("diff: ParseUnified: malformed binary file line %q", line)
+	}
+
+	fd := &FileDiff{IsBinary: true}
+	if oldLabel == "/dev/null" {
+		fd.IsNew = true
+	} else {
+		fd.OldPath = stripGitPrefix(oldLabel)
+	}
+	if newLabel == "/dev/null" {
+		fd.IsDelete = true
+	} else {
+		fd.NewPath = stripGitPrefix(newLabel)
+	}
+	return fd, nil
+}
+
+// stripGitPrefix removes the "a/" or "b/" prefix git-style tools put on
+// paths in unified diff headers, if present.
+func stripGitPrefix(path string) string {
+	if rest, ok := strings.CutPrefix(path, "a/"); ok {
+		return rest
+	}
+	if rest, ok := strings.CutPrefix(path, "b/"); ok {
+		return rest
+	}
+	return path
+}
@@ -0,0 +1,229 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatUnified_SimpleAddition(t *testing.T) {
+	oldContent := "line1\nline2\nline3\n"
+	newContent := "line1\nline2\nline2.5\nline3\n"
+
+	engine := NewEngine()
+	fd := engine.ComputeDiff("a.txt", "a.txt", oldContent, newContent)
+	patch := engine.FormatUnified(fd)
+
+	if !strings.HasPrefix(patch, "--- a/a.txt\n+++ b/a.txt\n") {
+		t.Fatalf("expected standard file headers, got:\n%s", patch)
+	}
+	if !strings.Contains(patch, "+line2.5\n") {
+		t.Fatalf("expected an added line, got:\n%s", patch)
+	}
+	if strings.Contains(patch, "No newline at end of file") {
+		t.Fatalf("did not expect a no-newline marker, got:\n%s", patch)
+	}
+}
+
+func TestFormatUnified_NewFile(t *testing.T) {
+	fd := ComputeDiff("", "new.txt", "", "hello\nworld\n")
+	patch := FormatUnified(fd)
+
+	if !strings.Contains(patch, "--- /dev/null\n") {
+		t.Fatalf("expected /dev/null old side for a new file, got:\n%s", patch)
+	}
+	if !strings.Contains(patch, "+++ b/new.txt\n") {
+		t.Fatalf("expected a new.txt new side, got:\n%s", patch)
+	}
+}
+
+func TestFormatUnified_DeletedFile(t *testing.T) {
+	fd := ComputeDiff("gone.txt", "", "hello\nworld\n", "")
+	patch := FormatUnified(fd)
+
+	if !strings.Contains(patch, "--- a/gone.txt\n") {
+		t.Fatalf("expected a gone.txt old side, got:\n%s", patch)
+	}
+	if !strings.Contains(patch, "+++ /dev/null\n") {
+		t.Fatalf("expected /dev/null new side for a deleted file, got:\n%s", patch)
+	}
+}
+
+func TestFormatUnified_NoNewlineAtEOF(t *testing.T) {
+	oldContent := "line1\nline2"
+	newContent := "line1\nline2\nline3"
+
+	fd := ComputeDiff("a.txt", "a.txt", oldContent, newContent)
+	patch := FormatUnified(fd)
+
+	if strings.Count(patch, "No newline at end of file") != 1 {
+		t.Fatalf("expected exactly one no-newline marker, got:\n%s", patch)
+	}
+	if !strings.HasSuffix(patch, "+line3\n\\ No newline at end of file\n") {
+		t.Fatalf("expected the marker right after the final added line, got:\n%s", patch)
+	}
+}
+
+func TestFormatUnified_CountOmittedWhenOne(t *testing.T) {
+	fd := ComputeDiff("a.txt", "a.txt", "a\nb\nc\n", "a\nx\nc\n")
+	patch := FormatUnified(fd)
+
+	if !strings.Contains(patch, "@@ -1,3 +1,3 @@\n") {
+		t.Fatalf("expected a 3-line hunk header, got:\n%s", patch)
+	}
+}
+
+func TestApplyPatch_RoundTripsSimpleAddition(t *testing.T) {
+	oldContent := "line1\nline2\nline3\n"
+	newContent := "line1\nline2\nline2.5\nline3\n"
+
+	fd := ComputeDiff("a.txt", "a.txt", oldContent, newContent)
+	result, err := ApplyPatch(oldContent, fd)
+	if err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if result != newContent {
+		t.Fatalf("expected %q, got %q", newContent, result)
+	}
+}
+
+func TestApplyPatch_RoundTripsMultipleHunks(t *testing.T) {
+	oldContent := strings.Repeat("same\n", 10) +
+		"old-a\n" + strings.Repeat("same\n", 10) + "old-b\n" + strings.Repeat("same\n", 10)
+	newContent := strings.Repeat("same\n", 10) +
+		"new-a\n" + strings.Repeat("same\n", 10) + "new-b\n" + strings.Repeat("same\n", 10)
+
+	fd := ComputeDiff("a.txt", "a.txt", oldContent, newContent)
+	if len(fd.Hunks) < 2 {
+		t.Fatalf("expected at least 2 hunks for this fixture, got %d", len(fd.Hunks))
+	}
+
+	result, err := ApplyPatch(oldContent, fd)
+	if err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if result != newContent {
+		t.Fatalf("expected %q, got %q", newContent, result)
+	}
+}
+
+func TestApplyPatch_NoNewlineAtEOF(t *testing.T) {
+	oldContent := "line1\nline2"
+	newContent := "line1\nline2\nline3"
+
+	fd := ComputeDiff("a.txt", "a.txt", oldContent, newContent)
+	result, err := ApplyPatch(oldContent, fd)
+	if err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if result != newContent {
+		t.Fatalf("expected %q, got %q", newContent, result)
+	}
+}
+
+func TestApplyPatch_ContextMismatchReturnsError(t *testing.T) {
+	fd := ComputeDiff("a.txt", "a.txt", "a\nb\nc\n", "a\nx\nc\n")
+
+	_, err := ApplyPatch("completely\ndifferent\ncontent\n", fd)
+	if err == nil {
+		t.Fatal("expected an error when a hunk's context can't be found")
+	}
+	if !strings.Contains(err.Error(), "context mismatch") {
+		t.Fatalf("expected a context mismatch error, got: %v", err)
+	}
+}
+
+func TestApplyPatch_FuzzyMatchesShiftedContext(t *testing.T) {
+	oldContent := "a\nb\nc\n"
+	fd := ComputeDiff("a.txt", "a.txt", oldContent, "a\nx\nc\n")
+
+	// Prepend extra lines so the hunk's declared OldStart no longer lines up,
+	// but its context is still present a few lines further down.
+	shifted := "extra1\nextra2\nextra3\n" + oldContent
+	result, err := ApplyPatch(shifted, fd)
+	if err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if result != "extra1\nextra2\nextra3\na\nx\nc\n" {
+		t.Fatalf("unexpected result: %q", result)
+	}
+}
+
+func TestParseUnified_RoundTripsFormatUnified(t *testing.T) {
+	oldContent := "line1\nline2\nline3\n"
+	newContent := "line1\nline2\nline2.5\nline3\n"
+
+	fd := ComputeDiff("a.txt", "a.txt", oldContent, newContent)
+	patch := FormatUnified(fd)
+
+	parsed, err := ParseUnified(strings.NewReader(patch))
+	if err != nil {
+		t.Fatalf("ParseUnified: %v", err)
+	}
+	if len(parsed) != 1 {
+		t.Fatalf("expected 1 file diff, got %d", len(parsed))
+	}
+
+	result, err := ApplyPatch(oldContent, parsed[0])
+	if err != nil {
+		t.Fatalf("ApplyPatch on parsed diff: %v", err)
+	}
+	if result != newContent {
+		t.Fatalf("expected %q, got %q", newContent, result)
+	}
+}
+
+func TestParseUnified_NewAndDeletedFiles(t *testing.T) {
+	patch := FormatUnified(ComputeDiff("", "new.txt", "", "hello\n")) +
+		FormatUnified(ComputeDiff("gone.txt", "", "hello\n", ""))
+
+	parsed, err := ParseUnified(strings.NewReader(patch))
+	if err != nil {
+		t.Fatalf("ParseUnified: %v", err)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("expected 2 file diffs, got %d", len(parsed))
+	}
+	if !parsed[0].IsNew || parsed[0].NewPath != "new.txt" {
+		t.Fatalf("expected the first diff to be a new file new.txt, got %+v", parsed[0])
+	}
+	if !parsed[1].IsDelete || parsed[1].OldPath != "gone.txt" {
+		t.Fatalf("expected the second diff to be a deleted file gone.txt, got %+v", parsed[1])
+	}
+}
+
+func TestParseUnified_PreservesNoNewlineMarker(t *testing.T) {
+	oldContent := "line1\nline2"
+	newContent := "line1\nline2\nline3"
+
+	patch := FormatUnified(ComputeDiff("a.txt", "a.txt", oldContent, newContent))
+	parsed, err := ParseUnified(strings.NewReader(patch))
+	if err != nil {
+		t.Fatalf("ParseUnified: %v", err)
+	}
+	if len(parsed) != 1 {
+		t.Fatalf("expected 1 file diff, got %d", len(parsed))
+	}
+	if !parsed[0].NewNoNewlineAtEOF {
+		t.Fatal("expected NewNoNewlineAtEOF to be preserved across the round trip")
+	}
+
+	result, err := ApplyPatch(oldContent, parsed[0])
+	if err != nil {
+		t.Fatalf("ApplyPatch on parsed diff: %v", err)
+	}
+	if result != newContent {
+		t.Fatalf("expected %q, got %q", newContent, result)
+	}
+}
+
+func TestFormatRange_OmitsCountOfOne(t *testing.T) {
+	if got := formatRange(5, 1); got != "5" {
+		t.Fatalf("expected \"5\", got %q", got)
+	}
+	if got := formatRange(5, 3); got != "5,3" {
+		t.Fatalf("expected \"5,3\", got %q", got)
+	}
+	if got := formatRange(0, 0); got != "0,0" {
+		t.Fatalf("expected \"0,0\", got %q", got)
+	}
+}
@@ -0,0 +1,310 @@
+package diff
+
+import "sort"
+
+// defaultSimilarityThreshold is the minimum Jaccard similarity score, out of
+// 1.0, a deleted/added file pair must reach to be reported as a rename or
+// copy, matching git's default "-M50%/-C50%" behavior.
+const defaultSimilarityThreshold = 0.5
+
+// defaultMaxRenameCandidates caps how many deleted/added files are compared
+// against each other, bounding the worst-case O(n*m) scoring pass.
+const defaultMaxRenameCandidates = 200
+
+// shingleWindow is the number of lines hashed together into one shingle when
+// fingerprinting a file for similarity scoring.
+const shingleWindow = 5
+
+// TreeDiffOptions configures ComputeTreeDiff.
+type TreeDiffOptions struct {
+	// SimilarityThreshold is the minimum Jaccard similarity, in [0,1], for a
+	// deleted/added pair to be reported as a rename or copy. Zero means
+	// defaultSimilarityThreshold.
+	SimilarityThreshold float64
+
+	// DetectCopies also reports high-similarity added files that still have
+	// a surviving file at their old path as copies, not just renames. When
+	// false, only deleted-then-added pairs are considered.
+	DetectCopies bool
+
+	// MaxCandidates caps how many deleted files are compared against how
+	// many added files during similarity scoring. Zero means
+	// defaultMaxRenameCandidates.
+	MaxCandidates int
+}
+
+// TreeDiff is the result of diffing two entire file trees, keyed by path.
+type TreeDiff struct {
+	Files []*FileDiff
+}
+
+// similarityCandidate is one deleted-or-copy-source / added file pairing
+// considered for rename/copy detection.
+type similarityCandidate struct {
+	oldPath string
+	newPath string
+	score   float64
+}
+
+// ComputeTreeDiff diffs two file trees (path -> content) and, beyond the
+// per-file diffs ComputeDiff already produces, detects renames and copies:
+// files with identical content are paired as exact renames, and remaining
+// deleted/added files are scored by shingled-fingerprint Jaccard similarity
+// and greedily matched above opts.SimilarityThreshold. A rename/copy with
+// SimilarityScore 1.0 carries no hunk, since its content didn't change.
+func (e *Engine) ComputeTreeDiff(old, new map[string]string, opts TreeDiffOptions) *TreeDiff {
+	threshold := opts.SimilarityThreshold
+	if threshold <= 0 {
+		threshold = defaultSimilarityThreshold
+	}
+	maxCandidates := opts.MaxCandidates
+	if maxCandidates <= 0 {
+		maxCandidates = defaultMaxRenameCandidates
+	}
+
+	deleted := make(map[string]string)
+	for path, content := range old {
+		if _, ok := new[path]; !ok {
+			deleted[path] = content
+		}
+	}
+	added := make(map[string]string)
+	for path, content := range new {
+		if _, ok := old[path]; !ok {
+			added[path] = content
+		}
+	}
+
+	renameOf := make(map[string]string) // newPath -> oldPath
+	copyOf := make(map[string]string)   // newPath -> oldPath
+	scoreOf := make(map[string]float64) // newPath -> similarity score
+
+	matchExactRenames(deleted, added, renameOf, scoreOf)
+	matchFuzzyRenamesAndCopies(old, deleted, added, opts.DetectCopies, threshold, maxCandidates, renameOf, copyOf, scoreOf)
+
+	var result TreeDiff
+	for newPath, content := range new {
+		if oldPath, ok := renameOf[newPath]; ok {
+			result.Files = append(result.Files, e.buildRenameOrCopyDiff(oldPath, newPath, old[oldPath], content, scoreOf[newPath], false))
+			delete(deleted, oldPath)
+			continue
+		}
+		if oldPath, ok := copyOf[newPath]; ok {
+			result.Files = append(result.Files, e.buildRenameOrCopyDiff(oldPath, newPath, old[oldPath], content, scoreOf[newPath], true))
+			continue
+		}
+		if oldContent, ok := old[newPath]; ok {
+			if oldContent == content {
+				continue // unchanged file; nothing to report
+			}
+			result.Files = append(result.Files, e.ComputeDiff(newPath, newPath, oldContent, content))
+			continue
+		}
+		result.Files = append(result.Files, e.ComputeDiff("", newPath, "", content))
+	}
+	for oldPath, content := range deleted {
+		result.Files = append(result.Files, e.ComputeDiff(oldPath, "", content, ""))
+	}
+
+	sort.Slice(result.Files, func(i, j int) bool {
+		return treeDiffSortKey(result.Files[i]) < treeDiffSortKey(result.Files[j])
+	})
+
+	return &result
+}
+
+// ComputeTreeDiff diffs old and new using the default engine.
+func ComputeTreeDiff(old, new map[string]string, opts TreeDiffOptions) *TreeDiff {
+	return DefaultEngine.ComputeTreeDiff(old, new, opts)
+}
+
+// treeDiffSortKey returns the path ComputeTreeDiff's output is sorted by, so
+// results are stable regardless of map iteration order.
+func treeDiffSortKey(fd *FileDiff) string {
+	if fd.NewPath != "" {
+		return fd.NewPath
+	}
+	return fd.OldPath
+}
+
+// matchExactRenames pairs up deleted and added files with identical content
+// as 100%-similarity renames, removing matched entries from deleted/added.
+func matchExactRenames(deleted, added map[string]string, renameOf map[string]string, scoreOf map[string]float64) {
+	byContent := make(map[string][]string, len(deleted))
+	for oldPath, content := range deleted {
+		byContent[content] = append(byContent[content], oldPath)
+	}
+
+	var addedPaths []string
+	for newPath := range added {
+		addedPaths = append(addedPaths, newPath)
+	}
+	sort.Strings(addedPaths)
+
+	for _, newPath := range addedPaths {
+		candidates := byContent[added[newPath]]
+		if len(candidates) == 0 {
+			continue
+		}
+		oldPath := candidates[0]
+		byContent[added[newPath]] = candidates[1:]
+
+		renameOf[newPath] = oldPath
+		scoreOf[newPath] = 1.0
+		delete(deleted, oldPath)
+		delete(added, newPath)
+	}
+}
+
+// matchFuzzyRenamesAndCopies scores remaining deleted/added pairs by
+// shingled-fingerprint Jaccard similarity and greedily matches the highest
+// scores above threshold, bounded to maxCandidates deleted files and
+// maxCandidates added files.
+func matchFuzzyRenamesAndCopies(old map[string]string, deleted, added map[string]string, detectCopies bool, threshold float64, maxCandidates int, renameOf, copyOf map[string]string, scoreOf map[string]float64) {
+	// Rename sources are limited to deleted files; copy sources, when
+	// enabled, are every old file, since a copy's source need not disappear.
+	sourcePool := deleted
+	if detectCopies {
+		sourcePool = old
+	}
+
+	oldPaths := sortedKeysCapped(sourcePool, maxCandidates)
+	newPaths := sortedKeysCapped(added, maxCandidates)
+	if len(oldPaths) == 0 || len(newPaths) == 0 {
+		return
+	}
+
+	fingerprints := make(map[string]map[uint64]struct{}, len(oldPaths)+len(newPaths))
+	for _, p := range oldPaths {
+		fingerprints[p] = shingleFingerprint(sourcePool[p])
+	}
+	for _, p := range newPaths {
+		fingerprints[p] = shingleFingerprint(added[p])
+	}
+
+	var candidates []similarityCandidate
+	for _, oldPath := range oldPaths {
+		for _, newPath := range newPaths {
+			score := jaccardSimilarity(fingerprints[oldPath], fingerprints[newPath])
+			if score >= threshold {
+				candidates = append(candidates, similarityCandidate{oldPath: oldPath, newPath: newPath, score: score})
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		if candidates[i].oldPath != candidates[j].oldPath {
+			return candidates[i].oldPath < candidates[j].oldPath
+		}
+		return candidates[i].newPath < candidates[j].newPath
+	})
+
+	usedOld := make(map[string]bool, len(oldPaths))
+	usedNew := make(map[string]bool, len(newPaths))
+	for _, c := range candidates {
+		if usedNew[c.newPath] {
+			continue
+		}
+		_, deletedSource := deleted[c.oldPath]
+		if !deletedSource {
+			// The source survives under its old path, so this can only be a
+			// copy, not a rename; a rename source is consumed once, a copy
+			// source can seed any number of copies.
+			if !detectCopies {
+				continue
+			}
+			copyOf[c.newPath] = c.oldPath
+			scoreOf[c.newPath] = c.score
+			usedNew[c.newPath] = true
+			continue
+		}
+		if usedOld[c.oldPath] {
+			continue
+		}
+
+		renameOf[c.newPath] = c.oldPath
+		scoreOf[c.newPath] = c.score
+		usedNew[c.newPath] = true
+		usedOld[c.oldPath] = true
+	}
+}
+
+// sortedKeysCapped returns up to limit keys of m in sorted order.
+func sortedKeysCapped(m map[string]string, limit int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys
+}
+
+// shingleFingerprint hashes content into a set of rolling 5-line window
+// FNV-1a hashes, a cheap similarity fingerprint that tolerates small edits.
+func shingleFingerprint(content string) map[uint64]struct{} {
+	lines := splitLines(content)
+	fingerprint := make(map[uint64]struct{})
+
+	if len(lines) == 0 {
+		return fingerprint
+	}
+	if len(lines) < shingleWindow {
+		fingerprint[hash(content)] = struct{}{}
+		return fingerprint
+	}
+
+	for i := 0; i+shingleWindow <= len(lines); i++ {
+		window := lines[i : i+shingleWindow]
+		var h uint64
+		for _, line := range window {
+			h ^= hash(line)
+		}
+		fingerprint[h] = struct{}{}
+	}
+	return fingerprint
+}
+
+// jaccardSimilarity is |a∩b| / |a∪b| for two shingle sets, 0 if both are empty.
+func jaccardSimilarity(a, b map[uint64]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for h := range a {
+		if _, ok := b[h]; ok {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	if union == 0 {
+		return 0
+	}
+	return float64(intersection) / float64(union)
+}
+
+// buildRenameOrCopyDiff builds the FileDiff for a detected rename or copy,
+// skipping hunk computation entirely when the content is identical.
+func (e *Engine) buildRenameOrCopyDiff(oldPath, newPath, oldContent, newContent string, score float64, isCopy bool) *FileDiff {
+	var fd *FileDiff
+	if score >= 1.0 {
+		fd = &FileDiff{OldPath: oldPath, NewPath: newPath}
+		fd.OldLineCount = countLines(oldContent)
+		fd.NewLineCount = countLines(newContent)
+	} else {
+		fd = e.ComputeDiff(oldPath, newPath, oldContent, newContent)
+		fd.IsNew = false
+		fd.IsDelete = false
+	}
+
+	fd.IsRename = !isCopy
+	fd.IsCopy = isCopy
+	fd.SimilarityScore = score
+	return fd
+}
@@ -0,0 +1,164 @@
+package diff
+
+import (
+	"strconv"
+	"testing"
+)
+
+func findFileDiff(t *testing.T, td *TreeDiff, newPath string) *FileDiff {
+	t.Helper()
+	for _, fd := range td.Files {
+		if fd.NewPath == newPath {
+			return fd
+		}
+	}
+	t.Fatalf("no FileDiff with NewPath %q in %d results", newPath, len(td.Files))
+	return nil
+}
+
+func TestComputeTreeDiff_ExactRename(t *testing.T) {
+	old := map[string]string{"old/path.go": "package foo\n\nfunc A() {}\n"}
+	new := map[string]string{"new/path.go": "package foo\n\nfunc A() {}\n"}
+
+	td := NewEngine().ComputeTreeDiff(old, new, TreeDiffOptions{})
+
+	if len(td.Files) != 1 {
+		t.Fatalf("expected 1 file diff, got %d", len(td.Files))
+	}
+	fd := td.Files[0]
+	if !fd.IsRename || fd.IsCopy {
+		t.Fatalf("expected an exact rename, got %+v", fd)
+	}
+	if fd.OldPath != "old/path.go" || fd.NewPath != "new/path.go" {
+		t.Fatalf("unexpected paths: %+v", fd)
+	}
+	if fd.SimilarityScore != 1.0 {
+		t.Fatalf("expected similarity 1.0, got %v", fd.SimilarityScore)
+	}
+	if len(fd.Hunks) != 0 {
+		t.Fatalf("expected no hunks for an identical rename, got %d", len(fd.Hunks))
+	}
+}
+
+func TestComputeTreeDiff_FuzzyRenameWithEdits(t *testing.T) {
+	content := ""
+	for i := 0; i < 30; i++ {
+		content += "line" + strconv.Itoa(i) + "\n"
+	}
+
+	old := map[string]string{"old.go": content + "unique-old-tail\n"}
+	new := map[string]string{"new.go": content + "unique-new-tail\n"}
+
+	td := NewEngine().ComputeTreeDiff(old, new, TreeDiffOptions{})
+
+	if len(td.Files) != 1 {
+		t.Fatalf("expected 1 file diff, got %d", len(td.Files))
+	}
+	fd := td.Files[0]
+	if !fd.IsRename {
+		t.Fatalf("expected a fuzzy rename, got %+v", fd)
+	}
+	if fd.SimilarityScore <= 0 || fd.SimilarityScore >= 1.0 {
+		t.Fatalf("expected a similarity score strictly between 0 and 1, got %v", fd.SimilarityScore)
+	}
+	if len(fd.Hunks) == 0 {
+		t.Fatal("expected hunks describing the edit within the rename")
+	}
+}
+
+func TestComputeTreeDiff_BelowThresholdIsDeleteAndAdd(t *testing.T) {
+	old := map[string]string{"old.go": "aaaaa\nbbbbb\nccccc\n"}
+	new := map[string]string{"new.go": "xxxxx\nyyyyy\nzzzzz\n"}
+
+	td := NewEngine().ComputeTreeDiff(old, new, TreeDiffOptions{})
+
+	if len(td.Files) != 2 {
+		t.Fatalf("expected 2 file diffs (a delete and an add), got %d", len(td.Files))
+	}
+	for _, fd := range td.Files {
+		if fd.IsRename || fd.IsCopy {
+			t.Fatalf("did not expect a rename/copy below the similarity threshold, got %+v", fd)
+		}
+	}
+}
+
+func TestComputeTreeDiff_CopyDetection(t *testing.T) {
+	shared := "package foo\n\nfunc Shared() {}\n"
+	old := map[string]string{"a.go": shared}
+	new := map[string]string{
+		"a.go": shared,
+		"b.go": shared,
+	}
+
+	td := NewEngine().ComputeTreeDiff(old, new, TreeDiffOptions{DetectCopies: true})
+
+	fd := findFileDiff(t, td, "b.go")
+	if !fd.IsCopy || fd.IsRename {
+		t.Fatalf("expected b.go to be detected as a copy of a.go, got %+v", fd)
+	}
+	if fd.OldPath != "a.go" {
+		t.Fatalf("expected copy source a.go, got %q", fd.OldPath)
+	}
+}
+
+func TestComputeTreeDiff_UnchangedFileOmitted(t *testing.T) {
+	content := "package foo\n"
+	old := map[string]string{"a.go": content}
+	new := map[string]string{"a.go": content}
+
+	td := NewEngine().ComputeTreeDiff(old, new, TreeDiffOptions{})
+
+	if len(td.Files) != 0 {
+		t.Fatalf("expected no file diffs for an unchanged tree, got %d", len(td.Files))
+	}
+}
+
+func TestComputeTreeDiff_PlainModification(t *testing.T) {
+	old := map[string]string{"a.go": "package foo\n\nfunc A() {}\n"}
+	new := map[string]string{"a.go": "package foo\n\nfunc A() { return }\n"}
+
+	td := ComputeTreeDiff(old, new, TreeDiffOptions{})
+
+	if len(td.Files) != 1 {
+		t.Fatalf("expected 1 file diff, got %d", len(td.Files))
+	}
+	fd := td.Files[0]
+	if fd.IsRename || fd.IsCopy {
+		t.Fatalf("did not expect a same-path modification to be flagged as a rename/copy, got %+v", fd)
+	}
+	if len(fd.Hunks) == 0 {
+		t.Fatal("expected a hunk describing the modification")
+	}
+}
+
+func TestComputeTreeDiff_MaxCandidatesBoundsComparisons(t *testing.T) {
+	old := map[string]string{}
+	new := map[string]string{}
+	for i := 0; i < 10; i++ {
+		old[string(rune('a'+i))+"-old.go"] = "aaaaaaaaaa\n"
+		new[string(rune('a'+i))+"-new.go"] = "bbbbbbbbbb\n"
+	}
+
+	// MaxCandidates smaller than the candidate set should not panic and
+	// should still return a FileDiff for every path.
+	td := NewEngine().ComputeTreeDiff(old, new, TreeDiffOptions{MaxCandidates: 2})
+
+	if len(td.Files) != len(old)+len(new) {
+		t.Fatalf("expected every old/new path to be represented, got %d files", len(td.Files))
+	}
+}
+
+func TestJaccardSimilarity(t *testing.T) {
+	a := map[uint64]struct{}{1: {}, 2: {}, 3: {}}
+	b := map[uint64]struct{}{2: {}, 3: {}, 4: {}}
+
+	got := jaccardSimilarity(a, b)
+	want := 2.0 / 4.0
+	if got != want {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	if jaccardSimilarity(nil, nil) != 0 {
+		t.Fatal("expected 0 similarity for two empty sets")
+	}
+}
@@ -0,0 +1,156 @@
+// Package failpoint provides named injection points for deterministic
+// testing of concurrent hot paths, following the PingCAP failpoint pattern
+// (https://github.com/pingcap/failpoint) but as a small in-tree runtime
+// with no codegen step or external dependency.
+//
+// A call site declares an injection point with Eval:
+//
+//	if action, ok := failpoint.Eval("apischeduler/beforeAcquire"); ok {
+//	    if action.IsReturn() {
+//	        return errors.New(action.ReturnValue())
+//	    }
+//	}
+//
+// Tests activate it with Enable:
+//
+//	failpoint.Enable("apischeduler/beforeAcquire", `return("injected failure")`)
+//	defer failpoint.Disable("apischeduler/beforeAcquire")
+//
+// When no points are registered, Eval is a single atomic load and a nil
+// check, so the hot path pays nothing in the common case.
+package failpoint
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Action is the parsed side effect requested for an enabled failpoint.
+type Action struct {
+	kind  actionKind
+	value string
+}
+
+type actionKind int
+
+const (
+	actionNone actionKind = iota
+	actionReturn
+	actionSleep
+)
+
+// IsReturn reports whether this action is a "return(...)" injection.
+func (a Action) IsReturn() bool { return a.kind == actionReturn }
+
+// ReturnValue is the raw (quote-stripped) payload of a "return(...)" action.
+func (a Action) ReturnValue() string { return a.value }
+
+// IsSleep reports whether this action is a "sleep(...)" injection.
+func (a Action) IsSleep() bool { return a.kind == actionSleep }
+
+// SleepDuration parses the payload of a "sleep(...)" action as a duration.
+func (a Action) SleepDuration() (time.Duration, error) {
+	return time.ParseDuration(a.value)
+}
+
+var (
+	// points is swapped wholesale on every Enable/Disable (copy-on-write)
+	// so Eval never takes a lock on the hot path.
+	points atomic.Pointer[map[string]Action]
+
+	// mu serializes Enable/Disable against each other; Eval never acquires it.
+	mu sync.Mutex
+)
+
+// Enable activates the named failpoint with the given expression. Supported
+// forms:
+//
+//	return(<value>)  -- Action.IsReturn()/ReturnValue(), value may be quoted
+//	sleep(<duration>) -- Action.IsSleep()/SleepDuration(), e.g. sleep(200ms)
+func Enable(name, expr string) error {
+	action, err := parseExpr(expr)
+	if err != nil {
+		return fmt.Errorf("failpoint: invalid expression %q for %q: %w", expr, name, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	next := make(map[string]Action)
+	if current := points.Load(); current != nil {
+		for k, v := range *current {
+			next[k] = v
+		}
+	}
+	next[name] = action
+	points.Store(&next)
+	return nil
+}
+
+// Disable deactivates the named failpoint. A no-op if it wasn't enabled.
+func Disable(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	current := points.Load()
+	if current == nil {
+		return
+	}
+	if _, ok := (*current)[name]; !ok {
+		return
+	}
+
+	next := make(map[string]Action, len(*current)-1)
+	for k, v := range *current {
+		if k != name {
+			next[k] = v
+		}
+	}
+	points.Store(&next)
+}
+
+// Eval checks whether name is enabled, returning its Action and true if so.
+// Safe to call from any goroutine; costs a single atomic load when no
+// failpoints are registered.
+func Eval(name string) (Action, bool) {
+	current := points.Load()
+	if current == nil {
+		return Action{}, false
+	}
+	action, ok := (*current)[name]
+	return action, ok
+}
+
+// parseExpr parses the small subset of failpoint expressions this package
+// supports: return(value) and sleep(duration).
+func parseExpr(expr string) (Action, error) {
+	expr = strings.TrimSpace(expr)
+
+	if rest, ok := cutCall(expr, "return"); ok {
+		return Action{kind: actionReturn, value: unquote(rest)}, nil
+	}
+	if rest, ok := cutCall(expr, "sleep"); ok {
+		return Action{kind: actionSleep, value: strings.TrimSpace(rest)}, nil
+	}
+
+	return Action{}, fmt.Errorf("unrecognized failpoint expression %q (want return(...) or sleep(...))", expr)
+}
+
+// cutCall reports whether expr is "<fn>(<arg>)" and returns <arg> trimmed.
+func cutCall(expr, fn string) (arg string, ok bool) {
+	prefix := fn + "("
+	if !strings.HasPrefix(expr, prefix) || !strings.HasSuffix(expr, ")") {
+		return "", false
+	}
+	return strings.TrimSpace(expr[len(prefix) : len(expr)-1]), true
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
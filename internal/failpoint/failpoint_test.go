@@ -0,0 +1,82 @@
+package failpoint
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEval_DisabledByDefault(t *testing.T) {
+	if _, ok := Eval("nonexistent/point"); ok {
+		t.Fatal("expected an unregistered failpoint to be disabled")
+	}
+}
+
+func TestEnableDisable_Return(t *testing.T) {
+	name := "test/return"
+	if err := Enable(name, `return("boom")`); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	defer Disable(name)
+
+	action, ok := Eval(name)
+	if !ok {
+		t.Fatal("expected the failpoint to be enabled")
+	}
+	if !action.IsReturn() {
+		t.Fatal("expected a return action")
+	}
+	if action.ReturnValue() != "boom" {
+		t.Fatalf("expected ReturnValue()=boom, got %q", action.ReturnValue())
+	}
+
+	Disable(name)
+	if _, ok := Eval(name); ok {
+		t.Fatal("expected the failpoint to be disabled")
+	}
+}
+
+func TestEnableDisable_Sleep(t *testing.T) {
+	name := "test/sleep"
+	if err := Enable(name, "sleep(50ms)"); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	defer Disable(name)
+
+	action, ok := Eval(name)
+	if !ok || !action.IsSleep() {
+		t.Fatalf("expected a sleep action, got ok=%v action=%+v", ok, action)
+	}
+	d, err := action.SleepDuration()
+	if err != nil {
+		t.Fatalf("SleepDuration: %v", err)
+	}
+	if d != 50*time.Millisecond {
+		t.Fatalf("expected 50ms, got %v", d)
+	}
+}
+
+func TestEnable_RejectsUnrecognizedExpression(t *testing.T) {
+	if err := Enable("test/bad", "panic()"); err == nil {
+		t.Fatal("expected an error for an unsupported expression")
+	}
+}
+
+func TestEnable_MultiplePointsCoexist(t *testing.T) {
+	if err := Enable("test/a", `return("a")`); err != nil {
+		t.Fatalf("Enable a: %v", err)
+	}
+	defer Disable("test/a")
+	if err := Enable("test/b", `return("b")`); err != nil {
+		t.Fatalf("Enable b: %v", err)
+	}
+	defer Disable("test/b")
+
+	a, ok := Eval("test/a")
+	if !ok || a.ReturnValue() != "a" {
+		t.Fatalf("expected test/a to still be enabled with value 'a', got ok=%v value=%q", ok, a.ReturnValue())
+	}
+	b, ok := Eval("test/b")
+	if !ok || b.ReturnValue() != "b" {
+		t.Fatalf("expected test/b to be enabled with value 'b', got ok=%v value=%q", ok, b.ReturnValue())
+	}
+}
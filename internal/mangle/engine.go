@@ -61,6 +61,8 @@ type Engine struct {
 	autoEval        bool
 	persistence     Persistence
 	fileFacts       map[string][]ast.Atom
+	lastEvalSummary string
+	lastEvalTime    time.Duration
 }
 
 // Fact represents a single fact in the knowledge graph.
@@ -175,6 +177,7 @@ func (e *Engine) RecomputeRules() error {
 	}()
 
 	// Use EvalProgramWithStats for visibility
+	start := time.Now()
 	stats, err := mengine.EvalProgramWithStats(e.programInfo, e.store)
 	close(done)
 
@@ -182,10 +185,41 @@ func (e *Engine) RecomputeRules() error {
 		return err
 	}
 
+	e.lastEvalTime = time.Since(start)
+	e.lastEvalSummary = fmt.Sprintf("%+v", stats)
 	log.Printf("Recomputation complete. Stats: %+v", stats)
 	return nil
 }
 
+// RecomputeRulesContext is RecomputeRules with ctx honored as a deadline on
+// the evaluation: a query loop that never reaches fixed point (e.g. a
+// pathological rule set loaded by `nerd mangle query`) is abandoned once ctx
+// is done rather than blocking the caller forever. The evaluation itself
+// keeps running in the background to completion since EvalProgramWithStats
+// has no native cancellation; only the caller's wait is bounded.
+func (e *Engine) RecomputeRulesContext(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- e.RecomputeRules()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("rule evaluation timed out: %w", ctx.Err())
+	}
+}
+
+// LastEvalSummary returns a human-readable summary of the most recent
+// EvalProgramWithStats call, and how long it took - the rule-fire-count and
+// eval-time data :stats reports in the mangle query REPL.
+func (e *Engine) LastEvalSummary() (summary string, duration time.Duration) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.lastEvalSummary, e.lastEvalTime
+}
+
 // LoadSchema loads and compiles a Mangle schema file (.mg).
 func (e *Engine) LoadSchema(path string) error {
 	data, err := os.ReadFile(path)
@@ -337,6 +371,64 @@ func (e *Engine) AddFactsContext(ctx context.Context, facts []Fact) error {
 	return e.AddFacts(facts)
 }
 
+// AssertAtom inserts an already-parsed, fully ground atom directly into the
+// fact store. It exists for callers - like the `nerd mangle query` REPL's
+// `:assert` command - that parse a fact with parse.Atom themselves and so
+// already hold an ast.Atom, rather than a Fact needing type coercion.
+func (e *Engine) AssertAtom(atom ast.Atom) error {
+	for _, arg := range atom.Args {
+		if _, ok := arg.(ast.Constant); !ok {
+			return fmt.Errorf(":assert requires a ground fact, got non-constant arg %v", arg)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.programInfo == nil {
+		return fmt.Errorf("no schemas loaded; call LoadSchema first")
+	}
+
+	if e.store.Add(atom) {
+		e.factCount++
+	}
+
+	if e.autoEval {
+		_, err := mengine.EvalProgramWithStats(e.programInfo, e.store)
+		return err
+	}
+	return nil
+}
+
+// RetractAtom removes an already-parsed, fully ground atom from the fact
+// store, the `:retract` counterpart to AssertAtom.
+func (e *Engine) RetractAtom(atom ast.Atom) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.baseStore.Remove(atom) && e.factCount > 0 {
+		e.factCount--
+	}
+	return nil
+}
+
+// ListRules returns the Datalog source of every rule clause in the loaded
+// program, for the `:rules` REPL command.
+func (e *Engine) ListRules() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.programInfo == nil {
+		return nil
+	}
+
+	rules := make([]string, 0, len(e.programInfo.Rules))
+	for _, clause := range e.programInfo.Rules {
+		rules = append(rules, clause.String())
+	}
+	return rules
+}
+
 // ReplaceFactsForFile removes previously stored facts for a file before inserting new ones.
 func (e *Engine) ReplaceFactsForFile(file string, facts []Fact) error {
 	target := canonicalPath(file)
@@ -621,6 +713,30 @@ func (e *Engine) Query(ctx context.Context, query string) (*QueryResult, error)
 		return nil, err
 	}
 
+	start := time.Now()
+	bindings, err := e.QueryTerms(ctx, shape.atom)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryResult{
+		Bindings: bindings,
+		Duration: time.Since(start),
+	}, nil
+}
+
+// QueryTerms evaluates query - an atom whose unbound positions hold
+// ast.Variable terms - and returns one map of variable name to bound value
+// per matching row. It backs both Query (string notation) and the `nerd
+// mangle query` command/REPL, which already hold a parsed ast.Atom.
+func (e *Engine) QueryTerms(ctx context.Context, query ast.Atom) ([]map[string]interface{}, error) {
+	variables := make([]queryVariable, 0, len(query.Args))
+	for idx, arg := range query.Args {
+		if variable, ok := arg.(ast.Variable); ok {
+			variables = append(variables, queryVariable{Name: variable.Symbol, Index: idx})
+		}
+	}
+
 	e.mu.RLock()
 	queryContext := e.queryContext
 	if queryContext == nil {
@@ -628,14 +744,14 @@ func (e *Engine) Query(ctx context.Context, query string) (*QueryResult, error)
 		return nil, fmt.Errorf("no schemas loaded; cannot execute query")
 	}
 
-	decl, ok := queryContext.PredToDecl[shape.atom.Predicate]
+	decl, ok := queryContext.PredToDecl[query.Predicate]
 	if !ok {
 		e.mu.RUnlock()
-		return nil, fmt.Errorf("predicate %s is not declared", shape.atom.Predicate.Symbol)
+		return nil, fmt.Errorf("predicate %s is not declared", query.Predicate.Symbol)
 	}
 	if len(decl.Modes()) == 0 {
 		e.mu.RUnlock()
-		return nil, fmt.Errorf("predicate %s has no modes declared", shape.atom.Predicate.Symbol)
+		return nil, fmt.Errorf("predicate %s has no modes declared", query.Predicate.Symbol)
 	}
 	mode := decl.Modes()[0]
 	e.mu.RUnlock()
@@ -661,15 +777,15 @@ func (e *Engine) Query(ctx context.Context, query string) (*QueryResult, error)
 
 	go func() {
 		var results []map[string]interface{}
-		err := queryContext.EvalQuery(shape.atom, mode, unionfind.New(), func(fact ast.Atom) error {
+		err := queryContext.EvalQuery(query, mode, unionfind.New(), func(fact ast.Atom) error {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
 			default:
 			}
 
-			row := make(map[string]interface{}, len(shape.variables))
-			for _, binding := range shape.variables {
+			row := make(map[string]interface{}, len(variables))
+			for _, binding := range variables {
 				if binding.Index >= len(fact.Args) {
 					continue
 				}
@@ -687,10 +803,7 @@ func (e *Engine) Query(ctx context.Context, query string) (*QueryResult, error)
 
 	select {
 	case results := <-resultChan:
-		return &QueryResult{
-			Bindings: results,
-			Duration: time.Since(start),
-		}, nil
+		return results, nil
 	case err := <-errChan:
 		return nil, err
 	case <-ctx.Done():
@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/google/mangle/parse"
 )
 
 func TestNewEngine(t *testing.T) {
@@ -288,3 +290,101 @@ func TestEngineToggleAutoEval(t *testing.T) {
 	// Toggle on
 	engine.ToggleAutoEval(true)
 }
+
+func TestEngineQueryTerms(t *testing.T) {
+	cfg := DefaultConfig()
+	engine, err := NewEngine(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	schema := `Decl person(Name, Age) descr [mode("-", "-")].`
+	if err := engine.LoadSchemaString(schema); err != nil {
+		t.Fatalf("LoadSchemaString() error = %v", err)
+	}
+	if err := engine.AddFact("person", "Alice", int64(30)); err != nil {
+		t.Fatalf("AddFact() error = %v", err)
+	}
+
+	atom, err := parse.Atom("person(X, Y)")
+	if err != nil {
+		t.Fatalf("parse.Atom() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := engine.QueryTerms(ctx, atom)
+	if err != nil {
+		t.Fatalf("QueryTerms() error = %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+	if rows[0]["X"] != "Alice" {
+		t.Errorf("expected X=Alice, got %v", rows[0]["X"])
+	}
+}
+
+func TestEngineAssertAndRetractAtom(t *testing.T) {
+	cfg := DefaultConfig()
+	engine, err := NewEngine(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	schema := `Decl person(Name, Age) descr [mode("-", "-")].`
+	if err := engine.LoadSchemaString(schema); err != nil {
+		t.Fatalf("LoadSchemaString() error = %v", err)
+	}
+
+	atom, err := parse.Atom(`person("Alice", 30)`)
+	if err != nil {
+		t.Fatalf("parse.Atom() error = %v", err)
+	}
+
+	if err := engine.AssertAtom(atom); err != nil {
+		t.Fatalf("AssertAtom() error = %v", err)
+	}
+
+	facts, err := engine.GetFacts("person")
+	if err != nil {
+		t.Fatalf("GetFacts() error = %v", err)
+	}
+	if len(facts) != 1 {
+		t.Fatalf("expected 1 fact after assert, got %d", len(facts))
+	}
+
+	if err := engine.RetractAtom(atom); err != nil {
+		t.Fatalf("RetractAtom() error = %v", err)
+	}
+	facts, err = engine.GetFacts("person")
+	if err != nil {
+		t.Fatalf("GetFacts() error = %v", err)
+	}
+	if len(facts) != 0 {
+		t.Fatalf("expected 0 facts after retract, got %d", len(facts))
+	}
+}
+
+func TestEngineListRules(t *testing.T) {
+	cfg := DefaultConfig()
+	engine, err := NewEngine(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	schema := `
+Decl person(Name, Age) descr [mode("-", "-")].
+Decl adult(Name) descr [mode("-")].
+adult(Name) :- person(Name, Age), :gt(Age, 17).
+`
+	if err := engine.LoadSchemaString(schema); err != nil {
+		t.Fatalf("LoadSchemaString() error = %v", err)
+	}
+
+	rules := engine.ListRules()
+	if len(rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d: %v", len(rules), rules)
+	}
+}
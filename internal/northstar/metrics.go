@@ -0,0 +1,33 @@
+package northstar
+
+import "time"
+
+// Metrics receives instrumentation events from a Store. It lets operators
+// watch the same per-operation durations and drift-event backlog that OPA
+// users watch via its storage-layer metrics, without forcing every caller
+// to take a dependency on a particular metrics backend.
+//
+// The zero value a Store is constructed with is noopMetrics, which discards
+// every event; RegisterPrometheus swaps in an implementation that publishes
+// to a Prometheus registry instead.
+type Metrics interface {
+	// ObserveOpDuration records how long one Store operation took. op is
+	// one of "save_vision", "record_observation", "record_alignment",
+	// "record_drift", "resolve_drift", "get_state".
+	ObserveOpDuration(op string, d time.Duration)
+	// SetActiveDriftCount reports the current unresolved drift-event
+	// backlog.
+	SetActiveDriftCount(n int)
+	// SetTasksSinceCheck reports tasks completed since the last alignment
+	// check.
+	SetTasksSinceCheck(n int)
+	// SetOverallAlignment reports the current running alignment score.
+	SetOverallAlignment(v float64)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveOpDuration(string, time.Duration) {}
+func (noopMetrics) SetActiveDriftCount(int)                 {}
+func (noopMetrics) SetTasksSinceCheck(int)                  {}
+func (noopMetrics) SetOverallAlignment(float64)             {}
@@ -0,0 +1,63 @@
+package northstar
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// prometheusMetrics is the Metrics implementation returned by
+// RegisterPrometheus. It mirrors the per-operation durations and
+// drift/alignment gauges OPA exposes for its own storage layer.
+type prometheusMetrics struct {
+	opDuration       *prometheus.HistogramVec
+	activeDriftCount prometheus.Gauge
+	tasksSinceCheck  prometheus.Gauge
+	overallAlignment prometheus.Gauge
+}
+
+// RegisterPrometheus builds a Prometheus-backed Metrics implementation,
+// registers its collectors against reg, installs it on s, and returns it so
+// callers can register it on additional Stores without creating duplicate
+// collectors.
+func RegisterPrometheus(reg prometheus.Registerer, s *Store) (Metrics, error) {
+	m := &prometheusMetrics{
+		opDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "northstar_store_op_duration_seconds",
+			Help:    "Duration of northstar.Store operations, by op.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"op"}),
+		activeDriftCount: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "northstar_active_drift_count",
+			Help: "Current number of unresolved drift events.",
+		}),
+		tasksSinceCheck: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "northstar_tasks_since_check",
+			Help: "Tasks completed since the last alignment check.",
+		}),
+		overallAlignment: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "northstar_overall_alignment",
+			Help: "Current running alignment score (0.0-1.0).",
+		}),
+	}
+
+	collectors := []prometheus.Collector{m.opDuration, m.activeDriftCount, m.tasksSinceCheck, m.overallAlignment}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if s != nil {
+		s.SetMetrics(m)
+	}
+	return m, nil
+}
+
+func (m *prometheusMetrics) ObserveOpDuration(op string, d time.Duration) {
+	m.opDuration.WithLabelValues(op).Observe(d.Seconds())
+}
+
+func (m *prometheusMetrics) SetActiveDriftCount(n int)     { m.activeDriftCount.Set(float64(n)) }
+func (m *prometheusMetrics) SetTasksSinceCheck(n int)      { m.tasksSinceCheck.Set(float64(n)) }
+func (m *prometheusMetrics) SetOverallAlignment(v float64) { m.overallAlignment.Set(v) }
@@ -0,0 +1,286 @@
+package northstar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// errStopIteration is returned by an Iterate* callback to stop iteration
+// early without signaling a real failure (mirrors filepath.SkipDir). The
+// thin GetRecent*/GetActive* wrappers use it internally to stop once their
+// limit is reached; it never escapes to their own callers.
+var errStopIteration = errors.New("northstar: stop iteration")
+
+// ObservationFilter narrows IterateObservations to a time range, a set of
+// observation types, a set of tags, and a minimum relevance score. The zero
+// value matches every observation.
+type ObservationFilter struct {
+	Since        time.Time
+	Until        time.Time
+	Types        []ObservationType
+	Tags         []string
+	MinRelevance float64
+}
+
+func (f ObservationFilter) matches(obs *Observation) bool {
+	if !f.Since.IsZero() && obs.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && obs.Timestamp.After(f.Until) {
+		return false
+	}
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			if obs.Type == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.MinRelevance > 0 && obs.Relevance < f.MinRelevance {
+		return false
+	}
+	if len(f.Tags) > 0 {
+		for _, want := range f.Tags {
+			found := false
+			for _, got := range obs.Tags {
+				if got == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AlignmentFilter narrows IterateAlignmentChecks to a time range and a set
+// of results. The zero value matches every alignment check.
+type AlignmentFilter struct {
+	Since   time.Time
+	Until   time.Time
+	Results []AlignmentResult
+}
+
+func (f AlignmentFilter) matches(check *AlignmentCheck) bool {
+	if !f.Since.IsZero() && check.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && check.Timestamp.After(f.Until) {
+		return false
+	}
+	if len(f.Results) > 0 {
+		found := false
+		for _, r := range f.Results {
+			if check.Result == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// DriftFilter narrows IterateDriftEvents to a time range, a set of
+// severities, and whether only unresolved events should be visited. The
+// zero value matches every drift event.
+type DriftFilter struct {
+	Since      time.Time
+	Until      time.Time
+	Severities []DriftSeverity
+	ActiveOnly bool
+}
+
+func (f DriftFilter) matches(event *DriftEvent) bool {
+	if f.ActiveOnly && event.Resolved {
+		return false
+	}
+	if !f.Since.IsZero() && event.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && event.Timestamp.After(f.Until) {
+		return false
+	}
+	if len(f.Severities) > 0 {
+		found := false
+		for _, sev := range f.Severities {
+			if event.Severity == sev {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// StoreBackend selects which concrete storage implementation a Store uses.
+type StoreBackend string
+
+const (
+	// BackendSQLite is the default backend: a local SQLite file, as used by
+	// every existing caller of NewStore.
+	BackendSQLite StoreBackend = "sqlite"
+	// BackendDisk is a Badger-backed key/value store, for callers that want
+	// the OPA-style single-writer/many-readers locking regime enforced by
+	// Badger's own transaction model rather than SQLite's.
+	BackendDisk StoreBackend = "disk"
+)
+
+// defaultPartitions lists the record partitions every Store backend must be
+// able to serve. A disk-backed Store pre-validates StoreOptions.Partitions
+// against this list; partitions are otherwise created lazily on first write.
+var defaultPartitions = []string{"observations", "alignment", "drift"}
+
+// StoreOptions configures NewStoreWithOptions. The zero value is equivalent
+// to NewStore's historical behavior: a SQLite backend that creates its
+// directory on demand.
+type StoreOptions struct {
+	// Backend selects the storage implementation. Empty means BackendSQLite.
+	Backend StoreBackend
+	// AutoCreate creates dir (and, for BackendSQLite, the containing
+	// directory of the database file) if it doesn't already exist. When
+	// false, opening a Store against a missing directory is an error.
+	AutoCreate bool
+	// Partitions restricts which record partitions this Store validates
+	// support for. Empty means all of defaultPartitions.
+	Partitions []string
+}
+
+// resolvePartitions returns opts.Partitions, defaulted and validated against
+// defaultPartitions.
+func resolvePartitions(opts StoreOptions) ([]string, error) {
+	if len(opts.Partitions) == 0 {
+		return defaultPartitions, nil
+	}
+	known := make(map[string]bool, len(defaultPartitions))
+	for _, p := range defaultPartitions {
+		known[p] = true
+	}
+	for _, p := range opts.Partitions {
+		if !known[p] {
+			return nil, fmt.Errorf("unknown northstar store partition %q (known: %v)", p, defaultPartitions)
+		}
+	}
+	return opts.Partitions, nil
+}
+
+// storage is implemented by every Store backend. Store itself is a thin
+// wrapper that forwards to whichever backend NewStoreWithOptions selected,
+// mirroring the core.TransactionStore / InMemoryTransactionStore /
+// BadgerTransactionStore split.
+type storage interface {
+	Path() string
+	Close() error
+	Metrics() StorageMetrics
+
+	SaveVision(v *Vision) error
+	LoadVision() (*Vision, error)
+	HasVision() bool
+
+	RecordObservation(obs *Observation) error
+	GetRecentObservations(limit int) ([]Observation, error)
+	// IterateObservations visits every observation matching filter, newest
+	// first, calling fn once per match without ever materializing the full
+	// result set. fn returning a non-nil error stops iteration and that
+	// error is returned (errStopIteration is swallowed by callers that use
+	// it to implement a limit).
+	IterateObservations(ctx context.Context, filter ObservationFilter, fn func(*Observation) error) error
+
+	RecordAlignmentCheck(check *AlignmentCheck) error
+	GetAlignmentHistory(limit int) ([]AlignmentCheck, error)
+	// IterateAlignmentChecks visits every alignment check matching filter,
+	// newest first, without materializing the full result set.
+	IterateAlignmentChecks(ctx context.Context, filter AlignmentFilter, fn func(*AlignmentCheck) error) error
+
+	RecordDriftEvent(drift *DriftEvent) error
+	ResolveDriftEvent(id string, resolution string) error
+	GetActiveDriftEvents() ([]DriftEvent, error)
+	// IterateDriftEvents visits every drift event matching filter, newest
+	// first, without materializing the full result set.
+	IterateDriftEvents(ctx context.Context, filter DriftFilter, fn func(*DriftEvent) error) error
+
+	GetState() (*GuardianState, error)
+	IncrementTaskCount() (int, error)
+	ResetSessionObservations() error
+}
+
+// OpMetrics is a snapshot of one operation's observed count, cumulative
+// payload size, and cumulative duration. Dividing TotalDuration/Count (or
+// TotalBytes/Count) gives the per-call average.
+type OpMetrics struct {
+	Count         int64         `json:"count"`
+	TotalBytes    int64         `json:"total_bytes"`
+	TotalDuration time.Duration `json:"total_duration"`
+}
+
+// StorageMetrics is a point-in-time snapshot of a Store backend's
+// per-operation counters, returned by Store.StorageMetrics().
+type StorageMetrics struct {
+	SaveVision           OpMetrics `json:"save_vision"`
+	RecordObservation    OpMetrics `json:"record_observation"`
+	RecordAlignmentCheck OpMetrics `json:"record_alignment_check"`
+	RecordDriftEvent     OpMetrics `json:"record_drift_event"`
+	ResolveDriftEvent    OpMetrics `json:"resolve_drift_event"`
+	GetState             OpMetrics `json:"get_state"`
+}
+
+// opCounters holds the atomic counters backing one OpMetrics entry. It is
+// embedded (by pointer) in each backend's metrics struct and updated via
+// recordOp; Snapshot reads it out into a plain OpMetrics value.
+type opCounters struct {
+	count         int64
+	totalBytes    int64
+	totalDuration int64 // nanoseconds
+}
+
+func (c *opCounters) record(start time.Time, bytes int) {
+	atomic.AddInt64(&c.count, 1)
+	atomic.AddInt64(&c.totalBytes, int64(bytes))
+	atomic.AddInt64(&c.totalDuration, int64(time.Since(start)))
+}
+
+func (c *opCounters) snapshot() OpMetrics {
+	return OpMetrics{
+		Count:         atomic.LoadInt64(&c.count),
+		TotalBytes:    atomic.LoadInt64(&c.totalBytes),
+		TotalDuration: time.Duration(atomic.LoadInt64(&c.totalDuration)),
+	}
+}
+
+// storageMetricsCounters is the atomic-counter twin of StorageMetrics, held
+// by value inside each backend and snapshotted on demand.
+type storageMetricsCounters struct {
+	saveVision           opCounters
+	recordObservation    opCounters
+	recordAlignmentCheck opCounters
+	recordDriftEvent     opCounters
+	resolveDriftEvent    opCounters
+	getState             opCounters
+}
+
+func (m *storageMetricsCounters) snapshot() StorageMetrics {
+	return StorageMetrics{
+		SaveVision:           m.saveVision.snapshot(),
+		RecordObservation:    m.recordObservation.snapshot(),
+		RecordAlignmentCheck: m.recordAlignmentCheck.snapshot(),
+		RecordDriftEvent:     m.recordDriftEvent.snapshot(),
+		ResolveDriftEvent:    m.resolveDriftEvent.snapshot(),
+		GetState:             m.getState.snapshot(),
+	}
+}
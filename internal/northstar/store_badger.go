@@ -0,0 +1,575 @@
+package northstar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// badgerStorage is the BackendDisk storage implementation. It follows the
+// same durability model as core.BadgerTransactionStore: Badger itself
+// enforces the OPA-style locking regime (any number of concurrent readers,
+// a single in-flight write transaction, readers blocked while a commit is
+// running), so no extra mutex is needed here.
+type badgerStorage struct {
+	db      *badger.DB
+	dbPath  string
+	metrics storageMetricsCounters
+}
+
+// newBadgerStorage opens (or creates) a Badger-backed Northstar knowledge
+// store at dir/northstar_knowledge.badger, validating opts.Partitions.
+func newBadgerStorage(dir string, opts StoreOptions) (*badgerStorage, error) {
+	if _, err := resolvePartitions(opts); err != nil {
+		return nil, err
+	}
+
+	dbPath := filepath.Join(dir, "northstar_knowledge.badger")
+	if opts.AutoCreate {
+		if err := os.MkdirAll(dbPath, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+	} else if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("northstar store directory does not exist (AutoCreate is false): %w", err)
+	}
+
+	badgerOpts := badger.DefaultOptions(dbPath)
+	badgerOpts.Logger = nil // Badger's default logger is noisy at Info level.
+
+	db, err := badger.Open(badgerOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open badger store at %s: %w", dbPath, err)
+	}
+
+	return &badgerStorage{db: db, dbPath: dbPath}, nil
+}
+
+// Close releases the underlying Badger database handle.
+func (s *badgerStorage) Close() error {
+	return s.db.Close()
+}
+
+// Path returns the Badger database directory.
+func (s *badgerStorage) Path() string {
+	return s.dbPath
+}
+
+// Metrics returns a snapshot of this backend's per-operation counters.
+func (s *badgerStorage) Metrics() StorageMetrics {
+	return s.metrics.snapshot()
+}
+
+const (
+	badgerVisionKey = "vision"
+	badgerStateKey  = "state"
+
+	badgerObsPrefix      = "obs/"
+	badgerAlignPrefix    = "align/"
+	badgerDriftPrefix    = "drift/"
+	badgerDriftActive    = "drift_active/"
+	badgerDriftIdxPrefix = "drift_idx/"
+)
+
+// timeKey returns a lexicographically-sortable-by-time key component.
+func timeKey(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
+
+// =============================================================================
+// VISION OPERATIONS
+// =============================================================================
+
+func (s *badgerStorage) SaveVision(v *Vision) error {
+	start := time.Now()
+
+	now := time.Now()
+	if v.CreatedAt.IsZero() {
+		v.CreatedAt = now
+	}
+	v.UpdatedAt = now
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vision: %w", err)
+	}
+
+	err = s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(badgerVisionKey), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save vision: %w", err)
+	}
+
+	if err := s.updateState(func(state *GuardianState) { state.VisionDefined = true }); err != nil {
+		return err
+	}
+
+	s.metrics.saveVision.record(start, len(data))
+	return nil
+}
+
+func (s *badgerStorage) LoadVision() (*Vision, error) {
+	var v Vision
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(badgerVisionKey))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &v)
+		})
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil // No vision defined
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vision: %w", err)
+	}
+	return &v, nil
+}
+
+func (s *badgerStorage) HasVision() bool {
+	v, err := s.LoadVision()
+	return err == nil && v != nil
+}
+
+// =============================================================================
+// OBSERVATION OPERATIONS
+// =============================================================================
+
+func (s *badgerStorage) RecordObservation(obs *Observation) error {
+	start := time.Now()
+
+	if obs.ID == "" {
+		obs.ID = fmt.Sprintf("obs-%d", time.Now().UnixNano())
+	}
+	if obs.Timestamp.IsZero() {
+		obs.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(obs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal observation: %w", err)
+	}
+
+	key := []byte(fmt.Sprintf("%s%s/%s", badgerObsPrefix, timeKey(obs.Timestamp), obs.ID))
+	if err := s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, data)
+	}); err != nil {
+		return fmt.Errorf("failed to record observation: %w", err)
+	}
+
+	if err := s.updateState(func(state *GuardianState) { state.SessionObservations++ }); err != nil {
+		return err
+	}
+
+	s.metrics.recordObservation.record(start, len(data))
+	return nil
+}
+
+// GetRecentObservations retrieves recent observations. It is a thin wrapper
+// around IterateObservations with an explicit limit.
+func (s *badgerStorage) GetRecentObservations(limit int) ([]Observation, error) {
+	var observations []Observation
+	err := s.IterateObservations(context.Background(), ObservationFilter{}, func(obs *Observation) error {
+		observations = append(observations, *obs)
+		if limit > 0 && len(observations) >= limit {
+			return errStopIteration
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopIteration) {
+		return nil, err
+	}
+	return observations, nil
+}
+
+// IterateObservations streams observations newest-first off a single Badger
+// iterator, holding at most one decoded record in memory at a time rather
+// than materializing the whole obs/ keyspace into a slice.
+func (s *badgerStorage) IterateObservations(ctx context.Context, filter ObservationFilter, fn func(*Observation) error) error {
+	return s.db.View(func(txn *badger.Txn) error {
+		iterOpts := badger.DefaultIteratorOptions
+		iterOpts.Reverse = true
+		it := txn.NewIterator(iterOpts)
+		defer it.Close()
+
+		seek := append([]byte(badgerObsPrefix), 0xFF)
+		prefix := []byte(badgerObsPrefix)
+		for it.Seek(seek); it.ValidForPrefix(prefix); it.Next() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			var obs Observation
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &obs)
+			}); err != nil {
+				return err
+			}
+			if !filter.matches(&obs) {
+				continue
+			}
+			if err := fn(&obs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// =============================================================================
+// ALIGNMENT CHECK OPERATIONS
+// =============================================================================
+
+func (s *badgerStorage) RecordAlignmentCheck(check *AlignmentCheck) error {
+	start := time.Now()
+
+	if check.ID == "" {
+		check.ID = fmt.Sprintf("check-%d", time.Now().UnixNano())
+	}
+	if check.Timestamp.IsZero() {
+		check.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(check)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alignment check: %w", err)
+	}
+
+	key := []byte(fmt.Sprintf("%s%s/%s", badgerAlignPrefix, timeKey(check.Timestamp), check.ID))
+	if err := s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, data)
+	}); err != nil {
+		return fmt.Errorf("failed to record alignment check: %w", err)
+	}
+
+	if err := s.updateState(func(state *GuardianState) {
+		state.LastCheck = check.Timestamp
+		state.TasksSinceCheck = 0
+		state.OverallAlignment = state.OverallAlignment*0.8 + check.Score*0.2
+	}); err != nil {
+		return err
+	}
+
+	s.metrics.recordAlignmentCheck.record(start, len(data))
+	return nil
+}
+
+// GetAlignmentHistory retrieves alignment check history. It is a thin
+// wrapper around IterateAlignmentChecks with an explicit limit.
+func (s *badgerStorage) GetAlignmentHistory(limit int) ([]AlignmentCheck, error) {
+	var checks []AlignmentCheck
+	err := s.IterateAlignmentChecks(context.Background(), AlignmentFilter{}, func(check *AlignmentCheck) error {
+		checks = append(checks, *check)
+		if limit > 0 && len(checks) >= limit {
+			return errStopIteration
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopIteration) {
+		return nil, err
+	}
+	return checks, nil
+}
+
+// IterateAlignmentChecks streams alignment checks newest-first off a single
+// Badger iterator rather than materializing the whole align/ keyspace into
+// a slice.
+func (s *badgerStorage) IterateAlignmentChecks(ctx context.Context, filter AlignmentFilter, fn func(*AlignmentCheck) error) error {
+	return s.db.View(func(txn *badger.Txn) error {
+		iterOpts := badger.DefaultIteratorOptions
+		iterOpts.Reverse = true
+		it := txn.NewIterator(iterOpts)
+		defer it.Close()
+
+		seek := append([]byte(badgerAlignPrefix), 0xFF)
+		prefix := []byte(badgerAlignPrefix)
+		for it.Seek(seek); it.ValidForPrefix(prefix); it.Next() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			var check AlignmentCheck
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &check)
+			}); err != nil {
+				return err
+			}
+			if !filter.matches(&check) {
+				continue
+			}
+			if err := fn(&check); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// =============================================================================
+// DRIFT EVENT OPERATIONS
+// =============================================================================
+
+func (s *badgerStorage) driftPrimaryKey(drift *DriftEvent) []byte {
+	return []byte(fmt.Sprintf("%s%s/%s", badgerDriftPrefix, timeKey(drift.Timestamp), drift.ID))
+}
+
+func (s *badgerStorage) driftIndexKey(id string) []byte {
+	return []byte(badgerDriftIdxPrefix + id)
+}
+
+func (s *badgerStorage) RecordDriftEvent(drift *DriftEvent) error {
+	start := time.Now()
+
+	if drift.ID == "" {
+		drift.ID = fmt.Sprintf("drift-%d", time.Now().UnixNano())
+	}
+	if drift.Timestamp.IsZero() {
+		drift.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(drift)
+	if err != nil {
+		return fmt.Errorf("failed to marshal drift event: %w", err)
+	}
+
+	primaryKey := s.driftPrimaryKey(drift)
+	activeKey := []byte(badgerDriftActive + string(primaryKey))
+	if err := s.db.Update(func(txn *badger.Txn) error {
+		if err := txn.Set(primaryKey, data); err != nil {
+			return err
+		}
+		if err := txn.Set(s.driftIndexKey(drift.ID), primaryKey); err != nil {
+			return err
+		}
+		return txn.Set(activeKey, primaryKey)
+	}); err != nil {
+		return fmt.Errorf("failed to record drift event: %w", err)
+	}
+
+	if err := s.updateState(func(state *GuardianState) { state.ActiveDriftCount++ }); err != nil {
+		return err
+	}
+
+	s.metrics.recordDriftEvent.record(start, len(data))
+	return nil
+}
+
+func (s *badgerStorage) ResolveDriftEvent(id string, resolution string) error {
+	start := time.Now()
+
+	now := time.Now()
+	resolved := false
+	err := s.db.Update(func(txn *badger.Txn) error {
+		idxItem, err := txn.Get(s.driftIndexKey(id))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		primaryKey, err := idxItem.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+
+		item, err := txn.Get(primaryKey)
+		if err != nil {
+			return err
+		}
+		var drift DriftEvent
+		if err := item.Value(func(val []byte) error { return json.Unmarshal(val, &drift) }); err != nil {
+			return err
+		}
+		if drift.Resolved {
+			return nil
+		}
+
+		drift.Resolved = true
+		drift.ResolvedAt = &now
+		drift.Resolution = resolution
+
+		data, err := json.Marshal(&drift)
+		if err != nil {
+			return err
+		}
+		if err := txn.Set(primaryKey, data); err != nil {
+			return err
+		}
+		activeKey := []byte(badgerDriftActive + string(primaryKey))
+		if err := txn.Delete(activeKey); err != nil {
+			return err
+		}
+		resolved = true
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve drift event %s: %w", id, err)
+	}
+
+	if resolved {
+		if err := s.updateState(func(state *GuardianState) {
+			if state.ActiveDriftCount > 0 {
+				state.ActiveDriftCount--
+			}
+		}); err != nil {
+			return err
+		}
+	}
+
+	s.metrics.resolveDriftEvent.record(start, len(resolution))
+	return nil
+}
+
+func (s *badgerStorage) GetActiveDriftEvents() ([]DriftEvent, error) {
+	var events []DriftEvent
+	err := s.db.View(func(txn *badger.Txn) error {
+		iterOpts := badger.DefaultIteratorOptions
+		iterOpts.Reverse = true
+		it := txn.NewIterator(iterOpts)
+		defer it.Close()
+
+		seek := append([]byte(badgerDriftActive), 0xFF)
+		prefix := []byte(badgerDriftActive)
+		for it.Seek(seek); it.ValidForPrefix(prefix); it.Next() {
+			primaryKey, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			primaryItem, err := txn.Get(primaryKey)
+			if err != nil {
+				continue
+			}
+			var event DriftEvent
+			if err := primaryItem.Value(func(val []byte) error {
+				return json.Unmarshal(val, &event)
+			}); err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
+	return events, err
+}
+
+// IterateDriftEvents streams drift events newest-first off a single Badger
+// iterator over the drift/ primary keyspace rather than materializing the
+// whole range into a slice. Unlike GetActiveDriftEvents (which walks the
+// small, bounded active-only index), this visits every drift event ever
+// recorded, so ActiveOnly is applied as a regular filter predicate here.
+func (s *badgerStorage) IterateDriftEvents(ctx context.Context, filter DriftFilter, fn func(*DriftEvent) error) error {
+	return s.db.View(func(txn *badger.Txn) error {
+		iterOpts := badger.DefaultIteratorOptions
+		iterOpts.Reverse = true
+		it := txn.NewIterator(iterOpts)
+		defer it.Close()
+
+		seek := append([]byte(badgerDriftPrefix), 0xFF)
+		prefix := []byte(badgerDriftPrefix)
+		for it.Seek(seek); it.ValidForPrefix(prefix); it.Next() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			var event DriftEvent
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &event)
+			}); err != nil {
+				return err
+			}
+			if !filter.matches(&event) {
+				continue
+			}
+			if err := fn(&event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// =============================================================================
+// GUARDIAN STATE OPERATIONS
+// =============================================================================
+
+// updateState loads the current GuardianState (defaulting to the same zero
+// values SQLite's INSERT OR IGNORE seeds), applies mutate, and persists the
+// result - all inside a single Badger write transaction so concurrent
+// updaters never clobber each other.
+func (s *badgerStorage) updateState(mutate func(*GuardianState)) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		state := GuardianState{OverallAlignment: 1.0}
+		item, err := txn.Get([]byte(badgerStateKey))
+		switch {
+		case errors.Is(err, badger.ErrKeyNotFound):
+			// Use the zero-value default seeded above.
+		case err != nil:
+			return err
+		default:
+			if err := item.Value(func(val []byte) error {
+				return json.Unmarshal(val, &state)
+			}); err != nil {
+				return err
+			}
+		}
+
+		mutate(&state)
+
+		data, err := json.Marshal(&state)
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(badgerStateKey), data)
+	})
+}
+
+func (s *badgerStorage) GetState() (*GuardianState, error) {
+	start := time.Now()
+
+	state := GuardianState{OverallAlignment: 1.0}
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(badgerStateKey))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &state)
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guardian state: %w", err)
+	}
+
+	s.metrics.getState.record(start, 0)
+	return &state, nil
+}
+
+func (s *badgerStorage) IncrementTaskCount() (int, error) {
+	count := 0
+	err := s.updateState(func(state *GuardianState) {
+		state.TasksSinceCheck++
+		count = state.TasksSinceCheck
+	})
+	return count, err
+}
+
+func (s *badgerStorage) ResetSessionObservations() error {
+	return s.updateState(func(state *GuardianState) { state.SessionObservations = 0 })
+}
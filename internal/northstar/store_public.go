@@ -0,0 +1,200 @@
+package northstar
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Store manages the Northstar knowledge database. It is a thin wrapper
+// around a storage backend selected by NewStoreWithOptions (NewStore always
+// selects BackendSQLite), so callers never need to know which concrete
+// backend is in play.
+type Store struct {
+	backend storage
+	metrics Metrics
+}
+
+// NewStore creates or opens a Northstar knowledge store using the default
+// SQLite backend, creating nerdDir if it doesn't exist.
+func NewStore(nerdDir string) (*Store, error) {
+	return NewStoreWithOptions(nerdDir, StoreOptions{Backend: BackendSQLite, AutoCreate: true})
+}
+
+// NewStoreWithOptions creates or opens a Northstar knowledge store using the
+// backend named in opts.Backend (BackendSQLite if empty).
+func NewStoreWithOptions(dir string, opts StoreOptions) (*Store, error) {
+	if _, err := resolvePartitions(opts); err != nil {
+		return nil, err
+	}
+
+	var backend storage
+	var err error
+	switch opts.Backend {
+	case "", BackendSQLite:
+		backend, err = newSQLiteStorage(dir, opts.AutoCreate)
+	case BackendDisk:
+		backend, err = newBadgerStorage(dir, opts)
+	default:
+		return nil, fmt.Errorf("unknown northstar store backend %q", opts.Backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{backend: backend, metrics: noopMetrics{}}, nil
+}
+
+// SetMetrics installs m as this Store's instrumentation sink, replacing the
+// default no-op. See RegisterPrometheus for the Prometheus adapter.
+func (s *Store) SetMetrics(m Metrics) {
+	if m == nil {
+		m = noopMetrics{}
+	}
+	s.metrics = m
+}
+
+// Close releases the underlying storage backend.
+func (s *Store) Close() error {
+	return s.backend.Close()
+}
+
+// Path returns the storage backend's location on disk.
+func (s *Store) Path() string {
+	return s.backend.Path()
+}
+
+// StorageMetrics returns a snapshot of this Store's per-operation counts,
+// cumulative payload sizes, and cumulative durations.
+func (s *Store) StorageMetrics() StorageMetrics {
+	return s.backend.Metrics()
+}
+
+// =============================================================================
+// VISION OPERATIONS
+// =============================================================================
+
+// SaveVision stores or updates the project vision.
+func (s *Store) SaveVision(v *Vision) error {
+	start := time.Now()
+	err := s.backend.SaveVision(v)
+	s.metrics.ObserveOpDuration("save_vision", time.Since(start))
+	return err
+}
+
+// LoadVision retrieves the project vision.
+func (s *Store) LoadVision() (*Vision, error) { return s.backend.LoadVision() }
+
+// HasVision returns true if a vision is defined.
+func (s *Store) HasVision() bool { return s.backend.HasVision() }
+
+// =============================================================================
+// OBSERVATION OPERATIONS
+// =============================================================================
+
+// RecordObservation stores a new observation.
+func (s *Store) RecordObservation(obs *Observation) error {
+	start := time.Now()
+	err := s.backend.RecordObservation(obs)
+	s.metrics.ObserveOpDuration("record_observation", time.Since(start))
+	return err
+}
+
+// GetRecentObservations retrieves recent observations.
+func (s *Store) GetRecentObservations(limit int) ([]Observation, error) {
+	return s.backend.GetRecentObservations(limit)
+}
+
+// IterateObservations streams every observation matching filter, newest
+// first, calling fn once per match without ever materializing the full
+// result set - use this instead of GetRecentObservations for anything
+// larger than a UI-sized page.
+func (s *Store) IterateObservations(ctx context.Context, filter ObservationFilter, fn func(*Observation) error) error {
+	return s.backend.IterateObservations(ctx, filter, fn)
+}
+
+// =============================================================================
+// ALIGNMENT CHECK OPERATIONS
+// =============================================================================
+
+// RecordAlignmentCheck stores an alignment check result.
+func (s *Store) RecordAlignmentCheck(check *AlignmentCheck) error {
+	start := time.Now()
+	err := s.backend.RecordAlignmentCheck(check)
+	s.metrics.ObserveOpDuration("record_alignment", time.Since(start))
+	s.refreshGauges()
+	return err
+}
+
+// GetAlignmentHistory retrieves alignment check history.
+func (s *Store) GetAlignmentHistory(limit int) ([]AlignmentCheck, error) {
+	return s.backend.GetAlignmentHistory(limit)
+}
+
+// IterateAlignmentChecks streams every alignment check matching filter,
+// newest first, without materializing the full result set.
+func (s *Store) IterateAlignmentChecks(ctx context.Context, filter AlignmentFilter, fn func(*AlignmentCheck) error) error {
+	return s.backend.IterateAlignmentChecks(ctx, filter, fn)
+}
+
+// =============================================================================
+// DRIFT EVENT OPERATIONS
+// =============================================================================
+
+// RecordDriftEvent stores a drift event.
+func (s *Store) RecordDriftEvent(drift *DriftEvent) error {
+	start := time.Now()
+	err := s.backend.RecordDriftEvent(drift)
+	s.metrics.ObserveOpDuration("record_drift", time.Since(start))
+	s.refreshGauges()
+	return err
+}
+
+// ResolveDriftEvent marks a drift event as resolved.
+func (s *Store) ResolveDriftEvent(id string, resolution string) error {
+	start := time.Now()
+	err := s.backend.ResolveDriftEvent(id, resolution)
+	s.metrics.ObserveOpDuration("resolve_drift", time.Since(start))
+	s.refreshGauges()
+	return err
+}
+
+// GetActiveDriftEvents retrieves unresolved drift events.
+func (s *Store) GetActiveDriftEvents() ([]DriftEvent, error) { return s.backend.GetActiveDriftEvents() }
+
+// IterateDriftEvents streams every drift event matching filter, newest
+// first, without materializing the full result set.
+func (s *Store) IterateDriftEvents(ctx context.Context, filter DriftFilter, fn func(*DriftEvent) error) error {
+	return s.backend.IterateDriftEvents(ctx, filter, fn)
+}
+
+// =============================================================================
+// GUARDIAN STATE OPERATIONS
+// =============================================================================
+
+// GetState retrieves the current guardian state.
+func (s *Store) GetState() (*GuardianState, error) {
+	start := time.Now()
+	state, err := s.backend.GetState()
+	s.metrics.ObserveOpDuration("get_state", time.Since(start))
+	return state, err
+}
+
+// refreshGauges reports the guardian state's drift/alignment fields to the
+// configured Metrics sink. Errors are swallowed: a gauge refresh must never
+// fail the drift/alignment write it follows.
+func (s *Store) refreshGauges() {
+	state, err := s.backend.GetState()
+	if err != nil {
+		return
+	}
+	s.metrics.SetActiveDriftCount(state.ActiveDriftCount)
+	s.metrics.SetTasksSinceCheck(state.TasksSinceCheck)
+	s.metrics.SetOverallAlignment(state.OverallAlignment)
+}
+
+// IncrementTaskCount increments the task counter since last check.
+func (s *Store) IncrementTaskCount() (int, error) { return s.backend.IncrementTaskCount() }
+
+// ResetSessionObservations resets the session observation counter.
+func (s *Store) ResetSessionObservations() error { return s.backend.ResetSessionObservations() }
@@ -0,0 +1,75 @@
+package northstar
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ExportObservationsStream writes every observation matching filter to w as
+// a sequence of length-prefixed JSON frames (writeFrame), newest first, so
+// a caller can export millions of observations without ever holding them
+// all in memory at once.
+func (s *Store) ExportObservationsStream(ctx context.Context, w io.Writer, filter ObservationFilter) error {
+	return s.backend.IterateObservations(ctx, filter, func(obs *Observation) error {
+		return writeFrame(w, obs)
+	})
+}
+
+// ImportObservationsStream reads length-prefixed JSON frames from r (as
+// written by ExportObservationsStream) and records each as a new
+// observation, decoding and persisting one frame at a time rather than
+// buffering the whole stream. It returns the number of observations
+// imported.
+func (s *Store) ImportObservationsStream(r io.Reader) (int, error) {
+	count := 0
+	for {
+		var obs Observation
+		if err := readFrame(r, &obs); err != nil {
+			if errors.Is(err, io.EOF) {
+				return count, nil
+			}
+			return count, fmt.Errorf("failed to read observation frame %d: %w", count, err)
+		}
+		if err := s.RecordObservation(&obs); err != nil {
+			return count, fmt.Errorf("failed to record imported observation %d: %w", count, err)
+		}
+		count++
+	}
+}
+
+// writeFrame writes v to w as a 4-byte big-endian length prefix followed by
+// that many bytes of JSON.
+func writeFrame(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var lenPrefix [4]byte
+	binary.BigEndian.PutUint32(lenPrefix[:], uint32(len(data)))
+	if _, err := w.Write(lenPrefix[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// readFrame reads one writeFrame-encoded frame from r into v. It returns
+// io.EOF (unwrapped) when r is exhausted at a frame boundary.
+func readFrame(r io.Reader, v interface{}) error {
+	var lenPrefix [4]byte
+	if _, err := io.ReadFull(r, lenPrefix[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("truncated frame length prefix: %w", err)
+		}
+		return err
+	}
+	data := make([]byte, binary.BigEndian.Uint32(lenPrefix[:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return fmt.Errorf("truncated frame body: %w", err)
+	}
+	return json.Unmarshal(data, v)
+}
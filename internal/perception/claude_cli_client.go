@@ -31,6 +31,13 @@ func (e *RateLimitError) Error() string {
 	return fmt.Sprintf("%s rate limit exceeded", e.Provider)
 }
 
+// RetryAfterHint implements core.RateLimitClassifier so APIScheduler can
+// back off by the provider's advertised Retry-After without core needing
+// to import this package.
+func (e *RateLimitError) RetryAfterHint() time.Duration {
+	return e.RetryAfter
+}
+
 // StreamChunk represents a chunk of streaming output from Claude CLI.
 type StreamChunk struct {
 	Type    string `json:"type"`
@@ -422,7 +422,13 @@ func (c *AntigravityClient) authenticateLegacy(ctx context.Context) (string, str
 func (c *AntigravityClient) triggerNewAuth(ctx context.Context) (string, string, error) {
 	logging.PerceptionWarn("[Antigravity] Authentication required. Opening browser...")
 
-	result, err := antigravity.StartAuth()
+	srv, err := antigravity.StartCallbackServer(antigravity.CallbackOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to start callback server: %w", err)
+	}
+	defer srv.Close()
+
+	result, err := antigravity.StartAuth(srv.RedirectURI, srv.Challenge)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to start auth: %w", err)
 	}
@@ -432,12 +438,12 @@ func (c *AntigravityClient) triggerNewAuth(ctx context.Context) (string, string,
 		fmt.Printf("[Antigravity] Failed to open browser: %v. Please copy/paste the URL above.\n", err)
 	}
 
-	code, err := antigravity.WaitForCallback(ctx, result.State)
+	code, err := srv.Wait(ctx, result.State)
 	if err != nil {
 		return "", "", fmt.Errorf("auth failed: %w", err)
 	}
 
-	token, err := c.tokenManager.ExchangeCode(ctx, code, result.Verifier)
+	token, err := c.tokenManager.ExchangeCode(ctx, code, srv.Verifier, srv.RedirectURI)
 	if err != nil {
 		return "", "", fmt.Errorf("token exchange failed: %w", err)
 	}
@@ -0,0 +1,192 @@
+package perception
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CompleteStructured asks client for a response conforming to schema, a raw
+// JSON Schema object in the same map[string]interface{} shape used elsewhere
+// in this package (see client_schema.go). Schema enforcement happens via
+// prompt instructions rather than a provider-specific API field, since most
+// of this package's clients (Z.AI included, see BuildZAIPiggybackEnvelopeSchema)
+// only offer generic "json_object" mode at the API level, not per-schema
+// constraints — a prompt-level contract works uniformly across all of them.
+// The response is validated against schema and, if it doesn't conform, the
+// request is retried once with the validator's error fed back to the model.
+func CompleteStructured(ctx context.Context, client LLMClient, systemPrompt, userPrompt string, schema map[string]interface{}) (string, error) {
+	if client == nil {
+		return "", fmt.Errorf("structured completion: nil LLM client")
+	}
+
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("structured completion: failed to marshal schema: %w", err)
+	}
+
+	augmentedPrompt := userPrompt + StructuredOutputInstructions(string(schemaJSON))
+
+	response, err := client.CompleteWithSystem(ctx, systemPrompt, augmentedPrompt)
+	if err != nil {
+		return "", fmt.Errorf("structured completion: %w", err)
+	}
+
+	candidate := extractJSON(response)
+	if verr := validateJSONSchema(candidate, schema); verr == nil {
+		return candidate, nil
+	}
+
+	retryPrompt := augmentedPrompt + structuredOutputRetryInstructions(candidate)
+	response, err = client.CompleteWithSystem(ctx, systemPrompt, retryPrompt)
+	if err != nil {
+		return "", fmt.Errorf("structured completion retry: %w", err)
+	}
+
+	candidate = extractJSON(response)
+	if verr := validateJSONSchema(candidate, schema); verr != nil {
+		return "", fmt.Errorf("structured completion: response failed schema validation after retry: %w", verr)
+	}
+	return candidate, nil
+}
+
+// StructuredOutputInstructions is the prompt suffix CompleteStructured
+// appends to request schema-conformant JSON. It's exported so callers that
+// need to talk to a client's own streaming path directly (bypassing
+// CompleteStructured's request/validate/retry loop, e.g. for incremental
+// parsing as tokens arrive) can still ask for the same shape.
+func StructuredOutputInstructions(schemaJSON string) string {
+	return fmt.Sprintf("\n\nRespond with ONLY valid JSON matching this JSON Schema, with no markdown fences and no commentary:\n%s", schemaJSON)
+}
+
+func structuredOutputRetryInstructions(previousResponse string) string {
+	// The validation error itself is appended by the caller re-running
+	// validateJSONSchema; we only need to point the model back at its own
+	// output here since the error text travels via the wrapping fmt.Errorf.
+	return fmt.Sprintf("\n\nYour previous response did not match the required schema:\n%s\nReturn ONLY corrected JSON matching the schema, with no other text.", previousResponse)
+}
+
+// extractJSON strips markdown code fences around a JSON response, if present.
+func extractJSON(response string) string {
+	text := strings.TrimSpace(response)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(text, "```")
+		text = strings.TrimSpace(text)
+	}
+	return text
+}
+
+// validateJSONSchema checks jsonText against the subset of JSON Schema this
+// package uses: "type", "properties", "required", "additionalProperties",
+// and "items". It is not a general-purpose validator (no $ref, oneOf,
+// patterns, numeric bounds, etc.) — just enough to catch a model dropping a
+// required field or returning the wrong shape.
+func validateJSONSchema(jsonText string, schema map[string]interface{}) error {
+	var data interface{}
+	if err := json.Unmarshal([]byte(jsonText), &data); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return validateJSONValue(data, schema, "$")
+}
+
+func validateJSONValue(value interface{}, schema map[string]interface{}, path string) error {
+	if typ, ok := schema["type"].(string); ok {
+		if err := checkJSONType(value, typ, path); err != nil {
+			return err
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		for key, propSchemaRaw := range properties {
+			propSchema, ok := propSchemaRaw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if propValue, present := v[key]; present {
+				if err := validateJSONValue(propValue, propSchema, path+"."+key); err != nil {
+					return err
+				}
+			}
+		}
+		for _, key := range requiredFields(schema) {
+			if _, present := v[key]; !present {
+				return fmt.Errorf("%s: missing required field %q", path, key)
+			}
+		}
+		if additionalProperties, ok := schema["additionalProperties"].(bool); ok && !additionalProperties {
+			for key := range v {
+				if _, allowed := properties[key]; !allowed {
+					return fmt.Errorf("%s: unexpected field %q", path, key)
+				}
+			}
+		}
+
+	case []interface{}:
+		if itemSchema, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := validateJSONValue(item, itemSchema, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// requiredFields reads schema["required"], accepting either a literal
+// []string (how schemas are built as Go code in this package, e.g.
+// client_schema.go) or a []interface{} (how it would decode from JSON).
+func requiredFields(schema map[string]interface{}) []string {
+	switch required := schema["required"].(type) {
+	case []string:
+		return required
+	case []interface{}:
+		fields := make([]string, 0, len(required))
+		for _, r := range required {
+			if key, ok := r.(string); ok {
+				fields = append(fields, key)
+			}
+		}
+		return fields
+	default:
+		return nil
+	}
+}
+
+func checkJSONType(value interface{}, typ, path string) error {
+	switch typ {
+	case "object":
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("%s: expected object, got %T", path, value)
+		}
+	case "array":
+		if _, ok := value.([]interface{}); !ok {
+			return fmt.Errorf("%s: expected array, got %T", path, value)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected string, got %T", path, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: expected number, got %T", path, value)
+		}
+	case "integer":
+		f, ok := value.(float64)
+		if !ok || f != math.Trunc(f) {
+			return fmt.Errorf("%s: expected integer, got %v", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", path, value)
+		}
+	}
+	return nil
+}
@@ -126,7 +126,7 @@ func (r *ReviewerShard) firstPassCreative(
 
 	response, err := r.llmClient.Complete(ctx, prompt)
 	if err != nil {
-		return nil, fmt.Errorf("LLM completion failed: %w", err)
+		return nil, classifyTransient(fmt.Errorf("LLM completion failed: %w", err), 2*time.Second, "first pass LLM call")
 	}
 
 	return r.parseFirstPassResponse(response)
@@ -157,7 +157,7 @@ func (r *ReviewerShard) searchPastSuggestions(
 	// Vector search with enhancement type filter
 	vectors, err := db.VectorRecall(queryText+" enhancement_suggestion", 10)
 	if err != nil {
-		return nil, err
+		return nil, classifyTransient(err, 2*time.Second, "vector search")
 	}
 
 	for _, v := range vectors {
@@ -219,7 +219,7 @@ func (r *ReviewerShard) selfInterrogate(
 	// Execute interrogation to get questions using CompleteWithSystem
 	questionsOutput, err := r.llmClient.CompleteWithSystem(ctx, systemPrompt, taskDescription)
 	if err != nil {
-		return nil, fmt.Errorf("interrogation LLM call failed: %w", err)
+		return nil, classifyTransient(fmt.Errorf("interrogation LLM call failed: %w", err), 2*time.Second, "self-interrogation LLM call")
 	}
 
 	// Extract questions from the LLM output
@@ -385,7 +385,7 @@ func (r *ReviewerShard) secondPassCreative(
 
 	response, err := r.llmClient.Complete(ctx, prompt)
 	if err != nil {
-		return nil, fmt.Errorf("LLM completion failed: %w", err)
+		return nil, classifyTransient(fmt.Errorf("LLM completion failed: %w", err), 2*time.Second, "second pass LLM call")
 	}
 
 	return r.parseFirstPassResponse(response) // Same format as first pass
@@ -11,6 +11,10 @@ type EnhancementResult struct {
 	SystemInsights    []SystemInsight    `json:"system_insights"`
 	FeatureIdeas      []FeatureIdea      `json:"feature_ideas"`
 
+	// Proposals accepted from the suggestions above and promoted into the
+	// durable Enhancement Proposal lifecycle (see proposal.go).
+	Proposals []Proposal `json:"proposals,omitempty"`
+
 	// Self-consultation metadata
 	VectorInspiration []PastSuggestion `json:"vector_inspiration,omitempty"`
 	SelfQA            []SelfQuestion   `json:"self_qa,omitempty"`
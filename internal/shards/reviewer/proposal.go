@@ -0,0 +1,161 @@
+// Package reviewer provides code review functionality with multi-shard orchestration.
+// This file defines the Enhancement Proposal subsystem: accepted creative
+// suggestions are promoted into durable Proposal documents modeled on the
+// Kubernetes KEP/PRR lifecycle (provisional -> implementable -> implemented
+// -> deprecated), with a separate Approval document recording per-stage
+// sign-off so promotion can be gated on review.
+package reviewer
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProposalStage is one step in a Proposal's lifecycle. Stages only move
+// forward; RequiredFields below enforces what metadata must be present
+// before a proposal is allowed to sit at each one.
+type ProposalStage string
+
+const (
+	StageProvisional   ProposalStage = "provisional"
+	StageImplementable ProposalStage = "implementable"
+	StageImplemented   ProposalStage = "implemented"
+	StageDeprecated    ProposalStage = "deprecated"
+)
+
+// stageOrder gives each stage's position in the lifecycle, so Promote can
+// refuse to skip stages or move backwards.
+var stageOrder = map[ProposalStage]int{
+	StageProvisional:   0,
+	StageImplementable: 1,
+	StageImplemented:   2,
+	StageDeprecated:    3,
+}
+
+// IsValidStage reports whether stage is one of the four recognized lifecycle
+// stages.
+func (s ProposalStage) IsValidStage() bool {
+	_, ok := stageOrder[s]
+	return ok
+}
+
+// Approval captures one approver's sign-off on a proposal reaching a given
+// stage - e.g. a production-readiness review before promotion to
+// "implementable".
+type Approval struct {
+	Stage      ProposalStage `yaml:"stage" json:"stage"`
+	Approver   string        `yaml:"approver" json:"approver"`
+	Notes      string        `yaml:"notes,omitempty" json:"notes,omitempty"`
+	ApprovedAt time.Time     `yaml:"approved_at" json:"approved_at"`
+}
+
+// Proposal is a persisted Enhancement Proposal: a creative suggestion
+// (FileSuggestion, ModuleSuggestion, FeatureIdea, ...) that's been accepted
+// and is now tracked through its lifecycle with stable identity.
+type Proposal struct {
+	ID    string        `yaml:"id" json:"id"`
+	Title string        `yaml:"title" json:"title"`
+	Stage ProposalStage `yaml:"stage" json:"stage"`
+
+	// Milestone is the targeted release/milestone, e.g. "v0.9" - required
+	// once a proposal reaches StageImplementable.
+	Milestone string `yaml:"milestone,omitempty" json:"milestone,omitempty"`
+
+	// Owner is the module that owns this proposal, e.g. "internal/core" -
+	// the owning-SIG equivalent from the KEP process.
+	Owner string `yaml:"owner" json:"owner"`
+
+	// Approvers lists who must sign off before this proposal can advance.
+	Approvers []string `yaml:"approvers,omitempty" json:"approvers,omitempty"`
+
+	// SourceSuggestion identifies the EnhancementResult suggestion this
+	// proposal was created from, e.g. "file_suggestion:internal/core/x.go:0".
+	SourceSuggestion string `yaml:"source_suggestion,omitempty" json:"source_suggestion,omitempty"`
+
+	Approvals []Approval `yaml:"approvals,omitempty" json:"approvals,omitempty"`
+
+	CreatedAt time.Time `yaml:"created_at" json:"created_at"`
+	UpdatedAt time.Time `yaml:"updated_at" json:"updated_at"`
+}
+
+// RequiredFields returns the names of the metadata fields that must be
+// populated for a proposal to validly sit at stage. Each stage's
+// requirements are cumulative with the one before it.
+func RequiredFields(stage ProposalStage) []string {
+	switch stage {
+	case StageProvisional:
+		return []string{"Title", "Owner"}
+	case StageImplementable:
+		return []string{"Title", "Owner", "Milestone", "Approvers"}
+	case StageImplemented, StageDeprecated:
+		return []string{"Title", "Owner", "Milestone", "Approvers"}
+	default:
+		return nil
+	}
+}
+
+// Validate checks that p has the metadata required by its current stage,
+// and, for StageImplementable and beyond, that it carries at least one
+// recorded Approval for every stage it has passed through.
+func (p *Proposal) Validate() error {
+	if !p.Stage.IsValidStage() {
+		return fmt.Errorf("proposal %s: unknown stage %q", p.ID, p.Stage)
+	}
+	if p.ID == "" {
+		return fmt.Errorf("proposal must have an ID")
+	}
+
+	for _, field := range RequiredFields(p.Stage) {
+		if p.missingField(field) {
+			return fmt.Errorf("proposal %s: stage %q requires %s", p.ID, p.Stage, field)
+		}
+	}
+
+	if stageOrder[p.Stage] >= stageOrder[StageImplementable] {
+		if !p.hasApprovalFor(StageProvisional) {
+			return fmt.Errorf("proposal %s: stage %q requires an approval recorded at stage %q", p.ID, p.Stage, StageProvisional)
+		}
+	}
+
+	return nil
+}
+
+func (p *Proposal) missingField(field string) bool {
+	switch field {
+	case "Title":
+		return p.Title == ""
+	case "Owner":
+		return p.Owner == ""
+	case "Milestone":
+		return p.Milestone == ""
+	case "Approvers":
+		return len(p.Approvers) == 0
+	default:
+		return false
+	}
+}
+
+func (p *Proposal) hasApprovalFor(stage ProposalStage) bool {
+	for _, a := range p.Approvals {
+		if a.Stage == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// CanPromoteTo reports whether p is allowed to move to next: next must be
+// the immediate successor of p.Stage, and p must already validate against
+// next's requirements.
+func (p *Proposal) CanPromoteTo(next ProposalStage) error {
+	if !next.IsValidStage() {
+		return fmt.Errorf("unknown stage %q", next)
+	}
+	if stageOrder[next] != stageOrder[p.Stage]+1 {
+		return fmt.Errorf("proposal %s: cannot promote from %q directly to %q", p.ID, p.Stage, next)
+	}
+
+	probe := *p
+	probe.Stage = next
+	return probe.Validate()
+}
@@ -0,0 +1,43 @@
+package reviewer
+
+import (
+	"codenerd/internal/core"
+)
+
+// ProposalFacts converts a proposal into the facts rules query it by:
+// proposal_stage(id, stage) for its current lifecycle position, and
+// approved(id, stage, approver) for every sign-off it's accumulated.
+func ProposalFacts(p *Proposal) []core.Fact {
+	facts := make([]core.Fact, 0, 1+len(p.Approvals))
+
+	facts = append(facts, core.Fact{
+		Predicate: "proposal_stage",
+		Args:      []interface{}{p.ID, string(p.Stage)},
+	})
+
+	for _, a := range p.Approvals {
+		facts = append(facts, core.Fact{
+			Predicate: "approved",
+			Args:      []interface{}{p.ID, string(a.Stage), a.Approver},
+		})
+	}
+
+	return facts
+}
+
+// AssertProposalFacts asserts ProposalFacts for every proposal in proposals
+// into kernel, so Mangle rules can reason over proposal_stage/approved
+// facts alongside the rest of the reviewer's output.
+func AssertProposalFacts(kernel *core.RealKernel, proposals []Proposal) error {
+	if kernel == nil {
+		return nil
+	}
+	for i := range proposals {
+		for _, fact := range ProposalFacts(&proposals[i]) {
+			if err := kernel.Assert(fact); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package reviewer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProposalStore persists Proposal documents as YAML files under
+// <projectRoot>/.codenerd/proposals/, one file per proposal keyed by ID.
+type ProposalStore struct {
+	dir string
+}
+
+// NewProposalStore returns a store rooted at projectRoot's .codenerd/proposals
+// directory.
+func NewProposalStore(projectRoot string) *ProposalStore {
+	return &ProposalStore{dir: filepath.Join(projectRoot, ".codenerd", "proposals")}
+}
+
+func (s *ProposalStore) path(id string) string {
+	return filepath.Join(s.dir, id+".yaml")
+}
+
+// Create validates p against its current stage and persists it, refusing to
+// overwrite an existing proposal with the same ID.
+func (s *ProposalStore) Create(p *Proposal) error {
+	if p.ID == "" {
+		return fmt.Errorf("proposal must have an ID")
+	}
+	if _, err := os.Stat(s.path(p.ID)); err == nil {
+		return fmt.Errorf("proposal %s already exists", p.ID)
+	}
+
+	now := p.CreatedAt
+	if now.IsZero() {
+		now = time.Now()
+		p.CreatedAt = now
+	}
+	p.UpdatedAt = now
+	if p.Stage == "" {
+		p.Stage = StageProvisional
+	}
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	return s.save(p)
+}
+
+// Load reads the proposal with the given ID.
+func (s *ProposalStore) Load(id string) (*Proposal, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load proposal %s: %w", id, err)
+	}
+	var p Proposal
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse proposal %s: %w", id, err)
+	}
+	return &p, nil
+}
+
+// List returns every persisted proposal, sorted by ID.
+func (s *ProposalStore) List() ([]*Proposal, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read proposals directory: %w", err)
+	}
+
+	var proposals []*Proposal
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ".yaml")
+		p, err := s.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, p)
+	}
+
+	sort.Slice(proposals, func(i, j int) bool { return proposals[i].ID < proposals[j].ID })
+	return proposals, nil
+}
+
+// Promote advances the proposal identified by id to next, refusing the move
+// if the proposal's metadata doesn't yet satisfy next's requirements.
+func (s *ProposalStore) Promote(id string, next ProposalStage) (*Proposal, error) {
+	p, err := s.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CanPromoteTo(next); err != nil {
+		return nil, err
+	}
+	p.Stage = next
+	p.UpdatedAt = time.Now()
+	if err := s.save(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Approve records an Approval for the proposal identified by id and
+// persists it.
+func (s *ProposalStore) Approve(id string, approval Approval) (*Proposal, error) {
+	p, err := s.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.ApprovedAt.IsZero() {
+		approval.ApprovedAt = time.Now()
+	}
+	p.Approvals = append(p.Approvals, approval)
+	p.UpdatedAt = time.Now()
+	if err := s.save(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *ProposalStore) save(p *Proposal) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create proposals directory: %w", err)
+	}
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal proposal %s: %w", p.ID, err)
+	}
+	if err := os.WriteFile(s.path(p.ID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write proposal %s: %w", p.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package reviewer
+
+import (
+	"testing"
+)
+
+func TestProposalValidate_ProvisionalRequiresTitleAndOwner(t *testing.T) {
+	p := &Proposal{ID: "prop-1", Stage: StageProvisional}
+	if err := p.Validate(); err == nil {
+		t.Error("expected Validate to fail without Title/Owner")
+	}
+
+	p.Title = "Dedup snapshots"
+	p.Owner = "internal/core"
+	if err := p.Validate(); err != nil {
+		t.Errorf("expected Validate to pass, got %v", err)
+	}
+}
+
+func TestProposalCanPromoteTo_RequiresMilestoneAndApprovers(t *testing.T) {
+	p := &Proposal{ID: "prop-2", Stage: StageProvisional, Title: "x", Owner: "internal/core"}
+
+	if err := p.CanPromoteTo(StageImplementable); err == nil {
+		t.Error("expected promotion to implementable to fail without milestone/approvers")
+	}
+
+	p.Milestone = "v0.9"
+	p.Approvers = []string{"alice"}
+	if err := p.CanPromoteTo(StageImplementable); err == nil {
+		t.Error("expected promotion to fail without a recorded provisional approval")
+	}
+
+	p.Approvals = append(p.Approvals, Approval{Stage: StageProvisional, Approver: "alice"})
+	if err := p.CanPromoteTo(StageImplementable); err != nil {
+		t.Errorf("expected promotion to succeed, got %v", err)
+	}
+}
+
+func TestProposalCanPromoteTo_RefusesSkippingStages(t *testing.T) {
+	p := &Proposal{
+		ID: "prop-3", Stage: StageProvisional, Title: "x", Owner: "internal/core",
+		Milestone: "v0.9", Approvers: []string{"alice"},
+		Approvals: []Approval{{Stage: StageProvisional, Approver: "alice"}},
+	}
+
+	if err := p.CanPromoteTo(StageImplemented); err == nil {
+		t.Error("expected CanPromoteTo to refuse skipping implementable")
+	}
+}
+
+func TestProposalStore_CreateLoadPromoteApprove(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewProposalStore(tmpDir)
+
+	p := &Proposal{
+		ID:    "prop-4",
+		Title: "Dedup snapshots",
+		Owner: "internal/core",
+	}
+	if err := store.Create(p); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if err := store.Create(p); err == nil {
+		t.Error("expected Create to refuse a duplicate ID")
+	}
+
+	loaded, err := store.Load("prop-4")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Stage != StageProvisional {
+		t.Errorf("expected stage %q, got %q", StageProvisional, loaded.Stage)
+	}
+
+	if _, err := store.Promote("prop-4", StageImplementable); err == nil {
+		t.Error("expected Promote to fail without milestone/approvers/approval")
+	}
+
+	loaded.Milestone = "v0.9"
+	loaded.Approvers = []string{"alice"}
+	if err := store.save(loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Approve("prop-4", Approval{Stage: StageProvisional, Approver: "alice"}); err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+
+	promoted, err := store.Promote("prop-4", StageImplementable)
+	if err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	if promoted.Stage != StageImplementable {
+		t.Errorf("expected stage %q, got %q", StageImplementable, promoted.Stage)
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "prop-4" {
+		t.Errorf("expected one proposal prop-4, got %+v", list)
+	}
+}
+
+func TestProposalFacts(t *testing.T) {
+	p := &Proposal{
+		ID:        "prop-5",
+		Stage:     StageImplementable,
+		Approvals: []Approval{{Stage: StageProvisional, Approver: "alice"}},
+	}
+
+	facts := ProposalFacts(p)
+	if len(facts) != 2 {
+		t.Fatalf("expected 2 facts, got %d", len(facts))
+	}
+	if facts[0].Predicate != "proposal_stage" || facts[0].Args[1] != "implementable" {
+		t.Errorf("unexpected proposal_stage fact: %+v", facts[0])
+	}
+	if facts[1].Predicate != "approved" || facts[1].Args[2] != "alice" {
+		t.Errorf("unexpected approved fact: %+v", facts[1])
+	}
+}
@@ -0,0 +1,116 @@
+// Package reviewer - Requeue classification for transient pipeline failures.
+// This file implements a typed error that distinguishes transient failures
+// (safe to retry after a backoff) from permanent ones (bad task, parse
+// error) in the creative enhancement pipeline.
+package reviewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"codenerd/internal/logging"
+)
+
+// RequeueError marks an error as transient: the operation that produced it
+// can be retried after waiting After, rather than treated as a terminal
+// failure. Permanent is true for errors that look transient in shape
+// (network-ish error strings) but have been explicitly classified as
+// non-retryable (e.g. auth failures) - callers should treat a Permanent
+// RequeueError the same as a plain error.
+type RequeueError struct {
+	Err       error
+	After     time.Duration
+	Reason    string
+	Permanent bool
+}
+
+// Error implements the error interface.
+func (e *RequeueError) Error() string {
+	if e.Permanent {
+		return fmt.Sprintf("%s (permanent): %v", e.Reason, e.Err)
+	}
+	return fmt.Sprintf("%s (requeue after %s): %v", e.Reason, e.After, e.Err)
+}
+
+// Unwrap allows errors.Is/errors.As to see through to the underlying error.
+func (e *RequeueError) Unwrap() error {
+	return e.Err
+}
+
+// NewRequeueError wraps err as a transient failure that should be retried
+// after the given backoff, tagged with a human-readable reason (e.g.
+// "llm rate limit", "vector search timeout").
+func NewRequeueError(err error, after time.Duration, reason string) *RequeueError {
+	return &RequeueError{Err: err, After: after, Reason: reason}
+}
+
+// IsRequeue reports whether err (or anything it wraps) is a non-permanent
+// RequeueError, returning the backoff duration to honor before retrying.
+func IsRequeue(err error) (bool, time.Duration) {
+	var rq *RequeueError
+	if !errors.As(err, &rq) {
+		return false, 0
+	}
+	if rq.Permanent {
+		return false, 0
+	}
+	return true, rq.After
+}
+
+// classifyTransient wraps err as a RequeueError when it looks like a
+// retryable condition (per isRetryableError, the same classification
+// llmCompleteWithRetry uses), so producers in the creative enhancement
+// pipeline (LLM calls, vector search) can surface a backoff hint instead
+// of failing the whole enhancement step outright. Non-retryable errors are
+// returned unwrapped - they are permanent failures, not requeue candidates.
+func classifyTransient(err error, after time.Duration, reason string) error {
+	if err == nil {
+		return nil
+	}
+	if !isRetryableError(err) {
+		return err
+	}
+	return NewRequeueError(err, after, reason)
+}
+
+// maxEnhancementRequeues bounds how many times executeCreativeEnhancementWithRequeue
+// will honor a RequeueError's backoff hint before giving up and surfacing the
+// error to its caller, which treats enhancement failures as non-fatal.
+const maxEnhancementRequeues = 2
+
+// executeCreativeEnhancementWithRequeue runs the creative enhancement
+// pipeline, and when a producer inside it (LLM call, vector search) fails
+// with a transient RequeueError, waits out the hinted backoff and retries
+// instead of giving up on the first failure. Permanent errors (including
+// plain, non-RequeueError ones) are returned immediately.
+func (r *ReviewerShard) executeCreativeEnhancementWithRequeue(
+	ctx context.Context,
+	fileContents map[string]string,
+	holoCtx *HolographicContext,
+	findings []ReviewFinding,
+) (*EnhancementResult, error) {
+	var lastErr error
+	for attempt := 0; attempt <= maxEnhancementRequeues; attempt++ {
+		enhancement, err := r.ExecuteCreativeEnhancement(ctx, fileContents, holoCtx, findings)
+		if err == nil {
+			return enhancement, nil
+		}
+		lastErr = err
+
+		requeue, after := IsRequeue(err)
+		if !requeue || attempt == maxEnhancementRequeues {
+			return nil, err
+		}
+
+		logging.ReviewerDebug("Enhancement pipeline hit a transient error, requeuing after %s (attempt %d/%d): %v",
+			after, attempt+1, maxEnhancementRequeues, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(after):
+		}
+	}
+	return nil, lastErr
+}
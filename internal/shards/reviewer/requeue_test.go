@@ -0,0 +1,115 @@
+package reviewer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIsRequeue_TransientVsPermanent(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantRetry bool
+		wantAfter time.Duration
+	}{
+		{
+			name:      "plain error is not a requeue",
+			err:       errors.New("boom"),
+			wantRetry: false,
+		},
+		{
+			name:      "transient RequeueError",
+			err:       NewRequeueError(errors.New("rate limit"), 3*time.Second, "llm rate limit"),
+			wantRetry: true,
+			wantAfter: 3 * time.Second,
+		},
+		{
+			name: "permanent RequeueError is not retried",
+			err: &RequeueError{
+				Err:       errors.New("invalid api key"),
+				After:     3 * time.Second,
+				Reason:    "llm auth failure",
+				Permanent: true,
+			},
+			wantRetry: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry, after := IsRequeue(tt.err)
+			if retry != tt.wantRetry {
+				t.Errorf("IsRequeue() retry = %v, want %v", retry, tt.wantRetry)
+			}
+			if after != tt.wantAfter {
+				t.Errorf("IsRequeue() after = %v, want %v", after, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestIsRequeue_UnwrapsWrappedError(t *testing.T) {
+	rq := NewRequeueError(errors.New("connection reset"), 2*time.Second, "vector search")
+	wrapped := fmt.Errorf("searchPastSuggestions: %w", rq)
+
+	retry, after := IsRequeue(wrapped)
+	if !retry {
+		t.Fatal("IsRequeue() on a wrapped RequeueError should still report retryable")
+	}
+	if after != 2*time.Second {
+		t.Errorf("IsRequeue() after = %v, want 2s", after)
+	}
+
+	var target *RequeueError
+	if !errors.As(wrapped, &target) {
+		t.Fatal("errors.As() should see through fmt.Errorf wrapping to *RequeueError")
+	}
+}
+
+func TestClassifyTransient(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantRequeue bool
+	}{
+		{
+			name:        "nil error stays nil",
+			err:         nil,
+			wantRequeue: false,
+		},
+		{
+			name:        "timeout is classified as transient",
+			err:         errors.New("request timeout"),
+			wantRequeue: true,
+		},
+		{
+			name:        "rate limit is classified as transient",
+			err:         errors.New("429 rate limit exceeded"),
+			wantRequeue: true,
+		},
+		{
+			name:        "auth failure is not classified as transient",
+			err:         errors.New("401 unauthorized"),
+			wantRequeue: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyTransient(tt.err, time.Second, "test")
+			if tt.err == nil {
+				if got != nil {
+					t.Fatalf("classifyTransient(nil) = %v, want nil", got)
+				}
+				return
+			}
+
+			retry, _ := IsRequeue(got)
+			if retry != tt.wantRequeue {
+				t.Errorf("classifyTransient(%v) requeue = %v, want %v", tt.err, retry, tt.wantRequeue)
+			}
+		})
+	}
+}
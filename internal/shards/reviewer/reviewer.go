@@ -45,6 +45,7 @@
 //   - facts.go          - Fact generation and assertion
 //   - feedback.go       - Feedback loop integration
 //   - knowledge.go      - Knowledge base integration
+//   - requeue.go        - RequeueError, transient vs. permanent failure classification
 package reviewer
 
 import (
@@ -641,7 +642,7 @@ func (r *ReviewerShard) executeNeuroSymbolicReview(ctx context.Context, task *Re
 			holoCtx, _ = r.holographicProvider.GetContext(filesToReview[0])
 		}
 
-		enhancement, err := r.ExecuteCreativeEnhancement(ctx, fileContents, holoCtx, result.Findings)
+		enhancement, err := r.executeCreativeEnhancementWithRequeue(ctx, fileContents, holoCtx, result.Findings)
 		if err != nil {
 			logging.Get(logging.CategoryReviewer).Warn("Enhancement failed: %v", err)
 		} else {
@@ -2,6 +2,7 @@ package system
 
 import (
 	"codenerd/internal/perception"
+	"context"
 	"errors"
 )
 
@@ -16,6 +17,12 @@ func (c *Cortex) Close() error {
 
 	var errs []error
 
+	if c.Bootstrapper != nil {
+		if err := c.Bootstrapper.Shutdown(context.Background()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	if c.ShardManager != nil {
 		c.ShardManager.StopAll()
 	}
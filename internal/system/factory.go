@@ -9,6 +9,7 @@ import (
 	"codenerd/internal/config"
 	"codenerd/internal/core"
 	coreshards "codenerd/internal/core/shards"
+	corestorage "codenerd/internal/core/storage"
 	"codenerd/internal/embedding"
 	"codenerd/internal/logging"
 	"codenerd/internal/mangle"
@@ -79,6 +80,7 @@ type Cortex struct {
 	LocalDB        *store.LocalStore
 	Workspace      string
 	JITCompiler    *prompt.JITPromptCompiler
+	Bootstrapper   *core.Bootstrapper
 }
 
 // BootCortex initializes the entire system stack for a given workspace.
@@ -196,6 +198,41 @@ func BootCortex(ctx context.Context, workspace string, apiKey string, disableSys
 		fmt.Fprintf(os.Stderr, "Warning: Perception init failed: %v\n", err)
 	}
 
+	// Attach a durable store for persistent-category facts (core.IsPersistent)
+	// so they survive a process restart, and drive the quiescent-boot
+	// lifecycle (load persistent facts, reconcile ephemeral state, activate)
+	// via a Bootstrapper. SetFactStore is strictly opt-in - leaving it unset
+	// (e.g. a failed NewBadgerStore below) keeps persistence a no-op rather
+	// than failing boot.
+	persistenceCfg := appCfg.GetPersistence()
+	var bootstrapper *core.Bootstrapper
+	if persistenceCfg.Enabled != nil && *persistenceCfg.Enabled {
+		storePath := filepath.Join(workspace, ".nerd", "store")
+		if badgerStore, err := corestorage.NewBadgerStore(corestorage.Config{Path: storePath}); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to open persistent fact store: %v\n", err)
+		} else {
+			factStore := corestorage.Store(badgerStore)
+			if persistenceCfg.SealSensitive {
+				keyringPath := filepath.Join(workspace, ".nerd", "keyring.json")
+				keyProvider, keyErr := core.NewFileKeyProvider(keyringPath)
+				passphrase := os.Getenv(persistenceCfg.SealPassphraseEnv)
+				if keyErr != nil || passphrase == "" {
+					fmt.Fprintf(os.Stderr, "Warning: Sealing disabled - keyring %s or %s unavailable, falling back to unsealed store\n", keyringPath, persistenceCfg.SealPassphraseEnv)
+				} else if sealed, sealErr := core.NewSealedStore(badgerStore, keyProvider, core.DefaultClassifier(), passphrase); sealErr != nil {
+					fmt.Fprintf(os.Stderr, "Warning: Failed to seal persistent fact store: %v\n", sealErr)
+				} else {
+					factStore = sealed
+				}
+			}
+			kernel.SetFactStore(factStore)
+		}
+
+		bootstrapper = core.NewBootstrapper(kernel)
+		if err := bootstrapper.Run(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Bootstrap sequence failed: %v\n", err)
+		}
+	}
+
 	// Load persisted world facts if available.
 	// Prefer LocalStore world cache (fast depth) and fall back to scan.mg.
 	loadedWorld := false
@@ -537,6 +574,7 @@ func BootCortex(ctx context.Context, workspace string, apiKey string, disableSys
 		LocalDB:        localDB,
 		Workspace:      workspace,
 		JITCompiler:    jitCompiler,
+		Bootstrapper:   bootstrapper,
 	}, nil
 }
 
@@ -1,11 +1,14 @@
 package context_harness
 
 import (
+	"container/heap"
 	"context"
 	"fmt"
+	"math"
+	"sort"
 
-	"codenerd/internal/core"
 	internalcontext "codenerd/internal/context"
+	"codenerd/internal/core"
 )
 
 // scoredFact represents a fact with an activation score for sorting.
@@ -14,6 +17,26 @@ type scoredFact struct {
 	score float64
 }
 
+// maxRelevanceBoost is the score RetrieveContext adds when a fact matches
+// the query (see containsKeyword below).
+const maxRelevanceBoost = 30.0
+
+// scoredFactHeap is a min-heap of scoredFact ordered by score ascending, so
+// the root is always the current weakest survivor of the top-K cut.
+type scoredFactHeap []scoredFact
+
+func (h scoredFactHeap) Len() int            { return len(h) }
+func (h scoredFactHeap) Less(i, j int) bool  { return h[i].score < h[j].score }
+func (h scoredFactHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *scoredFactHeap) Push(x interface{}) { *h = append(*h, x.(scoredFact)) }
+func (h *scoredFactHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
 // MockContextEngine provides fast mock implementations for CI testing.
 // It uses simplified scoring instead of the real 7-component ActivationEngine.
 // Facts persist within a scenario but don't use real compression.
@@ -148,62 +171,87 @@ func (e *MockContextEngine) RetrieveContext(ctx context.Context, query string, t
 		}
 	}
 
-	// Score each fact
-	for _, fact := range e.facts {
-		score := 0.0
+	// Bound the heap to roughly the number of facts that could fit the
+	// token budget, plus slack so threshold filtering downstream still has
+	// a healthy pool to work with instead of exactly K candidates.
+	const avgTokensPerFact = 20
+	heapCap := int(math.Ceil(float64(tokenBudget)/avgTokensPerFact)) + heapSlack(tokenBudget, avgTokensPerFact)
+	if heapCap < 1 {
+		heapCap = 1
+	}
+
+	survivors := &scoredFactHeap{}
+	heap.Init(survivors)
 
-		// Base score: all facts start with 50 (matches real ActivationEngine)
-		score += 50
+	// Score each fact, pushing into a bounded min-heap in a single pass
+	// instead of materializing and bubble-sorting every fact.
+	for _, fact := range e.facts {
+		// Cheap components: base + recency + back-reference + predicate
+		// boost. None of these require scanning the fact's arguments, so
+		// compute them first and use them to decide whether the (more
+		// expensive) keyword scan is even worth doing.
+		score := 50.0 // Base score: all facts start with 50 (matches real ActivationEngine)
 
-		// Recency score: newer facts score higher (max +40)
 		factTurnID := -1
 		if len(fact.Args) > 0 {
 			if turnID, ok := fact.Args[0].(int); ok {
 				factTurnID = turnID
 				if maxTurnID > 0 {
 					recency := float64(turnID) / float64(maxTurnID)
-					score += recency * 40
+					score += recency * 40 // Recency score: newer facts score higher (max +40)
 				}
 			}
 		}
 
-		// CRITICAL FIX: Back-reference boost (max +50)
-		// Facts from referenced turns get a major boost to overcome recency penalty
 		if factTurnID >= 0 && referencedTurns[factTurnID] {
-			score += 50 // Significant boost to overcome low recency
-		}
-
-		// Relevance score: keyword matching with query (max +30)
-		for _, arg := range fact.Args {
-			if str, ok := arg.(string); ok {
-				if containsKeyword(query, str) {
-					score += 30
-					break // Only count once per fact
-				}
-			}
+			score += 50 // Back-reference boost: overcomes recency penalty for referenced turns
 		}
 
-		// Predicate priority boost
+		var predicateBoost float64
 		switch fact.Predicate {
 		case "turn_error_message":
-			score += 25 // Errors are important
+			predicateBoost = 25 // Errors are important
 		case "turn_topic":
-			score += 20 // Topics provide context
+			predicateBoost = 20 // Topics provide context
 		case "turn_references_file":
-			score += 15 // File refs are useful
+			predicateBoost = 15 // File refs are useful
 		case "turn_references_symbol":
-			score += 15 // Symbol refs are useful
+			predicateBoost = 15 // Symbol refs are useful
 		case "conversation_turn":
-			score += 10 // Base conversational context
+			predicateBoost = 10 // Base conversational context
 		case "turn_references_back":
-			score += 30 // Back-references are highly relevant
+			predicateBoost = 30 // Back-references are highly relevant
+		}
+		score += predicateBoost
+
+		// Short-circuit: if even the best-case relevance boost can't beat
+		// the heap's current weakest survivor, skip the keyword scan.
+		upperBound := score + maxRelevanceBoost
+		if survivors.Len() < heapCap || upperBound > (*survivors)[0].score {
+			for _, arg := range fact.Args {
+				if str, ok := arg.(string); ok {
+					if containsKeyword(query, str) {
+						score += 30 // Relevance score: keyword matching with query (max +30)
+						break       // Only count once per fact
+					}
+				}
+			}
 		}
 
-		scoredFacts = append(scoredFacts, scoredFact{fact: fact, score: score})
+		heap.Push(survivors, scoredFact{fact: fact, score: score})
+		if survivors.Len() > heapCap {
+			heap.Pop(survivors)
+		}
 	}
 
-	// Sort by score descending
-	sortByScore(scoredFacts)
+	// Drain the heap and sort only the survivors (at most heapCap of them).
+	scoredFacts = make([]scoredFact, survivors.Len())
+	for i := len(scoredFacts) - 1; i >= 0; i-- {
+		scoredFacts[i] = heap.Pop(survivors).(scoredFact)
+	}
+	sort.Slice(scoredFacts, func(i, j int) bool {
+		return scoredFacts[i].score > scoredFacts[j].score
+	})
 
 	// CRITICAL: Apply threshold filtering BEFORE budget selection
 	// This is the key fix - only facts with meaningful activation pass
@@ -220,7 +268,6 @@ func (e *MockContextEngine) RetrieveContext(ctx context.Context, query string, t
 	// Trim filtered facts to budget
 	result := make([]core.Fact, 0, len(filtered))
 	tokens := 0
-	const avgTokensPerFact = 20
 
 	for _, sf := range filtered {
 		if tokens+avgTokensPerFact > tokenBudget {
@@ -375,15 +422,28 @@ func findSubstring(query, target string) bool {
 	return false
 }
 
-// sortByScore sorts scored facts by score descending (in-place)
+// sortByScore sorts scored facts by score descending (in-place).
+// RetrieveContext no longer calls this directly (it drains a bounded
+// min-heap instead, see scoredFactHeap), but it's kept for callers that
+// already have a fully-materialized slice to sort.
 func sortByScore(facts []scoredFact) {
-	// Simple bubble sort (good enough for test harness)
-	n := len(facts)
-	for i := 0; i < n-1; i++ {
-		for j := 0; j < n-i-1; j++ {
-			if facts[j].score < facts[j+1].score {
-				facts[j], facts[j+1] = facts[j+1], facts[j]
-			}
-		}
+	sort.Slice(facts, func(i, j int) bool {
+		return facts[i].score > facts[j].score
+	})
+}
+
+// heapSlack returns extra headroom added on top of the token-budget-derived
+// heap capacity, so threshold filtering downstream still has a pool of
+// candidates to choose from instead of exactly the facts that fit the
+// budget. Scales with the budget but is bounded to keep the heap small.
+func heapSlack(tokenBudget, avgTokensPerFact int) int {
+	budgetFacts := tokenBudget / avgTokensPerFact
+	slack := budgetFacts / 2 // 50% slack
+	if slack < 5 {
+		slack = 5
+	}
+	if slack > 50 {
+		slack = 50
 	}
+	return slack
 }
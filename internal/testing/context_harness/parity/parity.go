@@ -0,0 +1,395 @@
+// Package parity contract-tests the simplified MockContextEngine scoring
+// against the real 7-component ActivationEngine. The mock exists so CI can
+// run multi-hundred-turn scenarios without a live LLM, but its scoring in
+// mock_engine.go is a hand-tuned approximation (activationThreshold=100.0,
+// per-predicate boosts, a flat recency weight of 40) that can silently drift
+// from the real engine. This package generates synthetic scenarios, runs them
+// through both engines via the shared context_harness.ContextEngine
+// interface, and measures how far the two disagree.
+package parity
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"codenerd/internal/core"
+	harness "codenerd/internal/testing/context_harness"
+)
+
+// GeneratorConfig controls procedural scenario generation.
+type GeneratorConfig struct {
+	Seed int // deterministic seed; same seed -> same scenario
+
+	MinTurns int
+	MaxTurns int
+
+	// BackRefDistances are the candidate distances (in turns) for
+	// "what was X again?" style back-references.
+	BackRefDistances []int
+
+	// Predicates is the mix of turn-metadata predicate kinds to emit.
+	// Weighted by index frequency below.
+	Predicates []string
+
+	// QueryKeywordOverlap is the fraction (0.0-1.0) of query keywords that
+	// are drawn from facts actually present in the scenario, vs. novel
+	// keywords with no match (probing false positives).
+	QueryKeywordOverlap float64
+}
+
+// DefaultGeneratorConfig returns a reasonable generator configuration.
+func DefaultGeneratorConfig() GeneratorConfig {
+	return GeneratorConfig{
+		MinTurns:            10,
+		MaxTurns:            80,
+		BackRefDistances:    []int{1, 3, 7, 15, 30},
+		Predicates:          []string{"file", "symbol", "error", "topic"},
+		QueryKeywordOverlap: 0.7,
+	}
+}
+
+// lcg is a tiny deterministic linear congruential generator so scenario
+// generation is reproducible without depending on math/rand's global state
+// (the harness re-runs the same seed across mock/real comparisons and needs
+// identical scenarios both times).
+type lcg struct{ state uint64 }
+
+func newLCG(seed int) *lcg {
+	return &lcg{state: uint64(seed)*2654435761 + 1}
+}
+
+func (g *lcg) next() uint64 {
+	g.state = g.state*6364136223846793005 + 1442695040888963407
+	return g.state
+}
+
+func (g *lcg) intn(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return int(g.next() % uint64(n))
+}
+
+func (g *lcg) float64() float64 {
+	return float64(g.next()%1000000) / 1000000.0
+}
+
+// GenerateScenario procedurally builds a harness.Scenario from cfg, varying
+// turn count, back-reference distance, predicate mix, and query keyword
+// overlap according to the seed.
+func GenerateScenario(cfg GeneratorConfig) *harness.Scenario {
+	rng := newLCG(cfg.Seed)
+
+	turnCount := cfg.MinTurns + rng.intn(cfg.MaxTurns-cfg.MinTurns+1)
+	turns := make([]harness.Turn, 0, turnCount)
+
+	topics := []string{"auth", "parser", "scheduler", "cache", "retry", "render"}
+	files := []string{"auth.go", "parser.go", "scheduler.go", "cache.go", "retry.go", "render.go"}
+	errs := []string{"nil pointer dereference", "index out of range", "deadlock", "timeout"}
+
+	for t := 0; t < turnCount; t++ {
+		meta := harness.TurnMetadata{}
+
+		switch cfg.Predicates[rng.intn(len(cfg.Predicates))] {
+		case "file":
+			meta.FilesReferenced = []string{files[rng.intn(len(files))]}
+		case "symbol":
+			meta.SymbolsReferenced = []string{fmt.Sprintf("Handle%d", rng.intn(20))}
+		case "error":
+			meta.ErrorMessages = []string{errs[rng.intn(len(errs))]}
+		case "topic":
+			meta.Topics = []string{topics[rng.intn(len(topics))]}
+		}
+
+		// Occasionally emit a back-reference to an earlier turn at one of
+		// the configured distances.
+		if t > 0 && rng.float64() < 0.15 && len(cfg.BackRefDistances) > 0 {
+			dist := cfg.BackRefDistances[rng.intn(len(cfg.BackRefDistances))]
+			if ref := t - dist; ref >= 0 {
+				meta.IsQuestionReferringBack = true
+				refTurn := ref
+				meta.ReferencesBackToTurn = &refTurn
+			}
+		}
+
+		turns = append(turns, harness.Turn{
+			TurnID:   t,
+			Speaker:  []string{"user", "assistant"}[t%2],
+			Message:  fmt.Sprintf("turn %d about %s", t, topics[rng.intn(len(topics))]),
+			Intent:   []string{"debug", "implement", "test", "refactor"}[rng.intn(4)],
+			Metadata: meta,
+		})
+	}
+
+	// Build checkpoints at ~quarter intervals, with queries drawn from
+	// in-scenario keywords (overlap) or novel ones (non-overlap probe).
+	var checkpoints []harness.Checkpoint
+	for frac := 1; frac <= 4; frac++ {
+		afterTurn := (turnCount * frac) / 4
+		if afterTurn == 0 {
+			continue
+		}
+		var query string
+		if rng.float64() < cfg.QueryKeywordOverlap {
+			query = topics[rng.intn(len(topics))]
+		} else {
+			query = fmt.Sprintf("nonexistent-keyword-%d", rng.intn(1000))
+		}
+		checkpoints = append(checkpoints, harness.Checkpoint{
+			AfterTurn:   afterTurn,
+			Query:       query,
+			Description: fmt.Sprintf("parity probe after turn %d", afterTurn),
+		})
+	}
+
+	return &harness.Scenario{
+		ScenarioID:  fmt.Sprintf("parity-gen-%d", cfg.Seed),
+		Name:        fmt.Sprintf("Generated Parity Scenario (seed %d)", cfg.Seed),
+		Description: "Procedurally generated scenario for mock/real parity checking.",
+		Turns:       turns,
+		Checkpoints: checkpoints,
+		Mode:        harness.MockMode,
+		Category:    harness.CategoryMock,
+	}
+}
+
+// ToleranceConfig sets the acceptable divergence between engines.
+type ToleranceConfig struct {
+	// MinTopKOverlap is the minimum fraction of the top-K retrieved facts
+	// (by string identity) that must agree between mock and real.
+	MinTopKOverlap float64
+	// TopK is how many top-scored facts to compare per checkpoint.
+	TopK int
+	// MinRankCorrelation is the minimum Spearman rank correlation required
+	// over facts retrieved by both engines at a checkpoint.
+	MinRankCorrelation float64
+}
+
+// DefaultTolerance returns the tolerance used by CI.
+func DefaultTolerance() ToleranceConfig {
+	return ToleranceConfig{MinTopKOverlap: 0.6, TopK: 10, MinRankCorrelation: 0.5}
+}
+
+// QueryDivergence records how much mock and real disagreed at one query.
+type QueryDivergence struct {
+	ScenarioID      string
+	Query           string
+	TopKOverlap     float64
+	RankCorrelation float64
+	MockOnly        []string
+	RealOnly        []string
+}
+
+// Report summarizes divergence across a batch of scenarios.
+type Report struct {
+	Divergences []QueryDivergence
+	WorstFirst  []QueryDivergence // same entries, sorted worst-to-best
+}
+
+// WorstK returns the k most-divergent queries (lowest combined agreement).
+func (r *Report) WorstK(k int) []QueryDivergence {
+	if k > len(r.WorstFirst) {
+		k = len(r.WorstFirst)
+	}
+	return r.WorstFirst[:k]
+}
+
+func agreementScore(d QueryDivergence) float64 {
+	return d.TopKOverlap + d.RankCorrelation
+}
+
+// Run executes scenarios against both engines and returns a divergence
+// report. It does not itself enforce tolerance; use CheckCI for the
+// fail-the-build entry point.
+func Run(ctx context.Context, kernel *core.RealKernel, mock, real harness.ContextEngine, scenarios []*harness.Scenario, tol ToleranceConfig) (*Report, error) {
+	report := &Report{}
+
+	for _, scenario := range scenarios {
+		if err := mock.Reset(); err != nil {
+			return nil, fmt.Errorf("reset mock engine: %w", err)
+		}
+		if err := real.Reset(); err != nil {
+			return nil, fmt.Errorf("reset real engine: %w", err)
+		}
+
+		turnByID := make(map[int]harness.Turn, len(scenario.Turns))
+		for _, t := range scenario.Turns {
+			turnByID[t.TurnID] = t
+		}
+		checkpointByTurn := make(map[int][]harness.Checkpoint)
+		for _, cp := range scenario.Checkpoints {
+			checkpointByTurn[cp.AfterTurn] = append(checkpointByTurn[cp.AfterTurn], cp)
+		}
+
+		for _, turn := range scenario.Turns {
+			t := turn
+			if _, _, err := mock.CompressTurn(ctx, &t); err != nil {
+				return nil, fmt.Errorf("mock CompressTurn turn %d: %w", turn.TurnID, err)
+			}
+			if _, _, err := real.CompressTurn(ctx, &t); err != nil {
+				return nil, fmt.Errorf("real CompressTurn turn %d: %w", turn.TurnID, err)
+			}
+
+			for _, cp := range checkpointByTurn[turn.TurnID] {
+				div, err := compareRetrieval(ctx, scenario.ScenarioID, cp.Query, mock, real)
+				if err != nil {
+					return nil, err
+				}
+				report.Divergences = append(report.Divergences, div)
+			}
+		}
+	}
+
+	sorted := append([]QueryDivergence(nil), report.Divergences...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return agreementScore(sorted[i]) < agreementScore(sorted[j])
+	})
+	report.WorstFirst = sorted
+
+	return report, nil
+}
+
+func compareRetrieval(ctx context.Context, scenarioID, query string, mock, real harness.ContextEngine) (QueryDivergence, error) {
+	const tokenBudget = 4000
+
+	mockFacts, err := mock.RetrieveContext(ctx, query, tokenBudget)
+	if err != nil {
+		return QueryDivergence{}, fmt.Errorf("mock RetrieveContext(%q): %w", query, err)
+	}
+	realFacts, err := real.RetrieveContext(ctx, query, tokenBudget)
+	if err != nil {
+		return QueryDivergence{}, fmt.Errorf("real RetrieveContext(%q): %w", query, err)
+	}
+
+	overlap, mockOnly, realOnly := topKOverlap(mockFacts, realFacts, 10)
+	corr := spearmanOverShared(mockFacts, realFacts)
+
+	return QueryDivergence{
+		ScenarioID:      scenarioID,
+		Query:           query,
+		TopKOverlap:     overlap,
+		RankCorrelation: corr,
+		MockOnly:        mockOnly,
+		RealOnly:        realOnly,
+	}, nil
+}
+
+// topKOverlap compares the top-k fact strings from each slice and returns
+// the overlap fraction plus the facts unique to each side.
+func topKOverlap(a, b []core.Fact, k int) (overlap float64, onlyA, onlyB []string) {
+	topA := topKStrings(a, k)
+	topB := topKStrings(b, k)
+
+	setB := make(map[string]bool, len(topB))
+	for _, s := range topB {
+		setB[s] = true
+	}
+	setA := make(map[string]bool, len(topA))
+	for _, s := range topA {
+		setA[s] = true
+	}
+
+	shared := 0
+	for _, s := range topA {
+		if setB[s] {
+			shared++
+		} else {
+			onlyA = append(onlyA, s)
+		}
+	}
+	for _, s := range topB {
+		if !setA[s] {
+			onlyB = append(onlyB, s)
+		}
+	}
+
+	denom := len(topA)
+	if len(topB) > denom {
+		denom = len(topB)
+	}
+	if denom == 0 {
+		return 1.0, onlyA, onlyB // both empty: trivially agree
+	}
+	return float64(shared) / float64(denom), onlyA, onlyB
+}
+
+func topKStrings(facts []core.Fact, k int) []string {
+	if k > len(facts) {
+		k = len(facts)
+	}
+	out := make([]string, 0, k)
+	for i := 0; i < k; i++ {
+		out = append(out, facts[i].String())
+	}
+	return out
+}
+
+// spearmanOverShared computes Spearman rank correlation between the
+// positions of facts that appear in both retrieval results, using each
+// engine's own ordering as the rank. Facts retrieved by only one engine
+// are excluded (they can't be compared). Returns 1.0 if fewer than 2
+// facts are shared (nothing to disagree about).
+func spearmanOverShared(a, b []core.Fact) float64 {
+	rankA := make(map[string]int, len(a))
+	for i, f := range a {
+		rankA[f.String()] = i
+	}
+	rankB := make(map[string]int, len(b))
+	for i, f := range b {
+		rankB[f.String()] = i
+	}
+
+	var shared []string
+	for key := range rankA {
+		if _, ok := rankB[key]; ok {
+			shared = append(shared, key)
+		}
+	}
+
+	n := len(shared)
+	if n < 2 {
+		return 1.0
+	}
+
+	var sumSqDiff float64
+	for _, key := range shared {
+		d := float64(rankA[key] - rankB[key])
+		sumSqDiff += d * d
+	}
+
+	nf := float64(n)
+	return 1.0 - (6.0*sumSqDiff)/(nf*(nf*nf-1.0))
+}
+
+// CheckCI runs scenarios against both engines and returns an error
+// describing the worst offenders when divergence exceeds tol. Intended to
+// be called from a CI-mode test or command so that mock-scoring drift
+// cannot silently invalidate scenario tests.
+func CheckCI(ctx context.Context, kernel *core.RealKernel, mock, real harness.ContextEngine, scenarios []*harness.Scenario, tol ToleranceConfig) error {
+	report, err := Run(ctx, kernel, mock, real, scenarios, tol)
+	if err != nil {
+		return err
+	}
+
+	var failures []QueryDivergence
+	for _, d := range report.Divergences {
+		if d.TopKOverlap < tol.MinTopKOverlap || d.RankCorrelation < tol.MinRankCorrelation {
+			failures = append(failures, d)
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	worst := report.WorstK(int(math.Min(5, float64(len(report.WorstFirst)))))
+	msg := fmt.Sprintf("mock/real context engine parity failed: %d/%d queries exceeded tolerance (topK>=%.2f, rankCorr>=%.2f); worst offenders:",
+		len(failures), len(report.Divergences), tol.MinTopKOverlap, tol.MinRankCorrelation)
+	for _, w := range worst {
+		msg += fmt.Sprintf("\n  [%s] query=%q topKOverlap=%.2f rankCorr=%.2f mockOnly=%d realOnly=%d",
+			w.ScenarioID, w.Query, w.TopKOverlap, w.RankCorrelation, len(w.MockOnly), len(w.RealOnly))
+	}
+	return fmt.Errorf("%s", msg)
+}
@@ -0,0 +1,114 @@
+package parity
+
+import (
+	"testing"
+
+	"codenerd/internal/core"
+)
+
+func TestGenerateScenarioDeterministic(t *testing.T) {
+	cfg := DefaultGeneratorConfig()
+	cfg.Seed = 42
+
+	a := GenerateScenario(cfg)
+	b := GenerateScenario(cfg)
+
+	if len(a.Turns) != len(b.Turns) {
+		t.Fatalf("same seed produced different turn counts: %d vs %d", len(a.Turns), len(b.Turns))
+	}
+	for i := range a.Turns {
+		if a.Turns[i].Message != b.Turns[i].Message {
+			t.Fatalf("turn %d diverged across identical seeds", i)
+		}
+	}
+}
+
+func TestGenerateScenarioVariesBySeed(t *testing.T) {
+	cfg := DefaultGeneratorConfig()
+	cfg.Seed = 1
+	a := GenerateScenario(cfg)
+	cfg.Seed = 2
+	b := GenerateScenario(cfg)
+
+	if len(a.Turns) == len(b.Turns) && a.Turns[0].Message == b.Turns[0].Message {
+		t.Fatalf("different seeds produced identical scenarios")
+	}
+}
+
+func TestTopKOverlapIdentical(t *testing.T) {
+	facts := []core.Fact{
+		{Predicate: "turn_topic", Args: []interface{}{1, "auth"}},
+		{Predicate: "turn_topic", Args: []interface{}{2, "cache"}},
+	}
+
+	overlap, onlyA, onlyB := topKOverlap(facts, facts, 10)
+	if overlap != 1.0 {
+		t.Fatalf("overlap = %.2f, want 1.0 for identical sets", overlap)
+	}
+	if len(onlyA) != 0 || len(onlyB) != 0 {
+		t.Fatalf("expected no unique facts, got onlyA=%v onlyB=%v", onlyA, onlyB)
+	}
+}
+
+func TestTopKOverlapDisjoint(t *testing.T) {
+	a := []core.Fact{{Predicate: "turn_topic", Args: []interface{}{1, "auth"}}}
+	b := []core.Fact{{Predicate: "turn_topic", Args: []interface{}{2, "cache"}}}
+
+	overlap, onlyA, onlyB := topKOverlap(a, b, 10)
+	if overlap != 0.0 {
+		t.Fatalf("overlap = %.2f, want 0.0 for disjoint sets", overlap)
+	}
+	if len(onlyA) != 1 || len(onlyB) != 1 {
+		t.Fatalf("expected one unique fact per side, got onlyA=%v onlyB=%v", onlyA, onlyB)
+	}
+}
+
+func TestSpearmanOverSharedIdenticalOrder(t *testing.T) {
+	facts := []core.Fact{
+		{Predicate: "turn_topic", Args: []interface{}{1, "auth"}},
+		{Predicate: "turn_topic", Args: []interface{}{2, "cache"}},
+		{Predicate: "turn_topic", Args: []interface{}{3, "retry"}},
+	}
+
+	corr := spearmanOverShared(facts, facts)
+	if corr != 1.0 {
+		t.Fatalf("spearmanOverShared(same, same) = %.2f, want 1.0", corr)
+	}
+}
+
+func TestSpearmanOverSharedReversedOrder(t *testing.T) {
+	a := []core.Fact{
+		{Predicate: "turn_topic", Args: []interface{}{1, "auth"}},
+		{Predicate: "turn_topic", Args: []interface{}{2, "cache"}},
+		{Predicate: "turn_topic", Args: []interface{}{3, "retry"}},
+	}
+	b := []core.Fact{a[2], a[1], a[0]}
+
+	corr := spearmanOverShared(a, b)
+	if corr != -1.0 {
+		t.Fatalf("spearmanOverShared(forward, reversed) = %.2f, want -1.0", corr)
+	}
+}
+
+func TestSpearmanOverSharedBelowTwoSharedIsTrivial(t *testing.T) {
+	a := []core.Fact{{Predicate: "turn_topic", Args: []interface{}{1, "auth"}}}
+	b := []core.Fact{{Predicate: "turn_topic", Args: []interface{}{2, "cache"}}}
+
+	if corr := spearmanOverShared(a, b); corr != 1.0 {
+		t.Fatalf("spearmanOverShared with <2 shared facts = %.2f, want 1.0 (nothing to disagree about)", corr)
+	}
+}
+
+func TestReportWorstKTakesPrefixOfWorstFirst(t *testing.T) {
+	report := &Report{
+		WorstFirst: []QueryDivergence{
+			{Query: "best", TopKOverlap: 1.0, RankCorrelation: 1.0},
+			{Query: "worst", TopKOverlap: 0.1, RankCorrelation: 0.0},
+		},
+	}
+
+	worst := report.WorstK(1)
+	if len(worst) != 1 || worst[0].Query != "best" {
+		t.Fatalf("WorstK should not re-sort an already-ordered slice; got %+v", worst)
+	}
+}
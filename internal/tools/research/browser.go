@@ -1,355 +1,812 @@
-package research
-
-import (
-	"context"
-	"encoding/base64"
-	"fmt"
-	"sync"
-
-	"codenerd/internal/browser"
-	"codenerd/internal/logging"
-	"codenerd/internal/tools"
-)
-
-// browserManager holds a shared browser session manager.
-var (
-	browserMgr     *browser.SessionManager
-	browserMgrOnce sync.Once
-	browserMgrMu   sync.Mutex
-)
-
-// getBrowserManager returns the shared browser session manager.
-func getBrowserManager() *browser.SessionManager {
-	browserMgrOnce.Do(func() {
-		browserMgr = browser.NewSessionManager(browser.DefaultConfig(), nil)
-	})
-	return browserMgr
-}
-
-// BrowserNavigateTool returns a tool for navigating to a URL with a browser.
-func BrowserNavigateTool() *tools.Tool {
-	return &tools.Tool{
-		Name:        "browser_navigate",
-		Description: "Navigate to a URL using a headless browser, useful for JavaScript-rendered pages",
-		Category:    tools.CategoryResearch,
-		Priority:    60,
-		Execute:     executeBrowserNavigate,
-		Schema: tools.ToolSchema{
-			Required: []string{"url"},
-			Properties: map[string]tools.Property{
-				"url": {
-					Type:        "string",
-					Description: "The URL to navigate to",
-				},
-				"wait_stable": {
-					Type:        "boolean",
-					Description: "Wait for page to be stable before returning (default: true)",
-					Default:     true,
-				},
-				"session_id": {
-					Type:        "string",
-					Description: "Optional session ID to reuse an existing browser session",
-				},
-			},
-		},
-	}
-}
-
-func executeBrowserNavigate(ctx context.Context, args map[string]any) (string, error) {
-	url, _ := args["url"].(string)
-	if url == "" {
-		return "", fmt.Errorf("url is required")
-	}
-
-	sessionID, _ := args["session_id"].(string)
-
-	logging.BrowserDebug("Browser navigate: url=%s, session=%s", url, sessionID)
-
-	mgr := getBrowserManager()
-
-	// Start browser if needed
-	if err := mgr.Start(ctx); err != nil {
-		return "", fmt.Errorf("failed to start browser: %w", err)
-	}
-
-	var session *browser.Session
-	var err error
-
-	if sessionID != "" {
-		// Navigate existing session
-		err = mgr.Navigate(ctx, sessionID, url)
-		if err != nil {
-			return "", fmt.Errorf("failed to navigate: %w", err)
-		}
-		sess, ok := mgr.GetSession(sessionID)
-		if !ok {
-			return "", fmt.Errorf("session not found after navigation")
-		}
-		session = &sess
-	} else {
-		// Create new session
-		session, err = mgr.CreateSession(ctx, url)
-		if err != nil {
-			return "", fmt.Errorf("failed to create session: %w", err)
-		}
-	}
-
-	logging.Browser("Browser navigated to %s (session=%s)", url, session.ID)
-
-	return fmt.Sprintf("Successfully navigated to %s\nSession ID: %s\nStatus: %s",
-		url, session.ID, session.Status), nil
-}
-
-// BrowserExtractTool returns a tool for extracting content from a browser page.
-func BrowserExtractTool() *tools.Tool {
-	return &tools.Tool{
-		Name:        "browser_extract",
-		Description: "Extract text content from the current browser page",
-		Category:    tools.CategoryResearch,
-		Priority:    55,
-		Execute:     executeBrowserExtract,
-		Schema: tools.ToolSchema{
-			Required: []string{"session_id"},
-			Properties: map[string]tools.Property{
-				"session_id": {
-					Type:        "string",
-					Description: "The browser session ID",
-				},
-				"selector": {
-					Type:        "string",
-					Description: "Optional CSS selector to extract specific element (default: body)",
-					Default:     "body",
-				},
-				"include_html": {
-					Type:        "boolean",
-					Description: "Include raw HTML in output (default: false)",
-					Default:     false,
-				},
-			},
-		},
-	}
-}
-
-func executeBrowserExtract(ctx context.Context, args map[string]any) (string, error) {
-	sessionID, _ := args["session_id"].(string)
-	if sessionID == "" {
-		return "", fmt.Errorf("session_id is required")
-	}
-
-	selector := "body"
-	if sel, ok := args["selector"].(string); ok && sel != "" {
-		selector = sel
-	}
-
-	logging.BrowserDebug("Browser extract: session=%s, selector=%s", sessionID, selector)
-
-	mgr := getBrowserManager()
-
-	page, ok := mgr.Page(sessionID)
-	if !ok {
-		return "", fmt.Errorf("session not found: %s", sessionID)
-	}
-
-	// Get text content
-	el, err := page.Element(selector)
-	if err != nil {
-		return "", fmt.Errorf("element not found: %s", selector)
-	}
-
-	text, err := el.Text()
-	if err != nil {
-		return "", fmt.Errorf("failed to get text: %w", err)
-	}
-
-	logging.Browser("Browser extract completed: %d chars", len(text))
-	return text, nil
-}
-
-// BrowserScreenshotTool returns a tool for capturing screenshots.
-func BrowserScreenshotTool() *tools.Tool {
-	return &tools.Tool{
-		Name:        "browser_screenshot",
-		Description: "Capture a screenshot of the current browser page",
-		Category:    tools.CategoryResearch,
-		Priority:    50,
-		Execute:     executeBrowserScreenshot,
-		Schema: tools.ToolSchema{
-			Required: []string{"session_id"},
-			Properties: map[string]tools.Property{
-				"session_id": {
-					Type:        "string",
-					Description: "The browser session ID",
-				},
-				"full_page": {
-					Type:        "boolean",
-					Description: "Capture full page or just viewport (default: false)",
-					Default:     false,
-				},
-			},
-		},
-	}
-}
-
-func executeBrowserScreenshot(ctx context.Context, args map[string]any) (string, error) {
-	sessionID, _ := args["session_id"].(string)
-	if sessionID == "" {
-		return "", fmt.Errorf("session_id is required")
-	}
-
-	fullPage := false
-	if fp, ok := args["full_page"].(bool); ok {
-		fullPage = fp
-	}
-
-	logging.BrowserDebug("Browser screenshot: session=%s, full_page=%v", sessionID, fullPage)
-
-	mgr := getBrowserManager()
-
-	data, err := mgr.Screenshot(ctx, sessionID, fullPage)
-	if err != nil {
-		return "", fmt.Errorf("failed to capture screenshot: %w", err)
-	}
-
-	// Return base64-encoded image
-	encoded := base64.StdEncoding.EncodeToString(data)
-
-	logging.Browser("Browser screenshot captured: %d bytes", len(data))
-	return fmt.Sprintf("data:image/png;base64,%s", encoded), nil
-}
-
-// BrowserClickTool returns a tool for clicking elements.
-func BrowserClickTool() *tools.Tool {
-	return &tools.Tool{
-		Name:        "browser_click",
-		Description: "Click an element on the page",
-		Category:    tools.CategoryResearch,
-		Priority:    50,
-		Execute:     executeBrowserClick,
-		Schema: tools.ToolSchema{
-			Required: []string{"session_id", "selector"},
-			Properties: map[string]tools.Property{
-				"session_id": {
-					Type:        "string",
-					Description: "The browser session ID",
-				},
-				"selector": {
-					Type:        "string",
-					Description: "CSS selector for the element to click",
-				},
-			},
-		},
-	}
-}
-
-func executeBrowserClick(ctx context.Context, args map[string]any) (string, error) {
-	sessionID, _ := args["session_id"].(string)
-	if sessionID == "" {
-		return "", fmt.Errorf("session_id is required")
-	}
-
-	selector, _ := args["selector"].(string)
-	if selector == "" {
-		return "", fmt.Errorf("selector is required")
-	}
-
-	logging.BrowserDebug("Browser click: session=%s, selector=%s", sessionID, selector)
-
-	mgr := getBrowserManager()
-
-	if err := mgr.Click(ctx, sessionID, selector); err != nil {
-		return "", fmt.Errorf("failed to click: %w", err)
-	}
-
-	logging.Browser("Browser clicked: %s", selector)
-	return fmt.Sprintf("Clicked element: %s", selector), nil
-}
-
-// BrowserTypeTool returns a tool for typing into input fields.
-func BrowserTypeTool() *tools.Tool {
-	return &tools.Tool{
-		Name:        "browser_type",
-		Description: "Type text into an input field",
-		Category:    tools.CategoryResearch,
-		Priority:    50,
-		Execute:     executeBrowserType,
-		Schema: tools.ToolSchema{
-			Required: []string{"session_id", "selector", "text"},
-			Properties: map[string]tools.Property{
-				"session_id": {
-					Type:        "string",
-					Description: "The browser session ID",
-				},
-				"selector": {
-					Type:        "string",
-					Description: "CSS selector for the input element",
-				},
-				"text": {
-					Type:        "string",
-					Description: "Text to type",
-				},
-			},
-		},
-	}
-}
-
-func executeBrowserType(ctx context.Context, args map[string]any) (string, error) {
-	sessionID, _ := args["session_id"].(string)
-	if sessionID == "" {
-		return "", fmt.Errorf("session_id is required")
-	}
-
-	selector, _ := args["selector"].(string)
-	if selector == "" {
-		return "", fmt.Errorf("selector is required")
-	}
-
-	text, _ := args["text"].(string)
-	if text == "" {
-		return "", fmt.Errorf("text is required")
-	}
-
-	logging.BrowserDebug("Browser type: session=%s, selector=%s, text_len=%d", sessionID, selector, len(text))
-
-	mgr := getBrowserManager()
-
-	if err := mgr.Type(ctx, sessionID, selector, text); err != nil {
-		return "", fmt.Errorf("failed to type: %w", err)
-	}
-
-	logging.Browser("Browser typed %d chars into %s", len(text), selector)
-	return fmt.Sprintf("Typed %d characters into: %s", len(text), selector), nil
-}
-
-// BrowserCloseTool returns a tool for closing browser sessions.
-func BrowserCloseTool() *tools.Tool {
-	return &tools.Tool{
-		Name:        "browser_close",
-		Description: "Close a browser session",
-		Category:    tools.CategoryResearch,
-		Priority:    40,
-		Execute:     executeBrowserClose,
-		Schema: tools.ToolSchema{
-			Required: []string{"session_id"},
-			Properties: map[string]tools.Property{
-				"session_id": {
-					Type:        "string",
-					Description: "The browser session ID to close",
-				},
-			},
-		},
-	}
-}
-
-func executeBrowserClose(ctx context.Context, args map[string]any) (string, error) {
-	sessionID, _ := args["session_id"].(string)
-	if sessionID == "" {
-		return "", fmt.Errorf("session_id is required")
-	}
-
-	logging.BrowserDebug("Browser close: session=%s", sessionID)
-
-	// Note: The browser package doesn't have a direct close session method,
-	// so we just log this for now. The session will be cleaned up on shutdown.
-	logging.Browser("Browser session marked for close: %s", sessionID)
-	return fmt.Sprintf("Session %s marked for close", sessionID), nil
-}
+package research
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"codenerd/internal/browser"
+	"codenerd/internal/logging"
+	"codenerd/internal/tools"
+)
+
+// browserManager holds a shared browser session manager.
+var (
+	browserMgr     *browser.SessionManager
+	browserMgrOnce sync.Once
+	browserMgrMu   sync.Mutex
+)
+
+// getBrowserManager returns the shared browser session manager.
+func getBrowserManager() *browser.SessionManager {
+	browserMgrOnce.Do(func() {
+		browserMgr = browser.NewSessionManager(browser.DefaultConfig(), nil)
+	})
+	return browserMgr
+}
+
+// currentPage holds the page/session ID most recently selected by
+// browser_switch_page, used as the fallback target for tools that are
+// called without an explicit session_id/page_id/context_id.
+var (
+	currentPage   string
+	currentPageMu sync.Mutex
+)
+
+func setCurrentPageID(id string) {
+	currentPageMu.Lock()
+	defer currentPageMu.Unlock()
+	currentPage = id
+}
+
+func currentPageID() string {
+	currentPageMu.Lock()
+	defer currentPageMu.Unlock()
+	return currentPage
+}
+
+// resolveSessionID picks the page a tool call should act on: an explicit
+// session_id or page_id wins, then the most recently active page in
+// context_id, then the page last selected by browser_switch_page. Returns ""
+// with no error if none of these are set; callers that require an existing
+// page (extract/click/type) should treat that as a validation error, while
+// navigate treats it as "create a new page".
+func resolveSessionID(mgr *browser.SessionManager, args map[string]any) (string, error) {
+	if sid, _ := args["session_id"].(string); sid != "" {
+		return sid, nil
+	}
+	if pid, _ := args["page_id"].(string); pid != "" {
+		return pid, nil
+	}
+	if cid, _ := args["context_id"].(string); cid != "" {
+		pages, err := mgr.ListPages(cid)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve context %s: %w", cid, err)
+		}
+		if len(pages) == 0 {
+			return "", fmt.Errorf("context %s has no pages", cid)
+		}
+		latest := pages[0]
+		for _, p := range pages[1:] {
+			if p.LastActive.After(latest.LastActive) {
+				latest = p
+			}
+		}
+		return latest.ID, nil
+	}
+	return currentPageID(), nil
+}
+
+// BrowserNavigateTool returns a tool for navigating to a URL with a browser.
+func BrowserNavigateTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_navigate",
+		Description: "Navigate to a URL using a headless browser, useful for JavaScript-rendered pages",
+		Category:    tools.CategoryResearch,
+		Priority:    60,
+		Execute:     executeBrowserNavigate,
+		Schema: tools.ToolSchema{
+			Required: []string{"url"},
+			Properties: map[string]tools.Property{
+				"url": {
+					Type:        "string",
+					Description: "The URL to navigate to",
+				},
+				"wait_stable": {
+					Type:        "boolean",
+					Description: "Wait for page to be stable before returning (default: true)",
+					Default:     true,
+				},
+				"session_id": {
+					Type:        "string",
+					Description: "Optional session/page ID to reuse an existing browser page",
+				},
+				"page_id": {
+					Type:        "string",
+					Description: "Alias for session_id",
+				},
+				"context_id": {
+					Type:        "string",
+					Description: "Optional context ID; navigates the context's most recently active page if session_id/page_id aren't given",
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserNavigate(ctx context.Context, args map[string]any) (string, error) {
+	url, _ := args["url"].(string)
+	if url == "" {
+		return "", fmt.Errorf("url is required")
+	}
+
+	mgr := getBrowserManager()
+
+	sessionID, err := resolveSessionID(mgr, args)
+	if err != nil {
+		return "", err
+	}
+
+	logging.BrowserDebug("Browser navigate: url=%s, session=%s", url, sessionID)
+
+	// Start browser if needed
+	if err := mgr.Start(ctx); err != nil {
+		return "", fmt.Errorf("failed to start browser: %w", err)
+	}
+
+	var session *browser.Session
+
+	if sessionID != "" {
+		// Navigate existing session
+		if err := mgr.Navigate(ctx, sessionID, url); err != nil {
+			return "", fmt.Errorf("failed to navigate: %w", err)
+		}
+		sess, ok := mgr.GetSession(sessionID)
+		if !ok {
+			return "", fmt.Errorf("session not found after navigation")
+		}
+		session = &sess
+	} else {
+		// Create new session
+		session, err = mgr.CreateSession(ctx, url)
+		if err != nil {
+			return "", fmt.Errorf("failed to create session: %w", err)
+		}
+	}
+
+	setCurrentPageID(session.ID)
+
+	logging.Browser("Browser navigated to %s (session=%s)", url, session.ID)
+
+	return fmt.Sprintf("Successfully navigated to %s\nSession ID: %s\nStatus: %s",
+		url, session.ID, session.Status), nil
+}
+
+// BrowserExtractTool returns a tool for extracting content from a browser page.
+func BrowserExtractTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_extract",
+		Description: "Extract text content from the current browser page",
+		Category:    tools.CategoryResearch,
+		Priority:    55,
+		Execute:     executeBrowserExtract,
+		Schema: tools.ToolSchema{
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session/page ID",
+				},
+				"page_id": {
+					Type:        "string",
+					Description: "Alias for session_id",
+				},
+				"context_id": {
+					Type:        "string",
+					Description: "Context ID to extract from its most recently active page if session_id/page_id aren't given",
+				},
+				"selector": {
+					Type:        "string",
+					Description: "Optional CSS selector to extract specific element (default: body)",
+					Default:     "body",
+				},
+				"include_html": {
+					Type:        "boolean",
+					Description: "Include raw HTML in output (default: false)",
+					Default:     false,
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserExtract(ctx context.Context, args map[string]any) (string, error) {
+	mgr := getBrowserManager()
+	sessionID, err := resolveSessionID(mgr, args)
+	if err != nil {
+		return "", err
+	}
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id, page_id, or context_id is required")
+	}
+
+	selector := "body"
+	if sel, ok := args["selector"].(string); ok && sel != "" {
+		selector = sel
+	}
+
+	logging.BrowserDebug("Browser extract: session=%s, selector=%s", sessionID, selector)
+
+	page, ok := mgr.Page(sessionID)
+	if !ok {
+		return "", fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	// Get text content
+	el, err := page.Element(selector)
+	if err != nil {
+		return "", fmt.Errorf("element not found: %s", selector)
+	}
+
+	text, err := el.Text()
+	if err != nil {
+		return "", fmt.Errorf("failed to get text: %w", err)
+	}
+
+	logging.Browser("Browser extract completed: %d chars", len(text))
+	return text, nil
+}
+
+// BrowserScreenshotTool returns a tool for capturing screenshots.
+func BrowserScreenshotTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_screenshot",
+		Description: "Capture a screenshot of the current browser page",
+		Category:    tools.CategoryResearch,
+		Priority:    50,
+		Execute:     executeBrowserScreenshot,
+		Schema: tools.ToolSchema{
+			Required: []string{"session_id"},
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session ID",
+				},
+				"full_page": {
+					Type:        "boolean",
+					Description: "Capture full page or just viewport (default: false)",
+					Default:     false,
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserScreenshot(ctx context.Context, args map[string]any) (string, error) {
+	sessionID, _ := args["session_id"].(string)
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id is required")
+	}
+
+	fullPage := false
+	if fp, ok := args["full_page"].(bool); ok {
+		fullPage = fp
+	}
+
+	logging.BrowserDebug("Browser screenshot: session=%s, full_page=%v", sessionID, fullPage)
+
+	mgr := getBrowserManager()
+
+	data, err := mgr.Screenshot(ctx, sessionID, fullPage)
+	if err != nil {
+		return "", fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+
+	// Return base64-encoded image
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	logging.Browser("Browser screenshot captured: %d bytes", len(data))
+	return fmt.Sprintf("data:image/png;base64,%s", encoded), nil
+}
+
+// BrowserClickTool returns a tool for clicking elements.
+func BrowserClickTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_click",
+		Description: "Click an element on the page",
+		Category:    tools.CategoryResearch,
+		Priority:    50,
+		Execute:     executeBrowserClick,
+		Schema: tools.ToolSchema{
+			Required: []string{"selector"},
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session/page ID",
+				},
+				"page_id": {
+					Type:        "string",
+					Description: "Alias for session_id",
+				},
+				"context_id": {
+					Type:        "string",
+					Description: "Context ID to click in its most recently active page if session_id/page_id aren't given",
+				},
+				"selector": {
+					Type:        "string",
+					Description: "CSS selector for the element to click",
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserClick(ctx context.Context, args map[string]any) (string, error) {
+	mgr := getBrowserManager()
+	sessionID, err := resolveSessionID(mgr, args)
+	if err != nil {
+		return "", err
+	}
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id, page_id, or context_id is required")
+	}
+
+	selector, _ := args["selector"].(string)
+	if selector == "" {
+		return "", fmt.Errorf("selector is required")
+	}
+
+	logging.BrowserDebug("Browser click: session=%s, selector=%s", sessionID, selector)
+
+	if err := mgr.Click(ctx, sessionID, selector); err != nil {
+		return "", fmt.Errorf("failed to click: %w", err)
+	}
+
+	logging.Browser("Browser clicked: %s", selector)
+	return fmt.Sprintf("Clicked element: %s", selector), nil
+}
+
+// BrowserTypeTool returns a tool for typing into input fields.
+func BrowserTypeTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_type",
+		Description: "Type text into an input field",
+		Category:    tools.CategoryResearch,
+		Priority:    50,
+		Execute:     executeBrowserType,
+		Schema: tools.ToolSchema{
+			Required: []string{"selector", "text"},
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session/page ID",
+				},
+				"page_id": {
+					Type:        "string",
+					Description: "Alias for session_id",
+				},
+				"context_id": {
+					Type:        "string",
+					Description: "Context ID to type in its most recently active page if session_id/page_id aren't given",
+				},
+				"selector": {
+					Type:        "string",
+					Description: "CSS selector for the input element",
+				},
+				"text": {
+					Type:        "string",
+					Description: "Text to type",
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserType(ctx context.Context, args map[string]any) (string, error) {
+	mgr := getBrowserManager()
+	sessionID, err := resolveSessionID(mgr, args)
+	if err != nil {
+		return "", err
+	}
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id, page_id, or context_id is required")
+	}
+
+	selector, _ := args["selector"].(string)
+	if selector == "" {
+		return "", fmt.Errorf("selector is required")
+	}
+
+	text, _ := args["text"].(string)
+	if text == "" {
+		return "", fmt.Errorf("text is required")
+	}
+
+	logging.BrowserDebug("Browser type: session=%s, selector=%s, text_len=%d", sessionID, selector, len(text))
+
+	if err := mgr.Type(ctx, sessionID, selector, text); err != nil {
+		return "", fmt.Errorf("failed to type: %w", err)
+	}
+
+	logging.Browser("Browser typed %d chars into %s", len(text), selector)
+	return fmt.Sprintf("Typed %d characters into: %s", len(text), selector), nil
+}
+
+// BrowserCloseTool returns a tool for closing browser sessions.
+func BrowserCloseTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_close",
+		Description: "Close a browser session",
+		Category:    tools.CategoryResearch,
+		Priority:    40,
+		Execute:     executeBrowserClose,
+		Schema: tools.ToolSchema{
+			Required: []string{"session_id"},
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session ID to close",
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserClose(ctx context.Context, args map[string]any) (string, error) {
+	sessionID, _ := args["session_id"].(string)
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id is required")
+	}
+
+	logging.BrowserDebug("Browser close: session=%s", sessionID)
+
+	// Note: The browser package doesn't have a direct close session method,
+	// so we just log this for now. The session will be cleaned up on shutdown.
+	logging.Browser("Browser session marked for close: %s", sessionID)
+	return fmt.Sprintf("Session %s marked for close", sessionID), nil
+}
+
+// BrowserNewPageTool returns a tool that opens a new page, either in a fresh
+// isolated browser context or an existing one (for multiple tabs sharing
+// cookies/storage).
+func BrowserNewPageTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_new_page",
+		Description: "Open a new browser page/tab, optionally inside an existing isolated context so it shares that context's cookies/storage",
+		Category:    tools.CategoryResearch,
+		Priority:    55,
+		Execute:     executeBrowserNewPage,
+		Schema: tools.ToolSchema{
+			Properties: map[string]tools.Property{
+				"url": {
+					Type:        "string",
+					Description: "URL to open (default: about:blank)",
+					Default:     "about:blank",
+				},
+				"context_id": {
+					Type:        "string",
+					Description: "Existing context ID to open the page in; if omitted, a fresh isolated context is created",
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserNewPage(ctx context.Context, args map[string]any) (string, error) {
+	url, _ := args["url"].(string)
+	if url == "" {
+		url = "about:blank"
+	}
+	contextID, _ := args["context_id"].(string)
+
+	mgr := getBrowserManager()
+	if err := mgr.Start(ctx); err != nil {
+		return "", fmt.Errorf("failed to start browser: %w", err)
+	}
+
+	if contextID == "" {
+		bctx, err := mgr.NewContext(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to create browser context: %w", err)
+		}
+		contextID = bctx.ID
+	}
+
+	session, err := mgr.NewPage(ctx, contextID, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to open page: %w", err)
+	}
+
+	setCurrentPageID(session.ID)
+
+	logging.Browser("Browser opened page %s in context %s (url=%s)", session.ID, contextID, url)
+	return fmt.Sprintf("Opened page\nContext ID: %s\nPage ID: %s\nURL: %s", contextID, session.ID, url), nil
+}
+
+// BrowserListPagesTool returns a tool for listing the pages open in a
+// browser context.
+func BrowserListPagesTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_list_pages",
+		Description: "List the pages/tabs open in a browser context",
+		Category:    tools.CategoryResearch,
+		Priority:    45,
+		Execute:     executeBrowserListPages,
+		Schema: tools.ToolSchema{
+			Required: []string{"context_id"},
+			Properties: map[string]tools.Property{
+				"context_id": {
+					Type:        "string",
+					Description: "The browser context ID to list pages for",
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserListPages(ctx context.Context, args map[string]any) (string, error) {
+	contextID, _ := args["context_id"].(string)
+	if contextID == "" {
+		return "", fmt.Errorf("context_id is required")
+	}
+
+	mgr := getBrowserManager()
+	pages, err := mgr.ListPages(contextID)
+	if err != nil {
+		return "", fmt.Errorf("failed to list pages: %w", err)
+	}
+	if len(pages) == 0 {
+		return fmt.Sprintf("Context %s has no pages", contextID), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Pages in context %s:\n", contextID))
+	for _, p := range pages {
+		sb.WriteString(fmt.Sprintf("  %s  [%s] %s\n", p.ID, p.Status, p.URL))
+	}
+	return sb.String(), nil
+}
+
+// BrowserSwitchPageTool returns a tool that sets the page other browser
+// tools should default to when called without an explicit session_id.
+func BrowserSwitchPageTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_switch_page",
+		Description: "Switch the current page other browser_* tools default to when session_id/page_id isn't given",
+		Category:    tools.CategoryResearch,
+		Priority:    45,
+		Execute:     executeBrowserSwitchPage,
+		Schema: tools.ToolSchema{
+			Required: []string{"session_id"},
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The page/session ID to make current",
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserSwitchPage(ctx context.Context, args map[string]any) (string, error) {
+	sessionID, _ := args["session_id"].(string)
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id is required")
+	}
+
+	mgr := getBrowserManager()
+	if _, ok := mgr.GetSession(sessionID); !ok {
+		return "", fmt.Errorf("unknown page: %s", sessionID)
+	}
+
+	setCurrentPageID(sessionID)
+	logging.Browser("Browser switched current page to %s", sessionID)
+	return fmt.Sprintf("Switched current page to: %s", sessionID), nil
+}
+
+// browserActionStepTypes lists every step type BrowserRunActionsTool accepts.
+// executeBrowserRunActions validates every step against this set before
+// running any of them, so a typo'd action type fails fast instead of
+// partway through a run that already had side effects.
+var browserActionStepTypes = map[string]bool{
+	"navigate":          true,
+	"waitForSelector":   true,
+	"waitForNavigation": true,
+	"click":             true,
+	"type":              true,
+	"select":            true,
+	"press":             true,
+	"evaluate":          true,
+	"extract":           true,
+	"screenshot":        true,
+	"sleep":             true,
+	"assert":            true,
+}
+
+// browserActionResult is one entry in BrowserRunActionsTool's accumulated
+// run log, returned even when a step fails so the LLM can see what happened
+// up to (and including) the failure.
+type browserActionResult struct {
+	Index    int    `json:"index"`
+	Type     string `json:"type"`
+	Success  bool   `json:"success"`
+	Output   string `json:"output,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// BrowserRunActionsTool returns a tool that executes an ordered script of
+// page actions against one session in a single call, so callers that need
+// to log in and paginate don't pay a tool round-trip per step.
+func BrowserRunActionsTool() *tools.Tool {
+	return &tools.Tool{
+		Name: "browser_actions",
+		Description: "Run an ordered list of page actions against one browser session in a single call. " +
+			"Each step is an object with a \"type\" (navigate, waitForSelector, waitForNavigation, click, type, " +
+			"select, press, evaluate, extract, screenshot, sleep, assert), plus type-specific fields " +
+			"(url, selector, text, value, key, script, attribute, full_page, ms, contains) and an optional " +
+			"\"optional\": true to let the run continue past that step's failure. Stops at the first failed " +
+			"non-optional step but always returns the full step-by-step log.",
+		Category: tools.CategoryResearch,
+		Priority: 58,
+		Execute:  executeBrowserRunActions,
+		Schema: tools.ToolSchema{
+			Required: []string{"session_id", "steps"},
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session/page ID to run the actions against",
+				},
+				"steps": {
+					Type:        "array",
+					Description: "Ordered list of step objects, see tool description for the supported shapes",
+					Items:       &tools.PropertyItems{Type: "object"},
+				},
+				"timeout_ms": {
+					Type:        "number",
+					Description: "Per-step timeout in milliseconds (default: 10000)",
+					Default:     10000,
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserRunActions(ctx context.Context, args map[string]any) (string, error) {
+	sessionID, _ := args["session_id"].(string)
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id is required")
+	}
+
+	rawSteps, ok := args["steps"].([]interface{})
+	if !ok || len(rawSteps) == 0 {
+		return "", fmt.Errorf("steps is required and must be a non-empty array")
+	}
+
+	steps := make([]map[string]any, 0, len(rawSteps))
+	for i, raw := range rawSteps {
+		step, ok := raw.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("step %d: expected an object", i)
+		}
+		stepType, _ := step["type"].(string)
+		if !browserActionStepTypes[stepType] {
+			return "", fmt.Errorf("step %d: unknown action type %q", i, stepType)
+		}
+		steps = append(steps, step)
+	}
+
+	timeoutMs := 10000
+	if v, ok := args["timeout_ms"].(float64); ok && v > 0 {
+		timeoutMs = int(v)
+	}
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+
+	mgr := getBrowserManager()
+	if err := mgr.Start(ctx); err != nil {
+		return "", fmt.Errorf("failed to start browser: %w", err)
+	}
+
+	log := make([]browserActionResult, 0, len(steps))
+	for i, step := range steps {
+		stepType, _ := step["type"].(string)
+		optional, _ := step["optional"].(bool)
+
+		result := browserActionResult{Index: i, Type: stepType, Optional: optional}
+		output, err := runBrowserActionStep(ctx, mgr, sessionID, stepType, step, timeout)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Success = true
+			result.Output = output
+		}
+		log = append(log, result)
+
+		if err != nil && !optional {
+			logging.Browser("Browser actions: step %d (%s) failed, stopping run: %v", i, stepType, err)
+			break
+		}
+	}
+
+	encoded, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode action log: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// runBrowserActionStep dispatches a single validated step to its
+// browser.SessionManager handler under a fresh per-step timeout.
+func runBrowserActionStep(ctx context.Context, mgr *browser.SessionManager, sessionID, stepType string, step map[string]any, timeout time.Duration) (string, error) {
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	selector, _ := step["selector"].(string)
+
+	switch stepType {
+	case "navigate":
+		url, _ := step["url"].(string)
+		if url == "" {
+			return "", fmt.Errorf("navigate: url is required")
+		}
+		return "", mgr.Navigate(stepCtx, sessionID, url)
+	case "waitForSelector":
+		if selector == "" {
+			return "", fmt.Errorf("waitForSelector: selector is required")
+		}
+		return "", mgr.WaitForSelector(stepCtx, sessionID, selector)
+	case "waitForNavigation":
+		return "", mgr.WaitForNavigation(stepCtx, sessionID)
+	case "click":
+		if selector == "" {
+			return "", fmt.Errorf("click: selector is required")
+		}
+		return "", mgr.Click(stepCtx, sessionID, selector)
+	case "type":
+		text, _ := step["text"].(string)
+		if selector == "" || text == "" {
+			return "", fmt.Errorf("type: selector and text are required")
+		}
+		return "", mgr.Type(stepCtx, sessionID, selector, text)
+	case "select":
+		value, _ := step["value"].(string)
+		if selector == "" {
+			return "", fmt.Errorf("select: selector is required")
+		}
+		return "", mgr.Select(stepCtx, sessionID, selector, value)
+	case "press":
+		key, _ := step["key"].(string)
+		if key == "" {
+			return "", fmt.Errorf("press: key is required")
+		}
+		return "", mgr.Press(stepCtx, sessionID, key)
+	case "evaluate":
+		script, _ := step["script"].(string)
+		if script == "" {
+			return "", fmt.Errorf("evaluate: script is required")
+		}
+		return mgr.Evaluate(stepCtx, sessionID, script)
+	case "extract":
+		if selector == "" {
+			return "", fmt.Errorf("extract: selector is required")
+		}
+		attribute, _ := step["attribute"].(string)
+		return mgr.ExtractAttribute(stepCtx, sessionID, selector, attribute)
+	case "screenshot":
+		fullPage, _ := step["full_page"].(bool)
+		data, err := mgr.Screenshot(stepCtx, sessionID, fullPage)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("data:image/png;base64,%s", base64.StdEncoding.EncodeToString(data)), nil
+	case "sleep":
+		ms, _ := step["ms"].(float64)
+		select {
+		case <-stepCtx.Done():
+			return "", stepCtx.Err()
+		case <-time.After(time.Duration(ms) * time.Millisecond):
+			return "", nil
+		}
+	case "assert":
+		if selector == "" {
+			return "", fmt.Errorf("assert: selector is required")
+		}
+		wantText, _ := step["contains"].(string)
+		ok, err := mgr.AssertSelector(stepCtx, sessionID, selector, wantText)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			if wantText == "" {
+				return "", fmt.Errorf("assertion failed: selector %s not found", selector)
+			}
+			return "", fmt.Errorf("assertion failed: selector %s does not contain %q", selector, wantText)
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown action type: %s", stepType)
+	}
+}
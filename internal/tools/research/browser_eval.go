@@ -0,0 +1,100 @@
+package research
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"codenerd/internal/logging"
+	"codenerd/internal/tools"
+)
+
+// BrowserEvalTool returns a tool that runs an arbitrary JS expression
+// against a browser session's page and returns its result coerced to JSON,
+// for cases the fixed extract/click/type set can't reach (computed styles,
+// SPA router actions, values behind Shadow DOM).
+func BrowserEvalTool() *tools.Tool {
+	return &tools.Tool{
+		Name: "browser_eval",
+		Description: "Evaluate a JS expression in a browser session's page (Runtime.evaluate, returnByValue + " +
+			"awaitPromise) and return the result as JSON. \"args\" (any JSON value) is available to the " +
+			"expression as a local `args` binding. Output is capped at 256 KB with a truncation marker.",
+		Category: tools.CategoryResearch,
+		Priority: 55,
+		Execute:  executeBrowserEval,
+		Schema: tools.ToolSchema{
+			Required: []string{"session_id", "expression"},
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session/page ID to evaluate the expression in",
+				},
+				"expression": {
+					Type:        "string",
+					Description: "A JS expression (not a statement list) to evaluate, e.g. \"document.title\" or \"args.x + 1\"",
+				},
+				"args": {
+					Type:        "object",
+					Description: "Optional JSON value made available to the expression as `args`",
+				},
+				"world": {
+					Type:        "string",
+					Description: "JS execution context: \"main\" (default) or \"isolated\"",
+					Enum:        []string{"main", "isolated"},
+					Default:     "main",
+				},
+				"timeout_ms": {
+					Type:        "number",
+					Description: "Evaluation timeout in milliseconds (default: 10000)",
+					Default:     10000,
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserEval(ctx context.Context, args map[string]any) (string, error) {
+	sessionID, _ := args["session_id"].(string)
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id is required")
+	}
+	expression, _ := args["expression"].(string)
+	if expression == "" {
+		return "", fmt.Errorf("expression is required")
+	}
+
+	world, _ := args["world"].(string)
+	if world == "" {
+		world = "main"
+	}
+
+	argsJSON := ""
+	if rawArgs, ok := args["args"]; ok && rawArgs != nil {
+		encoded, err := json.Marshal(rawArgs)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode args: %w", err)
+		}
+		argsJSON = string(encoded)
+	}
+
+	timeoutMs := 10000
+	if v, ok := args["timeout_ms"].(float64); ok && v > 0 {
+		timeoutMs = int(v)
+	}
+	evalCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+
+	mgr := getBrowserManager()
+	if err := mgr.Start(ctx); err != nil {
+		return "", fmt.Errorf("failed to start browser: %w", err)
+	}
+
+	result, err := mgr.EvaluateExpr(evalCtx, sessionID, expression, argsJSON, world)
+	if err != nil {
+		return "", fmt.Errorf("eval failed: %w", err)
+	}
+
+	logging.Browser("Browser eval: session=%s world=%s result_bytes=%d", sessionID, world, len(result))
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package research
+
+import (
+	"context"
+	"fmt"
+
+	"codenerd/internal/browser"
+	"codenerd/internal/logging"
+	"codenerd/internal/tools"
+)
+
+// BrowserInterceptTool returns a tool that enables CDP request interception
+// for a session: matching requests can be blocked or have headers rewritten,
+// and every request/response is buffered for later export via
+// browser_export_har.
+func BrowserInterceptTool() *tools.Tool {
+	return &tools.Tool{
+		Name: "browser_intercept",
+		Description: "Enable request interception on a browser session. Rules are an array of objects with " +
+			"optional \"url_glob\" (shell-style glob matched against the request URL), \"resource_types\" " +
+			"(e.g. image, font, media, stylesheet), \"block\": true to fail matching requests, and/or " +
+			"\"set_headers\" to merge headers (e.g. inject Authorization) into matching requests. " +
+			"All requests are recorded (timings, status, mimeType, sizes, truncated POST body) for " +
+			"browser_export_har regardless of whether a rule matches them.",
+		Category: tools.CategoryResearch,
+		Priority: 50,
+		Execute:  executeBrowserIntercept,
+		Schema: tools.ToolSchema{
+			Required: []string{"session_id"},
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session/page ID to intercept requests for",
+				},
+				"rules": {
+					Type:        "array",
+					Description: "Ordered list of rule objects, see tool description for the supported shapes",
+					Items:       &tools.PropertyItems{Type: "object"},
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserIntercept(ctx context.Context, args map[string]any) (string, error) {
+	sessionID, _ := args["session_id"].(string)
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id is required")
+	}
+
+	var rules []browser.InterceptRule
+	if rawRules, ok := args["rules"].([]interface{}); ok {
+		for i, raw := range rawRules {
+			ruleMap, ok := raw.(map[string]any)
+			if !ok {
+				return "", fmt.Errorf("rule %d: expected an object", i)
+			}
+			rule := browser.InterceptRule{}
+			rule.URLGlob, _ = ruleMap["url_glob"].(string)
+			rule.Block, _ = ruleMap["block"].(bool)
+			if rawTypes, ok := ruleMap["resource_types"].([]interface{}); ok {
+				for _, t := range rawTypes {
+					if s, ok := t.(string); ok {
+						rule.ResourceTypes = append(rule.ResourceTypes, s)
+					}
+				}
+			}
+			if rawHeaders, ok := ruleMap["set_headers"].(map[string]any); ok {
+				rule.SetHeaders = make(map[string]string, len(rawHeaders))
+				for k, v := range rawHeaders {
+					if s, ok := v.(string); ok {
+						rule.SetHeaders[k] = s
+					}
+				}
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	mgr := getBrowserManager()
+	if err := mgr.Start(ctx); err != nil {
+		return "", fmt.Errorf("failed to start browser: %w", err)
+	}
+
+	if err := mgr.EnableIntercept(ctx, sessionID, rules); err != nil {
+		return "", fmt.Errorf("failed to enable intercept: %w", err)
+	}
+
+	logging.Browser("Browser intercept enabled: session=%s rules=%d", sessionID, len(rules))
+	return fmt.Sprintf("Intercept enabled for session %s with %d rule(s)", sessionID, len(rules)), nil
+}
+
+// BrowserExportHARTool returns a tool that dumps a session's buffered
+// request/response log as HAR 1.2 JSON.
+func BrowserExportHARTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_export_har",
+		Description: "Export a browser session's intercepted requests/responses as a HAR 1.2 JSON document",
+		Category:    tools.CategoryResearch,
+		Priority:    45,
+		Execute:     executeBrowserExportHAR,
+		Schema: tools.ToolSchema{
+			Required: []string{"session_id"},
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session/page ID whose HAR log to export (must have had browser_intercept enabled)",
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserExportHAR(ctx context.Context, args map[string]any) (string, error) {
+	sessionID, _ := args["session_id"].(string)
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id is required")
+	}
+
+	mgr := getBrowserManager()
+	har, err := mgr.ExportHAR(sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to export HAR: %w", err)
+	}
+
+	logging.Browser("Browser HAR exported: session=%s bytes=%d", sessionID, len(har))
+	return string(har), nil
+}
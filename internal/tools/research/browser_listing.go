@@ -0,0 +1,136 @@
+package research
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"codenerd/internal/browser"
+	"codenerd/internal/logging"
+	"codenerd/internal/tools"
+)
+
+// BrowserListingParseTool returns a tool that parses an Apache/nginx-style
+// directory-listing page (or nginx's "autoindex_format json" output) into
+// structured file entries, optionally recursing into subdirectories.
+func BrowserListingParseTool() *tools.Tool {
+	return &tools.Tool{
+		Name: "browser_listing_parse",
+		Description: "Parse a directory-listing page (Apache mod_autoindex, nginx autoindex, or nginx's " +
+			"autoindex_format json) into [{name, is_dir, size, mod_time, href}] entries. Pass \"session_id\" to " +
+			"read the currently-loaded page (for listings that need JS to render), or \"html\" plus \"base_url\" " +
+			"to parse a raw string without a browser. With \"session_id\" and \"max_depth\" > 0, navigates into " +
+			"each subdirectory and recurses, returning a flattened listing with nested entries' names prefixed " +
+			"by their path. \"include\"/\"exclude\" are glob patterns (filepath.Match) applied to entry names; " +
+			"exclude wins on conflict.",
+		Category: tools.CategoryResearch,
+		Priority: 50,
+		Execute:  executeBrowserListingParse,
+		Schema: tools.ToolSchema{
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session/page ID to read the listing from",
+				},
+				"html": {
+					Type:        "string",
+					Description: "Raw directory-listing HTML or JSON to parse instead of a browser session",
+				},
+				"base_url": {
+					Type:        "string",
+					Description: "Base URL to resolve entry hrefs against when parsing \"html\" directly",
+				},
+				"max_depth": {
+					Type:        "integer",
+					Description: "How many levels of subdirectories to recurse into (session_id only, default: 0)",
+					Default:     0,
+				},
+				"include": {
+					Type:        "array",
+					Description: "Glob patterns; an entry must match at least one to be kept (default: keep all)",
+					Items:       &tools.PropertyItems{Type: "string"},
+				},
+				"exclude": {
+					Type:        "array",
+					Description: "Glob patterns; an entry matching any of these is dropped",
+					Items:       &tools.PropertyItems{Type: "string"},
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserListingParse(ctx context.Context, args map[string]any) (string, error) {
+	sessionID, _ := args["session_id"].(string)
+	rawHTML, _ := args["html"].(string)
+	if sessionID == "" && rawHTML == "" {
+		return "", fmt.Errorf("either session_id or html is required")
+	}
+
+	include := stringSliceArg(args["include"])
+	exclude := stringSliceArg(args["exclude"])
+
+	var entries []browser.FileEntry
+	var err error
+
+	if rawHTML != "" {
+		baseURL, _ := args["base_url"].(string)
+		entries, err = browser.ParseListing(rawHTML, baseURL)
+		if err == nil {
+			entries = filterEntries(entries, include, exclude)
+		}
+	} else {
+		maxDepth := 0
+		if v, ok := args["max_depth"].(float64); ok && v > 0 {
+			maxDepth = int(v)
+		}
+
+		mgr := getBrowserManager()
+		if startErr := mgr.Start(ctx); startErr != nil {
+			return "", fmt.Errorf("failed to start browser: %w", startErr)
+		}
+		entries, err = mgr.ListDirectory(ctx, sessionID, maxDepth, include, exclude)
+	}
+	if err != nil {
+		return "", fmt.Errorf("parse listing failed: %w", err)
+	}
+
+	logging.Browser("Browser listing parse: session=%s entries=%d", sessionID, len(entries))
+
+	encoded, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode listing: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// filterEntries applies include/exclude globs to file entries parsed
+// directly from "html" (ListDirectory applies the same filter itself when
+// recursing, since it needs to know which directories to skip entering).
+func filterEntries(entries []browser.FileEntry, include, exclude []string) []browser.FileEntry {
+	if len(include) == 0 && len(exclude) == 0 {
+		return entries
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if !e.IsDir && !browser.MatchesGlobs(e.Name, include, exclude) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+func stringSliceArg(raw any) []string {
+	rawSlice, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(rawSlice))
+	for _, v := range rawSlice {
+		if s, ok := v.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
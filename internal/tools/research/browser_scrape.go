@@ -0,0 +1,123 @@
+package research
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"codenerd/internal/browser"
+	"codenerd/internal/logging"
+	"codenerd/internal/tools"
+)
+
+// BrowserScrapeTool returns a tool that extracts a typed JSON structure from
+// the current page using a declarative field schema, instead of the single
+// flat text blob browser_extract returns.
+func BrowserScrapeTool() *tools.Tool {
+	return &tools.Tool{
+		Name: "browser_scrape",
+		Description: "Extract structured data from a browser session's page using a field schema: a map of " +
+			"field name to {\"selector\", \"attr\" or \"html\": true or \"text\": true, \"multiple\": true to " +
+			"collect every match, \"transform\": \"trim\"|\"number\"|\"url_absolute\"|\"regex:<pattern>:<group>\", " +
+			"\"children\": a nested schema evaluated relative to each matched element}. Returns " +
+			"{fields, missing, page_url, page_title}. Use \"children\" with \"multiple\" to pull a list of " +
+			"repeated items (e.g. product cards) in one call.",
+		Category: tools.CategoryResearch,
+		Priority: 55,
+		Execute:  executeBrowserScrape,
+		Schema: tools.ToolSchema{
+			Required: []string{"session_id", "schema"},
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session/page ID to scrape",
+				},
+				"schema": {
+					Type:        "object",
+					Description: "Map of field name to field spec, see tool description for the supported shape",
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserScrape(ctx context.Context, args map[string]any) (string, error) {
+	sessionID, _ := args["session_id"].(string)
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id is required")
+	}
+
+	rawSchema, ok := args["schema"].(map[string]any)
+	if !ok || len(rawSchema) == 0 {
+		return "", fmt.Errorf("schema is required and must be a non-empty object")
+	}
+
+	schema, err := parseScrapeSchema(rawSchema)
+	if err != nil {
+		return "", err
+	}
+
+	mgr := getBrowserManager()
+	if err := mgr.Start(ctx); err != nil {
+		return "", fmt.Errorf("failed to start browser: %w", err)
+	}
+
+	fields, missing, pageURL, pageTitle, err := mgr.Scrape(ctx, sessionID, schema)
+	if err != nil {
+		return "", fmt.Errorf("scrape failed: %w", err)
+	}
+
+	logging.Browser("Browser scrape: session=%s fields=%d missing=%d", sessionID, len(fields), len(missing))
+
+	result := map[string]interface{}{
+		"fields":     fields,
+		"missing":    missing,
+		"page_url":   pageURL,
+		"page_title": pageTitle,
+	}
+	encoded, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode scrape result: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// parseScrapeSchema converts a JSON-decoded map[string]any field schema into
+// browser.FieldSpec values, recursing into "children" sub-schemas.
+func parseScrapeSchema(raw map[string]any) (map[string]browser.FieldSpec, error) {
+	schema := make(map[string]browser.FieldSpec, len(raw))
+	for name, rawSpec := range raw {
+		specMap, ok := rawSpec.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field %q: expected an object", name)
+		}
+		spec, err := parseFieldSpec(name, specMap)
+		if err != nil {
+			return nil, err
+		}
+		schema[name] = spec
+	}
+	return schema, nil
+}
+
+func parseFieldSpec(name string, specMap map[string]any) (browser.FieldSpec, error) {
+	spec := browser.FieldSpec{}
+	spec.Selector, _ = specMap["selector"].(string)
+	if spec.Selector == "" {
+		return spec, fmt.Errorf("field %q: selector is required", name)
+	}
+	spec.Attr, _ = specMap["attr"].(string)
+	spec.HTML, _ = specMap["html"].(bool)
+	spec.Text, _ = specMap["text"].(bool)
+	spec.Multiple, _ = specMap["multiple"].(bool)
+	spec.Transform, _ = specMap["transform"].(string)
+
+	if rawChildren, ok := specMap["children"].(map[string]any); ok {
+		children, err := parseScrapeSchema(rawChildren)
+		if err != nil {
+			return spec, err
+		}
+		spec.Children = children
+	}
+	return spec, nil
+}
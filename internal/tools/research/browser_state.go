@@ -0,0 +1,221 @@
+package research
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"codenerd/internal/logging"
+	"codenerd/internal/tools"
+)
+
+// browserStateNamePattern restricts storage-state names to safe filename
+// characters, since the name becomes part of a path under browserStateDir.
+var browserStateNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// browserStateDir returns (creating if needed) the directory storage-state
+// snapshots are saved under: ~/.codenerd/browser-state.
+func browserStateDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	dir := filepath.Join(home, ".codenerd", "browser-state")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create browser-state dir: %w", err)
+	}
+	return dir, nil
+}
+
+func browserStatePath(name string) (string, error) {
+	if !browserStateNamePattern.MatchString(name) {
+		return "", fmt.Errorf("name must match %s", browserStateNamePattern.String())
+	}
+	dir, err := browserStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// encryptState encrypts data with AES-256-GCM under a key derived from
+// passphrase via a plain SHA-256 hash - adequate to keep a stored OAuth
+// cookie snapshot from being read in the clear off disk, not a
+// passphrase-guessing-resistant KDF like scrypt/argon2.
+func encryptState(data []byte, passphrase string) ([]byte, error) {
+	key := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// decryptState reverses encryptState.
+func decryptState(data []byte, passphrase string) ([]byte, error) {
+	key := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted state is too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// BrowserSaveStateTool returns a tool that persists a session's cookies and
+// localStorage/sessionStorage to disk so an interactive login can be
+// re-entered headlessly in a later run.
+func BrowserSaveStateTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_save_state",
+		Description: "Save a browser session's cookies and storage to ~/.codenerd/browser-state/<name>.json for later reuse, optionally passphrase-encrypted",
+		Category:    tools.CategoryResearch,
+		Priority:    45,
+		Execute:     executeBrowserSaveState,
+		Schema: tools.ToolSchema{
+			Required: []string{"session_id", "name"},
+			Properties: map[string]tools.Property{
+				"session_id": {
+					Type:        "string",
+					Description: "The browser session/page ID to snapshot",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name for the saved state file (alphanumeric, '_' and '-' only)",
+				},
+				"passphrase": {
+					Type:        "string",
+					Description: "Optional passphrase to AES-GCM encrypt the saved state (e.g. for OAuth cookies)",
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserSaveState(ctx context.Context, args map[string]any) (string, error) {
+	sessionID, _ := args["session_id"].(string)
+	if sessionID == "" {
+		return "", fmt.Errorf("session_id is required")
+	}
+	name, _ := args["name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	passphrase, _ := args["passphrase"].(string)
+
+	path, err := browserStatePath(name)
+	if err != nil {
+		return "", err
+	}
+
+	mgr := getBrowserManager()
+	data, err := mgr.ExportState(sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to export state: %w", err)
+	}
+
+	if passphrase != "" {
+		data, err = encryptState(data, passphrase)
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt state: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	logging.Browser("Browser state saved: session=%s name=%s encrypted=%v", sessionID, name, passphrase != "")
+	return fmt.Sprintf("Saved state for session %s to %s", sessionID, path), nil
+}
+
+// BrowserLoadStateTool returns a tool that restores a previously-saved
+// storage state into a new session.
+func BrowserLoadStateTool() *tools.Tool {
+	return &tools.Tool{
+		Name:        "browser_load_state",
+		Description: "Create a new browser session from a previously-saved ~/.codenerd/browser-state/<name>.json snapshot",
+		Category:    tools.CategoryResearch,
+		Priority:    45,
+		Execute:     executeBrowserLoadState,
+		Schema: tools.ToolSchema{
+			Required: []string{"name"},
+			Properties: map[string]tools.Property{
+				"name": {
+					Type:        "string",
+					Description: "Name of the saved state file to load",
+				},
+				"url": {
+					Type:        "string",
+					Description: "URL to navigate to (default: the URL the state was saved from)",
+				},
+				"passphrase": {
+					Type:        "string",
+					Description: "Passphrase the state was encrypted with, if any",
+				},
+			},
+		},
+	}
+}
+
+func executeBrowserLoadState(ctx context.Context, args map[string]any) (string, error) {
+	name, _ := args["name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	url, _ := args["url"].(string)
+	passphrase, _ := args["passphrase"].(string)
+
+	path, err := browserStatePath(name)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	if passphrase != "" {
+		data, err = decryptState(data, passphrase)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt state (wrong passphrase?): %w", err)
+		}
+	}
+
+	mgr := getBrowserManager()
+	if err := mgr.Start(ctx); err != nil {
+		return "", fmt.Errorf("failed to start browser: %w", err)
+	}
+
+	session, err := mgr.CreateSessionWithState(ctx, url, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to restore state: %w", err)
+	}
+
+	setCurrentPageID(session.ID)
+
+	logging.Browser("Browser state loaded: name=%s session=%s", name, session.ID)
+	return fmt.Sprintf("Restored state %q into new session\nSession ID: %s\nURL: %s", name, session.ID, session.URL), nil
+}
@@ -20,6 +20,17 @@ func RegisterAll(registry *tools.Registry) error {
 		BrowserClickTool(),
 		BrowserTypeTool(),
 		BrowserCloseTool(),
+		BrowserNewPageTool(),
+		BrowserListPagesTool(),
+		BrowserSwitchPageTool(),
+		BrowserRunActionsTool(),
+		BrowserSaveStateTool(),
+		BrowserLoadStateTool(),
+		BrowserInterceptTool(),
+		BrowserExportHARTool(),
+		BrowserScrapeTool(),
+		BrowserEvalTool(),
+		BrowserListingParseTool(),
 
 		// Caching
 		CacheGetTool(),
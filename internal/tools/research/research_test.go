@@ -2,6 +2,7 @@ package research
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 )
@@ -79,6 +80,323 @@ func TestBrowserCloseTool_Definition(t *testing.T) {
 	}
 }
 
+func TestBrowserNewPageTool_Definition(t *testing.T) {
+	t.Parallel()
+
+	tool := BrowserNewPageTool()
+
+	if tool == nil {
+		t.Fatal("expected non-nil tool")
+	}
+	if tool.Name != "browser_new_page" {
+		t.Errorf("Name mismatch: got %q", tool.Name)
+	}
+}
+
+func TestBrowserListPagesTool_Definition(t *testing.T) {
+	t.Parallel()
+
+	tool := BrowserListPagesTool()
+
+	if tool == nil {
+		t.Fatal("expected non-nil tool")
+	}
+	if tool.Name != "browser_list_pages" {
+		t.Errorf("Name mismatch: got %q", tool.Name)
+	}
+}
+
+func TestBrowserSwitchPageTool_Definition(t *testing.T) {
+	t.Parallel()
+
+	tool := BrowserSwitchPageTool()
+
+	if tool == nil {
+		t.Fatal("expected non-nil tool")
+	}
+	if tool.Name != "browser_switch_page" {
+		t.Errorf("Name mismatch: got %q", tool.Name)
+	}
+}
+
+func TestExecuteBrowserListPages_MissingContextID(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserListPages(context.Background(), map[string]any{})
+	if err == nil {
+		t.Error("expected error for missing context_id")
+	}
+}
+
+func TestExecuteBrowserSwitchPage_MissingSessionID(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserSwitchPage(context.Background(), map[string]any{})
+	if err == nil {
+		t.Error("expected error for missing session_id")
+	}
+}
+
+func TestBrowserRunActionsTool_Definition(t *testing.T) {
+	t.Parallel()
+
+	tool := BrowserRunActionsTool()
+
+	if tool == nil {
+		t.Fatal("expected non-nil tool")
+	}
+	if tool.Name != "browser_actions" {
+		t.Errorf("Name mismatch: got %q", tool.Name)
+	}
+}
+
+func TestExecuteBrowserRunActions_MissingSteps(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserRunActions(context.Background(), map[string]any{
+		"session_id": "sess-1",
+	})
+	if err == nil {
+		t.Error("expected error for missing steps")
+	}
+}
+
+func TestExecuteBrowserRunActions_UnknownStepType(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserRunActions(context.Background(), map[string]any{
+		"session_id": "sess-1",
+		"steps": []interface{}{
+			map[string]any{"type": "teleport"},
+		},
+	})
+	if err == nil {
+		t.Error("expected validation error for unknown step type before any step runs")
+	}
+}
+
+func TestBrowserSaveStateTool_Definition(t *testing.T) {
+	t.Parallel()
+
+	tool := BrowserSaveStateTool()
+
+	if tool == nil {
+		t.Fatal("expected non-nil tool")
+	}
+	if tool.Name != "browser_save_state" {
+		t.Errorf("Name mismatch: got %q", tool.Name)
+	}
+}
+
+func TestBrowserLoadStateTool_Definition(t *testing.T) {
+	t.Parallel()
+
+	tool := BrowserLoadStateTool()
+
+	if tool == nil {
+		t.Fatal("expected non-nil tool")
+	}
+	if tool.Name != "browser_load_state" {
+		t.Errorf("Name mismatch: got %q", tool.Name)
+	}
+}
+
+func TestExecuteBrowserSaveState_MissingSessionID(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserSaveState(context.Background(), map[string]any{
+		"name": "my-login",
+	})
+	if err == nil {
+		t.Error("expected error for missing session_id")
+	}
+}
+
+func TestExecuteBrowserSaveState_MissingName(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserSaveState(context.Background(), map[string]any{
+		"session_id": "sess-1",
+	})
+	if err == nil {
+		t.Error("expected error for missing name")
+	}
+}
+
+func TestExecuteBrowserLoadState_MissingName(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserLoadState(context.Background(), map[string]any{})
+	if err == nil {
+		t.Error("expected error for missing name")
+	}
+}
+
+func TestExecuteBrowserSaveState_InvalidName(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserSaveState(context.Background(), map[string]any{
+		"session_id": "sess-1",
+		"name":       "../../etc/passwd",
+	})
+	if err == nil {
+		t.Error("expected error for unsafe name")
+	}
+}
+
+func TestBrowserInterceptTool_Definition(t *testing.T) {
+	t.Parallel()
+
+	tool := BrowserInterceptTool()
+
+	if tool == nil {
+		t.Fatal("expected non-nil tool")
+	}
+	if tool.Name != "browser_intercept" {
+		t.Errorf("Name mismatch: got %q", tool.Name)
+	}
+}
+
+func TestBrowserExportHARTool_Definition(t *testing.T) {
+	t.Parallel()
+
+	tool := BrowserExportHARTool()
+
+	if tool == nil {
+		t.Fatal("expected non-nil tool")
+	}
+	if tool.Name != "browser_export_har" {
+		t.Errorf("Name mismatch: got %q", tool.Name)
+	}
+}
+
+func TestExecuteBrowserIntercept_MissingSessionID(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserIntercept(context.Background(), map[string]any{})
+	if err == nil {
+		t.Error("expected error for missing session_id")
+	}
+}
+
+func TestExecuteBrowserExportHAR_MissingSessionID(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserExportHAR(context.Background(), map[string]any{})
+	if err == nil {
+		t.Error("expected error for missing session_id")
+	}
+}
+
+func TestExecuteBrowserExportHAR_UnknownSession(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserExportHAR(context.Background(), map[string]any{
+		"session_id": "no-such-session",
+	})
+	if err == nil {
+		t.Error("expected error for a session with no intercept log")
+	}
+}
+
+func TestBrowserScrapeTool_Definition(t *testing.T) {
+	t.Parallel()
+
+	tool := BrowserScrapeTool()
+
+	if tool == nil {
+		t.Fatal("expected non-nil tool")
+	}
+	if tool.Name != "browser_scrape" {
+		t.Errorf("Name mismatch: got %q", tool.Name)
+	}
+}
+
+func TestExecuteBrowserScrape_MissingSchema(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserScrape(context.Background(), map[string]any{
+		"session_id": "sess-1",
+	})
+	if err == nil {
+		t.Error("expected error for missing schema")
+	}
+}
+
+func TestParseScrapeSchema_MissingSelector(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseScrapeSchema(map[string]any{
+		"title": map[string]any{"attr": "href"},
+	})
+	if err == nil {
+		t.Error("expected error for a field with no selector")
+	}
+}
+
+func TestParseScrapeSchema_NestedChildren(t *testing.T) {
+	t.Parallel()
+
+	schema, err := parseScrapeSchema(map[string]any{
+		"products": map[string]any{
+			"selector": ".product",
+			"multiple": true,
+			"children": map[string]any{
+				"title": map[string]any{"selector": ".title"},
+				"price": map[string]any{"selector": ".price", "transform": "number"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	products, ok := schema["products"]
+	if !ok {
+		t.Fatal("expected \"products\" field in schema")
+	}
+	if !products.Multiple {
+		t.Error("expected products.Multiple to be true")
+	}
+	if len(products.Children) != 2 {
+		t.Errorf("expected 2 children, got %d", len(products.Children))
+	}
+}
+
+func TestBrowserEvalTool_Definition(t *testing.T) {
+	t.Parallel()
+
+	tool := BrowserEvalTool()
+
+	if tool == nil {
+		t.Fatal("expected non-nil tool")
+	}
+	if tool.Name != "browser_eval" {
+		t.Errorf("Name mismatch: got %q", tool.Name)
+	}
+}
+
+func TestExecuteBrowserEval_MissingExpression(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserEval(context.Background(), map[string]any{
+		"session_id": "sess-1",
+	})
+	if err == nil {
+		t.Error("expected error for missing expression")
+	}
+}
+
+func TestExecuteBrowserEval_MissingSessionID(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserEval(context.Background(), map[string]any{
+		"expression": "document.title",
+	})
+	if err == nil {
+		t.Error("expected error for missing session_id")
+	}
+}
+
 // =============================================================================
 // WEB SEARCH TOOL TESTS
 // =============================================================================
@@ -318,6 +636,50 @@ func TestExecuteCacheGet_MissingKey(t *testing.T) {
 	}
 }
 
+func TestBrowserListingParseTool_Definition(t *testing.T) {
+	t.Parallel()
+
+	tool := BrowserListingParseTool()
+
+	if tool == nil {
+		t.Fatal("expected non-nil tool")
+	}
+	if tool.Name != "browser_listing_parse" {
+		t.Errorf("Name mismatch: got %q", tool.Name)
+	}
+}
+
+func TestExecuteBrowserListingParse_MissingSessionAndHTML(t *testing.T) {
+	t.Parallel()
+
+	_, err := executeBrowserListingParse(context.Background(), map[string]any{})
+	if err == nil {
+		t.Error("expected error when neither session_id nor html is given")
+	}
+}
+
+func TestExecuteBrowserListingParse_RawHTML(t *testing.T) {
+	t.Parallel()
+
+	out, err := executeBrowserListingParse(context.Background(), map[string]any{
+		"html": `<html><body><pre>
+<a href="../">../</a>
+<a href="sub/">sub/</a>                               27-Jul-2026 10:00    -
+<a href="readme.txt">readme.txt</a>                     27-Jul-2026 10:01  123
+</pre></body></html>`,
+		"base_url": "https://example.com/dist/",
+	})
+	if err != nil {
+		t.Fatalf("executeBrowserListingParse failed: %v", err)
+	}
+	if !strings.Contains(out, "readme.txt") {
+		t.Errorf("expected readme.txt in output, got %s", out)
+	}
+	if strings.Contains(out, `"name": "../"`) {
+		t.Error("parent-directory link should not be included as an entry")
+	}
+}
+
 // =============================================================================
 // REGISTER ALL TEST
 // =============================================================================